@@ -56,6 +56,11 @@ func (au *AuthUsecase) Register(input RegisterInput) (*AuthResponse, error) {
 		CreatedAt: time.Now(),
 	}
 
+	// Bot and imported user addresses are reserved
+	if models.IsBotEmail(input.Email) || models.IsImportedUserEmail(input.Email) {
+		return nil, errors.New("email already exists")
+	}
+
 	// Check if email exists
 	existingUser, err := au.userRepo.FindByEmail(input.Email)
 	if err == nil && existingUser != nil {
@@ -103,6 +108,12 @@ func (au *AuthUsecase) Login(input LoginInput) (*AuthResponse, error) {
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Bots authenticate with their API token only, and imported placeholder
+	// accounts have no credentials
+	if user.IsBot || models.IsImportedUserEmail(user.Email) {
+		return nil, errors.New("invalid email or password")
+	}
+
 	// Check password
 	err = au.authService.ComparePassword(user.Password, input.Password)
 	if err != nil {
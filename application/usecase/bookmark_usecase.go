@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+// Bookmark page sizes
+const (
+	DefaultBookmarkLimit = 20
+	MaxBookmarkLimit     = 50
+)
+
+const (
+	reminderInterval  = time.Second
+	reminderBatchSize = 50
+)
+
+type BookmarkUsecase struct {
+	bookmarkRepo repositories.BookmarkRepository
+	messageRepo  repositories.MessageRepository
+	chatRepo     repositories.ChatRepository
+	wsHub        *websocket.Hub
+}
+
+func NewBookmarkUsecase(
+	bookmarkRepo repositories.BookmarkRepository,
+	messageRepo repositories.MessageRepository,
+	chatRepo repositories.ChatRepository,
+	wsHub *websocket.Hub,
+) *BookmarkUsecase {
+	return &BookmarkUsecase{
+		bookmarkRepo: bookmarkRepo,
+		messageRepo:  messageRepo,
+		chatRepo:     chatRepo,
+		wsHub:        wsHub,
+	}
+}
+
+// CreateBookmark saves a message the user can see, with an optional note and reminder
+func (bu *BookmarkUsecase) CreateBookmark(userID, messageID int, note string, remindAt *time.Time) (*dto.BookmarkResponse, error) {
+	now := time.Now().UTC()
+	if err := models.ValidateBookmark(note, remindAt, now); err != nil {
+		return nil, err
+	}
+
+	message, err := bu.messageRepo.FindById(messageID)
+	if err != nil {
+		return nil, errors.New("message not found")
+	}
+
+	if _, err := bu.chatRepo.GetUserRole(message.ChatId, userID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	if _, err := bu.bookmarkRepo.FindByUserAndMessage(userID, messageID); err == nil {
+		return nil, errors.New("message already bookmarked")
+	}
+
+	bookmark := &models.Bookmark{
+		UserId:    userID,
+		MessageId: message.ID,
+		ChatId:    message.ChatId,
+		Note:      note,
+		RemindAt:  utcTime(remindAt),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := bu.bookmarkRepo.Create(bookmark); err != nil {
+		logger.Error("Failed to create bookmark: %v", err)
+		return nil, err
+	}
+
+	return bu.GetBookmark(bookmark.ID, userID)
+}
+
+// GetBookmark returns one of the user's bookmarks
+func (bu *BookmarkUsecase) GetBookmark(id, userID int) (*dto.BookmarkResponse, error) {
+	bookmark, err := bu.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks := []models.Bookmark{*bookmark}
+	if err := bu.attachMessages(bookmarks); err != nil {
+		return nil, err
+	}
+
+	return dto.NewBookmarkResponse(&bookmarks[0]), nil
+}
+
+// GetBookmarks pages the user's bookmarks across chats, newest first
+func (bu *BookmarkUsecase) GetBookmarks(userID, chatID, cursor, limit int) (*dto.BookmarkListResponse, error) {
+	if limit <= 0 {
+		limit = DefaultBookmarkLimit
+	}
+	if limit > MaxBookmarkLimit {
+		limit = MaxBookmarkLimit
+	}
+
+	// Fetch one extra row to know whether there is another page
+	bookmarks, err := bu.bookmarkRepo.FindByUserId(userID, chatID, cursor, limit+1)
+	if err != nil {
+		logger.Error("Failed to get bookmarks: %v", err)
+		return nil, err
+	}
+
+	response := &dto.BookmarkListResponse{
+		HasMore: len(bookmarks) > limit,
+	}
+	if response.HasMore {
+		bookmarks = bookmarks[:limit]
+	}
+
+	if err := bu.attachMessages(bookmarks); err != nil {
+		return nil, err
+	}
+	response.Bookmarks = dto.NewBookmarkResponseList(bookmarks)
+
+	if len(bookmarks) > 0 {
+		response.NextCursor = bookmarks[len(bookmarks)-1].ID
+	}
+
+	return response, nil
+}
+
+// UpdateBookmark replaces the note and reminder of a bookmark. A changed
+// reminder time is sent again even if the previous one already fired.
+func (bu *BookmarkUsecase) UpdateBookmark(id, userID int, note string, remindAt *time.Time) (*dto.BookmarkResponse, error) {
+	bookmark, err := bu.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := models.ValidateBookmark(note, remindAt, now); err != nil {
+		return nil, err
+	}
+
+	bookmark.Note = note
+	bookmark.RemindAt = utcTime(remindAt)
+	bookmark.RemindedAt = nil
+	bookmark.UpdatedAt = now
+
+	if err := bu.bookmarkRepo.Update(bookmark); err != nil {
+		return nil, err
+	}
+
+	return bu.GetBookmark(id, userID)
+}
+
+// DeleteBookmark removes one of the user's bookmarks
+func (bu *BookmarkUsecase) DeleteBookmark(id, userID int) error {
+	if _, err := bu.findOwned(id, userID); err != nil {
+		return err
+	}
+
+	return bu.bookmarkRepo.Delete(id)
+}
+
+// Run sends due bookmark reminders until the process exits
+func (bu *BookmarkUsecase) Run() {
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		bu.sendDueReminders()
+	}
+}
+
+func (bu *BookmarkUsecase) sendDueReminders() {
+	now := time.Now().UTC()
+	due, err := bu.bookmarkRepo.FindDueReminders(now, reminderBatchSize)
+	if err != nil {
+		logger.Error("Failed to find due bookmark reminders: %v", err)
+		return
+	}
+
+	for i := range due {
+		bu.remind(&due[i], now)
+	}
+}
+
+func (bu *BookmarkUsecase) remind(bookmark *models.Bookmark, now time.Time) {
+	claimed, err := bu.bookmarkRepo.MarkReminded(bookmark, now)
+	if err != nil {
+		logger.Error("Failed to mark bookmark %d as reminded: %v", bookmark.ID, err)
+		return
+	}
+	// Reminders of messages the user can no longer see are dropped
+	if !claimed || !bookmark.Available {
+		return
+	}
+
+	message, err := bu.messageRepo.FindById(bookmark.MessageId)
+	if err != nil {
+		return
+	}
+
+	event := events.NewWebSocketEvent(events.EventBookmarkReminder, bookmark.ChatId, &events.BookmarkEventData{
+		BookmarkID:     bookmark.ID,
+		ChatID:         bookmark.ChatId,
+		ChatName:       bookmark.ChatName,
+		MessageID:      message.ID,
+		SenderNickname: message.SenderNickname,
+		PlainText:      message.PlainText,
+		Note:           bookmark.Note,
+		RemindAt:       *bookmark.RemindAt,
+	})
+	eventJSON, err := event.ToJSON()
+	if err != nil {
+		logger.Error("Failed to marshal %s event: %v", events.EventBookmarkReminder, err)
+		return
+	}
+	bu.wsHub.BroadcastToUsers([]int{bookmark.UserId}, eventJSON)
+}
+
+// attachMessages loads the messages of available bookmarks
+func (bu *BookmarkUsecase) attachMessages(bookmarks []models.Bookmark) error {
+	var messageIDs []int
+	for _, bookmark := range bookmarks {
+		if bookmark.Available {
+			messageIDs = append(messageIDs, bookmark.MessageId)
+		}
+	}
+
+	messages, err := bu.messageRepo.FindByIds(messageIDs)
+	if err != nil {
+		logger.Error("Failed to load bookmarked messages: %v", err)
+		return err
+	}
+
+	byID := make(map[int]*models.Message, len(messages))
+	for i := range messages {
+		byID[messages[i].ID] = &messages[i]
+	}
+
+	for i := range bookmarks {
+		if bookmarks[i].Available {
+			bookmarks[i].Message = byID[bookmarks[i].MessageId]
+		}
+	}
+
+	return nil
+}
+
+func (bu *BookmarkUsecase) findOwned(id, userID int) (*models.Bookmark, error) {
+	bookmark, err := bu.bookmarkRepo.FindById(id)
+	if err != nil || bookmark.UserId != userID {
+		return nil, errors.New("bookmark not found")
+	}
+	return bookmark, nil
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+)
+
+// BotUsecase manages bot accounts. Bots are users that authenticate with an
+// API token instead of a password; once invited to a chat they use the same
+// API and /ws connection as everyone else.
+type BotUsecase struct {
+	userRepo repositories.UserRepository
+}
+
+func NewBotUsecase(userRepo repositories.UserRepository) *BotUsecase {
+	return &BotUsecase{
+		userRepo: userRepo,
+	}
+}
+
+// CreateBot creates a bot owned by the user. The returned API token is not
+// stored and cannot be retrieved again.
+func (bu *BotUsecase) CreateBot(ownerID int, nickname string) (*dto.BotResponse, error) {
+	nickname = strings.TrimSpace(nickname)
+	if err := models.ValidateNickname(nickname); err != nil {
+		return nil, err
+	}
+
+	if err := bu.checkHuman(ownerID); err != nil {
+		return nil, err
+	}
+
+	if existing, err := bu.userRepo.FindByNickname(nickname); err == nil && existing != nil {
+		return nil, errors.New("nickname already exists")
+	}
+
+	token, err := newBotToken()
+	if err != nil {
+		return nil, err
+	}
+	tokenHash := models.HashBotToken(token)
+
+	bot := &models.User{
+		Email:        models.BotEmail(nickname),
+		Nickname:     nickname,
+		CreatedAt:    time.Now(),
+		IsBot:        true,
+		OwnerId:      &ownerID,
+		APITokenHash: &tokenHash,
+	}
+	if _, err := bu.userRepo.Create(bot); err != nil {
+		logger.Error("Failed to create bot: %v", err)
+		return nil, err
+	}
+
+	response := dto.NewBotResponse(bot)
+	response.Token = token
+	return response, nil
+}
+
+// GetBots lists the bots owned by the user
+func (bu *BotUsecase) GetBots(ownerID int) ([]dto.BotResponse, error) {
+	bots, err := bu.userRepo.FindBotsByOwnerId(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewBotResponseList(bots), nil
+}
+
+// RotateToken issues a new API token for a bot; the previous token stops working
+func (bu *BotUsecase) RotateToken(botID, ownerID int) (*dto.BotResponse, error) {
+	bot, err := bu.userRepo.FindByID(botID)
+	if err != nil || !bot.IsBot || bot.OwnerId == nil || *bot.OwnerId != ownerID {
+		return nil, errors.New("bot not found")
+	}
+
+	token, err := newBotToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := bu.userRepo.UpdateAPITokenHash(bot.ID, models.HashBotToken(token)); err != nil {
+		logger.Error("Failed to rotate token of bot %d: %v", bot.ID, err)
+		return nil, err
+	}
+
+	response := dto.NewBotResponse(bot)
+	response.Token = token
+	return response, nil
+}
+
+// AuthenticateBot returns the bot holding an API token
+func (bu *BotUsecase) AuthenticateBot(token string) (*models.User, error) {
+	if !models.IsBotToken(token) {
+		return nil, errors.New("invalid token")
+	}
+	bot, err := bu.userRepo.FindBotByTokenHash(models.HashBotToken(token))
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+	return bot, nil
+}
+
+// checkHuman rejects bots, which cannot own other bots
+func (bu *BotUsecase) checkHuman(userID int) error {
+	user, err := bu.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.IsBot {
+		return errors.New("bots cannot manage bots")
+	}
+	return nil
+}
+
+func newBotToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return models.BotTokenPrefix + hex.EncodeToString(b), nil
+}
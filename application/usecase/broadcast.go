@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+// broadcastToUsers serializes a WebSocket event and sends it to the given users
+func broadcastToUsers(hub *websocket.Hub, users []models.User, eventType string, chatID int, data interface{}) {
+	userIDs := make([]int, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	event := events.NewWebSocketEvent(eventType, chatID, data)
+	eventJSON, err := event.ToJSON()
+	if err != nil {
+		logger.Error("Failed to marshal %s event: %v", eventType, err)
+		return
+	}
+	hub.BroadcastToUsers(userIDs, eventJSON)
+}
+
+// newMessageEventData builds the event payload describing a message
+func newMessageEventData(message *models.Message) *events.MessageEventData {
+	return &events.MessageEventData{
+		MessageID:       message.ID,
+		ChatID:          message.ChatId,
+		SenderID:        message.SenderId,
+		SenderNickname:  message.SenderNickname,
+		Content:         message.Content,
+		MessageType:     message.Type,
+		SystemEvent:     message.SystemEvent,
+		Formatted:       message.Formatted,
+		ClientMessageID: stringValue(message.ClientMessageId),
+		WebhookID:       message.WebhookId,
+		CreatedAt:       message.CreatedAt,
+		UpdatedAt:       message.UpdatedAt,
+		ExpiresAt:       message.ExpiresAt,
+		ForwardedFrom:   message.Origin(),
+		LinkPreviews:    message.LinkPreviews,
+		Poll:            message.Poll,
+		Attachments:     message.Attachments,
+		Encrypted:       message.Encrypted,
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
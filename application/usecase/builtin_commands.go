@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+)
+
+// shrug is escaped so the Markdown parser keeps its backslash and underscores
+const shrug = `¯\\\_(ツ)\_/¯`
+
+// RegisterBuiltinCommands adds the commands every chat supports
+func RegisterBuiltinCommands(
+	commands *CommandUsecase,
+	chats *ChatUsecase,
+	chatRepo repositories.ChatRepository,
+	userRepo repositories.UserRepository,
+) {
+	commands.Register(models.Command{
+		Name:        "help",
+		Description: "사용 가능한 명령어를 보여줍니다",
+	}, func(ctx *CommandContext) (*CommandResult, error) {
+		lines := []string{"사용 가능한 명령어:"}
+		for _, command := range commands.list() {
+			lines = append(lines, fmt.Sprintf("`%s` %s", command.Usage(), command.Description))
+		}
+		return &CommandResult{Reply: strings.Join(lines, "\n")}, nil
+	})
+
+	commands.Register(models.Command{
+		Name:        "me",
+		Description: "자신의 행동을 3인칭으로 표시합니다",
+		Args: []models.CommandArg{
+			{Name: "행동", Description: "표시할 행동", Required: true},
+		},
+	}, func(ctx *CommandContext) (*CommandResult, error) {
+		if ctx.Args == "" {
+			return &CommandResult{Reply: "사용법: `/me <행동>`"}, nil
+		}
+		return &CommandResult{Post: "_" + ctx.Args + "_"}, nil
+	})
+
+	commands.Register(models.Command{
+		Name:        "shrug",
+		Description: "메시지 끝에 어깨를 으쓱하는 이모티콘을 붙여 보냅니다",
+		Args: []models.CommandArg{
+			{Name: "메시지", Description: "함께 보낼 메시지"},
+		},
+	}, func(ctx *CommandContext) (*CommandResult, error) {
+		return &CommandResult{Post: strings.TrimSpace(ctx.Args + " " + shrug)}, nil
+	})
+
+	commands.Register(models.Command{
+		Name:        "topic",
+		Description: "채팅방 주제를 확인하거나 변경합니다",
+		Args: []models.CommandArg{
+			{Name: "주제", Description: "새 주제. 생략하면 현재 주제를 보여줍니다"},
+		},
+	}, func(ctx *CommandContext) (*CommandResult, error) {
+		if ctx.Args == "" {
+			chat, err := chatRepo.FindById(ctx.ChatID)
+			if err != nil {
+				return nil, errors.New("chat not found")
+			}
+			if chat.Topic == "" {
+				return &CommandResult{Reply: "설정된 주제가 없습니다"}, nil
+			}
+			return &CommandResult{Reply: "현재 주제: " + chat.Topic}, nil
+		}
+
+		if _, err := chats.SetTopic(ctx.ChatID, ctx.UserID, ctx.Args); err != nil {
+			return nil, err
+		}
+		return &CommandResult{Reply: "주제를 변경했습니다"}, nil
+	})
+
+	commands.Register(models.Command{
+		Name:        "invite",
+		Description: "사용자를 채팅방에 초대합니다",
+		Args: []models.CommandArg{
+			{Name: "@닉네임", Description: "초대할 사용자", Required: true, Repeated: true},
+		},
+	}, func(ctx *CommandContext) (*CommandResult, error) {
+		mentions := strings.Fields(ctx.Args)
+		if len(mentions) == 0 {
+			return &CommandResult{Reply: "사용법: `/invite <@닉네임...>`"}, nil
+		}
+
+		userIDs := make([]int, 0, len(mentions))
+		for _, mention := range mentions {
+			nickname := strings.TrimPrefix(mention, "@")
+			user, err := userRepo.FindByNickname(nickname)
+			if err != nil {
+				return &CommandResult{Reply: fmt.Sprintf("사용자를 찾을 수 없습니다: @%s", nickname)}, nil
+			}
+			userIDs = append(userIDs, user.ID)
+		}
+
+		added, err := chats.AddMembers(ctx.ChatID, ctx.UserID, userIDs)
+		if err != nil {
+			switch err.Error() {
+			case "users already in chat":
+				return &CommandResult{Reply: "이미 채팅방에 참여중인 사용자입니다"}, nil
+			case "encrypted chats are private":
+				return &CommandResult{Reply: "암호화된 채팅방에는 멤버를 초대할 수 없습니다"}, nil
+			}
+			return nil, err
+		}
+
+		nicknames := make([]string, len(added))
+		for i, user := range added {
+			nicknames[i] = user.Nickname + "님"
+		}
+		return &CommandResult{Reply: strings.Join(nicknames, ", ") + "을(를) 초대했습니다"}, nil
+	})
+}
@@ -3,27 +3,44 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
 	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/domain/repositories"
 	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
 )
 
 type ChatUsecase struct {
 	chatRepo    repositories.ChatRepository
 	messageRepo repositories.MessageRepository
 	userRepo    repositories.UserRepository
+	draftRepo   repositories.DraftRepository
+	messages    *MessageUsecase
+	webhooks    *OutgoingWebhookUsecase
+	wsHub       *websocket.Hub
 }
 
 func NewChatUsecase(
 	chatRepo repositories.ChatRepository,
 	messageRepo repositories.MessageRepository,
 	userRepo repositories.UserRepository,
+	draftRepo repositories.DraftRepository,
+	messages *MessageUsecase,
+	webhooks *OutgoingWebhookUsecase,
+	wsHub *websocket.Hub,
 ) *ChatUsecase {
 	return &ChatUsecase{
 		chatRepo:    chatRepo,
 		messageRepo: messageRepo,
 		userRepo:    userRepo,
+		draftRepo:   draftRepo,
+		messages:    messages,
+		webhooks:    webhooks,
+		wsHub:       wsHub,
 	}
 }
 
@@ -65,11 +82,23 @@ func (cu *ChatUsecase) GetUserChats(userID int) ([]dto.ChatListResponse, error)
 		usersMap[chatID] = users
 	}
 
+	// Get the user's unsent drafts
+	drafts := make(map[int]*models.Draft)
+	userDrafts, err := cu.draftRepo.FindByUserId(userID)
+	if err != nil {
+		logger.Error("Failed to get drafts for userID %d: %v", userID, err)
+	}
+	for i := range userDrafts {
+		drafts[userDrafts[i].ChatId] = &userDrafts[i]
+	}
+
 	// Create response
-	return dto.NewChatListResponse(chats, lastMessages, usersMap), nil
+	return dto.NewChatListResponse(chats, lastMessages, usersMap, drafts), nil
 }
 
-func (cu *ChatUsecase) CreatePrivateChat(user1ID, user2ID int) (*dto.ChatResponse, error) {
+// CreatePrivateChat creates a chat between two users. Messages of an
+// encrypted chat must be end-to-end encrypted by the members' devices.
+func (cu *ChatUsecase) CreatePrivateChat(user1ID, user2ID int, encrypted bool) (*dto.ChatResponse, error) {
 	// Verify both users exist
 	user1, err := cu.userRepo.FindByID(user1ID)
 	if err != nil {
@@ -81,8 +110,14 @@ func (cu *ChatUsecase) CreatePrivateChat(user1ID, user2ID int) (*dto.ChatRespons
 		return nil, errors.New("user2 not found")
 	}
 
+	// Bots cannot hold device keys
+	if encrypted && (user1.IsBot || user2.IsBot) {
+		return nil, errors.New("not supported in encrypted chats")
+	}
+
 	chat := &models.Chat{
-		Name: user1.Nickname + "-" + user2.Nickname,
+		Name:      user1.Nickname + "-" + user2.Nickname,
+		Encrypted: encrypted,
 	}
 
 	if err := cu.chatRepo.Create(chat); err != nil {
@@ -99,10 +134,18 @@ func (cu *ChatUsecase) CreatePrivateChat(user1ID, user2ID int) (*dto.ChatRespons
 		return nil, errors.New("failed to add user2 to chat")
 	}
 
+	// Both participants manage a private chat
+	for _, userID := range []int{user1ID, user2ID} {
+		if err := cu.chatRepo.SetUserRole(chat.ID, userID, models.ChatRoleAdmin); err != nil {
+			cu.chatRepo.Delete(chat.ID)
+			return nil, errors.New("failed to set user role")
+		}
+	}
+
 	return dto.NewChatResponse(chat), nil
 }
 
-func (cu *ChatUsecase) CreateGroupChat(name string, userIDs []int) (*dto.ChatResponse, error) {
+func (cu *ChatUsecase) CreateGroupChat(creatorID int, name string, userIDs []int) (*dto.ChatResponse, error) {
 	// Check for empty name
 	if name == "" {
 		return nil, errors.New("chat name is required")
@@ -149,5 +192,408 @@ func (cu *ChatUsecase) CreateGroupChat(name string, userIDs []int) (*dto.ChatRes
 		}
 	}
 
+	// The creator owns the group chat
+	if err := cu.chatRepo.SetUserRole(chat.ID, creatorID, models.ChatRoleOwner); err != nil {
+		cu.chatRepo.Delete(chat.ID)
+		return nil, errors.New("failed to set user role")
+	}
+
+	if actor, err := cu.eventUser(creatorID); err == nil {
+		cu.messages.PostSystemMessage(chat.ID, &models.SystemEvent{
+			Action:   models.SystemActionChatCreated,
+			Actor:    actor,
+			ChatName: chat.Name,
+		})
+	}
+
 	return dto.NewChatResponse(chat), nil
 }
+
+// SetMessageTTL sets the lifetime of new messages in the chat. Zero turns
+// disappearing messages off; messages already sent keep their expiry.
+func (cu *ChatUsecase) SetMessageTTL(chatID, userID, ttlSeconds int) (*dto.ChatResponse, error) {
+	if ttlSeconds != 0 {
+		if err := models.ValidateMessageTTL(ttlSeconds); err != nil {
+			return nil, err
+		}
+	}
+
+	chat, err := cu.checkManager(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.MessageTTLSeconds == ttlSeconds {
+		return dto.NewChatResponse(chat), nil
+	}
+
+	if err := cu.chatRepo.UpdateMessageTTL(chatID, ttlSeconds); err != nil {
+		logger.Error("Failed to update message ttl: %v", err)
+		return nil, err
+	}
+	chat.MessageTTLSeconds = ttlSeconds
+
+	cu.broadcastChatUpdated(chat, userID)
+
+	if actor, err := cu.eventUser(userID); err == nil {
+		cu.messages.PostSystemMessage(chatID, &models.SystemEvent{
+			Action:     models.SystemActionDisappearingUpdated,
+			Actor:      actor,
+			TTLSeconds: &ttlSeconds,
+		})
+	}
+
+	return dto.NewChatResponse(chat), nil
+}
+
+// SetSlowMode makes members wait the given number of seconds between their
+// messages. Owners and admins are exempt; zero turns slow mode off.
+func (cu *ChatUsecase) SetSlowMode(chatID, userID, seconds int) (*dto.ChatResponse, error) {
+	if err := models.ValidateSlowMode(seconds); err != nil {
+		return nil, err
+	}
+
+	chat, err := cu.checkManager(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.SlowModeSeconds == seconds {
+		return dto.NewChatResponse(chat), nil
+	}
+
+	if err := cu.chatRepo.UpdateSlowMode(chatID, seconds); err != nil {
+		logger.Error("Failed to update slow mode: %v", err)
+		return nil, err
+	}
+	chat.SlowModeSeconds = seconds
+
+	cu.broadcastChatUpdated(chat, userID)
+
+	if actor, err := cu.eventUser(userID); err == nil {
+		cu.messages.PostSystemMessage(chatID, &models.SystemEvent{
+			Action:          models.SystemActionSlowModeUpdated,
+			Actor:           actor,
+			SlowModeSeconds: &seconds,
+		})
+	}
+
+	return dto.NewChatResponse(chat), nil
+}
+
+// SetRetention sets the chat's retention policy. The server-wide policy
+// still applies where it is stricter; a zero policy keeps messages as long
+// as the server does.
+func (cu *ChatUsecase) SetRetention(chatID, userID int, policy models.RetentionPolicy) (*dto.ChatResponse, error) {
+	if err := models.ValidateRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	chat, err := cu.checkManager(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.RetentionPolicy() == policy {
+		return dto.NewChatResponse(chat), nil
+	}
+
+	if err := cu.chatRepo.UpdateRetention(chatID, policy); err != nil {
+		logger.Error("Failed to update retention policy: %v", err)
+		return nil, err
+	}
+	chat.RetentionDays = policy.Days
+	chat.RetentionMaxMessages = policy.MaxMessages
+
+	cu.broadcastChatUpdated(chat, userID)
+
+	if actor, err := cu.eventUser(userID); err == nil {
+		cu.messages.PostSystemMessage(chatID, &models.SystemEvent{
+			Action:    models.SystemActionRetentionUpdated,
+			Actor:     actor,
+			Retention: &policy,
+		})
+	}
+
+	return dto.NewChatResponse(chat), nil
+}
+
+// RenameChat changes the name of a chat. Only chat managers can rename it.
+func (cu *ChatUsecase) RenameChat(chatID, userID int, name string) (*dto.ChatResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("chat name is required")
+	}
+
+	chat, err := cu.checkManager(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.Name == name {
+		return dto.NewChatResponse(chat), nil
+	}
+
+	previousName := chat.Name
+	chat.Name = name
+	if err := cu.chatRepo.Update(chat); err != nil {
+		logger.Error("Failed to rename chat: %v", err)
+		return nil, err
+	}
+
+	cu.broadcastChatUpdated(chat, userID)
+
+	if actor, err := cu.eventUser(userID); err == nil {
+		cu.messages.PostSystemMessage(chatID, &models.SystemEvent{
+			Action:       models.SystemActionChatRenamed,
+			Actor:        actor,
+			ChatName:     chat.Name,
+			PreviousName: previousName,
+		})
+	}
+
+	return dto.NewChatResponse(chat), nil
+}
+
+// SetTopic changes the topic of a chat; an empty topic clears it. Only chat
+// managers can change it.
+func (cu *ChatUsecase) SetTopic(chatID, userID int, topic string) (*dto.ChatResponse, error) {
+	topic = strings.TrimSpace(topic)
+	if utf8.RuneCountInString(topic) > models.MaxChatTopicLength {
+		return nil, errors.New("chat topic too long")
+	}
+
+	chat, err := cu.checkManager(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.Topic == topic {
+		return dto.NewChatResponse(chat), nil
+	}
+
+	chat.Topic = topic
+	if err := cu.chatRepo.UpdateTopic(chatID, topic); err != nil {
+		logger.Error("Failed to update chat topic: %v", err)
+		return nil, err
+	}
+
+	cu.broadcastChatUpdated(chat, userID)
+
+	if actor, err := cu.eventUser(userID); err == nil {
+		cu.messages.PostSystemMessage(chatID, &models.SystemEvent{
+			Action: models.SystemActionTopicUpdated,
+			Actor:  actor,
+			Topic:  topic,
+		})
+	}
+
+	return dto.NewChatResponse(chat), nil
+}
+
+// AddMembers invites users to a chat and returns the users who were added.
+// Only chat managers can invite; users already in the chat are skipped.
+func (cu *ChatUsecase) AddMembers(chatID, userID int, userIDs []int) ([]dto.UserInfo, error) {
+	userIDs = uniqueInts(userIDs)
+	if len(userIDs) == 0 {
+		return nil, errors.New("at least one other user is required")
+	}
+
+	chat, err := cu.checkManager(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Envelopes are addressed to the devices of the two members
+	if chat.Encrypted {
+		return nil, errors.New("encrypted chats are private")
+	}
+
+	var newMembers []*models.User
+	for _, targetID := range userIDs {
+		target, err := cu.userRepo.FindByID(targetID)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		if _, err := cu.chatRepo.GetUserRole(chatID, targetID); err == nil {
+			continue
+		}
+		newMembers = append(newMembers, target)
+	}
+	if len(newMembers) == 0 {
+		return nil, errors.New("users already in chat")
+	}
+
+	targets := make([]models.SystemEventUser, 0, len(newMembers))
+	added := make([]dto.UserInfo, 0, len(newMembers))
+	for _, member := range newMembers {
+		if err := cu.chatRepo.AddUserToChat(chatID, member.ID); err != nil {
+			logger.Error("Failed to add user %d to chat %d: %v", member.ID, chatID, err)
+			return nil, errors.New("failed to add user to chat group")
+		}
+		targets = append(targets, models.SystemEventUser{UserId: member.ID, Nickname: member.Nickname})
+		added = append(added, dto.NewUserInfo(member))
+	}
+
+	// Posted after adding, so the new members receive it
+	if actor, err := cu.eventUser(userID); err == nil {
+		cu.messages.PostSystemMessage(chatID, &models.SystemEvent{
+			Action:  models.SystemActionMembersAdded,
+			Actor:   actor,
+			Targets: targets,
+		})
+		cu.webhooks.Publish(models.OutgoingEventMemberAdded, chatID, &models.WebhookMemberData{
+			Actor:   actor,
+			Members: targets,
+		})
+	}
+
+	return added, nil
+}
+
+// RemoveMember removes a user from a chat. Members can always leave; removing
+// someone else takes a chat manager, and nobody can remove the owner.
+func (cu *ChatUsecase) RemoveMember(chatID, userID, targetID int) error {
+	if _, err := cu.chatRepo.FindById(chatID); err != nil {
+		return errors.New("chat not found")
+	}
+
+	role, err := cu.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return errors.New("user is not a member of this chat")
+	}
+
+	targetRole, err := cu.chatRepo.GetUserRole(chatID, targetID)
+	if err != nil {
+		return errors.New("member not found")
+	}
+	if targetID != userID && (!models.CanManageChat(role) || targetRole == models.ChatRoleOwner) {
+		return errors.New("unauthorized to update this chat")
+	}
+
+	event := &models.SystemEvent{Action: models.SystemActionMemberLeft}
+	if event.Actor, err = cu.eventUser(userID); err != nil {
+		return err
+	}
+	if targetID != userID {
+		target, err := cu.eventUser(targetID)
+		if err != nil {
+			return err
+		}
+		event.Action = models.SystemActionMemberRemoved
+		event.Targets = []models.SystemEventUser{target}
+	}
+
+	// Posted before removing, so the removed member learns about it too
+	cu.messages.PostSystemMessage(chatID, event)
+	members := event.Targets
+	if members == nil {
+		members = []models.SystemEventUser{event.Actor}
+	}
+	cu.webhooks.Publish(models.OutgoingEventMemberRemoved, chatID, &models.WebhookMemberData{
+		Actor:   event.Actor,
+		Members: members,
+	})
+
+	if err := cu.chatRepo.RemoveUserFromChat(chatID, targetID); err != nil {
+		logger.Error("Failed to remove user %d from chat %d: %v", targetID, chatID, err)
+		return err
+	}
+
+	// A chat is never left without someone to manage it
+	if models.CanManageChat(targetRole) {
+		if err := cu.chatRepo.EnsureOwner(chatID); err != nil {
+			logger.Error("Failed to hand over chat %d: %v", chatID, err)
+		}
+	}
+
+	return nil
+}
+
+// SetMemberRole makes a member an admin of a chat, or an admin a member
+// again. Only the owner hands out roles, and the owner's role cannot change.
+func (cu *ChatUsecase) SetMemberRole(chatID, userID, targetID int, role string) error {
+	if role != models.ChatRoleAdmin && role != models.ChatRoleMember {
+		return errors.New("invalid role")
+	}
+	if _, err := cu.chatRepo.FindById(chatID); err != nil {
+		return errors.New("chat not found")
+	}
+
+	userRole, err := cu.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return errors.New("user is not a member of this chat")
+	}
+	if userRole != models.ChatRoleOwner {
+		return errors.New("unauthorized to update this chat")
+	}
+
+	targetRole, err := cu.chatRepo.GetUserRole(chatID, targetID)
+	if err != nil {
+		return errors.New("member not found")
+	}
+	if targetRole == models.ChatRoleOwner {
+		return errors.New("unauthorized to update this chat")
+	}
+	if targetRole == role {
+		return nil
+	}
+
+	event := &models.SystemEvent{Action: models.SystemActionMemberRoleUpdated, Role: role}
+	if event.Actor, err = cu.eventUser(userID); err != nil {
+		return err
+	}
+	target, err := cu.eventUser(targetID)
+	if err != nil {
+		return err
+	}
+	event.Targets = []models.SystemEventUser{target}
+
+	if err := cu.chatRepo.SetUserRole(chatID, targetID, role); err != nil {
+		logger.Error("Failed to set role of user %d in chat %d: %v", targetID, chatID, err)
+		return err
+	}
+	cu.messages.PostSystemMessage(chatID, event)
+
+	return nil
+}
+
+// checkManager verifies the chat exists and the user may manage it
+func (cu *ChatUsecase) checkManager(chatID, userID int) (*models.Chat, error) {
+	chat, err := cu.chatRepo.FindById(chatID)
+	if err != nil {
+		return nil, errors.New("chat not found")
+	}
+
+	role, err := cu.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+	if !models.CanManageChat(role) {
+		return nil, errors.New("unauthorized to update this chat")
+	}
+
+	return chat, nil
+}
+
+// eventUser looks up a user taking part in a system event
+func (cu *ChatUsecase) eventUser(userID int) (models.SystemEventUser, error) {
+	user, err := cu.userRepo.FindByID(userID)
+	if err != nil {
+		logger.Error("Failed to find user %d: %v", userID, err)
+		return models.SystemEventUser{}, err
+	}
+	return models.SystemEventUser{UserId: user.ID, Nickname: user.Nickname}, nil
+}
+
+func (cu *ChatUsecase) broadcastChatUpdated(chat *models.Chat, userID int) {
+	users, err := cu.chatRepo.GetChatUsers(chat.ID)
+	if err != nil {
+		logger.Error("Failed to get chat users: %v", err)
+		return
+	}
+
+	broadcastToUsers(cu.wsHub, users, events.EventChatUpdated, chat.ID, &events.ChatEventData{
+		ChatID:            chat.ID,
+		Name:              chat.Name,
+		Topic:             chat.Topic,
+		MessageTTLSeconds: chat.MessageTTLSeconds,
+		SlowModeSeconds:   chat.SlowModeSeconds,
+		Retention:         chat.RetentionPolicy(),
+		UpdatedBy:         userID,
+	})
+}
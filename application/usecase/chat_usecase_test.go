@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/infrastructure/sqlite"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+	"github.com/f1rstid/realtime-chat/interfaces/repositories"
+)
+
+// testUsecases wires the chat, message and pin usecases to a database in the
+// test's temporary directory, like the router does
+type testUsecases struct {
+	chats    *ChatUsecase
+	messages *MessageUsecase
+	pins     *PinUsecase
+}
+
+// newTestDB migrates a database in the test's temporary directory
+func newTestDB(t *testing.T) {
+	t.Helper()
+	useTempLogDir(t)
+	if err := sqlite.InitDB(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sqlite.CloseDB)
+	if err := sqlite.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestUsecases creates the given number of users, with IDs from 1
+func newTestUsecases(t *testing.T, users int) *testUsecases {
+	t.Helper()
+	newTestDB(t)
+	for i := 1; i <= users; i++ {
+		query := `INSERT INTO users (id, email, password, nickname) VALUES ($1, $2, 'x', $3)`
+		if _, err := sqlite.DB.Exec(query, i, fmt.Sprintf("u%d@example.com", i), fmt.Sprintf("u%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cipher, err := repositories.NewContentCipher(sqlite.DB, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wsHub := websocket.NewHub()
+	chatRepo := repositories.NewChatRepository(sqlite.DB, cipher)
+	messageRepo := repositories.NewMessageRepository(sqlite.DB, cipher)
+	pinRepo := repositories.NewPinRepository(sqlite.DB, cipher)
+	userRepo := repositories.NewUserRepository(sqlite.DB)
+	webhooks := NewOutgoingWebhookUsecase(repositories.NewOutgoingWebhookRepository(sqlite.DB, cipher), chatRepo, nil)
+
+	messages := NewMessageUsecase(
+		messageRepo, chatRepo, pinRepo,
+		repositories.NewReactionRepository(sqlite.DB),
+		repositories.NewDeviceRepository(sqlite.DB),
+		NewLinkPreviewUsecase(repositories.NewLinkPreviewRepository(sqlite.DB, cipher), messageRepo, chatRepo, nil, wsHub),
+		NewReceiptUsecase(repositories.NewReceiptRepository(sqlite.DB), chatRepo, wsHub),
+		NewPollUsecase(repositories.NewPollRepository(sqlite.DB, cipher), messageRepo, chatRepo, wsHub),
+		NewCommandUsecase(),
+		NewModerationPipeline(repositories.NewModerationRepository(sqlite.DB, cipher), nil),
+		webhooks,
+		wsHub,
+	)
+	return &testUsecases{
+		chats:    NewChatUsecase(chatRepo, messageRepo, userRepo, repositories.NewDraftRepository(sqlite.DB, cipher), messages, webhooks, wsHub),
+		messages: messages,
+		pins:     NewPinUsecase(pinRepo, messageRepo, chatRepo, messages, wsHub),
+	}
+}
+
+func TestOwnerGrantsAdminWhoCanPin(t *testing.T) {
+	u := newTestUsecases(t, 3)
+	chat, err := u.chats.CreateGroupChat(1, "team", []int{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := u.messages.SendMessage(SendMessageInput{ChatID: chat.ChatID, SenderID: 3, Content: "release notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := u.pins.PinMessage(chat.ChatID, message.MessageID, 2); err == nil {
+		t.Fatal("a member pinned a message before becoming an admin")
+	}
+
+	// Only the owner hands out roles
+	if err := u.chats.SetMemberRole(chat.ChatID, 3, 2, models.ChatRoleAdmin); err == nil {
+		t.Fatal("a member granted the admin role")
+	}
+	if err := u.chats.SetMemberRole(chat.ChatID, 1, 2, models.ChatRoleAdmin); err != nil {
+		t.Fatalf("SetMemberRole: %v", err)
+	}
+	if _, err := u.pins.PinMessage(chat.ChatID, message.MessageID, 2); err != nil {
+		t.Fatalf("admin could not pin: %v", err)
+	}
+
+	if err := u.chats.SetMemberRole(chat.ChatID, 1, 2, models.ChatRoleMember); err != nil {
+		t.Fatalf("SetMemberRole: %v", err)
+	}
+	if err := u.pins.UnpinMessage(chat.ChatID, message.MessageID, 2); err == nil {
+		t.Fatal("a demoted admin unpinned a message")
+	}
+
+	// The owner's role cannot change, nor can roles outside admin and member be given
+	if err := u.chats.SetMemberRole(chat.ChatID, 1, 1, models.ChatRoleMember); err == nil {
+		t.Error("the owner demoted themselves")
+	}
+	if err := u.chats.SetMemberRole(chat.ChatID, 1, 2, models.ChatRoleOwner); err == nil {
+		t.Error("the owner role was given away")
+	}
+}
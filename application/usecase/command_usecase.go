@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// CommandContext is passed to a command handler
+type CommandContext struct {
+	ChatID int
+	UserID int
+	// Args is the text following the command name
+	Args string
+}
+
+// CommandResult tells what a command produced. Post is sent into the chat as
+// the caller's message; otherwise Reply is shown to the caller only. Handlers
+// changing chat state do so themselves and usually confirm with a reply.
+type CommandResult struct {
+	Post  string
+	Reply string
+}
+
+// CommandHandler runs a slash command
+type CommandHandler func(ctx *CommandContext) (*CommandResult, error)
+
+type registeredCommand struct {
+	command models.Command
+	handler CommandHandler
+}
+
+// CommandUsecase holds the slash commands messages are dispatched to
+type CommandUsecase struct {
+	mu       sync.RWMutex
+	commands map[string]registeredCommand
+}
+
+func NewCommandUsecase() *CommandUsecase {
+	return &CommandUsecase{
+		commands: make(map[string]registeredCommand),
+	}
+}
+
+// Register adds a command, replacing any command with the same name
+func (cu *CommandUsecase) Register(command models.Command, handler CommandHandler) {
+	cu.mu.Lock()
+	defer cu.mu.Unlock()
+	cu.commands[command.Name] = registeredCommand{command: command, handler: handler}
+}
+
+// GetCommands lists the available commands by name
+func (cu *CommandUsecase) GetCommands() []dto.CommandResponse {
+	return dto.NewCommandResponseList(cu.list())
+}
+
+func (cu *CommandUsecase) list() []models.Command {
+	cu.mu.RLock()
+	defer cu.mu.RUnlock()
+
+	commands := make([]models.Command, 0, len(cu.commands))
+	for _, registered := range cu.commands {
+		commands = append(commands, registered.command)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands
+}
+
+func (cu *CommandUsecase) lookup(name string) (registeredCommand, bool) {
+	cu.mu.RLock()
+	defer cu.mu.RUnlock()
+	registered, ok := cu.commands[name]
+	return registered, ok
+}
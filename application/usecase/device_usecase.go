@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+// DeviceUsecase keeps the directory of public keys used for end-to-end
+// encryption. Each device registers an identity key and a supply of one-time
+// prekeys; senders fetch a bundle per device to start a session with it.
+type DeviceUsecase struct {
+	deviceRepo repositories.DeviceRepository
+	chatRepo   repositories.ChatRepository
+	userRepo   repositories.UserRepository
+	wsHub      *websocket.Hub
+}
+
+func NewDeviceUsecase(
+	deviceRepo repositories.DeviceRepository,
+	chatRepo repositories.ChatRepository,
+	userRepo repositories.UserRepository,
+	wsHub *websocket.Hub,
+) *DeviceUsecase {
+	return &DeviceUsecase{
+		deviceRepo: deviceRepo,
+		chatRepo:   chatRepo,
+		userRepo:   userRepo,
+		wsHub:      wsHub,
+	}
+}
+
+// RegisterDeviceInput defines the input data for registering a device
+type RegisterDeviceInput struct {
+	UserID      int
+	Name        string
+	IdentityKey string
+	Prekeys     []models.Prekey
+}
+
+// RegisterDevice adds a device to the user's keys and tells their encrypted
+// chat contacts, who encrypt for the device from then on
+func (du *DeviceUsecase) RegisterDevice(input RegisterDeviceInput) (*dto.DeviceResponse, error) {
+	name, err := models.NormalizeDeviceName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidatePublicKey(input.IdentityKey); err != nil {
+		return nil, err
+	}
+	if err := models.ValidatePrekeys(input.Prekeys); err != nil {
+		return nil, err
+	}
+
+	count, err := du.deviceRepo.CountByUserId(input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= models.MaxDevicesPerUser {
+		return nil, errors.New("too many devices")
+	}
+
+	device := &models.Device{
+		UserId:      input.UserID,
+		Name:        name,
+		IdentityKey: input.IdentityKey,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := du.deviceRepo.Create(device, input.Prekeys); err != nil {
+		logger.Error("Failed to register device of user %d: %v", input.UserID, err)
+		return nil, err
+	}
+
+	du.broadcastDevice(events.EventDeviceAdded, device)
+	return dto.NewDeviceResponse(device), nil
+}
+
+// GetDevices returns the user's registered devices
+func (du *DeviceUsecase) GetDevices(userID int) ([]dto.DeviceResponse, error) {
+	devices, err := du.deviceRepo.FindByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewDeviceResponseList(devices), nil
+}
+
+// AddPrekeys tops up the one-time prekeys of one of the user's devices
+func (du *DeviceUsecase) AddPrekeys(deviceID, userID int, prekeys []models.Prekey) (*dto.DeviceResponse, error) {
+	if len(prekeys) == 0 {
+		return nil, errors.New("invalid prekey")
+	}
+	if err := models.ValidatePrekeys(prekeys); err != nil {
+		return nil, err
+	}
+
+	device, err := du.findOwnDevice(deviceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if device.PrekeyCount+len(prekeys) > models.MaxPrekeysPerDevice {
+		return nil, errors.New("too many prekeys")
+	}
+
+	if err := du.deviceRepo.AddPrekeys(deviceID, prekeys); err != nil {
+		logger.Error("Failed to add prekeys to device %d: %v", deviceID, err)
+		return nil, err
+	}
+
+	device, err = du.deviceRepo.FindById(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewDeviceResponse(device), nil
+}
+
+// RemoveDevice deletes one of the user's devices with its keys and tells
+// their encrypted chat contacts to stop encrypting for it
+func (du *DeviceUsecase) RemoveDevice(deviceID, userID int) error {
+	device, err := du.findOwnDevice(deviceID, userID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := du.deviceRepo.Delete(deviceID)
+	if err != nil {
+		logger.Error("Failed to remove device %d: %v", deviceID, err)
+		return err
+	}
+	if !deleted {
+		return errors.New("device not found")
+	}
+
+	du.broadcastDevice(events.EventDeviceRemoved, device)
+	return nil
+}
+
+// GetUserKeys returns a key bundle for each device of a user, handing out
+// one of each device's one-time prekeys. Keys are only given to users who
+// share an encrypted chat with the user, and to the user's own devices.
+func (du *DeviceUsecase) GetUserKeys(targetID, requesterID int) (*dto.UserKeysResponse, error) {
+	if _, err := du.userRepo.FindByID(targetID); err != nil {
+		return nil, errors.New("user not found")
+	}
+	if targetID != requesterID {
+		contacts, err := du.chatRepo.GetEncryptedChatContacts(requesterID)
+		if err != nil {
+			return nil, err
+		}
+		if !containsUser(contacts, targetID) {
+			return nil, errors.New("unauthorized to fetch keys")
+		}
+	}
+
+	devices, err := du.deviceRepo.FindByUserId(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := make([]models.DeviceKeyBundle, len(devices))
+	for i, device := range devices {
+		prekey, err := du.deviceRepo.ClaimPrekey(device.ID)
+		if err != nil {
+			logger.Error("Failed to claim prekey of device %d: %v", device.ID, err)
+			return nil, err
+		}
+		bundles[i] = models.DeviceKeyBundle{
+			DeviceId:    device.ID,
+			IdentityKey: device.IdentityKey,
+			Prekey:      prekey,
+		}
+	}
+
+	return &dto.UserKeysResponse{UserID: targetID, Devices: bundles}, nil
+}
+
+func (du *DeviceUsecase) findOwnDevice(deviceID, userID int) (*models.Device, error) {
+	device, err := du.deviceRepo.FindById(deviceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+	// Other users' devices are reported as missing
+	if device.UserId != userID {
+		return nil, errors.New("device not found")
+	}
+	return device, nil
+}
+
+// broadcastDevice sends a device event to the owner's other connections and
+// to everyone sharing an encrypted chat with the owner
+func (du *DeviceUsecase) broadcastDevice(eventType string, device *models.Device) {
+	users, err := du.chatRepo.GetEncryptedChatContacts(device.UserId)
+	if err != nil {
+		logger.Error("Failed to get encrypted chat contacts: %v", err)
+		users = nil
+	}
+	users = append(users, models.User{ID: device.UserId})
+
+	data := &events.DeviceEventData{
+		UserID:   device.UserId,
+		DeviceID: device.ID,
+	}
+	if eventType == events.EventDeviceAdded {
+		data.IdentityKey = device.IdentityKey
+	}
+	broadcastToUsers(du.wsHub, users, eventType, 0, data)
+}
+
+func containsUser(users []models.User, userID int) bool {
+	for _, user := range users {
+		if user.ID == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+type DraftUsecase struct {
+	draftRepo repositories.DraftRepository
+	chatRepo  repositories.ChatRepository
+	wsHub     *websocket.Hub
+}
+
+func NewDraftUsecase(
+	draftRepo repositories.DraftRepository,
+	chatRepo repositories.ChatRepository,
+	wsHub *websocket.Hub,
+) *DraftUsecase {
+	return &DraftUsecase{
+		draftRepo: draftRepo,
+		chatRepo:  chatRepo,
+		wsHub:     wsHub,
+	}
+}
+
+// SaveDraft stores the user's draft for a chat; empty content clears it.
+// The newest save wins, so a stale save from another device is ignored and
+// the stored draft is returned instead.
+func (du *DraftUsecase) SaveDraft(userID, chatID int, content string, updatedAt *time.Time, connectionID string) (*dto.DraftResponse, error) {
+	if err := models.ValidateDraft(content); err != nil {
+		return nil, err
+	}
+
+	if _, err := du.chatRepo.FindById(chatID); err != nil {
+		return nil, errors.New("chat not found")
+	}
+	if _, err := du.chatRepo.GetUserRole(chatID, userID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	// Client clocks ahead of the server would make their drafts impossible to replace
+	now := time.Now().UTC()
+	savedAt := now
+	if updatedAt != nil && updatedAt.Before(now) {
+		savedAt = updatedAt.UTC()
+	}
+
+	draft, applied, err := du.draftRepo.Save(&models.Draft{
+		UserId:    userID,
+		ChatId:    chatID,
+		Content:   content,
+		UpdatedAt: savedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if applied {
+		du.notifyOtherConnections(draft, connectionID)
+	}
+
+	return dto.NewDraftResponse(draft, applied), nil
+}
+
+// notifyOtherConnections pushes the draft to the user's other devices
+func (du *DraftUsecase) notifyOtherConnections(draft *models.Draft, connectionID string) {
+	event := events.NewWebSocketEvent(events.EventDraftUpdated, draft.ChatId, &events.DraftEventData{
+		ChatID:    draft.ChatId,
+		Content:   draft.Content,
+		UpdatedAt: draft.UpdatedAt,
+	})
+	eventJSON, err := event.ToJSON()
+	if err != nil {
+		logger.Error("Failed to marshal %s event: %v", events.EventDraftUpdated, err)
+		return
+	}
+	du.wsHub.SendToOtherConnections(draft.UserId, connectionID, eventJSON)
+}
@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/domain/services"
+	"github.com/f1rstid/realtime-chat/infrastructure/export"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+)
+
+const (
+	exportInterval = 5 * time.Second
+	// Exports listed per chat
+	maxListedExports = 20
+	// Expired export files removed per tick
+	exportPurgeBatchSize = 50
+	exportBufferSize     = 64 * 1024
+)
+
+// ExportUsecase generates chat transcripts in the background. Exports are
+// queued by CreateExport and written one at a time by Run into the export
+// store, streaming the messages so memory use does not grow with the chat.
+type ExportUsecase struct {
+	exportRepo  repositories.ExportRepository
+	chatRepo    repositories.ChatRepository
+	messageRepo repositories.MessageRepository
+	store       services.ExportStore
+	wake        chan struct{}
+}
+
+func NewExportUsecase(
+	exportRepo repositories.ExportRepository,
+	chatRepo repositories.ChatRepository,
+	messageRepo repositories.MessageRepository,
+	store services.ExportStore,
+) *ExportUsecase {
+	return &ExportUsecase{
+		exportRepo:  exportRepo,
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		store:       store,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// ExportDownload is the file of a completed export
+type ExportDownload struct {
+	File     io.ReadCloser
+	Size     int64
+	FileName string
+}
+
+// CreateExport queues a transcript of a chat. Transcripts include every
+// message and the member list, so only owners and admins may export.
+func (eu *ExportUsecase) CreateExport(chatID, userID int, format string) (*dto.ChatExportResponse, error) {
+	if !models.IsExportFormat(format) {
+		return nil, errors.New("invalid export format")
+	}
+	if err := eu.authorize(chatID, userID); err != nil {
+		return nil, err
+	}
+
+	chatExport := &models.ChatExport{
+		ChatId:      chatID,
+		RequestedBy: userID,
+		Format:      format,
+		Status:      models.ExportStatusPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := eu.exportRepo.Create(chatExport); err != nil {
+		logger.Error("Failed to create export of chat %d: %v", chatID, err)
+		return nil, err
+	}
+	eu.notify()
+
+	return dto.NewChatExportResponse(chatExport), nil
+}
+
+// GetExports returns the latest exports of a chat
+func (eu *ExportUsecase) GetExports(chatID, userID int) ([]dto.ChatExportResponse, error) {
+	if err := eu.authorize(chatID, userID); err != nil {
+		return nil, err
+	}
+
+	exports, err := eu.exportRepo.FindByChatId(chatID, maxListedExports)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewChatExportResponseList(exports), nil
+}
+
+// GetExport returns the status of an export
+func (eu *ExportUsecase) GetExport(exportID, userID int) (*dto.ChatExportResponse, error) {
+	chatExport, err := eu.findAuthorized(exportID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewChatExportResponse(chatExport), nil
+}
+
+// OpenExport opens the file of a completed export. The caller closes it.
+func (eu *ExportUsecase) OpenExport(exportID, userID int) (*ExportDownload, error) {
+	chatExport, err := eu.findAuthorized(exportID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch chatExport.Status {
+	case models.ExportStatusCompleted:
+	case models.ExportStatusExpired:
+		return nil, errors.New("export expired")
+	case models.ExportStatusFailed:
+		return nil, errors.New("export failed")
+	default:
+		return nil, errors.New("export not ready")
+	}
+
+	file, size, err := eu.store.Open(*chatExport.FileName)
+	if err != nil {
+		logger.Error("Failed to open export %d: %v", chatExport.ID, err)
+		return nil, err
+	}
+
+	return &ExportDownload{
+		File: file,
+		Size: size,
+		FileName: fmt.Sprintf("chat-%d-%s.%s", chatExport.ChatId, chatExport.CompletedAt.Format("20060102-150405"),
+			models.ExportExtension(chatExport.Format)),
+	}, nil
+}
+
+func (eu *ExportUsecase) findAuthorized(exportID, userID int) (*models.ChatExport, error) {
+	chatExport, err := eu.exportRepo.FindById(exportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("export not found")
+		}
+		return nil, err
+	}
+	if err := eu.authorize(chatExport.ChatId, userID); err != nil {
+		return nil, err
+	}
+	return chatExport, nil
+}
+
+// authorize checks that the user currently manages the chat, so members who
+// lost their role or left cannot download earlier exports
+func (eu *ExportUsecase) authorize(chatID, userID int) error {
+	if _, err := eu.chatRepo.FindById(chatID); err != nil {
+		return errors.New("chat not found")
+	}
+	role, err := eu.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return errors.New("user is not a member of this chat")
+	}
+	if !models.CanManageChat(role) {
+		return errors.New("unauthorized to export this chat")
+	}
+	return nil
+}
+
+// Run writes queued exports and removes expired files. Exports left running
+// by a previous process are queued again.
+func (eu *ExportUsecase) Run() {
+	if err := eu.exportRepo.ResetRunning(); err != nil {
+		logger.Error("Failed to requeue interrupted exports: %v", err)
+	}
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	for {
+		eu.runPendingExports()
+		eu.purgeExpiredExports()
+
+		select {
+		case <-ticker.C:
+		case <-eu.wake:
+		}
+	}
+}
+
+// notify wakes Run so new exports do not wait for the next tick
+func (eu *ExportUsecase) notify() {
+	select {
+	case eu.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (eu *ExportUsecase) runPendingExports() {
+	for {
+		chatExport, err := eu.exportRepo.ClaimNext(time.Now().UTC())
+		if err != nil {
+			logger.Error("Failed to claim export: %v", err)
+			return
+		}
+		if chatExport == nil {
+			return
+		}
+
+		if err := eu.generate(chatExport); err != nil {
+			logger.Error("Failed to export chat %d (export %d): %v", chatExport.ChatId, chatExport.ID, err)
+			if err := eu.exportRepo.Fail(chatExport.ID, time.Now().UTC()); err != nil {
+				logger.Error("Failed to mark export %d as failed: %v", chatExport.ID, err)
+			}
+		}
+	}
+}
+
+// generate writes the transcript to the store and records the finished export
+func (eu *ExportUsecase) generate(chatExport *models.ChatExport) (err error) {
+	chat, err := eu.chatRepo.FindById(chatExport.ChatId)
+	if err != nil {
+		return err
+	}
+	members, err := eu.chatRepo.GetChatMembers(chatExport.ChatId)
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("export-%d.%s", chatExport.ID, models.ExportExtension(chatExport.Format))
+	file, err := eu.store.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			if removeErr := eu.store.Remove(fileName); removeErr != nil {
+				logger.Error("Failed to remove export file %s: %v", fileName, removeErr)
+			}
+		}
+	}()
+
+	counter := &countingWriter{w: file}
+	buffered := bufio.NewWriterSize(counter, exportBufferSize)
+	writer, err := export.NewTranscriptWriter(chatExport.Format, buffered)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Begin(&models.TranscriptHeader{
+		ExportID:   chatExport.ID,
+		Chat:       chat,
+		Members:    members,
+		ExportedAt: *chatExport.StartedAt,
+	}); err != nil {
+		return err
+	}
+
+	messageCount := 0
+	err = eu.messageRepo.StreamByChatId(chatExport.ChatId, func(message *models.Message) error {
+		messageCount++
+		return writer.WriteMessage(message)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.End(); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	completedAt := time.Now().UTC()
+	expiresAt := completedAt.Add(models.ExportRetention)
+	chatExport.FileName = &fileName
+	chatExport.FileSize = counter.n
+	chatExport.MessageCount = messageCount
+	chatExport.CompletedAt = &completedAt
+	chatExport.ExpiresAt = &expiresAt
+	return eu.exportRepo.Complete(chatExport)
+}
+
+func (eu *ExportUsecase) purgeExpiredExports() {
+	expired, err := eu.exportRepo.FindExpired(time.Now().UTC(), exportPurgeBatchSize)
+	if err != nil {
+		logger.Error("Failed to find expired exports: %v", err)
+		return
+	}
+
+	for _, chatExport := range expired {
+		if chatExport.FileName != nil {
+			if err := eu.store.Remove(*chatExport.FileName); err != nil {
+				logger.Error("Failed to remove export file of export %d: %v", chatExport.ID, err)
+				continue
+			}
+		}
+		if err := eu.exportRepo.MarkExpired(chatExport.ID); err != nil {
+			logger.Error("Failed to mark export %d as expired: %v", chatExport.ID, err)
+		}
+	}
+}
+
+// countingWriter counts the bytes written to the export file
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,659 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/richtext"
+	"github.com/f1rstid/realtime-chat/infrastructure/slackimport"
+)
+
+const (
+	importInterval = 5 * time.Second
+	// Counts are saved every so many messages so the progress can be followed
+	importProgressInterval = 500
+	// Suffixes tried to make the nickname of an imported user unique
+	maxNicknameSuffix = 1000
+	// importedNickname is used for users whose names have no usable characters
+	importedNickname = "slack_user"
+)
+
+// nicknameInvalidChars are the characters a nickname cannot contain
+var nicknameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9가-힣_.]+`)
+
+// ImportUsecase imports Slack export archives. Imports run in the background
+// for uploaded archives and in the foreground for the import command.
+//
+// Imports are idempotent: each Slack user and channel is mapped to the user or
+// chat created or matched for it, and each message is stored with its Slack
+// timestamp as the sender's idempotency key. Importing an archive again, or
+// resuming an interrupted import, skips what was already imported.
+type ImportUsecase struct {
+	importRepo   repositories.ImportRepository
+	userRepo     repositories.UserRepository
+	chatRepo     repositories.ChatRepository
+	messageRepo  repositories.MessageRepository
+	reactionRepo repositories.ReactionRepository
+	archiveDir   string
+	admins       serverAdmins
+	wake         chan struct{}
+}
+
+func NewImportUsecase(
+	importRepo repositories.ImportRepository,
+	userRepo repositories.UserRepository,
+	chatRepo repositories.ChatRepository,
+	messageRepo repositories.MessageRepository,
+	reactionRepo repositories.ReactionRepository,
+	archiveDir string,
+	adminEmails []string,
+) *ImportUsecase {
+	return &ImportUsecase{
+		importRepo:   importRepo,
+		userRepo:     userRepo,
+		chatRepo:     chatRepo,
+		messageRepo:  messageRepo,
+		reactionRepo: reactionRepo,
+		archiveDir:   archiveDir,
+		admins:       newServerAdmins(adminEmails),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// CreateImport stores an uploaded archive and queues its import
+func (iu *ImportUsecase) CreateImport(userID int, archive io.Reader) (*dto.ChatImportResponse, error) {
+	if err := iu.authorize(userID); err != nil {
+		return nil, err
+	}
+
+	archivePath, err := iu.saveArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	chatImport := &models.ChatImport{
+		RequestedBy: userID,
+		Source:      models.ImportSourceUpload,
+		ArchivePath: archivePath,
+		Status:      models.ImportStatusPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := iu.importRepo.Create(chatImport); err != nil {
+		logger.Error("Failed to create import: %v", err)
+		os.Remove(archivePath)
+		return nil, err
+	}
+	iu.notify()
+
+	return dto.NewChatImportResponse(chatImport), nil
+}
+
+// saveArchive writes an upload to the archive directory and checks that it
+// is a Slack export
+func (iu *ImportUsecase) saveArchive(archive io.Reader) (string, error) {
+	if err := os.MkdirAll(iu.archiveDir, 0700); err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(iu.archiveDir, "slack-*.zip")
+	if err != nil {
+		return "", err
+	}
+	archivePath := file.Name()
+
+	_, err = io.Copy(file, archive)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(archivePath)
+		return "", err
+	}
+
+	opened, err := slackimport.Open(archivePath)
+	if err != nil {
+		os.Remove(archivePath)
+		return "", errors.New("invalid import archive")
+	}
+	opened.Close()
+
+	return archivePath, nil
+}
+
+// GetImport returns the status of an import
+func (iu *ImportUsecase) GetImport(importID, userID int) (*dto.ChatImportResponse, error) {
+	if err := iu.authorize(userID); err != nil {
+		return nil, err
+	}
+
+	chatImport, err := iu.findImport(importID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewChatImportResponse(chatImport), nil
+}
+
+// ResumeImport queues a failed import again. Users, chats and messages
+// imported before it failed are skipped.
+func (iu *ImportUsecase) ResumeImport(importID, userID int) (*dto.ChatImportResponse, error) {
+	if err := iu.authorize(userID); err != nil {
+		return nil, err
+	}
+
+	requeued, err := iu.importRepo.Requeue(importID)
+	if err != nil {
+		return nil, err
+	}
+	chatImport, err := iu.findImport(importID)
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		return nil, errors.New("import not resumable")
+	}
+	iu.notify()
+
+	return dto.NewChatImportResponse(chatImport), nil
+}
+
+func (iu *ImportUsecase) findImport(importID int) (*models.ChatImport, error) {
+	chatImport, err := iu.importRepo.FindById(importID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("import not found")
+		}
+		return nil, err
+	}
+	return chatImport, nil
+}
+
+// authorize checks that the user may import. Imports create accounts and
+// post as other users, so they are limited to server administrators.
+func (iu *ImportUsecase) authorize(userID int) error {
+	user, err := iu.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !iu.admins.includes(user) {
+		return errors.New("unauthorized to import")
+	}
+	return nil
+}
+
+// ImportArchive imports an archive on the server in the foreground, on
+// behalf of the given user. An unfinished import of the same archive is
+// resumed instead of starting over.
+func (iu *ImportUsecase) ImportArchive(archivePath string, userID int) (*models.ChatImport, error) {
+	archivePath, err := filepath.Abs(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	chatImport, err := iu.importRepo.FindUnfinishedByArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if chatImport != nil {
+		claimed, err := iu.importRepo.Claim(chatImport.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			return nil, errors.New("import already running")
+		}
+		logger.Info("Resuming import %d of %s", chatImport.ID, archivePath)
+	} else {
+		chatImport = &models.ChatImport{
+			RequestedBy: userID,
+			Source:      models.ImportSourceCommand,
+			ArchivePath: archivePath,
+			Status:      models.ImportStatusRunning,
+			CreatedAt:   now,
+			StartedAt:   &now,
+		}
+		if err := iu.importRepo.Create(chatImport); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := iu.execute(chatImport); err != nil {
+		return nil, err
+	}
+	return iu.importRepo.FindById(chatImport.ID)
+}
+
+// Run imports queued archives. Imports left running by a previous process
+// are queued again and resume where they stopped.
+func (iu *ImportUsecase) Run() {
+	if err := iu.importRepo.ResetRunning(); err != nil {
+		logger.Error("Failed to requeue interrupted imports: %v", err)
+	}
+
+	ticker := time.NewTicker(importInterval)
+	defer ticker.Stop()
+
+	for {
+		iu.runPendingImports()
+
+		select {
+		case <-ticker.C:
+		case <-iu.wake:
+		}
+	}
+}
+
+// notify wakes Run so new imports do not wait for the next tick
+func (iu *ImportUsecase) notify() {
+	select {
+	case iu.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (iu *ImportUsecase) runPendingImports() {
+	for {
+		chatImport, err := iu.importRepo.ClaimNext(time.Now().UTC())
+		if err != nil {
+			logger.Error("Failed to claim import: %v", err)
+			return
+		}
+		if chatImport == nil {
+			return
+		}
+		iu.execute(chatImport)
+	}
+}
+
+// execute runs a claimed import and records its outcome
+func (iu *ImportUsecase) execute(chatImport *models.ChatImport) error {
+	if err := iu.process(chatImport); err != nil {
+		logger.Error("Failed to import %s (import %d): %v", chatImport.ArchivePath, chatImport.ID, err)
+		if failErr := iu.importRepo.Fail(chatImport.ID, time.Now().UTC(), err.Error()); failErr != nil {
+			logger.Error("Failed to mark import %d as failed: %v", chatImport.ID, failErr)
+		}
+		return err
+	}
+
+	completedAt := time.Now().UTC()
+	chatImport.CompletedAt = &completedAt
+	if err := iu.importRepo.Complete(chatImport); err != nil {
+		logger.Error("Failed to mark import %d as completed: %v", chatImport.ID, err)
+		return err
+	}
+	logger.Info("Imported %s (import %d): %d users, %d chats, %d messages", chatImport.ArchivePath, chatImport.ID,
+		chatImport.UserCount, chatImport.ChatCount, chatImport.MessageCount)
+
+	// Uploaded archives are only kept to resume their import
+	if chatImport.Source == models.ImportSourceUpload {
+		if err := os.Remove(chatImport.ArchivePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Error("Failed to remove archive of import %d: %v", chatImport.ID, err)
+		}
+	}
+	return nil
+}
+
+// importRun is the state of one import of an archive
+type importRun struct {
+	chatImport *models.ChatImport
+	archive    *slackimport.Archive
+	// userIDs and nicknames are indexed by Slack user ID
+	userIDs   map[string]int
+	nicknames map[string]string
+}
+
+func (iu *ImportUsecase) process(chatImport *models.ChatImport) error {
+	archive, err := slackimport.Open(chatImport.ArchivePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	run := &importRun{
+		chatImport: chatImport,
+		archive:    archive,
+		userIDs:    make(map[string]int),
+		nicknames:  make(map[string]string),
+	}
+	if err := iu.importUsers(run); err != nil {
+		return err
+	}
+
+	channels, err := archive.Channels()
+	if err != nil {
+		return err
+	}
+	for i := range channels {
+		if err := iu.importChannel(run, &channels[i]); err != nil {
+			return fmt.Errorf("channel %s: %w", channels[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// importUsers maps every Slack user to the account with the same email, or
+// to a placeholder account that cannot sign in
+func (iu *ImportUsecase) importUsers(run *importRun) error {
+	users, err := run.archive.Users()
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		slackUser := &users[i]
+		var user *models.User
+
+		userID, err := iu.importRepo.FindMapping(models.ImportKindUser, slackUser.ID)
+		switch {
+		case err == nil:
+			if user, err = iu.userRepo.FindByID(userID); err != nil {
+				return err
+			}
+		case errors.Is(err, sql.ErrNoRows):
+			if user, err = iu.matchUser(slackUser); err != nil {
+				return err
+			}
+			if err := iu.importRepo.SaveMapping(models.ImportKindUser, slackUser.ID, user.ID, run.chatImport.ID); err != nil {
+				return err
+			}
+			run.chatImport.UserCount++
+		default:
+			return err
+		}
+
+		run.userIDs[slackUser.ID] = user.ID
+		run.nicknames[slackUser.ID] = user.Nickname
+	}
+	return iu.importRepo.UpdateCounts(run.chatImport)
+}
+
+func (iu *ImportUsecase) matchUser(slackUser *slackimport.User) (*models.User, error) {
+	if email := strings.ToLower(strings.TrimSpace(slackUser.Profile.Email)); email != "" {
+		if user, err := iu.userRepo.FindByEmail(email); err == nil && !user.IsBot {
+			return user, nil
+		}
+	}
+
+	// A placeholder left by an import that stopped before saving its mapping
+	email := models.ImportedUserEmail(strings.ToLower(slackUser.ID))
+	if user, err := iu.userRepo.FindByEmail(email); err == nil {
+		return user, nil
+	}
+
+	nickname, err := iu.uniqueNickname(slackUser)
+	if err != nil {
+		return nil, err
+	}
+	// Placeholders have no password, so they cannot sign in
+	return iu.userRepo.Create(&models.User{
+		Email:     email,
+		Nickname:  nickname,
+		CreatedAt: time.Now(),
+	})
+}
+
+// uniqueNickname derives a valid nickname from the Slack names of a user,
+// numbering it when taken
+func (iu *ImportUsecase) uniqueNickname(slackUser *slackimport.User) (string, error) {
+	base := importedNickname
+	for _, name := range []string{slackUser.Profile.DisplayName, slackUser.Name, slackUser.Profile.RealName} {
+		if candidate := sanitizeNickname(name); models.ValidateNickname(candidate) == nil {
+			base = candidate
+			break
+		}
+	}
+
+	for n := 1; n <= maxNicknameSuffix; n++ {
+		nickname := base
+		if n > 1 {
+			suffix := fmt.Sprintf("_%d", n)
+			nickname = truncateBytes(base, 20-len(suffix)) + suffix
+		}
+		if _, err := iu.userRepo.FindByNickname(nickname); errors.Is(err, sql.ErrNoRows) {
+			return nickname, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free nickname for slack user %s", slackUser.ID)
+}
+
+// sanitizeNickname replaces the characters nicknames cannot contain and
+// shortens the name to the nickname length limit
+func sanitizeNickname(name string) string {
+	name = nicknameInvalidChars.ReplaceAllString(strings.TrimSpace(name), "_")
+	return truncateBytes(strings.Trim(name, "_"), 20)
+}
+
+// truncateBytes shortens s to at most n bytes without splitting a character
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// importChannel creates the chat of a channel, adds its members and imports
+// its messages
+func (iu *ImportUsecase) importChannel(run *importRun, channel *slackimport.Channel) error {
+	chatID, err := iu.importRepo.FindMapping(models.ImportKindChannel, channel.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		chat := &models.Chat{Name: run.chatName(channel)}
+		if err := iu.chatRepo.Create(chat); err != nil {
+			return err
+		}
+		topic := channel.Topic.Value
+		if topic == "" {
+			topic = channel.Purpose.Value
+		}
+		if topic = strings.TrimSpace(topic); topic != "" {
+			if utf8.RuneCountInString(topic) > models.MaxChatTopicLength {
+				topic = string([]rune(topic)[:models.MaxChatTopicLength])
+			}
+			if err := iu.chatRepo.UpdateTopic(chat.ID, topic); err != nil {
+				return err
+			}
+		}
+		if err := iu.importRepo.SaveMapping(models.ImportKindChannel, channel.ID, chat.ID, run.chatImport.ID); err != nil {
+			return err
+		}
+		chatID = chat.ID
+		run.chatImport.ChatCount++
+	default:
+		return err
+	}
+
+	if err := iu.importMembers(run, channel, chatID); err != nil {
+		return err
+	}
+	if err := iu.importMessages(run, channel, chatID); err != nil {
+		return err
+	}
+	return iu.importRepo.UpdateCounts(run.chatImport)
+}
+
+// chatName names direct messages after their members, like private chats
+func (run *importRun) chatName(channel *slackimport.Channel) string {
+	if !channel.IsDirect() {
+		return channel.Name
+	}
+	var names []string
+	for _, member := range channel.Members {
+		if nickname, ok := run.nicknames[member]; ok {
+			names = append(names, nickname)
+		}
+	}
+	if len(names) == 0 {
+		return channel.Name
+	}
+	return strings.Join(names, "-")
+}
+
+// importMembers adds the channel members missing from the chat. The channel
+// creator owns the chat; both sides of a direct message manage it.
+func (iu *ImportUsecase) importMembers(run *importRun, channel *slackimport.Channel, chatID int) error {
+	for _, member := range channel.Members {
+		userID, ok := run.userIDs[member]
+		if !ok {
+			continue
+		}
+		if _, err := iu.chatRepo.GetUserRole(chatID, userID); err == nil {
+			continue
+		}
+		if err := iu.chatRepo.AddUserToChat(chatID, userID); err != nil {
+			return err
+		}
+
+		role := models.ChatRoleMember
+		switch {
+		case channel.Kind == slackimport.ChannelKindDM:
+			role = models.ChatRoleAdmin
+		case member == channel.Creator:
+			role = models.ChatRoleOwner
+		}
+		if role != models.ChatRoleMember {
+			if err := iu.chatRepo.SetUserRole(chatID, userID, role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importMessages stores the messages of a channel in timestamp order, so
+// their IDs follow the original order
+func (iu *ImportUsecase) importMessages(run *importRun, channel *slackimport.Channel, chatID int) error {
+	// Thread roots by Slack timestamp, for linking their replies
+	threadRoots := make(map[string]int)
+
+	return run.archive.Messages(channel, func(slackMessage *slackimport.Message) error {
+		if !slackMessage.IsContent() {
+			return nil
+		}
+		createdAt, err := slackimport.ParseTimestamp(slackMessage.TS)
+		if err != nil {
+			logger.Info("Skipping message with invalid timestamp %q in channel %s", slackMessage.TS, channel.ID)
+			return nil
+		}
+
+		senderID, botName, ok := run.sender(slackMessage)
+		if !ok {
+			return nil
+		}
+
+		// The Slack timestamp identifies the message within its channel
+		clientMessageID := "slack:" + channel.ID + ":" + slackMessage.TS
+		message, err := iu.messageRepo.FindByClientMessageId(senderID, clientMessageID)
+		switch {
+		case err == nil:
+		case errors.Is(err, sql.ErrNoRows):
+			content := run.messageContent(slackMessage)
+			if content == "" {
+				return nil
+			}
+
+			message = &models.Message{
+				ChatId:          chatID,
+				SenderId:        senderID,
+				Type:            models.MessageTypeUser,
+				Content:         content,
+				Formatted:       richtext.Parse(content),
+				CreatedAt:       createdAt,
+				UpdatedAt:       createdAt,
+				ClientMessageId: &clientMessageID,
+				BotName:         botName,
+			}
+			message.PlainText = message.Formatted.PlainText()
+			if slackMessage.Edited != nil {
+				if editedAt, err := slackimport.ParseTimestamp(slackMessage.Edited.TS); err == nil && editedAt.After(createdAt) {
+					message.UpdatedAt = editedAt
+				}
+			}
+			if slackMessage.IsReply() {
+				if rootID, ok := threadRoots[slackMessage.ThreadTS]; ok {
+					message.ThreadRootId = &rootID
+				}
+			}
+
+			if err := iu.messageRepo.Create(message); err != nil {
+				return err
+			}
+			run.chatImport.MessageCount++
+			if run.chatImport.MessageCount%importProgressInterval == 0 {
+				if err := iu.importRepo.UpdateCounts(run.chatImport); err != nil {
+					return err
+				}
+			}
+		default:
+			return err
+		}
+
+		if slackMessage.ThreadTS == slackMessage.TS {
+			threadRoots[slackMessage.TS] = message.ID
+		}
+
+		// Reactions are added again for messages imported before an
+		// interruption, in case it came before their reactions
+		for _, reaction := range slackMessage.Reactions {
+			for _, reactor := range reaction.Users {
+				if userID, ok := run.userIDs[reactor]; ok {
+					if err := iu.reactionRepo.Add(message.ID, userID, reaction.Name, createdAt); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// sender returns who an imported message is stored as. Messages of
+// integrations without a Slack user are stored as the user running the
+// import and shown under the integration's name, like webhook messages.
+func (run *importRun) sender(message *slackimport.Message) (int, *string, bool) {
+	if userID, ok := run.userIDs[message.User]; ok {
+		return userID, nil, true
+	}
+	if message.Subtype == "bot_message" {
+		botName := message.Username
+		if botName == "" {
+			botName = "Slack"
+		}
+		return run.chatImport.RequestedBy, &botName, true
+	}
+	return 0, nil, false
+}
+
+// messageContent converts the text of a message and lists its files, which
+// are not imported
+func (run *importRun) messageContent(message *slackimport.Message) string {
+	content := slackimport.FormatText(message.Text, func(userID string) string {
+		return run.nicknames[userID]
+	})
+	for _, file := range message.Files {
+		name := file.Name
+		if name == "" {
+			name = file.Title
+		}
+		if name != "" {
+			content += "\n[첨부 파일: " + name + "]"
+		}
+	}
+	return strings.TrimSpace(content)
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/infrastructure/sqlite"
+	"github.com/f1rstid/realtime-chat/interfaces/repositories"
+)
+
+// writeTestArchive writes a Slack export with one channel and one message
+func writeTestArchive(t *testing.T, ts string) string {
+	t.Helper()
+	files := map[string]string{
+		"users.json":              `[{"id":"U1","name":"alice","profile":{"email":"alice@example.com"}}]`,
+		"channels.json":           `[{"id":"C1","name":"general","creator":"U1","members":["U1"]}]`,
+		"general/2026-10-19.json": `[{"type":"message","user":"U1","text":"hello","ts":"` + ts + `"}]`,
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "export.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(file)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestImportStoresMessagesInLocalTime(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("KST", 9*60*60)
+	t.Cleanup(func() { time.Local = local })
+	newTestDB(t)
+
+	cipher, err := repositories.NewContentCipher(sqlite.DB, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iu := NewImportUsecase(
+		repositories.NewImportRepository(sqlite.DB),
+		repositories.NewUserRepository(sqlite.DB),
+		repositories.NewChatRepository(sqlite.DB, cipher),
+		repositories.NewMessageRepository(sqlite.DB, cipher),
+		repositories.NewReactionRepository(sqlite.DB),
+		t.TempDir(),
+		nil,
+	)
+
+	// 2026-10-19 01:00 UTC, 10:00 in the server's zone
+	if _, err := iu.ImportArchive(writeTestArchive(t, "1792371600.000100"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var createdAt string
+	if err := sqlite.DB.Get(&createdAt, `SELECT CAST(createdAt AS TEXT) FROM messages WHERE clientMessageId IS NOT NULL`); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(createdAt, "2026-10-19 10:00:00.0001") || !strings.HasSuffix(createdAt, "+09:00") {
+		t.Errorf("createdAt = %s, want 2026-10-19 10:00:00.0001 in the server's zone", createdAt)
+	}
+}
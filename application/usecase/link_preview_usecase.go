@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/domain/services"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+const (
+	// MaxLinkPreviewsPerMessage is the maximum number of URLs unfurled per message
+	MaxLinkPreviewsPerMessage = 3
+
+	linkPreviewCacheTTL   = 24 * time.Hour
+	linkPreviewFailureTTL = time.Hour
+	linkPreviewWorkers    = 4
+	linkPreviewQueueSize  = 256
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+type linkPreviewJob struct {
+	messageID int
+	chatID    int
+	content   string
+}
+
+type LinkPreviewUsecase struct {
+	previewRepo repositories.LinkPreviewRepository
+	messageRepo repositories.MessageRepository
+	chatRepo    repositories.ChatRepository
+	fetcher     services.LinkPreviewFetcher
+	wsHub       *websocket.Hub
+	jobs        chan linkPreviewJob
+}
+
+func NewLinkPreviewUsecase(
+	previewRepo repositories.LinkPreviewRepository,
+	messageRepo repositories.MessageRepository,
+	chatRepo repositories.ChatRepository,
+	fetcher services.LinkPreviewFetcher,
+	wsHub *websocket.Hub,
+) *LinkPreviewUsecase {
+	return &LinkPreviewUsecase{
+		previewRepo: previewRepo,
+		messageRepo: messageRepo,
+		chatRepo:    chatRepo,
+		fetcher:     fetcher,
+		wsHub:       wsHub,
+		jobs:        make(chan linkPreviewJob, linkPreviewQueueSize),
+	}
+}
+
+// Run starts the workers unfurling queued messages
+func (lu *LinkPreviewUsecase) Run() {
+	var wg sync.WaitGroup
+	for i := 0; i < linkPreviewWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range lu.jobs {
+				lu.process(job)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Enqueue schedules a new message for unfurling without blocking the caller
+func (lu *LinkPreviewUsecase) Enqueue(message *models.Message) {
+	if len(extractURLs(message.Content)) == 0 {
+		return
+	}
+
+	select {
+	case lu.jobs <- linkPreviewJob{messageID: message.ID, chatID: message.ChatId, content: message.Content}:
+	default:
+		logger.Error("Link preview queue is full, dropping message %d", message.ID)
+	}
+}
+
+// Refresh replaces the previews of an edited message
+func (lu *LinkPreviewUsecase) Refresh(message *models.Message) {
+	if len(extractURLs(message.Content)) == 0 {
+		if err := lu.previewRepo.SetMessageLinks(message.ChatId, message.ID, nil); err != nil {
+			logger.Error("Failed to clear link previews of message %d: %v", message.ID, err)
+		}
+		return
+	}
+	lu.Enqueue(message)
+}
+
+// AttachPreviews loads the previews of the given messages
+func (lu *LinkPreviewUsecase) AttachPreviews(messages []models.Message) error {
+	messageIDs := make([]int, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.ID
+	}
+
+	previews, err := lu.previewRepo.FindByMessageIds(messageIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range messages {
+		messages[i].LinkPreviews = previews[messages[i].ID]
+	}
+	return nil
+}
+
+func (lu *LinkPreviewUsecase) process(job linkPreviewJob) {
+	urls := extractURLs(job.content)
+	previews := make([]models.LinkPreview, 0, len(urls))
+	for _, rawURL := range urls {
+		preview, err := lu.getPreview(job.chatID, rawURL)
+		if err != nil {
+			logger.Error("Failed to get link preview for %s: %v", rawURL, err)
+			continue
+		}
+		if !preview.Failed {
+			previews = append(previews, *preview)
+		}
+	}
+
+	// Skip messages deleted or edited while their links were being fetched;
+	// an edit queues its own job
+	message, err := lu.messageRepo.FindById(job.messageID)
+	if err != nil || message.Content != job.content {
+		return
+	}
+
+	if err := lu.previewRepo.SetMessageLinks(message.ChatId, message.ID, urls); err != nil {
+		logger.Error("Failed to attach link previews to message %d: %v", message.ID, err)
+		return
+	}
+
+	if len(previews) == 0 {
+		return
+	}
+
+	users, err := lu.chatRepo.GetChatUsers(message.ChatId)
+	if err != nil {
+		logger.Error("Failed to get chat users: %v", err)
+		return
+	}
+
+	message.LinkPreviews = previews
+	broadcastToUsers(lu.wsHub, users, events.EventMessageUpdated, message.ChatId, newMessageEventData(message))
+}
+
+// getPreview returns the cached preview of a URL linked in a chat, fetching
+// it when missing or stale
+func (lu *LinkPreviewUsecase) getPreview(chatID int, rawURL string) (*models.LinkPreview, error) {
+	cached, err := lu.previewRepo.FindByURL(chatID, rawURL)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if cached != nil {
+		ttl := linkPreviewCacheTTL
+		if cached.Failed {
+			ttl = linkPreviewFailureTTL
+		}
+		if time.Since(cached.FetchedAt) < ttl {
+			return cached, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	preview, err := lu.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		// Cache the failure so the URL is not fetched for every message
+		logger.Info("Link preview fetch failed for %s: %v", rawURL, err)
+		preview = &models.LinkPreview{URL: rawURL, Failed: true, FetchedAt: time.Now()}
+	}
+	preview.URL = rawURL
+
+	if err := lu.previewRepo.Save(chatID, preview); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// extractURLs returns the distinct http(s) URLs of a message in order of appearance
+func extractURLs(content string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, match := range urlPattern.FindAllString(content, -1) {
+		match = strings.TrimRight(match, ".,;:!?)]}'\"")
+		parsed, err := url.Parse(match)
+		if err != nil || parsed.Host == "" || seen[match] {
+			continue
+		}
+		seen[match] = true
+		urls = append(urls, match)
+		if len(urls) == MaxLinkPreviewsPerMessage {
+			break
+		}
+	}
+	return urls
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+const (
+	expiryInterval  = 10 * time.Second
+	expiryBatchSize = 200
+)
+
+// MessageExpiryUsecase deletes disappearing messages once their lifetime ends.
+// Reads already hide expired messages, so the sweep only reclaims storage and
+// tells connected clients to drop them.
+type MessageExpiryUsecase struct {
+	messageRepo repositories.MessageRepository
+	chatRepo    repositories.ChatRepository
+	wsHub       *websocket.Hub
+}
+
+func NewMessageExpiryUsecase(
+	messageRepo repositories.MessageRepository,
+	chatRepo repositories.ChatRepository,
+	wsHub *websocket.Hub,
+) *MessageExpiryUsecase {
+	return &MessageExpiryUsecase{
+		messageRepo: messageRepo,
+		chatRepo:    chatRepo,
+		wsHub:       wsHub,
+	}
+}
+
+// Run sweeps expired messages until the process exits
+func (eu *MessageExpiryUsecase) Run() {
+	ticker := time.NewTicker(expiryInterval)
+	defer ticker.Stop()
+
+	for {
+		eu.sweep()
+		<-ticker.C
+	}
+}
+
+func (eu *MessageExpiryUsecase) sweep() {
+	for {
+		expired, err := eu.messageRepo.FindExpired(time.Now().UTC(), expiryBatchSize)
+		if err != nil {
+			logger.Error("Failed to find expired messages: %v", err)
+			return
+		}
+		if len(expired) == 0 {
+			return
+		}
+
+		ids := make([]int, len(expired))
+		byChat := make(map[int][]int)
+		for i, message := range expired {
+			ids[i] = message.ID
+			byChat[message.ChatId] = append(byChat[message.ChatId], message.ID)
+		}
+
+		if err := eu.messageRepo.DeleteByIds(ids); err != nil {
+			logger.Error("Failed to delete expired messages: %v", err)
+			return
+		}
+
+		for chatID, messageIDs := range byChat {
+			users, err := eu.chatRepo.GetChatUsers(chatID)
+			if err != nil {
+				logger.Error("Failed to get chat users: %v", err)
+				continue
+			}
+
+			broadcastToUsers(eu.wsHub, users, events.EventMessageExpired, chatID, &events.MessagesExpiredEventData{
+				ChatID:     chatID,
+				MessageIDs: messageIDs,
+			})
+		}
+
+		if len(expired) < expiryBatchSize {
+			return
+		}
+	}
+}
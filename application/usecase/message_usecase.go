@@ -2,7 +2,12 @@
 package usecase
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/f1rstid/realtime-chat/domain/dto"
@@ -10,29 +15,429 @@ import (
 	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/domain/repositories"
 	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/ratelimit"
+	"github.com/f1rstid/realtime-chat/infrastructure/richtext"
 	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
 )
 
+// errStorage wraps database failures while sending a message, so callers
+// retrying a send can tell them from a rejected message
+var errStorage = errors.New("storage error")
+
+func storageError(err error) error {
+	return fmt.Errorf("%w: %v", errStorage, err)
+}
+
+// Search page sizes
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+)
+
+// History page sizes
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 100
+)
+
+// Forwarding limits per request
+const (
+	MaxForwardMessages = 100
+	MaxForwardChats    = 10
+)
+
+// Send limits. Every user and every chat has a token bucket allowing the
+// burst at once and the rate per minute after that.
+const (
+	UserMessageRatePerMinute = 30
+	UserMessageBurst         = 10
+	ChatMessageRatePerMinute = 120
+	ChatMessageBurst         = 30
+)
+
 type MessageUsecase struct {
-	messageRepo repositories.MessageRepository
-	chatRepo    repositories.ChatRepository
-	wsHub       *websocket.Hub
+	messageRepo   repositories.MessageRepository
+	chatRepo      repositories.ChatRepository
+	pinRepo       repositories.PinRepository
+	reactionRepo  repositories.ReactionRepository
+	deviceRepo    repositories.DeviceRepository
+	linkPreviewer *LinkPreviewUsecase
+	receipts      *ReceiptUsecase
+	polls         *PollUsecase
+	commands      *CommandUsecase
+	moderation    *ModerationPipeline
+	webhooks      *OutgoingWebhookUsecase
+	wsHub         *websocket.Hub
+	userLimiter   *ratelimit.Limiter
+	chatLimiter   *ratelimit.Limiter
+	slowMode      *ratelimit.Cooldown
 }
 
 func NewMessageUsecase(
 	messageRepo repositories.MessageRepository,
 	chatRepo repositories.ChatRepository,
+	pinRepo repositories.PinRepository,
+	reactionRepo repositories.ReactionRepository,
+	deviceRepo repositories.DeviceRepository,
+	linkPreviewer *LinkPreviewUsecase,
+	receipts *ReceiptUsecase,
+	polls *PollUsecase,
+	commands *CommandUsecase,
+	moderation *ModerationPipeline,
+	webhooks *OutgoingWebhookUsecase,
 	wsHub *websocket.Hub,
 ) *MessageUsecase {
 	return &MessageUsecase{
-		messageRepo: messageRepo,
-		chatRepo:    chatRepo,
-		wsHub:       wsHub,
+		messageRepo:   messageRepo,
+		chatRepo:      chatRepo,
+		pinRepo:       pinRepo,
+		reactionRepo:  reactionRepo,
+		deviceRepo:    deviceRepo,
+		linkPreviewer: linkPreviewer,
+		receipts:      receipts,
+		polls:         polls,
+		commands:      commands,
+		moderation:    moderation,
+		webhooks:      webhooks,
+		wsHub:         wsHub,
+		userLimiter:   ratelimit.New(UserMessageRatePerMinute, UserMessageBurst),
+		chatLimiter:   ratelimit.New(ChatMessageRatePerMinute, ChatMessageBurst),
+		slowMode:      ratelimit.NewCooldown(models.MaxSlowModeSeconds * time.Second),
+	}
+}
+
+// SendMessageInput defines the input data for sending a message
+type SendMessageInput struct {
+	ChatID   int
+	SenderID int
+	Content  string
+	// TTLSeconds makes the message disappear after the given number of seconds.
+	// Zero uses the chat's disappearing messages setting.
+	TTLSeconds int
+	// ClientMessageID deduplicates retries of the same send
+	ClientMessageID string
+	// Attachments are cards shown below the content; only bots may send them
+	Attachments []models.MessageAttachment
+	// SenderIsBot is set from the authenticated caller
+	SenderIsBot bool
+	// ScheduledMessageID is set when the scheduler posts a scheduled message
+	ScheduledMessageID int
+}
+
+// SendMessage sends a new message in a chat. Messages starting with a slash
+// run the named command instead.
+func (mu *MessageUsecase) SendMessage(input SendMessageInput) (*dto.MessageResponse, error) {
+	if name, args, ok := models.ParseCommand(input.Content); ok {
+		return mu.runCommand(input, name, args)
+	}
+
+	// A doubled slash sends a message starting with a slash
+	if strings.HasPrefix(input.Content, "//") {
+		input.Content = input.Content[1:]
+	}
+
+	return mu.postMessage(input)
+}
+
+// runCommand dispatches a slash command typed by a chat member. Replies are
+// returned as ephemeral messages, which are neither stored nor broadcast.
+func (mu *MessageUsecase) runCommand(input SendMessageInput, name, args string) (*dto.MessageResponse, error) {
+	if _, err := mu.chatRepo.FindById(input.ChatID); err != nil {
+		return nil, errors.New("chat not found")
 	}
+	if _, err := mu.chatRepo.GetUserRole(input.ChatID, input.SenderID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	registered, ok := mu.commands.lookup(name)
+	if !ok {
+		return newEphemeralMessage(input.ChatID, fmt.Sprintf("알 수 없는 명령어입니다: `/%s`. `/help`로 사용 가능한 명령어를 확인하세요", name)), nil
+	}
+
+	result, err := registered.handler(&CommandContext{
+		ChatID: input.ChatID,
+		UserID: input.SenderID,
+		Args:   args,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Post != "" {
+		input.Content = result.Post
+		return mu.postMessage(input)
+	}
+	return newEphemeralMessage(input.ChatID, result.Reply), nil
 }
 
-// SendMessage sends a new message in a chat
-func (mu *MessageUsecase) SendMessage(chatID, senderID int, content string) (*dto.MessageResponse, error) {
+// postMessage stores and broadcasts a message sent by a user
+func (mu *MessageUsecase) postMessage(input SendMessageInput) (*dto.MessageResponse, error) {
+	message := &models.Message{
+		ChatId:   input.ChatID,
+		SenderId: input.SenderID,
+		Content:  input.Content,
+	}
+
+	if len(input.Attachments) > 0 {
+		if !input.SenderIsBot {
+			return nil, errors.New("only bots can send attachments")
+		}
+		if err := models.ValidateAttachments(input.Attachments); err != nil {
+			return nil, err
+		}
+		message.Attachments = formatAttachments(input.Attachments)
+	}
+
+	if input.TTLSeconds != 0 {
+		if err := models.ValidateMessageTTL(input.TTLSeconds); err != nil {
+			return nil, err
+		}
+		expiresAt := time.Now().UTC().Add(time.Duration(input.TTLSeconds) * time.Second)
+		message.ExpiresAt = &expiresAt
+	}
+
+	if input.ScheduledMessageID != 0 {
+		scheduledID := input.ScheduledMessageID
+		message.ScheduledMessageId = &scheduledID
+	}
+
+	if input.ClientMessageID == "" {
+		return mu.sendModerated(message)
+	}
+
+	if err := models.ValidateClientMessageID(input.ClientMessageID); err != nil {
+		return nil, err
+	}
+	clientMessageID := input.ClientMessageID
+	message.ClientMessageId = &clientMessageID
+
+	// A retry returns the message stored by the first attempt
+	if existing, err := mu.findByClientMessageID(input.SenderID, input.ChatID, clientMessageID); existing != nil || err != nil {
+		return existing, err
+	}
+
+	response, err := mu.sendModerated(message)
+	if err != nil {
+		// A concurrent retry may have won the unique index
+		if existing, findErr := mu.findByClientMessageID(input.SenderID, input.ChatID, clientMessageID); existing != nil || findErr != nil {
+			return existing, findErr
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// sendModerated applies the send limits and the moderation filters to a
+// user's message, then sends it with the masked content and queues it for
+// review if it was flagged
+func (mu *MessageUsecase) sendModerated(message *models.Message) (*dto.MessageResponse, error) {
+	chat, refund, err := mu.checkSendRate(message.ChatId, message.SenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := mu.moderateAndSend(chat, message)
+	if err != nil {
+		// A rejected or failed message does not count against the limits
+		refund()
+		return nil, err
+	}
+	return response, nil
+}
+
+// moderateAndSend runs the moderation filters over a message to chat, and
+// over the options of its poll, then sends it with the masked content and
+// queues it for review if it was flagged
+func (mu *MessageUsecase) moderateAndSend(chat *models.Chat, message *models.Message) (*dto.MessageResponse, error) {
+	// The filters cannot read encrypted messages
+	if chat.Encrypted {
+		return mu.sendMessage(message)
+	}
+
+	result, err := mu.moderation.Moderate(message.ChatId, message.SenderId, message.Content)
+	if err != nil {
+		return nil, err
+	}
+	message.Content = result.Content
+
+	// The question of a poll is the message content
+	if message.Poll != nil {
+		message.Poll.Question = result.Content
+		for i := range message.Poll.Options {
+			option, err := mu.moderation.Moderate(message.ChatId, message.SenderId, message.Poll.Options[i].Text)
+			if err != nil {
+				return nil, err
+			}
+			message.Poll.Options[i].Text = option.Content
+			result.Reasons = append(result.Reasons, option.Reasons...)
+		}
+	}
+
+	response, err := mu.sendMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	mu.moderation.Flag(message, result)
+
+	return response, nil
+}
+
+// checkSendRate takes a token from the sender's and the chat's buckets, then
+// starts the sender's slow mode interval. A check that rejects the send gives
+// back what the earlier ones took, so a rejected send costs nothing. The
+// returned refund does the same for a send that fails after the checks.
+// Membership is checked first so outsiders cannot drain a chat's bucket.
+func (mu *MessageUsecase) checkSendRate(chatID, userID int) (*models.Chat, func(), error) {
+	chat, err := mu.chatRepo.FindById(chatID)
+	if err != nil {
+		return nil, nil, errors.New("chat not found")
+	}
+	role, err := mu.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return nil, nil, errors.New("user is not a member of this chat")
+	}
+
+	var refunds []func()
+	refund := func() {
+		for _, r := range refunds {
+			r()
+		}
+	}
+
+	userKey := strconv.Itoa(userID)
+	if allowed, wait := mu.userLimiter.Allow(userKey); !allowed {
+		return nil, nil, &models.RateLimitError{Scope: models.RateLimitScopeUser, RetryAfter: wait}
+	}
+	refunds = append(refunds, func() { mu.userLimiter.Refund(userKey) })
+
+	chatKey := strconv.Itoa(chatID)
+	if allowed, wait := mu.chatLimiter.Allow(chatKey); !allowed {
+		refund()
+		return nil, nil, &models.RateLimitError{Scope: models.RateLimitScopeChat, RetryAfter: wait}
+	}
+	refunds = append(refunds, func() { mu.chatLimiter.Refund(chatKey) })
+
+	// Owners and admins are exempt from slow mode
+	if chat.SlowModeSeconds == 0 || models.CanManageChat(role) {
+		return chat, refund, nil
+	}
+	slowModeKey := chatKey + ":" + userKey
+	if allowed, wait := mu.slowMode.Take(slowModeKey, time.Duration(chat.SlowModeSeconds)*time.Second); !allowed {
+		refund()
+		return nil, nil, &models.RateLimitError{Scope: models.RateLimitScopeSlowMode, RetryAfter: wait}
+	}
+	refunds = append(refunds, func() { mu.slowMode.Cancel(slowModeKey) })
+
+	return chat, refund, nil
+}
+
+// sealEncrypted checks a message sent to an encrypted chat. Its content must
+// be an envelope from one of the sender's devices, and features that need the
+// server to read or write the content are unavailable.
+func (mu *MessageUsecase) sealEncrypted(message *models.Message) error {
+	if message.Poll != nil || len(message.Attachments) > 0 || message.WebhookId != nil {
+		return errors.New("not supported in encrypted chats")
+	}
+	if message.ForwardedFromMessageId != nil {
+		return errors.New("encrypted messages cannot be forwarded")
+	}
+	if err := mu.checkEnvelope(message.SenderId, message.Content); err != nil {
+		return err
+	}
+	message.Encrypted = true
+	return nil
+}
+
+// checkEnvelope checks the shape of an encrypted envelope and that the sender
+// encrypted it on one of their own devices. The ciphertext is left as is.
+func (mu *MessageUsecase) checkEnvelope(senderID int, content string) error {
+	envelope, err := models.ParseEncryptedEnvelope(content)
+	if err != nil {
+		return err
+	}
+	device, err := mu.deviceRepo.FindById(envelope.SenderDeviceId)
+	if err != nil || device.UserId != senderID {
+		return errors.New("unknown sender device")
+	}
+	return nil
+}
+
+// formatAttachments parses the Markdown text of each card
+func formatAttachments(attachments []models.MessageAttachment) models.MessageAttachments {
+	formatted := make(models.MessageAttachments, len(attachments))
+	for i, attachment := range attachments {
+		if attachment.Text != "" {
+			attachment.Formatted = richtext.Parse(attachment.Text)
+		} else {
+			attachment.Formatted = nil
+		}
+		formatted[i] = attachment
+	}
+	return formatted
+}
+
+// newEphemeralMessage builds a command reply shown only to the caller
+func newEphemeralMessage(chatID int, content string) *dto.MessageResponse {
+	now := time.Now()
+	return dto.NewMessageResponse(&models.Message{
+		ChatId:    chatID,
+		Type:      models.MessageTypeEphemeral,
+		Content:   content,
+		Formatted: richtext.Parse(content),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// findByClientMessageID returns the sender's message stored under the client ID, if any
+func (mu *MessageUsecase) findByClientMessageID(senderID, chatID int, clientMessageID string) (*dto.MessageResponse, error) {
+	existing, err := mu.messageRepo.FindByClientMessageId(senderID, clientMessageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to find message by client message id: %v", err)
+		return nil, storageError(err)
+	}
+	if existing.ChatId != chatID {
+		return nil, errors.New("client message id already used")
+	}
+
+	if err := mu.linkPreviewer.AttachPreviews([]models.Message{*existing}); err != nil {
+		logger.Error("Failed to attach link previews: %v", err)
+	}
+	return dto.NewMessageResponse(existing), nil
+}
+
+// SendScheduledMessage posts a due scheduled message as if the sender sent it
+// now, so commands, send limits and moderation apply. The scheduled message ID
+// is unique among messages, so a message is never posted twice for the same
+// schedule. A command with only an ephemeral reply returns a message without ID.
+func (mu *MessageUsecase) SendScheduledMessage(scheduled *models.ScheduledMessage) (*dto.MessageResponse, error) {
+	if _, err := mu.chatRepo.GetUserRole(scheduled.ChatId, scheduled.SenderId); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	existing, err := mu.messageRepo.FindByScheduledMessageId(scheduled.ID)
+	if err == nil {
+		return dto.NewMessageResponse(existing), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, storageError(err)
+	}
+
+	return mu.SendMessage(SendMessageInput{
+		ChatID:             scheduled.ChatId,
+		SenderID:           scheduled.SenderId,
+		Content:            scheduled.Content,
+		ScheduledMessageID: scheduled.ID,
+	})
+}
+
+// sendMessage persists a message and broadcasts it to the chat users
+func (mu *MessageUsecase) sendMessage(message *models.Message) (*dto.MessageResponse, error) {
+	chatID := message.ChatId
+
 	// Verify chat exists and get users
 	chat, err := mu.chatRepo.FindById(chatID)
 	if err != nil {
@@ -43,47 +448,296 @@ func (mu *MessageUsecase) SendMessage(chatID, senderID int, content string) (*dt
 	users, err := mu.chatRepo.GetChatUsers(chatID)
 	if err != nil {
 		logger.Error("Failed to get chat users: %v", err)
-		return nil, err
+		return nil, storageError(err)
 	}
 
-	message := &models.Message{
-		ChatId:    chat.ID,
-		SenderId:  senderID,
-		Content:   content,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	if chat.Encrypted && !message.IsSystem() {
+		if err := mu.sealEncrypted(message); err != nil {
+			return nil, err
+		}
+	}
+
+	message.ChatId = chat.ID
+	if message.IsSystem() {
+		// System summaries contain user-chosen names, which must not be read as Markdown
+		message.Formatted = models.PlainRichText(message.Content)
+	} else if message.Encrypted {
+		// Clients format the decrypted text themselves
+		message.Type = models.MessageTypeUser
+		message.Formatted = nil
+	} else {
+		message.Type = models.MessageTypeUser
+		message.Formatted = richtext.Parse(message.Content)
+	}
+	message.PlainText = message.Formatted.PlainText()
+	message.CreatedAt = time.Now()
+	message.UpdatedAt = message.CreatedAt
+
+	// Apply the chat's disappearing messages setting
+	if message.ExpiresAt == nil && chat.MessageTTLSeconds > 0 {
+		expiresAt := message.CreatedAt.UTC().Add(time.Duration(chat.MessageTTLSeconds) * time.Second)
+		message.ExpiresAt = &expiresAt
 	}
 
 	if err := mu.messageRepo.Create(message); err != nil {
 		logger.Error("Failed to create message: %v", err)
-		return nil, err
+		return nil, storageError(err)
+	}
+
+	if message.Poll != nil {
+		if err := mu.polls.CreateForMessage(message); err != nil {
+			logger.Error("Failed to create poll: %v", err)
+			if err := mu.messageRepo.Delete(message.ID); err != nil {
+				logger.Error("Failed to delete message of failed poll: %v", err)
+			}
+			return nil, err
+		}
 	}
 
 	// Extract user IDs
 	userIDs := make([]int, len(users))
+	var recipientIDs []int
 	for i, user := range users {
 		userIDs[i] = user.ID
+		if user.ID != message.SenderId {
+			recipientIDs = append(recipientIDs, user.ID)
+		}
 	}
 
-	// Create and broadcast WebSocket event
-	eventData := &events.MessageEventData{
-		MessageID:      message.ID,
-		ChatID:         message.ChatId,
-		SenderID:       message.SenderId,
-		SenderNickname: message.SenderNickname,
-		Content:        message.Content,
-		CreatedAt:      message.CreatedAt,
-		UpdatedAt:      message.UpdatedAt,
+	// Nobody waits on the delivery of messages posted by a system or a webhook
+	if !message.IsSystem() && message.WebhookId == nil {
+		mu.receipts.TrackMessage(message, recipientIDs)
 	}
 
+	// Create and broadcast WebSocket event; the hub reports its delivery per recipient
+	eventData := newMessageEventData(message)
+
 	event := events.NewWebSocketEvent(events.EventMessageCreated, chatID, eventData)
 	if eventJSON, err := event.ToJSON(); err == nil {
-		mu.wsHub.BroadcastToUsers(userIDs, eventJSON)
+		mu.wsHub.DeliverToUsers(userIDs, message.ID, eventJSON)
+	}
+	mu.webhooks.PublishMessage(models.OutgoingEventMessageCreated, eventData)
+
+	// Link previews are pushed with a message.updated event once fetched
+	if !message.IsSystem() && !message.Encrypted {
+		mu.linkPreviewer.Enqueue(message)
 	}
 
 	return dto.NewMessageResponse(message), nil
 }
 
+// PostSystemMessage records chat activity in the timeline. The actor is stored
+// as the sender. Failures are logged, as the activity itself already happened.
+func (mu *MessageUsecase) PostSystemMessage(chatID int, event *models.SystemEvent) {
+	_, err := mu.sendMessage(&models.Message{
+		ChatId:      chatID,
+		SenderId:    event.Actor.UserId,
+		Type:        models.MessageTypeSystem,
+		SystemEvent: event,
+		Content:     event.Summary(),
+	})
+	if err != nil {
+		logger.Error("Failed to post %s system message in chat %d: %v", event.Action, chatID, err)
+	}
+}
+
+// PostWebhookMessage posts a message through an incoming webhook, shown under
+// the given bot name. It goes through the moderation filters like a member's
+// message; the webhook's own rate limit replaces the send limits.
+func (mu *MessageUsecase) PostWebhookMessage(webhook *models.IncomingWebhook, content, botName string) (*dto.MessageResponse, error) {
+	chat, err := mu.chatRepo.FindById(webhook.ChatId)
+	if err != nil {
+		return nil, errors.New("chat not found")
+	}
+
+	webhookID := webhook.ID
+	return mu.moderateAndSend(chat, &models.Message{
+		ChatId:    webhook.ChatId,
+		SenderId:  webhook.CreatedBy,
+		Content:   content,
+		WebhookId: &webhookID,
+		BotName:   &botName,
+	})
+}
+
+// ForwardMessages copies messages into other chats, keeping a reference to the
+// original message. The user must belong to every source and destination chat.
+func (mu *MessageUsecase) ForwardMessages(userID int, messageIDs, chatIDs []int) ([]dto.MessageResponse, error) {
+	messageIDs, chatIDs = uniqueInts(messageIDs), uniqueInts(chatIDs)
+	if len(messageIDs) == 0 {
+		return nil, errors.New("no messages to forward")
+	}
+	if len(chatIDs) == 0 {
+		return nil, errors.New("no chats to forward to")
+	}
+	if len(messageIDs) > MaxForwardMessages || len(chatIDs) > MaxForwardChats {
+		return nil, errors.New("too many messages to forward")
+	}
+
+	member := make(map[int]bool)
+	isMember := func(chatID int) bool {
+		if _, ok := member[chatID]; !ok {
+			_, err := mu.chatRepo.GetUserRole(chatID, userID)
+			member[chatID] = err == nil
+		}
+		return member[chatID]
+	}
+
+	sources := make([]*models.Message, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		message, err := mu.messageRepo.FindById(messageID)
+		if err != nil {
+			return nil, errors.New("message not found")
+		}
+		if !isMember(message.ChatId) {
+			return nil, errors.New("user is not a member of this chat")
+		}
+		if message.IsSystem() {
+			return nil, errors.New("system messages cannot be modified")
+		}
+		// The envelope is only readable by the devices it was encrypted for
+		if message.Encrypted {
+			return nil, errors.New("encrypted messages cannot be forwarded")
+		}
+		sources = append(sources, message)
+	}
+
+	// Keep the original conversation order
+	sort.Slice(sources, func(i, j int) bool { return sources[i].ID < sources[j].ID })
+
+	for _, chatID := range chatIDs {
+		chat, err := mu.chatRepo.FindById(chatID)
+		if err != nil {
+			return nil, errors.New("chat not found")
+		}
+		if !isMember(chatID) {
+			return nil, errors.New("user is not a member of this chat")
+		}
+		if chat.Encrypted {
+			return nil, errors.New("encrypted messages cannot be forwarded")
+		}
+	}
+
+	// A forward counts as one message in each destination chat. It is sent
+	// to all of them or, when a limit rejects one, to none.
+	refunds := make([]func(), 0, len(chatIDs))
+	refund := func() {
+		for _, r := range refunds {
+			r()
+		}
+	}
+	for _, chatID := range chatIDs {
+		_, r, err := mu.checkSendRate(chatID, userID)
+		if err != nil {
+			refund()
+			return nil, err
+		}
+		refunds = append(refunds, r)
+	}
+
+	// Forwarded content goes through the filters of each destination chat.
+	// Every copy is moderated before any is sent, so a rejection sends none.
+	forwards := make([]*models.Message, 0, len(sources)*len(chatIDs))
+	results := make([]*ModerationResult, 0, len(sources)*len(chatIDs))
+	for _, chatID := range chatIDs {
+		for _, source := range sources {
+			forward := models.ForwardOf(source, chatID, userID)
+			// Only reveal the original chat to its members
+			if forward.ForwardedFromChatId != nil && !isMember(*forward.ForwardedFromChatId) {
+				forward.ForwardedFromChatId = nil
+			}
+
+			result, err := mu.moderation.Moderate(chatID, userID, forward.Content)
+			if err != nil {
+				refund()
+				return nil, err
+			}
+			forward.Content = result.Content
+			forwards = append(forwards, forward)
+			results = append(results, result)
+		}
+	}
+
+	responses := make([]dto.MessageResponse, 0, len(forwards))
+	for i, forward := range forwards {
+		response, err := mu.sendMessage(forward)
+		if err != nil {
+			return nil, err
+		}
+		mu.moderation.Flag(forward, results[i])
+		responses = append(responses, *response)
+	}
+
+	return responses, nil
+}
+
+// CreatePollInput defines the input data for posting a poll
+type CreatePollInput struct {
+	ChatID         int
+	CreatorID      int
+	Question       string
+	Options        []string
+	MultipleChoice bool
+	Anonymous      bool
+	// ClosesAt optionally stops voting at the given time
+	ClosesAt *time.Time
+}
+
+// CreatePoll posts a poll message in a chat. The message content is the question.
+func (mu *MessageUsecase) CreatePoll(input CreatePollInput) (*dto.MessageResponse, error) {
+	now := time.Now().UTC()
+	question := strings.TrimSpace(input.Question)
+	options := make([]string, len(input.Options))
+	for i, option := range input.Options {
+		options[i] = strings.TrimSpace(option)
+	}
+
+	var closesAt *time.Time
+	if input.ClosesAt != nil {
+		utc := input.ClosesAt.UTC()
+		closesAt = &utc
+	}
+
+	if err := models.ValidatePoll(question, options, closesAt, now); err != nil {
+		return nil, err
+	}
+
+	if _, err := mu.chatRepo.FindById(input.ChatID); err != nil {
+		return nil, errors.New("chat not found")
+	}
+	if _, err := mu.chatRepo.GetUserRole(input.ChatID, input.CreatorID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	chat, refund, err := mu.checkSendRate(input.ChatID, input.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	poll := &models.Poll{
+		Question:       question,
+		MultipleChoice: input.MultipleChoice,
+		Anonymous:      input.Anonymous,
+		ClosesAt:       closesAt,
+		Options:        make([]models.PollOption, len(options)),
+	}
+	for i, option := range options {
+		poll.Options[i].Text = option
+	}
+
+	response, err := mu.moderateAndSend(chat, &models.Message{
+		ChatId:   input.ChatID,
+		SenderId: input.CreatorID,
+		Content:  question,
+		Poll:     poll,
+	})
+	if err != nil {
+		refund()
+		return nil, err
+	}
+	return response, nil
+}
+
 // UpdateMessage updates an existing message
 func (mu *MessageUsecase) UpdateMessage(messageID, userID int, newContent string) (*dto.MessageResponse, error) {
 	// Get original message
@@ -92,10 +746,41 @@ func (mu *MessageUsecase) UpdateMessage(messageID, userID int, newContent string
 		return nil, errors.New("message not found")
 	}
 
+	if originalMessage.IsSystem() {
+		return nil, errors.New("system messages cannot be modified")
+	}
+
 	if originalMessage.SenderId != userID {
 		return nil, errors.New("unauthorized to update this message")
 	}
 
+	if originalMessage.WebhookId != nil {
+		return nil, errors.New("webhook messages cannot be edited")
+	}
+
+	// The content of a poll message is its question, which voters already answered
+	original := []models.Message{*originalMessage}
+	if err := mu.polls.AttachPolls(original, userID); err != nil {
+		return nil, err
+	}
+	if original[0].Poll != nil {
+		return nil, errors.New("poll messages cannot be edited")
+	}
+
+	// Encrypted messages are replaced by a new envelope, which cannot be moderated
+	moderated := &ModerationResult{Content: newContent}
+	if originalMessage.Encrypted {
+		if err := mu.checkEnvelope(userID, newContent); err != nil {
+			return nil, err
+		}
+	} else {
+		moderated, err = mu.moderation.Moderate(originalMessage.ChatId, userID, newContent)
+		if err != nil {
+			return nil, err
+		}
+		newContent = moderated.Content
+	}
+
 	// Get chat users before updating message
 	users, err := mu.chatRepo.GetChatUsers(originalMessage.ChatId)
 	if err != nil {
@@ -108,14 +793,30 @@ func (mu *MessageUsecase) UpdateMessage(messageID, userID int, newContent string
 		ID:        originalMessage.ID,
 		ChatId:    originalMessage.ChatId,
 		SenderId:  originalMessage.SenderId,
+		Type:      originalMessage.Type,
 		Content:   newContent,
 		CreatedAt: originalMessage.CreatedAt,
 		UpdatedAt: time.Now(),
+		ExpiresAt: originalMessage.ExpiresAt,
+
+		Attachments: originalMessage.Attachments,
+		Encrypted:   originalMessage.Encrypted,
+
+		ForwardedFromMessageId: originalMessage.ForwardedFromMessageId,
+		ForwardedFromChatId:    originalMessage.ForwardedFromChatId,
+		ForwardedFromSenderId:  originalMessage.ForwardedFromSenderId,
+		ForwardedFromNickname:  originalMessage.ForwardedFromNickname,
+	}
+
+	if !updatedMessage.Encrypted {
+		updatedMessage.Formatted = richtext.Parse(updatedMessage.Content)
+		updatedMessage.PlainText = updatedMessage.Formatted.PlainText()
 	}
 
 	if err := mu.messageRepo.Update(updatedMessage); err != nil {
 		return nil, err
 	}
+	mu.moderation.Flag(updatedMessage, moderated)
 
 	// Extract user IDs
 	userIDs := make([]int, len(users))
@@ -124,20 +825,17 @@ func (mu *MessageUsecase) UpdateMessage(messageID, userID int, newContent string
 	}
 
 	// Create and broadcast WebSocket event
-	eventData := &events.MessageEventData{
-		MessageID:      updatedMessage.ID,
-		ChatID:         updatedMessage.ChatId,
-		SenderID:       updatedMessage.SenderId,
-		SenderNickname: updatedMessage.SenderNickname,
-		Content:        updatedMessage.Content,
-		CreatedAt:      updatedMessage.CreatedAt,
-		UpdatedAt:      updatedMessage.UpdatedAt,
-	}
+	eventData := newMessageEventData(updatedMessage)
 
 	event := events.NewWebSocketEvent(events.EventMessageUpdated, updatedMessage.ChatId, eventData)
 	if eventJSON, err := event.ToJSON(); err == nil {
 		mu.wsHub.BroadcastToUsers(userIDs, eventJSON)
 	}
+	mu.webhooks.PublishMessage(models.OutgoingEventMessageUpdated, eventData)
+
+	if !updatedMessage.Encrypted && updatedMessage.Content != originalMessage.Content {
+		mu.linkPreviewer.Refresh(updatedMessage)
+	}
 
 	return dto.NewMessageResponse(updatedMessage), nil
 }
@@ -149,10 +847,29 @@ func (mu *MessageUsecase) DeleteMessage(messageID, userID int) error {
 		return errors.New("message not found")
 	}
 
+	if message.IsSystem() {
+		return errors.New("system messages cannot be modified")
+	}
+
 	if message.SenderId != userID {
 		return errors.New("unauthorized to delete this message")
 	}
 
+	return mu.deleteMessage(message)
+}
+
+// RemoveMessage deletes a message on behalf of a moderator, whatever its sender
+func (mu *MessageUsecase) RemoveMessage(messageID int) error {
+	message, err := mu.messageRepo.FindById(messageID)
+	if err != nil {
+		return errors.New("message not found")
+	}
+
+	return mu.deleteMessage(message)
+}
+
+// deleteMessage deletes a message and tells the chat users
+func (mu *MessageUsecase) deleteMessage(message *models.Message) error {
 	// Get chat users before deleting message
 	users, err := mu.chatRepo.GetChatUsers(message.ChatId)
 	if err != nil {
@@ -160,7 +877,7 @@ func (mu *MessageUsecase) DeleteMessage(messageID, userID int) error {
 		return err
 	}
 
-	if err := mu.messageRepo.Delete(messageID); err != nil {
+	if err := mu.messageRepo.Delete(message.ID); err != nil {
 		return err
 	}
 
@@ -184,46 +901,237 @@ func (mu *MessageUsecase) DeleteMessage(messageID, userID int) error {
 	if eventJSON, err := event.ToJSON(); err == nil {
 		mu.wsHub.BroadcastToUsers(userIDs, eventJSON)
 	}
+	mu.webhooks.PublishMessage(models.OutgoingEventMessageDeleted, eventData)
 
 	return nil
 }
 
-// GetChatMessages retrieves messages for a chat with cursor-based pagination
-func (mu *MessageUsecase) GetChatMessages(chatId int, cursor int) (*dto.ChatMessagesResponse, error) {
+// GetChatMessages retrieves a page of a chat's history, newest first. Pages go
+// back from Before, forward from After, or both ways around a message or time.
+func (mu *MessageUsecase) GetChatMessages(query models.MessageHistoryQuery) (*dto.ChatMessagesResponse, error) {
 	// Verify chat exists
-	chat, err := mu.chatRepo.FindById(chatId)
+	chat, err := mu.chatRepo.FindById(query.ChatId)
 	if err != nil {
 		return nil, errors.New("chat not found")
 	}
 
-	// Get messages
-	messages, err := mu.messageRepo.FindByChatId(chatId, cursor, 50)
-	if err != nil {
-		return nil, err
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if limit > MaxHistoryLimit {
+		limit = MaxHistoryLimit
 	}
 
-	// Get last message ID for the first page
-	var lastMessageId int
-	if cursor == 0 {
-		lastMessageId, err = mu.messageRepo.GetLastMessageId(chatId)
+	// A date anchor is resolved to the first message sent from then on
+	if query.At != nil {
+		query.Around, err = mu.messageRepo.FindFirstIdAt(chat.ID, *query.At)
 		if err != nil {
 			return nil, err
 		}
+		query.At = nil
+	}
+
+	// older is newest first, newer is oldest first; each is fetched with one
+	// extra row to know whether the history continues in that direction
+	var older, newer []models.Message
+	var hasOlder, hasNewer bool
+	switch {
+	case query.Around != 0:
+		olderLimit := (limit + 1) / 2
+		newerLimit := limit - olderLimit
+		if older, hasOlder, err = mu.findOlder(chat.ID, query.Around+1, olderLimit); err != nil {
+			return nil, err
+		}
+		if newer, hasNewer, err = mu.findNewer(chat.ID, query.Around, newerLimit); err != nil {
+			return nil, err
+		}
+
+	case query.After != 0:
+		if newer, hasNewer, err = mu.findNewer(chat.ID, query.After, limit); err != nil {
+			return nil, err
+		}
+		boundary := query.After + 1
+		if len(newer) > 0 {
+			boundary = newer[0].ID
+		}
+		if _, hasOlder, err = mu.findOlder(chat.ID, boundary, 0); err != nil {
+			return nil, err
+		}
+
+	default:
+		if older, hasOlder, err = mu.findOlder(chat.ID, query.Before, limit); err != nil {
+			return nil, err
+		}
+		if query.Before != 0 {
+			boundary := query.Before - 1
+			if len(older) > 0 {
+				boundary = older[0].ID
+			}
+			if _, hasNewer, err = mu.findNewer(chat.ID, boundary, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	messages := make([]models.Message, 0, len(newer)+len(older))
+	for i := len(newer) - 1; i >= 0; i-- {
+		messages = append(messages, newer[i])
+	}
+	messages = append(messages, older...)
+
+	if err := mu.linkPreviewer.AttachPreviews(messages); err != nil {
+		logger.Error("Failed to attach link previews: %v", err)
+	}
+	if err := mu.receipts.AttachSummaries(messages, query.UserId); err != nil {
+		logger.Error("Failed to attach delivery status: %v", err)
+	}
+	if err := mu.polls.AttachPolls(messages, query.UserId); err != nil {
+		return nil, err
+	}
+	if err := mu.attachReactions(messages, query.UserId); err != nil {
+		logger.Error("Failed to attach reactions: %v", err)
 	}
 
-	// Create response
 	response := &dto.ChatMessagesResponse{
-		ChatId:        chat.ID,
-		Messages:      dto.NewMessageResponseList(messages),
-		LastMessageId: lastMessageId,
-		HasMore:       len(messages) == 50,
-		NextCursor:    0,
+		ChatId:   chat.ID,
+		Messages: dto.NewMessageResponseList(messages),
+		HasMore:  hasOlder,
+		HasNewer: hasNewer,
 	}
 
-	// Set next cursor if there are more messages
+	// nextCursor pages back with before, prevCursor pages forward with after
 	if len(messages) > 0 {
+		response.PrevCursor = messages[0].ID
 		response.NextCursor = messages[len(messages)-1].ID
 	}
 
+	// Get last message ID and pins when the chat is opened at its latest messages
+	if !query.Anchored() {
+		response.LastMessageId, err = mu.messageRepo.GetLastMessageId(chat.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		pins, err := mu.pinRepo.FindByChatId(chat.ID)
+		if err != nil {
+			return nil, err
+		}
+		response.Pins = dto.NewPinnedMessageResponseList(pins)
+	}
+
+	return response, nil
+}
+
+// attachReactions sets the reaction counts of each message
+func (mu *MessageUsecase) attachReactions(messages []models.Message, viewerID int) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	messageIDs := make([]int, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.ID
+	}
+
+	reactions, err := mu.reactionRepo.Summarize(messageIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	byMessage := make(map[int][]models.Reaction)
+	for _, reaction := range reactions {
+		byMessage[reaction.MessageId] = append(byMessage[reaction.MessageId], reaction)
+	}
+	for i := range messages {
+		messages[i].Reactions = byMessage[messages[i].ID]
+	}
+	return nil
+}
+
+// findOlder returns up to limit messages older than the cursor, newest first,
+// and whether more exist beyond them
+func (mu *MessageUsecase) findOlder(chatID, cursor, limit int) ([]models.Message, bool, error) {
+	messages, err := mu.messageRepo.FindByChatId(chatID, cursor, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(messages) > limit {
+		return messages[:limit], true, nil
+	}
+	return messages, false, nil
+}
+
+// findNewer returns up to limit messages newer than the cursor, oldest first,
+// and whether more exist beyond them
+func (mu *MessageUsecase) findNewer(chatID, cursor, limit int) ([]models.Message, bool, error) {
+	messages, err := mu.messageRepo.FindAfterId(chatID, cursor, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(messages) > limit {
+		return messages[:limit], true, nil
+	}
+	return messages, false, nil
+}
+
+// SearchMessages searches messages in the chats the user belongs to with cursor-based pagination
+func (mu *MessageUsecase) SearchMessages(filter models.MessageSearchFilter) (*dto.MessageSearchResponse, error) {
+	if strings.TrimSpace(filter.Query) == "" {
+		return nil, errors.New("search query is required")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultSearchLimit
+	}
+	if filter.Limit > MaxSearchLimit {
+		filter.Limit = MaxSearchLimit
+	}
+
+	if filter.ChatId != 0 {
+		if _, err := mu.chatRepo.GetUserRole(filter.ChatId, filter.UserId); err != nil {
+			return nil, errors.New("user is not a member of this chat")
+		}
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	results, stoppedAt, err := mu.messageRepo.Search(filter)
+	if err != nil {
+		logger.Error("Failed to search messages: %v", err)
+		return nil, err
+	}
+
+	response := &dto.MessageSearchResponse{
+		HasMore: len(results) > limit,
+	}
+	if response.HasMore {
+		results = results[:limit]
+	}
+	response.Results = dto.NewMessageSearchResultList(results)
+
+	if len(results) > 0 {
+		response.NextCursor = results[len(results)-1].ID
+	}
+	// The search gave up before filling the page; the next page continues
+	// after the messages already read
+	if !response.HasMore && stoppedAt != 0 {
+		response.HasMore = true
+		response.NextCursor = stoppedAt
+	}
+
 	return response, nil
 }
+
+// uniqueInts removes duplicates while keeping the first occurrence order
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	unique := make([]int, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
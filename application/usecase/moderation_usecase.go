@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/domain/services"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+)
+
+// Review queue page sizes
+const (
+	DefaultFlagLimit = 20
+	MaxFlagLimit     = 100
+)
+
+// moderationTimeout bounds all filters of one message, including external classifiers
+const moderationTimeout = 5 * time.Second
+
+// ModerationPipeline runs the configured filters over a message before it is
+// stored. Filters run in order and each sees the content masked by the
+// previous ones. A rejecting filter stops the message with a
+// *models.ModerationError; flagged messages are posted and queued for review.
+type ModerationPipeline struct {
+	moderationRepo repositories.ModerationRepository
+	filters        []services.ModerationFilter
+}
+
+func NewModerationPipeline(
+	moderationRepo repositories.ModerationRepository,
+	filters []services.ModerationFilter,
+) *ModerationPipeline {
+	return &ModerationPipeline{
+		moderationRepo: moderationRepo,
+		filters:        filters,
+	}
+}
+
+// ModerationResult is the outcome for a message the filters let through
+type ModerationResult struct {
+	// Content is the message content with masked words
+	Content string
+	// Reasons is set when the message needs review
+	Reasons models.ModerationReasons
+}
+
+// Moderate runs the filters over the content of a message
+func (mp *ModerationPipeline) Moderate(chatID, senderID int, content string) (*ModerationResult, error) {
+	result := &ModerationResult{Content: content}
+	if len(mp.filters) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), moderationTimeout)
+	defer cancel()
+
+	for _, filter := range mp.filters {
+		verdict, err := filter.Moderate(ctx, models.ModerationInput{
+			ChatId:   chatID,
+			SenderId: senderID,
+			Content:  result.Content,
+		})
+		if err != nil {
+			// An unavailable filter, such as an unreachable classifier, must not stop the chat
+			logger.Error("Moderation filter %s failed: %v", filter.Name(), err)
+			continue
+		}
+		if verdict == nil {
+			continue
+		}
+
+		if verdict.Action == models.ModerationActionReject {
+			return nil, &models.ModerationError{Filter: verdict.Filter, Reason: verdict.Reason}
+		}
+		if verdict.Content != "" {
+			result.Content = verdict.Content
+		}
+		if verdict.Action == models.ModerationActionFlag {
+			result.Reasons = append(result.Reasons, models.ModerationReason{Filter: verdict.Filter, Reason: verdict.Reason})
+		}
+	}
+
+	return result, nil
+}
+
+// Flag queues a stored message for review when the filters flagged it.
+// Failures are logged, as the message was already posted.
+func (mp *ModerationPipeline) Flag(message *models.Message, result *ModerationResult) {
+	if len(result.Reasons) == 0 {
+		return
+	}
+
+	flag := &models.ModerationFlag{
+		MessageId: message.ID,
+		ChatId:    message.ChatId,
+		SenderId:  message.SenderId,
+		Content:   message.Content,
+		Reasons:   result.Reasons,
+		Status:    models.FlagStatusPending,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := mp.moderationRepo.CreateFlag(flag); err != nil {
+		logger.Error("Failed to flag message %d: %v", message.ID, err)
+	}
+}
+
+// ModerationUsecase is the review queue of flagged messages. Owners and
+// admins of a chat review the flags raised in it.
+type ModerationUsecase struct {
+	moderationRepo repositories.ModerationRepository
+	chatRepo       repositories.ChatRepository
+	messages       *MessageUsecase
+}
+
+func NewModerationUsecase(
+	moderationRepo repositories.ModerationRepository,
+	chatRepo repositories.ChatRepository,
+	messages *MessageUsecase,
+) *ModerationUsecase {
+	return &ModerationUsecase{
+		moderationRepo: moderationRepo,
+		chatRepo:       chatRepo,
+		messages:       messages,
+	}
+}
+
+// GetFlags returns a page of the flags the user may review, newest first
+func (mu *ModerationUsecase) GetFlags(userID, chatID int, status string, cursor, limit int) (*dto.ModerationFlagListResponse, error) {
+	if status != "" && status != models.FlagStatusPending &&
+		status != models.FlagStatusApproved && status != models.FlagStatusRemoved {
+		return nil, errors.New("invalid flag status")
+	}
+
+	if chatID != 0 {
+		if _, err := mu.chatRepo.FindById(chatID); err != nil {
+			return nil, errors.New("chat not found")
+		}
+		role, err := mu.chatRepo.GetUserRole(chatID, userID)
+		if err != nil {
+			return nil, errors.New("user is not a member of this chat")
+		}
+		if !models.CanManageChat(role) {
+			return nil, errors.New("unauthorized to review flags")
+		}
+	}
+
+	if limit <= 0 {
+		limit = DefaultFlagLimit
+	}
+	if limit > MaxFlagLimit {
+		limit = MaxFlagLimit
+	}
+
+	// Fetch one extra row to know whether there is another page
+	flags, err := mu.moderationRepo.FindFlags(userID, chatID, status, cursor, limit+1)
+	if err != nil {
+		logger.Error("Failed to get moderation flags: %v", err)
+		return nil, err
+	}
+
+	response := &dto.ModerationFlagListResponse{
+		HasMore: len(flags) > limit,
+	}
+	if response.HasMore {
+		flags = flags[:limit]
+	}
+	response.Flags = dto.NewModerationFlagResponseList(flags)
+
+	if len(flags) > 0 {
+		response.NextCursor = flags[len(flags)-1].ID
+	}
+
+	return response, nil
+}
+
+// ApproveFlag keeps a flagged message
+func (mu *ModerationUsecase) ApproveFlag(id, userID int) (*dto.ModerationFlagResponse, error) {
+	flag, err := mu.findReviewable(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := mu.moderationRepo.ResolveFlag(flag.ID, models.FlagStatusApproved, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, errors.New("flag already reviewed")
+	}
+
+	return mu.getFlag(flag.ID)
+}
+
+// RemoveFlaggedMessage deletes a flagged message for everyone and resolves
+// all pending flags of it
+func (mu *ModerationUsecase) RemoveFlaggedMessage(id, userID int) (*dto.ModerationFlagResponse, error) {
+	flag, err := mu.findReviewable(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if flag.Status != models.FlagStatusPending {
+		return nil, errors.New("flag already reviewed")
+	}
+
+	// The sender may have deleted the message already
+	if err := mu.messages.RemoveMessage(flag.MessageId); err != nil && err.Error() != "message not found" {
+		return nil, err
+	}
+
+	if err := mu.moderationRepo.ResolveMessageFlags(flag.MessageId, models.FlagStatusRemoved, userID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	return mu.getFlag(flag.ID)
+}
+
+// findReviewable returns a flag of a chat the user manages. Flags of chats the
+// user is not in are reported as missing.
+func (mu *ModerationUsecase) findReviewable(id, userID int) (*models.ModerationFlag, error) {
+	flag, err := mu.moderationRepo.FindFlagById(id)
+	if err != nil {
+		return nil, errors.New("flag not found")
+	}
+
+	role, err := mu.chatRepo.GetUserRole(flag.ChatId, userID)
+	if err != nil {
+		return nil, errors.New("flag not found")
+	}
+	if !models.CanManageChat(role) {
+		return nil, errors.New("unauthorized to review flags")
+	}
+
+	return flag, nil
+}
+
+func (mu *ModerationUsecase) getFlag(id int) (*dto.ModerationFlagResponse, error) {
+	flag, err := mu.moderationRepo.FindFlagById(id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewModerationFlagResponse(flag), nil
+}
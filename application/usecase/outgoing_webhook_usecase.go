@@ -0,0 +1,459 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/domain/services"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+)
+
+// Delivery page sizes
+const (
+	DefaultDeliveryLimit = 20
+	MaxDeliveryLimit     = 100
+)
+
+const (
+	deliveryInterval  = time.Second
+	deliveryBatchSize = 50
+	// Deliveries sent at the same time, so one slow receiver does not hold up the rest
+	deliveryWorkers = 8
+	// Length of the error stored with a failed attempt
+	maxDeliveryErrorLength = 500
+)
+
+// Headers sent with every delivery
+const (
+	HeaderWebhookID        = "X-Webhook-Id"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// OutgoingWebhookUsecase sends chat events to subscribed HTTP endpoints.
+// Events are queued as deliveries and sent by Run, which retries failed
+// deliveries with exponential backoff until MaxDeliveryAttempts.
+type OutgoingWebhookUsecase struct {
+	webhookRepo repositories.OutgoingWebhookRepository
+	chatRepo    repositories.ChatRepository
+	sender      services.WebhookSender
+	wake        chan struct{}
+}
+
+func NewOutgoingWebhookUsecase(
+	webhookRepo repositories.OutgoingWebhookRepository,
+	chatRepo repositories.ChatRepository,
+	sender services.WebhookSender,
+) *OutgoingWebhookUsecase {
+	return &OutgoingWebhookUsecase{
+		webhookRepo: webhookRepo,
+		chatRepo:    chatRepo,
+		sender:      sender,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// CreateOutgoingWebhook subscribes an endpoint to events. Without a chat the
+// webhook receives the events of every chat the user belongs to; for a single
+// chat the user must manage it. No events subscribes to all of them. The
+// signing secret is only returned here.
+func (ou *OutgoingWebhookUsecase) CreateOutgoingWebhook(userID int, chatID *int, rawURL string, eventTypes []string) (*dto.OutgoingWebhookResponse, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	eventTypes = uniqueStrings(eventTypes)
+	if len(eventTypes) == 0 {
+		eventTypes = models.OutgoingEvents
+	}
+	if err := models.ValidateOutgoingWebhook(rawURL, eventTypes); err != nil {
+		return nil, err
+	}
+
+	if chatID != nil {
+		if err := ou.checkManager(*chatID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &models.OutgoingWebhook{
+		OwnerId:   userID,
+		ChatId:    chatID,
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    strings.Join(eventTypes, ","),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := ou.webhookRepo.Create(webhook); err != nil {
+		logger.Error("Failed to create outgoing webhook: %v", err)
+		return nil, err
+	}
+
+	response := dto.NewOutgoingWebhookResponse(webhook)
+	response.Secret = secret
+	return response, nil
+}
+
+// GetOutgoingWebhooks lists the user's outgoing webhooks
+func (ou *OutgoingWebhookUsecase) GetOutgoingWebhooks(userID int) ([]dto.OutgoingWebhookResponse, error) {
+	webhooks, err := ou.webhookRepo.FindByOwnerId(userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewOutgoingWebhookResponseList(webhooks), nil
+}
+
+// GetOutgoingWebhook returns one of the user's outgoing webhooks
+func (ou *OutgoingWebhookUsecase) GetOutgoingWebhook(id, userID int) (*dto.OutgoingWebhookResponse, error) {
+	webhook, err := ou.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewOutgoingWebhookResponse(webhook), nil
+}
+
+// DeleteOutgoingWebhook removes a webhook; its queued deliveries are dropped
+func (ou *OutgoingWebhookUsecase) DeleteOutgoingWebhook(id, userID int) error {
+	if _, err := ou.findOwned(id, userID); err != nil {
+		return err
+	}
+	return ou.webhookRepo.Delete(id)
+}
+
+// GetDeliveries returns a page of a webhook's deliveries, newest first,
+// optionally only those with the given status
+func (ou *OutgoingWebhookUsecase) GetDeliveries(id, userID int, status string, cursor, limit int) (*dto.WebhookDeliveryListResponse, error) {
+	if status != "" && status != models.DeliveryStatusPending &&
+		status != models.DeliveryStatusSucceeded && status != models.DeliveryStatusDead {
+		return nil, errors.New("invalid delivery status")
+	}
+
+	if _, err := ou.findOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultDeliveryLimit
+	}
+	if limit > MaxDeliveryLimit {
+		limit = MaxDeliveryLimit
+	}
+
+	// Fetch one extra row to know whether there is another page
+	deliveries, err := ou.webhookRepo.FindDeliveries(id, status, cursor, limit+1)
+	if err != nil {
+		logger.Error("Failed to get webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	response := &dto.WebhookDeliveryListResponse{
+		HasMore: len(deliveries) > limit,
+	}
+	if response.HasMore {
+		deliveries = deliveries[:limit]
+	}
+	response.Deliveries = dto.NewWebhookDeliveryResponseList(deliveries)
+
+	if len(deliveries) > 0 {
+		response.NextCursor = deliveries[len(deliveries)-1].ID
+	}
+
+	return response, nil
+}
+
+// ReplayDelivery queues a delivery to be sent again right away with a fresh
+// set of attempts. Pending deliveries are left to their schedule.
+func (ou *OutgoingWebhookUsecase) ReplayDelivery(id, deliveryID, userID int) (*dto.WebhookDeliveryResponse, error) {
+	if _, err := ou.findOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := ou.webhookRepo.FindDeliveryById(deliveryID)
+	if err != nil || delivery.OutgoingWebhookId != id {
+		return nil, errors.New("delivery not found")
+	}
+	if delivery.Status == models.DeliveryStatusPending {
+		return nil, errors.New("delivery is already pending")
+	}
+
+	if err := ou.webhookRepo.Requeue(deliveryID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	ou.notify()
+
+	delivery, err = ou.webhookRepo.FindDeliveryById(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewWebhookDeliveryResponse(delivery), nil
+}
+
+// Publish queues an event for every webhook subscribed to it in the chat.
+// Failures are logged, as the event itself already happened.
+func (ou *OutgoingWebhookUsecase) Publish(eventType string, chatID int, data interface{}) {
+	ou.publish(eventType, chatID, nil, data)
+}
+
+// PublishMessage queues a message event. The deliveries refer to the message,
+// so they are deleted with it.
+func (ou *OutgoingWebhookUsecase) PublishMessage(eventType string, data *events.MessageEventData) {
+	messageID := data.MessageID
+	ou.publish(eventType, data.ChatID, &messageID, data)
+}
+
+func (ou *OutgoingWebhookUsecase) publish(eventType string, chatID int, messageID *int, data interface{}) {
+	webhooks, err := ou.webhookRepo.FindSubscribers(chatID)
+	if err != nil {
+		logger.Error("Failed to find webhooks of chat %d: %v", chatID, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	var deliveries []models.WebhookDelivery
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(&models.WebhookEvent{
+				Event:      eventType,
+				ChatId:     chatID,
+				OccurredAt: now,
+				Data:       data,
+			})
+			if err != nil {
+				logger.Error("Failed to marshal %s webhook payload: %v", eventType, err)
+				return
+			}
+		}
+
+		deliveries = append(deliveries, models.WebhookDelivery{
+			OutgoingWebhookId: webhook.ID,
+			EventType:         eventType,
+			ChatId:            chatID,
+			MessageId:         messageID,
+			Payload:           string(payload),
+			Status:            models.DeliveryStatusPending,
+			NextAttemptAt:     &now,
+			CreatedAt:         now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := ou.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		logger.Error("Failed to queue %s webhook deliveries: %v", eventType, err)
+		return
+	}
+	ou.notify()
+}
+
+// Run sends due deliveries until the process exits
+func (ou *OutgoingWebhookUsecase) Run() {
+	ticker := time.NewTicker(deliveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ou.wake:
+		}
+		ou.sendDueDeliveries()
+	}
+}
+
+// notify wakes Run so new deliveries do not wait for the next tick
+func (ou *OutgoingWebhookUsecase) notify() {
+	select {
+	case ou.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sendDueDeliveries sends a batch and waits for it, so a delivery is never
+// attempted twice at the same time. Each webhook gets its deliveries in order.
+func (ou *OutgoingWebhookUsecase) sendDueDeliveries() {
+	due, err := ou.webhookRepo.FindDueDeliveries(time.Now().UTC(), deliveryBatchSize)
+	if err != nil {
+		logger.Error("Failed to find due webhook deliveries: %v", err)
+		return
+	}
+
+	var webhookIDs []int
+	byWebhook := make(map[int][]*models.WebhookDelivery)
+	for i := range due {
+		webhookID := due[i].OutgoingWebhookId
+		if _, ok := byWebhook[webhookID]; !ok {
+			webhookIDs = append(webhookIDs, webhookID)
+		}
+		byWebhook[webhookID] = append(byWebhook[webhookID], &due[i])
+	}
+
+	slots := make(chan struct{}, deliveryWorkers)
+	var wg sync.WaitGroup
+	for _, webhookID := range webhookIDs {
+		// A deleted webhook takes its deliveries with it
+		webhook, err := ou.webhookRepo.FindById(webhookID)
+		if err != nil {
+			continue
+		}
+
+		deliveries := byWebhook[webhookID]
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			for _, delivery := range deliveries {
+				// Deliveries queued before the owner lost access are not sent
+				allowed, err := ou.canReceive(webhook, delivery.ChatId)
+				if err != nil {
+					logger.Error("Failed to check access of webhook %d: %v", webhook.ID, err)
+					continue
+				}
+				if !allowed {
+					ou.revoke(delivery)
+					continue
+				}
+				ou.attempt(webhook, delivery)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// attempt sends a delivery once and schedules a retry if it failed
+func (ou *OutgoingWebhookUsecase) attempt(webhook *models.OutgoingWebhook, delivery *models.WebhookDelivery) {
+	now := time.Now().UTC()
+	body := []byte(delivery.Payload)
+
+	header := http.Header{}
+	header.Set(HeaderWebhookID, strconv.Itoa(webhook.ID))
+	header.Set(HeaderWebhookDelivery, strconv.Itoa(delivery.ID))
+	header.Set(HeaderWebhookEvent, delivery.EventType)
+	header.Set(HeaderWebhookTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderWebhookSignature, models.SignWebhookPayload(webhook.Secret, now.Unix(), body))
+
+	statusCode, err := ou.sender.Send(context.Background(), webhook.URL, header, body)
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	if statusCode != 0 {
+		delivery.ResponseStatus = &statusCode
+	}
+
+	if err == nil {
+		delivery.Status = models.DeliveryStatusSucceeded
+		delivery.NextAttemptAt = nil
+		delivery.LastError = nil
+		delivery.DeliveredAt = &now
+	} else {
+		message := err.Error()
+		if len(message) > maxDeliveryErrorLength {
+			message = message[:maxDeliveryErrorLength]
+		}
+		delivery.LastError = &message
+
+		if delivery.Attempts >= models.MaxDeliveryAttempts {
+			delivery.Status = models.DeliveryStatusDead
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(models.DeliveryBackoff(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := ou.webhookRepo.RecordAttempt(delivery); err != nil {
+		logger.Error("Failed to record attempt of webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// canReceive reports whether the owner of a webhook may still receive the
+// events of a chat: as a member for webhooks of all their chats, as a manager
+// for webhooks of that chat only
+func (ou *OutgoingWebhookUsecase) canReceive(webhook *models.OutgoingWebhook, chatID int) (bool, error) {
+	role, err := ou.chatRepo.GetUserRole(chatID, webhook.OwnerId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return webhook.ChatId == nil || models.CanManageChat(role), nil
+}
+
+// revoke gives up a delivery whose webhook owner lost access to the chat
+func (ou *OutgoingWebhookUsecase) revoke(delivery *models.WebhookDelivery) {
+	message := "webhook owner no longer has access to the chat"
+	delivery.Status = models.DeliveryStatusDead
+	delivery.NextAttemptAt = nil
+	delivery.LastError = &message
+
+	if err := ou.webhookRepo.RecordAttempt(delivery); err != nil {
+		logger.Error("Failed to record revoked webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+func (ou *OutgoingWebhookUsecase) findOwned(id, userID int) (*models.OutgoingWebhook, error) {
+	webhook, err := ou.webhookRepo.FindById(id)
+	if err != nil || webhook.OwnerId != userID {
+		return nil, errors.New("webhook not found")
+	}
+	return webhook, nil
+}
+
+func (ou *OutgoingWebhookUsecase) checkManager(chatID, userID int) error {
+	if _, err := ou.chatRepo.FindById(chatID); err != nil {
+		return errors.New("chat not found")
+	}
+
+	role, err := ou.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return errors.New("user is not a member of this chat")
+	}
+	if !models.CanManageChat(role) {
+		return errors.New("unauthorized to manage webhooks")
+	}
+
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/webhook"
+)
+
+const testWebhookSecret = "test-secret"
+
+// fakeDeliveryRepo keeps one webhook and its deliveries in memory. Methods
+// the delivery loop does not use are left to the embedded nil interface.
+type fakeDeliveryRepo struct {
+	repositories.OutgoingWebhookRepository
+
+	mu         sync.Mutex
+	webhook    models.OutgoingWebhook
+	deliveries map[int]models.WebhookDelivery
+}
+
+// fakeChatRoles holds the roles of users in chat 1
+type fakeChatRoles struct {
+	repositories.ChatRepository
+
+	mu    sync.Mutex
+	roles map[int]string
+}
+
+func (r *fakeChatRoles) GetUserRole(chatID, userID int) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, ok := r.roles[userID]
+	if chatID != 1 || !ok {
+		return "", sql.ErrNoRows
+	}
+	return role, nil
+}
+
+func newFakeDeliveryRepo(url string) *fakeDeliveryRepo {
+	now := time.Now().UTC()
+	return &fakeDeliveryRepo{
+		webhook: models.OutgoingWebhook{ID: 1, OwnerId: 1, URL: url, Secret: testWebhookSecret},
+		deliveries: map[int]models.WebhookDelivery{
+			1: {
+				ID:                1,
+				OutgoingWebhookId: 1,
+				EventType:         models.OutgoingEventMessageCreated,
+				ChatId:            1,
+				Payload:           `{"event":"message.created","chatId":1}`,
+				Status:            models.DeliveryStatusPending,
+				NextAttemptAt:     &now,
+				CreatedAt:         now,
+			},
+		},
+	}
+}
+
+func (r *fakeDeliveryRepo) FindById(id int) (*models.OutgoingWebhook, error) {
+	if id != r.webhook.ID {
+		return nil, sql.ErrNoRows
+	}
+	webhook := r.webhook
+	return &webhook, nil
+}
+
+func (r *fakeDeliveryRepo) FindDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeDeliveryRepo) RecordAttempt(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *fakeDeliveryRepo) delivery(id int) models.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deliveries[id]
+}
+
+// makeDue moves the next attempt of a delivery to now, as if its backoff passed
+func (r *fakeDeliveryRepo) makeDue(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery := r.deliveries[id]
+	now := time.Now().UTC()
+	delivery.NextAttemptAt = &now
+	r.deliveries[id] = delivery
+}
+
+// useTempLogDir writes the logs of a test to its temporary directory
+func useTempLogDir(t *testing.T) {
+	t.Helper()
+	if err := logger.SetDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.SetDir("") })
+}
+
+func newTestDeliveryUsecase(t *testing.T, handler http.HandlerFunc, timeout time.Duration) (*OutgoingWebhookUsecase, *fakeDeliveryRepo) {
+	ou, repo, _ := newTestDeliveryUsecaseWithRoles(t, handler, timeout)
+	return ou, repo
+}
+
+// newTestDeliveryUsecaseWithRoles also returns the roles in the chat, where
+// the webhook owner starts as the owner
+func newTestDeliveryUsecaseWithRoles(t *testing.T, handler http.HandlerFunc, timeout time.Duration) (*OutgoingWebhookUsecase, *fakeDeliveryRepo, *fakeChatRoles) {
+	t.Helper()
+	useTempLogDir(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	options := webhook.DefaultOptions()
+	options.Timeout = timeout
+	options.AllowPrivateNetworks = true
+
+	repo := newFakeDeliveryRepo(server.URL)
+	roles := &fakeChatRoles{roles: map[int]string{1: models.ChatRoleOwner}}
+	return NewOutgoingWebhookUsecase(repo, roles, webhook.NewHTTPSender(options)), repo, roles
+}
+
+func TestDeliverySignsPayload(t *testing.T) {
+	var header http.Header
+	var body []byte
+	ou, repo := newTestDeliveryUsecase(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}, time.Second)
+
+	ou.sendDueDeliveries()
+
+	if got := repo.delivery(1).Status; got != models.DeliveryStatusSucceeded {
+		t.Fatalf("status = %s, want %s", got, models.DeliveryStatusSucceeded)
+	}
+	if string(body) != repo.delivery(1).Payload {
+		t.Errorf("body = %s, want the stored payload", body)
+	}
+	if got := header.Get(HeaderWebhookEvent); got != models.OutgoingEventMessageCreated {
+		t.Errorf("%s = %q, want %q", HeaderWebhookEvent, got, models.OutgoingEventMessageCreated)
+	}
+	if got := header.Get(HeaderWebhookDelivery); got != "1" {
+		t.Errorf("%s = %q, want 1", HeaderWebhookDelivery, got)
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get(HeaderWebhookTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s header: %v", HeaderWebhookTimestamp, err)
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age < -time.Minute || age > time.Minute {
+		t.Errorf("timestamp is %v off the current time", age)
+	}
+
+	// Receivers verify HMAC-SHA256 of "<timestamp>.<body>" with the secret
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(header.Get(HeaderWebhookTimestamp) + "." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get(HeaderWebhookSignature); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("%s = %q, want %q", HeaderWebhookSignature, got, want)
+	}
+}
+
+func TestDeliveryRetriesServerErrorWithBackoff(t *testing.T) {
+	ou, repo := newTestDeliveryUsecase(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}, time.Second)
+
+	for attempts := 1; attempts <= 3; attempts++ {
+		before := time.Now().UTC()
+		ou.sendDueDeliveries()
+
+		delivery := repo.delivery(1)
+		if delivery.Status != models.DeliveryStatusPending {
+			t.Fatalf("attempt %d: status = %s, want %s", attempts, delivery.Status, models.DeliveryStatusPending)
+		}
+		if delivery.Attempts != attempts {
+			t.Fatalf("attempts = %d, want %d", delivery.Attempts, attempts)
+		}
+		if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusInternalServerError {
+			t.Errorf("attempt %d: response status = %v, want 500", attempts, delivery.ResponseStatus)
+		}
+		if delivery.LastError == nil {
+			t.Errorf("attempt %d: no error recorded", attempts)
+		}
+
+		backoff := models.DeliveryBackoff(attempts)
+		if delivery.NextAttemptAt == nil || delivery.NextAttemptAt.Before(before.Add(backoff)) ||
+			delivery.NextAttemptAt.After(time.Now().UTC().Add(backoff)) {
+			t.Errorf("attempt %d: next attempt at %v, want %v after the attempt", attempts, delivery.NextAttemptAt, backoff)
+		}
+
+		// Not due again until the backoff passed
+		ou.sendDueDeliveries()
+		if got := repo.delivery(1).Attempts; got != attempts {
+			t.Fatalf("delivery was retried before its backoff passed")
+		}
+		repo.makeDue(1)
+	}
+}
+
+func TestDeliveryRetriesTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ou, repo := newTestDeliveryUsecase(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, 100*time.Millisecond)
+	defer close(release)
+
+	ou.sendDueDeliveries()
+
+	delivery := repo.delivery(1)
+	if delivery.Status != models.DeliveryStatusPending || delivery.Attempts != 1 {
+		t.Fatalf("status = %s after %d attempts, want a pending retry", delivery.Status, delivery.Attempts)
+	}
+	if delivery.ResponseStatus != nil {
+		t.Errorf("response status = %d, want none for a timeout", *delivery.ResponseStatus)
+	}
+	if delivery.LastError == nil {
+		t.Error("no error recorded for a timeout")
+	}
+	if delivery.NextAttemptAt == nil {
+		t.Error("no retry scheduled after a timeout")
+	}
+}
+
+func TestDeliveryDeadLettersAfterMaxAttempts(t *testing.T) {
+	var requests atomic.Int32
+	ou, repo := newTestDeliveryUsecase(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "down", http.StatusBadGateway)
+	}, time.Second)
+
+	for i := 0; i < models.MaxDeliveryAttempts+2; i++ {
+		ou.sendDueDeliveries()
+		if repo.delivery(1).Status == models.DeliveryStatusPending {
+			repo.makeDue(1)
+		}
+	}
+
+	delivery := repo.delivery(1)
+	if delivery.Status != models.DeliveryStatusDead {
+		t.Fatalf("status = %s, want %s", delivery.Status, models.DeliveryStatusDead)
+	}
+	if delivery.Attempts != models.MaxDeliveryAttempts {
+		t.Errorf("attempts = %d, want %d", delivery.Attempts, models.MaxDeliveryAttempts)
+	}
+	if got := int(requests.Load()); got != models.MaxDeliveryAttempts {
+		t.Errorf("receiver saw %d requests, want %d", got, models.MaxDeliveryAttempts)
+	}
+	if delivery.NextAttemptAt != nil {
+		t.Errorf("dead delivery still scheduled at %v", delivery.NextAttemptAt)
+	}
+}
+
+func TestDeliveryRevokedAfterOwnerLosesAccess(t *testing.T) {
+	var requests atomic.Int32
+	ou, repo, roles := newTestDeliveryUsecaseWithRoles(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}, time.Second)
+
+	// A webhook of a single chat needs its owner to manage the chat
+	chatID := 1
+	repo.webhook.ChatId = &chatID
+	roles.roles[1] = models.ChatRoleMember
+
+	ou.sendDueDeliveries()
+
+	delivery := repo.delivery(1)
+	if delivery.Status != models.DeliveryStatusDead {
+		t.Fatalf("status = %s, want %s", delivery.Status, models.DeliveryStatusDead)
+	}
+	if delivery.LastError == nil || delivery.NextAttemptAt != nil {
+		t.Errorf("revoked delivery has error %v and next attempt %v", delivery.LastError, delivery.NextAttemptAt)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("receiver saw %d requests after the owner was demoted", got)
+	}
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+// MaxPinnedMessagesPerChat is the maximum number of messages that can be pinned in a chat
+const MaxPinnedMessagesPerChat = 10
+
+type PinUsecase struct {
+	pinRepo     repositories.PinRepository
+	messageRepo repositories.MessageRepository
+	chatRepo    repositories.ChatRepository
+	messages    *MessageUsecase
+	wsHub       *websocket.Hub
+}
+
+func NewPinUsecase(
+	pinRepo repositories.PinRepository,
+	messageRepo repositories.MessageRepository,
+	chatRepo repositories.ChatRepository,
+	messages *MessageUsecase,
+	wsHub *websocket.Hub,
+) *PinUsecase {
+	return &PinUsecase{
+		pinRepo:     pinRepo,
+		messageRepo: messageRepo,
+		chatRepo:    chatRepo,
+		messages:    messages,
+		wsHub:       wsHub,
+	}
+}
+
+// PinMessage pins a message in a chat
+func (pu *PinUsecase) PinMessage(chatID, messageID, userID int) (*dto.PinnedMessageResponse, error) {
+	if err := pu.checkPermission(chatID, userID); err != nil {
+		return nil, err
+	}
+
+	message, err := pu.messageRepo.FindById(messageID)
+	if err != nil || message.ChatId != chatID {
+		return nil, errors.New("message not found")
+	}
+
+	pinned, err := pu.pinRepo.Exists(chatID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if pinned {
+		return nil, errors.New("message already pinned")
+	}
+
+	count, err := pu.pinRepo.CountByChatId(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxPinnedMessagesPerChat {
+		return nil, errors.New("pin limit reached")
+	}
+
+	pin := &models.PinnedMessage{
+		ChatId:           chatID,
+		MessageId:        message.ID,
+		PinnedBy:         userID,
+		PinnedAt:         time.Now(),
+		SenderId:         message.SenderId,
+		SenderNickname:   message.SenderNickname,
+		Content:          message.Content,
+		Formatted:        message.Formatted,
+		MessageCreatedAt: message.CreatedAt,
+		MessageUpdatedAt: message.UpdatedAt,
+	}
+
+	if err := pu.pinRepo.Create(pin); err != nil {
+		logger.Error("Failed to pin message: %v", err)
+		return nil, err
+	}
+
+	pu.broadcast(events.EventMessagePinned, pin)
+	pu.postSystemMessage(models.SystemActionMessagePinned, chatID, messageID, userID)
+
+	return dto.NewPinnedMessageResponse(pin), nil
+}
+
+// UnpinMessage removes a pin from a chat
+func (pu *PinUsecase) UnpinMessage(chatID, messageID, userID int) error {
+	if err := pu.checkPermission(chatID, userID); err != nil {
+		return err
+	}
+
+	pinned, err := pu.pinRepo.Exists(chatID, messageID)
+	if err != nil {
+		return err
+	}
+	if !pinned {
+		return errors.New("message not pinned")
+	}
+
+	if err := pu.pinRepo.Delete(chatID, messageID); err != nil {
+		return err
+	}
+
+	pu.broadcast(events.EventMessageUnpinned, &models.PinnedMessage{
+		ChatId:    chatID,
+		MessageId: messageID,
+		PinnedBy:  userID,
+	})
+	pu.postSystemMessage(models.SystemActionMessageUnpinned, chatID, messageID, userID)
+
+	return nil
+}
+
+// GetPinnedMessages returns the pins of a chat ordered by pin time
+func (pu *PinUsecase) GetPinnedMessages(chatID, userID int) ([]dto.PinnedMessageResponse, error) {
+	if _, err := pu.chatRepo.FindById(chatID); err != nil {
+		return nil, errors.New("chat not found")
+	}
+
+	if _, err := pu.chatRepo.GetUserRole(chatID, userID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	pins, err := pu.pinRepo.FindByChatId(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewPinnedMessageResponseList(pins), nil
+}
+
+// checkPermission verifies the chat exists and the user may manage its pins
+func (pu *PinUsecase) checkPermission(chatID, userID int) error {
+	if _, err := pu.chatRepo.FindById(chatID); err != nil {
+		return errors.New("chat not found")
+	}
+
+	role, err := pu.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return errors.New("user is not a member of this chat")
+	}
+
+	if !models.CanManageChat(role) {
+		return errors.New("unauthorized to pin messages")
+	}
+
+	return nil
+}
+
+func (pu *PinUsecase) broadcast(eventType string, pin *models.PinnedMessage) {
+	users, err := pu.chatRepo.GetChatUsers(pin.ChatId)
+	if err != nil {
+		logger.Error("Failed to get chat users: %v", err)
+		return
+	}
+
+	eventData := &events.PinEventData{
+		ChatID:           pin.ChatId,
+		MessageID:        pin.MessageId,
+		PinnedBy:         pin.PinnedBy,
+		PinnedByNickname: pin.PinnedByNickname,
+		Content:          pin.Content,
+		PinnedAt:         pin.PinnedAt,
+	}
+	broadcastToUsers(pu.wsHub, users, eventType, pin.ChatId, eventData)
+}
+
+// postSystemMessage records a pin change in the chat timeline
+func (pu *PinUsecase) postSystemMessage(action string, chatID, messageID, userID int) {
+	users, err := pu.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		logger.Error("Failed to get chat users: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		if user.ID == userID {
+			pu.messages.PostSystemMessage(chatID, &models.SystemEvent{
+				Action:    action,
+				Actor:     models.SystemEventUser{UserId: user.ID, Nickname: user.Nickname},
+				MessageId: messageID,
+			})
+			return
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+// PollUsecase handles voting on polls and keeps their tallies live.
+// Polls are created with their message by MessageUsecase.CreatePoll.
+type PollUsecase struct {
+	pollRepo    repositories.PollRepository
+	messageRepo repositories.MessageRepository
+	chatRepo    repositories.ChatRepository
+	wsHub       *websocket.Hub
+}
+
+func NewPollUsecase(
+	pollRepo repositories.PollRepository,
+	messageRepo repositories.MessageRepository,
+	chatRepo repositories.ChatRepository,
+	wsHub *websocket.Hub,
+) *PollUsecase {
+	return &PollUsecase{
+		pollRepo:    pollRepo,
+		messageRepo: messageRepo,
+		chatRepo:    chatRepo,
+		wsHub:       wsHub,
+	}
+}
+
+// CreateForMessage stores the poll of a message that was just created
+func (pu *PollUsecase) CreateForMessage(message *models.Message) error {
+	poll := message.Poll
+	poll.MessageId = message.ID
+	poll.ChatId = message.ChatId
+	poll.CreatorId = message.SenderId
+	poll.CreatedAt = message.CreatedAt
+
+	if err := pu.pollRepo.Create(poll); err != nil {
+		return err
+	}
+
+	poll.Tally(nil, message.SenderId, time.Now().UTC())
+	return nil
+}
+
+// GetPoll returns a poll with its current tallies
+func (pu *PollUsecase) GetPoll(pollID, userID int) (*dto.PollResponse, error) {
+	poll, err := pu.findForMember(pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pu.tally(poll, userID); err != nil {
+		return nil, err
+	}
+	return dto.NewPollResponse(poll), nil
+}
+
+// Vote replaces the user's votes on a poll with the given options
+func (pu *PollUsecase) Vote(pollID, userID int, optionIDs []int) (*dto.PollResponse, error) {
+	poll, err := pu.findForMember(pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	optionIDs = uniqueInts(optionIDs)
+	if len(optionIDs) == 0 || (!poll.MultipleChoice && len(optionIDs) > 1) {
+		return nil, errors.New("invalid poll vote")
+	}
+
+	valid := make(map[int]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+	for _, optionID := range optionIDs {
+		if !valid[optionID] {
+			return nil, errors.New("invalid poll vote")
+		}
+	}
+
+	return pu.replaceVotes(poll, userID, optionIDs)
+}
+
+// RetractVote removes the user's votes from a poll
+func (pu *PollUsecase) RetractVote(pollID, userID int) (*dto.PollResponse, error) {
+	poll, err := pu.findForMember(pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pu.replaceVotes(poll, userID, nil)
+}
+
+func (pu *PollUsecase) replaceVotes(poll *models.Poll, userID int, optionIDs []int) (*dto.PollResponse, error) {
+	now := time.Now().UTC()
+	if poll.IsClosed(now) {
+		return nil, errors.New("poll is closed")
+	}
+
+	if err := pu.pollRepo.ReplaceVotes(poll.ID, userID, optionIDs, now); err != nil {
+		logger.Error("Failed to record poll vote: %v", err)
+		return nil, err
+	}
+
+	if err := pu.tally(poll, userID); err != nil {
+		return nil, err
+	}
+
+	pu.broadcast(events.EventPollUpdated, poll)
+	return dto.NewPollResponse(poll), nil
+}
+
+// ClosePoll stops a poll from accepting votes. The creator and chat managers can close it.
+func (pu *PollUsecase) ClosePoll(pollID, userID int) (*dto.PollResponse, error) {
+	poll, err := pu.findForMember(pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.CreatorId != userID {
+		role, err := pu.chatRepo.GetUserRole(poll.ChatId, userID)
+		if err != nil || !models.CanManageChat(role) {
+			return nil, errors.New("unauthorized to close this poll")
+		}
+	}
+
+	now := time.Now().UTC()
+	if poll.IsClosed(now) {
+		return nil, errors.New("poll is closed")
+	}
+
+	closed, err := pu.pollRepo.Close(poll.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, errors.New("poll is closed")
+	}
+	poll.ClosedAt = &now
+
+	if err := pu.tally(poll, userID); err != nil {
+		return nil, err
+	}
+
+	pu.broadcast(events.EventPollClosed, poll)
+	return dto.NewPollResponse(poll), nil
+}
+
+// AttachPolls sets the poll of each poll message, tallied for the viewer
+func (pu *PollUsecase) AttachPolls(messages []models.Message, viewerID int) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	messageIDs := make([]int, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.ID
+	}
+
+	polls, err := pu.pollRepo.FindByMessageIds(messageIDs)
+	if err != nil || len(polls) == 0 {
+		return err
+	}
+
+	pollIDs := make([]int, len(polls))
+	for i, poll := range polls {
+		pollIDs[i] = poll.ID
+	}
+	votes, err := pu.pollRepo.FindVotes(pollIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	byMessage := make(map[int]*models.Poll, len(polls))
+	for i := range polls {
+		polls[i].Tally(votes, viewerID, now)
+		byMessage[polls[i].MessageId] = &polls[i]
+	}
+	for i := range messages {
+		messages[i].Poll = byMessage[messages[i].ID]
+	}
+	return nil
+}
+
+// findForMember loads a poll whose message still exists and checks the user belongs to its chat
+func (pu *PollUsecase) findForMember(pollID, userID int) (*models.Poll, error) {
+	poll, err := pu.pollRepo.FindById(pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+
+	if _, err := pu.messageRepo.FindById(poll.MessageId); err != nil {
+		return nil, errors.New("poll not found")
+	}
+
+	if _, err := pu.chatRepo.GetUserRole(poll.ChatId, userID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	return poll, nil
+}
+
+func (pu *PollUsecase) tally(poll *models.Poll, viewerID int) error {
+	votes, err := pu.pollRepo.FindVotes([]int{poll.ID})
+	if err != nil {
+		return err
+	}
+	poll.Tally(votes, viewerID, time.Now().UTC())
+	return nil
+}
+
+// broadcast sends the tallies of a poll to the chat users, without anyone's own votes
+func (pu *PollUsecase) broadcast(eventType string, poll *models.Poll) {
+	users, err := pu.chatRepo.GetChatUsers(poll.ChatId)
+	if err != nil {
+		logger.Error("Failed to get chat users: %v", err)
+		return
+	}
+
+	shared := *poll
+	shared.MyVotes = nil
+	broadcastToUsers(pu.wsHub, users, eventType, poll.ChatId, &events.PollEventData{
+		ChatID:    poll.ChatId,
+		MessageID: poll.MessageId,
+		Poll:      &shared,
+	})
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+const (
+	deliveryFlushInterval = 200 * time.Millisecond
+	deliveryQueueSize     = 1024
+)
+
+type delivery struct {
+	userID    int
+	messageID int
+}
+
+// ReceiptUsecase tracks whether each recipient received and read a message.
+// Deliveries are batched, so a message sent to a large group produces one
+// update per flush for its sender instead of one per recipient.
+type ReceiptUsecase struct {
+	receiptRepo repositories.ReceiptRepository
+	chatRepo    repositories.ChatRepository
+	wsHub       *websocket.Hub
+	deliveries  chan delivery
+}
+
+func NewReceiptUsecase(
+	receiptRepo repositories.ReceiptRepository,
+	chatRepo repositories.ChatRepository,
+	wsHub *websocket.Hub,
+) *ReceiptUsecase {
+	return &ReceiptUsecase{
+		receiptRepo: receiptRepo,
+		chatRepo:    chatRepo,
+		wsHub:       wsHub,
+		deliveries:  make(chan delivery, deliveryQueueSize),
+	}
+}
+
+// TrackMessage starts tracking a new message; every recipient begins with the sent status
+func (ru *ReceiptUsecase) TrackMessage(message *models.Message, recipientIDs []int) {
+	if err := ru.receiptRepo.CreateForMessage(message.ID, recipientIDs); err != nil {
+		logger.Error("Failed to create message receipts: %v", err)
+	}
+
+	message.Delivery = &models.DeliverySummary{
+		MessageId:  message.ID,
+		ChatId:     message.ChatId,
+		SenderId:   message.SenderId,
+		Recipients: len(recipientIDs),
+	}
+}
+
+// RecordDelivery queues a delivery reported by the hub. It never blocks the
+// connection; if the queue is full the client's ack covers the message later.
+func (ru *ReceiptUsecase) RecordDelivery(userID, messageID int) {
+	select {
+	case ru.deliveries <- delivery{userID: userID, messageID: messageID}:
+	default:
+		logger.Error("Delivery queue full, dropping delivery of message %d to user %d", messageID, userID)
+	}
+}
+
+// Run records queued deliveries until the process exits
+func (ru *ReceiptUsecase) Run() {
+	ticker := time.NewTicker(deliveryFlushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[int][]int)
+	for {
+		select {
+		case d := <-ru.deliveries:
+			pending[d.userID] = append(pending[d.userID], d.messageID)
+		case <-ticker.C:
+			if len(pending) > 0 {
+				ru.flush(pending)
+				pending = make(map[int][]int)
+			}
+		}
+	}
+}
+
+func (ru *ReceiptUsecase) flush(pending map[int][]int) {
+	now := time.Now().UTC()
+	changed := make(map[int]bool)
+	for userID, messageIDs := range pending {
+		updated, err := ru.receiptRepo.MarkDelivered(userID, uniqueInts(messageIDs), now)
+		if err != nil {
+			logger.Error("Failed to record deliveries for user %d: %v", userID, err)
+			continue
+		}
+		for _, messageID := range updated {
+			changed[messageID] = true
+		}
+	}
+
+	messageIDs := make([]int, 0, len(changed))
+	for messageID := range changed {
+		messageIDs = append(messageIDs, messageID)
+	}
+	ru.notifySenders(messageIDs, events.EventMessageDelivered)
+}
+
+// MarkRead marks the chat's messages up to messageID as read by the user
+func (ru *ReceiptUsecase) MarkRead(chatID, userID, messageID int) error {
+	if _, err := ru.chatRepo.FindById(chatID); err != nil {
+		return errors.New("chat not found")
+	}
+
+	if _, err := ru.chatRepo.GetUserRole(chatID, userID); err != nil {
+		return errors.New("user is not a member of this chat")
+	}
+
+	messageIDs, err := ru.receiptRepo.MarkReadUpTo(userID, chatID, messageID, time.Now().UTC())
+	if err != nil {
+		logger.Error("Failed to mark messages read: %v", err)
+		return err
+	}
+
+	ru.notifySenders(messageIDs, events.EventMessageRead)
+	return nil
+}
+
+// AttachSummaries sets the delivery summary of the viewer's own messages.
+// System messages are not tracked.
+func (ru *ReceiptUsecase) AttachSummaries(messages []models.Message, viewerID int) error {
+	var messageIDs []int
+	for _, message := range messages {
+		if message.SenderId == viewerID && !message.IsSystem() && message.WebhookId == nil {
+			messageIDs = append(messageIDs, message.ID)
+		}
+	}
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	summaries, err := ru.receiptRepo.Summarize(messageIDs)
+	if err != nil {
+		return err
+	}
+
+	byMessage := make(map[int]models.DeliverySummary, len(summaries))
+	for _, summary := range summaries {
+		byMessage[summary.MessageId] = summary
+	}
+	for i := range messages {
+		if summary, ok := byMessage[messages[i].ID]; ok && messages[i].SenderId == viewerID {
+			messages[i].Delivery = &summary
+		}
+	}
+	return nil
+}
+
+// notifySenders sends the updated delivery counts of each message to its sender
+func (ru *ReceiptUsecase) notifySenders(messageIDs []int, eventType string) {
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	summaries, err := ru.receiptRepo.Summarize(messageIDs)
+	if err != nil {
+		logger.Error("Failed to summarize deliveries: %v", err)
+		return
+	}
+
+	for _, summary := range summaries {
+		eventData := &events.ReceiptEventData{
+			ChatID:         summary.ChatId,
+			MessageID:      summary.MessageId,
+			Status:         summary.Status(),
+			RecipientCount: summary.Recipients,
+			DeliveredCount: summary.Delivered,
+			ReadCount:      summary.Read,
+		}
+
+		event := events.NewWebSocketEvent(eventType, summary.ChatId, eventData)
+		if eventJSON, err := event.ToJSON(); err == nil {
+			ru.wsHub.BroadcastToUsers([]int{summary.SenderId}, eventJSON)
+		}
+	}
+}
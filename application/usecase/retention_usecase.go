@@ -0,0 +1,386 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+)
+
+const (
+	retentionInterval = time.Hour
+	// Messages deleted per statement, so a large purge does not hold the
+	// database for long
+	retentionBatchSize = 500
+	// Pause between batches to let other writes through
+	retentionBatchPause      = 100 * time.Millisecond
+	retentionChatPageSize    = 200
+	maxListedRetentionRuns   = 50
+	maxLegalHoldReasonLength = 500
+)
+
+// RetentionUsecase deletes messages past their retention policy. Each chat
+// applies the stricter of its own policy and the server's; chats under a
+// legal hold are skipped. Every run is recorded with what it deleted from each
+// chat, so deletions can be accounted for later.
+type RetentionUsecase struct {
+	retentionRepo repositories.RetentionRepository
+	chatRepo      repositories.ChatRepository
+	messageRepo   repositories.MessageRepository
+	userRepo      repositories.UserRepository
+	wsHub         *websocket.Hub
+	serverPolicy  models.RetentionPolicy
+	admins        serverAdmins
+}
+
+func NewRetentionUsecase(
+	retentionRepo repositories.RetentionRepository,
+	chatRepo repositories.ChatRepository,
+	messageRepo repositories.MessageRepository,
+	userRepo repositories.UserRepository,
+	wsHub *websocket.Hub,
+	serverPolicy models.RetentionPolicy,
+	adminEmails []string,
+) *RetentionUsecase {
+	return &RetentionUsecase{
+		retentionRepo: retentionRepo,
+		chatRepo:      chatRepo,
+		messageRepo:   messageRepo,
+		userRepo:      userRepo,
+		wsHub:         wsHub,
+		serverPolicy:  serverPolicy,
+		admins:        newServerAdmins(adminEmails),
+	}
+}
+
+// GetChatRetention returns the policies that apply to a chat. Whether the
+// chat is on legal hold is only shown to server administrators.
+func (ru *RetentionUsecase) GetChatRetention(chatID, userID int) (*dto.ChatRetentionResponse, error) {
+	chat, err := ru.chatRepo.FindById(chatID)
+	if err != nil {
+		return nil, errors.New("chat not found")
+	}
+	if _, err := ru.chatRepo.GetUserRole(chatID, userID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	response := &dto.ChatRetentionResponse{
+		ChatID:    chatID,
+		Chat:      chat.RetentionPolicy(),
+		Server:    ru.serverPolicy,
+		Effective: ru.serverPolicy.Stricter(chat.RetentionPolicy()),
+	}
+	if ru.authorize(userID) == nil {
+		held, err := ru.isHeld(chatID)
+		if err != nil {
+			return nil, err
+		}
+		response.LegalHold = &held
+	}
+	return response, nil
+}
+
+// PlaceLegalHold keeps every message of a chat from the purge until the hold
+// is released. Placing a hold again replaces its reason.
+func (ru *RetentionUsecase) PlaceLegalHold(chatID, userID int, reason string) (*dto.LegalHoldResponse, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > maxLegalHoldReasonLength {
+		return nil, errors.New("invalid legal hold reason")
+	}
+	if err := ru.authorize(userID); err != nil {
+		return nil, err
+	}
+	if _, err := ru.chatRepo.FindById(chatID); err != nil {
+		return nil, errors.New("chat not found")
+	}
+
+	hold := &models.LegalHold{
+		ChatId:    chatID,
+		Reason:    reason,
+		PlacedBy:  userID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := ru.retentionRepo.PlaceLegalHold(hold); err != nil {
+		logger.Error("Failed to place legal hold on chat %d: %v", chatID, err)
+		return nil, err
+	}
+
+	// An existing hold keeps who placed it and when
+	saved, err := ru.retentionRepo.FindLegalHold(chatID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Legal hold placed on chat %d by user %d", chatID, userID)
+	return dto.NewLegalHoldResponse(saved), nil
+}
+
+// ReleaseLegalHold lets the purge delete the chat's messages again
+func (ru *RetentionUsecase) ReleaseLegalHold(chatID, userID int) error {
+	if err := ru.authorize(userID); err != nil {
+		return err
+	}
+
+	released, err := ru.retentionRepo.ReleaseLegalHold(chatID)
+	if err != nil {
+		logger.Error("Failed to release legal hold on chat %d: %v", chatID, err)
+		return err
+	}
+	if !released {
+		return errors.New("legal hold not found")
+	}
+	logger.Info("Legal hold on chat %d released by user %d", chatID, userID)
+	return nil
+}
+
+// GetLegalHolds returns every legal hold in the order they were placed
+func (ru *RetentionUsecase) GetLegalHolds(userID int) ([]dto.LegalHoldResponse, error) {
+	if err := ru.authorize(userID); err != nil {
+		return nil, err
+	}
+
+	holds, err := ru.retentionRepo.FindLegalHolds()
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewLegalHoldResponseList(holds), nil
+}
+
+// GetRuns returns the latest purge runs
+func (ru *RetentionUsecase) GetRuns(userID int) ([]dto.RetentionRunResponse, error) {
+	if err := ru.authorize(userID); err != nil {
+		return nil, err
+	}
+
+	runs, err := ru.retentionRepo.FindRuns(maxListedRetentionRuns)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewRetentionRunResponseList(runs), nil
+}
+
+// GetRun returns a purge run with what it deleted from each chat
+func (ru *RetentionUsecase) GetRun(runID, userID int) (*dto.RetentionRunResponse, error) {
+	if err := ru.authorize(userID); err != nil {
+		return nil, err
+	}
+
+	run, err := ru.retentionRepo.FindRunById(runID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("retention run not found")
+		}
+		return nil, err
+	}
+	chats, err := ru.retentionRepo.FindRunChats(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.NewRetentionRunResponse(run)
+	response.Chats = chats
+	return response, nil
+}
+
+// authorize checks that the user is a server administrator
+func (ru *RetentionUsecase) authorize(userID int) error {
+	user, err := ru.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !ru.admins.includes(user) {
+		return errors.New("unauthorized to manage retention")
+	}
+	return nil
+}
+
+func (ru *RetentionUsecase) isHeld(chatID int) (bool, error) {
+	_, err := ru.retentionRepo.FindLegalHold(chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Run purges messages past their retention until the process exits. Runs
+// left running by a previous process are recorded as failed.
+func (ru *RetentionUsecase) Run() {
+	if err := ru.retentionRepo.FailInterrupted(time.Now().UTC()); err != nil {
+		logger.Error("Failed to close interrupted retention runs: %v", err)
+	}
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		ru.purge()
+		<-ticker.C
+	}
+}
+
+// purge runs one pass over every chat and records it
+func (ru *RetentionUsecase) purge() {
+	run := &models.RetentionRun{
+		Status:    models.RetentionRunRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := ru.retentionRepo.CreateRun(run); err != nil {
+		logger.Error("Failed to create retention run: %v", err)
+		return
+	}
+
+	err := ru.purgeChats(run)
+
+	completedAt := time.Now().UTC()
+	run.CompletedAt = &completedAt
+	run.Status = models.RetentionRunCompleted
+	if err != nil {
+		logger.Error("Retention run %d failed: %v", run.ID, err)
+		message := err.Error()
+		run.Status = models.RetentionRunFailed
+		run.Error = &message
+	}
+	if err := ru.retentionRepo.FinishRun(run); err != nil {
+		logger.Error("Failed to finish retention run %d: %v", run.ID, err)
+		return
+	}
+	if run.DeletedCount > 0 {
+		logger.Info("Retention run %d deleted %d messages from %d chats", run.ID, run.DeletedCount, run.ChatCount)
+	}
+}
+
+func (ru *RetentionUsecase) purgeChats(run *models.RetentionRun) error {
+	afterID := 0
+	for {
+		chats, err := ru.retentionRepo.FindChats(afterID, retentionChatPageSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range chats {
+			chat := &chats[i]
+			policy := ru.serverPolicy.Stricter(chat.Policy())
+			if policy.IsZero() {
+				continue
+			}
+			if chat.LegalHold {
+				run.HeldChatCount++
+				continue
+			}
+
+			deleted, held, err := ru.purgeChat(run, chat.ChatId, policy)
+			if err != nil {
+				return err
+			}
+			if held {
+				run.HeldChatCount++
+			}
+			if deleted > 0 {
+				run.ChatCount++
+				run.DeletedCount += deleted
+			}
+		}
+
+		if len(chats) < retentionChatPageSize {
+			return nil
+		}
+		afterID = chats[len(chats)-1].ChatId
+	}
+}
+
+// purgeChat deletes the chat's messages past the policy in batches, oldest
+// first. The hold is checked before every batch, so a hold placed during a
+// long purge stops it. The run's entry for the chat is updated after each
+// batch, so deletions are recorded even if the process stops midway.
+func (ru *RetentionUsecase) purgeChat(run *models.RetentionRun, chatID int, policy models.RetentionPolicy) (int, bool, error) {
+	var before *time.Time
+	if policy.Days > 0 {
+		cutoff := run.StartedAt.AddDate(0, 0, -policy.Days)
+		before = &cutoff
+	}
+	belowID := 0
+	if policy.MaxMessages > 0 {
+		cutoffID, err := ru.messageRepo.FindRetentionCutoffId(chatID, policy.MaxMessages)
+		if err != nil {
+			return 0, false, err
+		}
+		belowID = cutoffID
+	}
+	if before == nil && belowID == 0 {
+		return 0, false, nil
+	}
+
+	entry := &models.RetentionRunChat{
+		RunId:       run.ID,
+		ChatId:      chatID,
+		Days:        policy.Days,
+		MaxMessages: policy.MaxMessages,
+	}
+	for {
+		held, err := ru.isHeld(chatID)
+		if err != nil {
+			return entry.DeletedCount, false, err
+		}
+		if held {
+			return entry.DeletedCount, true, nil
+		}
+
+		messages, err := ru.messageRepo.FindPurgeable(chatID, before, belowID, retentionBatchSize)
+		if err != nil {
+			return entry.DeletedCount, false, err
+		}
+		if len(messages) == 0 {
+			return entry.DeletedCount, false, nil
+		}
+
+		ids := make([]int, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+			if entry.DeletedCount == 0 && i == 0 {
+				entry.FirstMessageId = message.ID
+				entry.OldestCreatedAt = message.CreatedAt
+				entry.NewestCreatedAt = message.CreatedAt
+			}
+			entry.LastMessageId = message.ID
+			if message.CreatedAt.Before(entry.OldestCreatedAt) {
+				entry.OldestCreatedAt = message.CreatedAt
+			}
+			if message.CreatedAt.After(entry.NewestCreatedAt) {
+				entry.NewestCreatedAt = message.CreatedAt
+			}
+		}
+
+		if err := ru.messageRepo.DeleteByIds(ids); err != nil {
+			return entry.DeletedCount, false, err
+		}
+		entry.DeletedCount += len(ids)
+		if err := ru.retentionRepo.SaveRunChat(entry); err != nil {
+			return entry.DeletedCount, false, err
+		}
+		ru.broadcastPurged(chatID, ids)
+
+		if len(messages) < retentionBatchSize {
+			return entry.DeletedCount, false, nil
+		}
+		time.Sleep(retentionBatchPause)
+	}
+}
+
+func (ru *RetentionUsecase) broadcastPurged(chatID int, messageIDs []int) {
+	users, err := ru.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		logger.Error("Failed to get chat users: %v", err)
+		return
+	}
+
+	broadcastToUsers(ru.wsHub, users, events.EventMessagePurged, chatID, &events.MessagesExpiredEventData{
+		ChatID:     chatID,
+		MessageIDs: messageIDs,
+	})
+}
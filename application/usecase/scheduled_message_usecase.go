@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+)
+
+const (
+	// MaxScheduleAhead is how far in the future a message can be scheduled
+	MaxScheduleAhead = 365 * 24 * time.Hour
+
+	schedulerInterval  = time.Second
+	schedulerBatchSize = 50
+)
+
+type ScheduledMessageUsecase struct {
+	scheduledRepo  repositories.ScheduledMessageRepository
+	chatRepo       repositories.ChatRepository
+	messageUseCase *MessageUsecase
+}
+
+func NewScheduledMessageUsecase(
+	scheduledRepo repositories.ScheduledMessageRepository,
+	chatRepo repositories.ChatRepository,
+	messageUseCase *MessageUsecase,
+) *ScheduledMessageUsecase {
+	return &ScheduledMessageUsecase{
+		scheduledRepo:  scheduledRepo,
+		chatRepo:       chatRepo,
+		messageUseCase: messageUseCase,
+	}
+}
+
+// CreateScheduledMessage schedules a message to be posted at scheduledAt
+func (su *ScheduledMessageUsecase) CreateScheduledMessage(chatID, senderID int, content string, scheduledAt time.Time) (*dto.ScheduledMessageResponse, error) {
+	if err := validateSchedule(content, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	if _, err := su.chatRepo.FindById(chatID); err != nil {
+		return nil, errors.New("chat not found")
+	}
+
+	if _, err := su.chatRepo.GetUserRole(chatID, senderID); err != nil {
+		return nil, errors.New("user is not a member of this chat")
+	}
+
+	// Times are stored in UTC so they compare correctly as text in SQLite
+	now := time.Now().UTC()
+	scheduled := &models.ScheduledMessage{
+		ChatId:      chatID,
+		SenderId:    senderID,
+		Content:     content,
+		ScheduledAt: scheduledAt.UTC(),
+		Status:      models.ScheduledStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := su.scheduledRepo.Create(scheduled); err != nil {
+		logger.Error("Failed to create scheduled message: %v", err)
+		return nil, err
+	}
+
+	return dto.NewScheduledMessageResponse(scheduled), nil
+}
+
+// GetScheduledMessages returns the user's pending scheduled messages, optionally for one chat
+func (su *ScheduledMessageUsecase) GetScheduledMessages(userID, chatID int) ([]dto.ScheduledMessageResponse, error) {
+	scheduled, err := su.scheduledRepo.FindPendingBySenderId(userID, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewScheduledMessageResponseList(scheduled), nil
+}
+
+// GetScheduledMessage returns one of the user's scheduled messages
+func (su *ScheduledMessageUsecase) GetScheduledMessage(id, userID int) (*dto.ScheduledMessageResponse, error) {
+	scheduled, err := su.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewScheduledMessageResponse(scheduled), nil
+}
+
+// UpdateScheduledMessage changes the content or time of a pending scheduled message
+func (su *ScheduledMessageUsecase) UpdateScheduledMessage(id, userID int, content string, scheduledAt time.Time) (*dto.ScheduledMessageResponse, error) {
+	scheduled, err := su.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSchedule(content, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	scheduled.Content = content
+	scheduled.ScheduledAt = scheduledAt.UTC()
+	scheduled.UpdatedAt = time.Now().UTC()
+
+	updated, err := su.scheduledRepo.UpdatePending(scheduled)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errors.New("scheduled message already sent")
+	}
+
+	return dto.NewScheduledMessageResponse(scheduled), nil
+}
+
+// DeleteScheduledMessage cancels a pending scheduled message
+func (su *ScheduledMessageUsecase) DeleteScheduledMessage(id, userID int) error {
+	if _, err := su.findOwned(id, userID); err != nil {
+		return err
+	}
+
+	deleted, err := su.scheduledRepo.DeletePending(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("scheduled message already sent")
+	}
+
+	return nil
+}
+
+// Run dispatches due scheduled messages until the process exits.
+// Messages left dispatching by a crash are retried; SendScheduledMessage
+// guarantees they are not posted twice.
+func (su *ScheduledMessageUsecase) Run() {
+	if released, err := su.scheduledRepo.ReleaseDispatching(); err != nil {
+		logger.Error("Failed to release scheduled messages: %v", err)
+	} else if released > 0 {
+		logger.Info("Released %d scheduled messages interrupted during dispatch", released)
+	}
+
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		su.dispatchDue()
+	}
+}
+
+func (su *ScheduledMessageUsecase) dispatchDue() {
+	due, err := su.scheduledRepo.FindDue(time.Now().UTC(), schedulerBatchSize)
+	if err != nil {
+		logger.Error("Failed to find due scheduled messages: %v", err)
+		return
+	}
+
+	for i := range due {
+		su.dispatch(&due[i])
+	}
+}
+
+func (su *ScheduledMessageUsecase) dispatch(scheduled *models.ScheduledMessage) {
+	claimed, err := su.scheduledRepo.Claim(scheduled.ID)
+	if err != nil || !claimed {
+		return
+	}
+
+	message, err := su.messageUseCase.SendScheduledMessage(scheduled)
+	if err != nil {
+		var rateLimitErr *models.RateLimitError
+		if errors.As(err, &rateLimitErr) || errors.Is(err, errStorage) {
+			// Retry on the next tick
+			logger.Info("Scheduled message %d was not sent, retrying: %v", scheduled.ID, err)
+			if err := su.scheduledRepo.Release(scheduled.ID); err != nil {
+				logger.Error("Failed to release scheduled message %d: %v", scheduled.ID, err)
+			}
+			return
+		}
+
+		reason := err.Error()
+		var moderationErr *models.ModerationError
+		if errors.As(err, &moderationErr) && moderationErr.Reason != "" {
+			reason += ": " + moderationErr.Reason
+		}
+		logger.Info("Scheduled message %d cannot be sent: %s", scheduled.ID, reason)
+		if err := su.scheduledRepo.MarkFailed(scheduled.ID, reason); err != nil {
+			logger.Error("Failed to mark scheduled message %d as failed: %v", scheduled.ID, err)
+		}
+		return
+	}
+
+	// Commands replying only to the sender post no message
+	var messageID *int
+	if message.MessageID != 0 {
+		messageID = &message.MessageID
+	}
+	if err := su.scheduledRepo.MarkSent(scheduled.ID, messageID); err != nil {
+		logger.Error("Failed to mark scheduled message %d as sent: %v", scheduled.ID, err)
+	}
+}
+
+func (su *ScheduledMessageUsecase) findOwned(id, userID int) (*models.ScheduledMessage, error) {
+	scheduled, err := su.scheduledRepo.FindById(id)
+	if err != nil || scheduled.SenderId != userID {
+		return nil, errors.New("scheduled message not found")
+	}
+	return scheduled, nil
+}
+
+func validateSchedule(content string, scheduledAt time.Time) error {
+	if strings.TrimSpace(content) == "" {
+		return errors.New("content is required")
+	}
+
+	now := time.Now()
+	if !scheduledAt.After(now) {
+		return errors.New("scheduled time must be in the future")
+	}
+	if scheduledAt.After(now.Add(MaxScheduleAhead)) {
+		return errors.New("scheduled time is too far in the future")
+	}
+
+	return nil
+}
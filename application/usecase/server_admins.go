@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// serverAdmins are the users configured to run server-wide operations, such
+// as imports and legal holds, that no chat role grants
+type serverAdmins map[string]bool
+
+func newServerAdmins(emails []string) serverAdmins {
+	admins := make(serverAdmins, len(emails))
+	for _, email := range emails {
+		admins[strings.ToLower(email)] = true
+	}
+	return admins
+}
+
+// includes reports whether the user is a server administrator. Bots never are.
+func (a serverAdmins) includes(user *models.User) bool {
+	return !user.IsBot && a[strings.ToLower(user.Email)]
+}
@@ -21,10 +21,11 @@ func NewUserUseCase(
 	}
 }
 
-// GetAllUsersExcept retrieves all users except the specified user ID
-func (uc *UserUseCase) GetAllUsersExcept(excludeUserId int) ([]common.UserListData, error) {
+// GetAllUsersExcept retrieves all users except the specified user ID.
+// Bots are only included when asked for.
+func (uc *UserUseCase) GetAllUsersExcept(excludeUserId int, includeBots bool) ([]common.UserListData, error) {
 	// Get all users from repository
-	users, err := uc.userRepo.FindAllExcept(excludeUserId)
+	users, err := uc.userRepo.FindAllExcept(excludeUserId, includeBots)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +38,7 @@ func (uc *UserUseCase) GetAllUsersExcept(excludeUserId int) ([]common.UserListDa
 			Email:     user.Email,
 			Nickname:  user.Nickname,
 			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			IsBot:     user.IsBot,
 		}
 	}
 
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/dto"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/ratelimit"
+)
+
+// Messages a single webhook may post
+const (
+	WebhookRatePerMinute = 30
+	WebhookBurst         = 10
+)
+
+// WebhookPath is where webhook tokens are posted to
+const WebhookPath = "/hooks/"
+
+type WebhookUsecase struct {
+	webhookRepo repositories.WebhookRepository
+	chatRepo    repositories.ChatRepository
+	messages    *MessageUsecase
+	limiter     *ratelimit.Limiter
+}
+
+func NewWebhookUsecase(
+	webhookRepo repositories.WebhookRepository,
+	chatRepo repositories.ChatRepository,
+	messages *MessageUsecase,
+) *WebhookUsecase {
+	return &WebhookUsecase{
+		webhookRepo: webhookRepo,
+		chatRepo:    chatRepo,
+		messages:    messages,
+		limiter:     ratelimit.New(WebhookRatePerMinute, WebhookBurst),
+	}
+}
+
+// CreateWebhook creates an incoming webhook for a chat. The returned token is
+// not stored and cannot be retrieved again.
+func (wu *WebhookUsecase) CreateWebhook(chatID, userID int, name string) (*dto.WebhookResponse, error) {
+	name = strings.TrimSpace(name)
+	if err := models.ValidateBotName(name); err != nil {
+		return nil, err
+	}
+
+	if err := wu.checkManager(chatID, userID); err != nil {
+		return nil, err
+	}
+	// A webhook cannot encrypt for the members' devices
+	if chat, err := wu.chatRepo.FindById(chatID); err == nil && chat.Encrypted {
+		return nil, errors.New("not supported in encrypted chats")
+	}
+
+	token, err := newWebhookToken()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &models.IncomingWebhook{
+		ChatId:    chatID,
+		Name:      name,
+		TokenHash: models.HashWebhookToken(token),
+		CreatedBy: userID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := wu.webhookRepo.Create(webhook); err != nil {
+		logger.Error("Failed to create webhook: %v", err)
+		return nil, err
+	}
+
+	response := dto.NewWebhookResponse(webhook)
+	response.Token = token
+	response.URL = WebhookPath + token
+	return response, nil
+}
+
+// GetWebhooks lists the active webhooks of a chat
+func (wu *WebhookUsecase) GetWebhooks(chatID, userID int) ([]dto.WebhookResponse, error) {
+	if err := wu.checkManager(chatID, userID); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := wu.webhookRepo.FindActiveByChatId(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewWebhookResponseList(webhooks), nil
+}
+
+// RevokeWebhook disables a webhook. Messages it posted keep referring to it.
+func (wu *WebhookUsecase) RevokeWebhook(chatID, webhookID, userID int) error {
+	if err := wu.checkManager(chatID, userID); err != nil {
+		return err
+	}
+
+	webhook, err := wu.webhookRepo.FindById(webhookID)
+	if err != nil || webhook.ChatId != chatID {
+		return errors.New("webhook not found")
+	}
+
+	revoked, err := wu.webhookRepo.Revoke(webhookID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("webhook not found")
+	}
+
+	return nil
+}
+
+// ExecuteWebhook posts a message into the webhook's chat. The payload may
+// override the name the message is shown under. Messages are posted as the
+// webhook's creator, so the webhook stops working once they are no longer a
+// manager of the chat.
+func (wu *WebhookUsecase) ExecuteWebhook(token, text, username string) (*dto.MessageResponse, error) {
+	webhook, err := wu.webhookRepo.FindActiveByTokenHash(models.HashWebhookToken(token))
+	if err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	if err := wu.checkManager(webhook.ChatId, webhook.CreatedBy); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("content is required")
+	}
+
+	botName := webhook.Name
+	if username = strings.TrimSpace(username); username != "" {
+		if err := models.ValidateBotName(username); err != nil {
+			return nil, err
+		}
+		botName = username
+	}
+
+	if allowed, _ := wu.limiter.Allow(strconv.Itoa(webhook.ID)); !allowed {
+		return nil, errors.New("webhook rate limited")
+	}
+
+	message, err := wu.messages.PostWebhookMessage(webhook, text, botName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wu.webhookRepo.TouchLastUsed(webhook.ID, time.Now().UTC()); err != nil {
+		logger.Error("Failed to record use of webhook %d: %v", webhook.ID, err)
+	}
+
+	return message, nil
+}
+
+func (wu *WebhookUsecase) checkManager(chatID, userID int) error {
+	if _, err := wu.chatRepo.FindById(chatID); err != nil {
+		return errors.New("chat not found")
+	}
+
+	role, err := wu.chatRepo.GetUserRole(chatID, userID)
+	if err != nil {
+		return errors.New("user is not a member of this chat")
+	}
+	if !models.CanManageChat(role) {
+		return errors.New("unauthorized to manage webhooks")
+	}
+
+	return nil
+}
+
+func newWebhookToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
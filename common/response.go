@@ -55,44 +55,222 @@ type AuthData struct {
 type UserInfo struct {
 	ID       int    `json:"id" example:"1"`
 	Nickname string `json:"nickname" example:"홍길동"`
+	IsBot    bool   `json:"isBot" example:"false"`
 }
 
 // ChatData represents basic chat information
 type ChatData struct {
-	ChatID    int    `json:"chatId" example:"1"` // Changed from id to chatId
-	Name      string `json:"name" example:"개발팀 채팅방"`
+	ChatID            int    `json:"chatId" example:"1"` // Changed from id to chatId
+	Name              string `json:"name" example:"개발팀 채팅방"`
+	Topic             string `json:"topic" example:"이번 주 배포 일정"`
+	MessageTTLSeconds int    `json:"messageTtlSeconds" example:"0"`
+	SlowModeSeconds   int    `json:"slowModeSeconds" example:"0"`
+	// The chat's own retention policy
+	Retention RetentionPolicyData `json:"retention"`
+	// Encrypted chats only carry end-to-end encrypted messages
+	Encrypted bool   `json:"encrypted" example:"false"`
 	CreatedAt string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
 }
 
+// RetentionPolicyData represents how long messages are kept; 0 disables a limit
+type RetentionPolicyData struct {
+	// Messages older than this many days are deleted
+	Days int `json:"days" example:"365"`
+	// Messages beyond the latest this many are deleted
+	MaxMessages int `json:"maxMessages" example:"0"`
+}
+
 // ChatListData represents chat information with users
 type ChatListData struct {
-	ChatID      int          `json:"chatId" example:"1"` // Changed from id to chatId
-	Name        string       `json:"name" example:"개발팀 채팅방"`
-	CreatedAt   string       `json:"createdAt" example:"2024-03-23T12:00:00Z"`
-	LastMessage *LastMessage `json:"lastMessage,omitempty"`
-	Users       []UserInfo   `json:"users"`
+	ChatID            int                 `json:"chatId" example:"1"` // Changed from id to chatId
+	Name              string              `json:"name" example:"개발팀 채팅방"`
+	Topic             string              `json:"topic" example:"이번 주 배포 일정"`
+	MessageTTLSeconds int                 `json:"messageTtlSeconds" example:"0"`
+	SlowModeSeconds   int                 `json:"slowModeSeconds" example:"0"`
+	Retention         RetentionPolicyData `json:"retention"`
+	Encrypted         bool                `json:"encrypted" example:"false"`
+	CreatedAt         string              `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	LastMessage       *LastMessage        `json:"lastMessage,omitempty"`
+	Users             []UserInfo          `json:"users"`
+	Draft             *DraftInfo          `json:"draft,omitempty"`
 }
 
 // MessageData represents message information
 type MessageData struct {
-	MessageID      int    `json:"messageId" example:"1"` // Changed from id to messageId
-	ChatID         int    `json:"chatId" example:"1"`
-	SenderID       int    `json:"senderId" example:"1"`
-	SenderNickname string `json:"senderNickname" example:"홍길동"`
-	Content        string `json:"content" example:"안녕하세요"`
-	CreatedAt      string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
-	UpdatedAt      string `json:"updatedAt" example:"2024-03-23T12:00:00Z"`
+	MessageID       int    `json:"messageId" example:"1"` // Changed from id to messageId
+	ChatID          int    `json:"chatId" example:"1"`
+	SenderID        int    `json:"senderId" example:"1"`
+	SenderNickname  string `json:"senderNickname" example:"홍길동"`
+	Content         string `json:"content" example:"**안녕하세요**"`
+	CreatedAt       string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	UpdatedAt       string `json:"updatedAt" example:"2024-03-23T12:00:00Z"`
+	ExpiresAt       string `json:"expiresAt,omitempty" example:"2024-03-24T12:00:00Z"`
+	ClientMessageID string `json:"clientMessageId,omitempty" example:"7f9c2d1e-5b4a-4c3e-9a8b-1d2e3f4a5b6c"`
+	MessageType     string `json:"messageType" example:"user" enums:"user,system,ephemeral"`
+	WebhookID       int    `json:"webhookId,omitempty" example:"1"`
+	// Set on replies in a thread
+	ThreadRootID int `json:"threadRootId,omitempty" example:"1"`
+	// Set on messages of encrypted chats, whose content is an encrypted envelope
+	Encrypted bool `json:"encrypted,omitempty" example:"false"`
+
+	SystemEvent   *SystemEventData    `json:"systemEvent,omitempty"`
+	Formatted     []RichTextBlockData `json:"formatted"`
+	ForwardedFrom *ForwardedFromData  `json:"forwardedFrom,omitempty"`
+	LinkPreviews  []LinkPreviewData   `json:"linkPreviews,omitempty"`
+	Poll          *PollData           `json:"poll,omitempty"`
+	Attachments   []AttachmentData    `json:"attachments,omitempty"`
+	Reactions     []ReactionData      `json:"reactions,omitempty"`
+	Delivery      *DeliveryData       `json:"delivery,omitempty"`
+}
+
+// ReactionData represents the users who reacted to a message with one emoji
+type ReactionData struct {
+	Emoji string `json:"emoji" example:"thumbsup"`
+	Count int    `json:"count" example:"3"`
+	// Whether the current user is one of them
+	Reacted bool `json:"reacted" example:"true"`
+}
+
+// AttachmentData represents a card sent by a bot below its message
+type AttachmentData struct {
+	Color     string                `json:"color,omitempty" example:"#2EB67D"`
+	Title     string                `json:"title,omitempty" example:"빌드 성공"`
+	TitleURL  string                `json:"titleUrl,omitempty" example:"https://ci.example.com/builds/128"`
+	Text      string                `json:"text,omitempty" example:"**main** #128"`
+	Formatted []RichTextBlockData   `json:"formatted,omitempty"`
+	Fields    []AttachmentFieldData `json:"fields,omitempty"`
+	ImageURL  string                `json:"imageUrl,omitempty" example:"https://ci.example.com/badge.png"`
+	Footer    string                `json:"footer,omitempty" example:"CI"`
+}
+
+// AttachmentFieldData represents a labelled value of a card
+type AttachmentFieldData struct {
+	Title string `json:"title" example:"소요 시간"`
+	Value string `json:"value" example:"3분 12초"`
+	Short bool   `json:"short,omitempty" example:"true"`
+}
+
+// PollData represents a poll with its current tallies
+type PollData struct {
+	PollID         int              `json:"pollId" example:"1"`
+	MessageID      int              `json:"messageId" example:"10"`
+	ChatID         int              `json:"chatId" example:"1"`
+	CreatorID      int              `json:"creatorId" example:"1"`
+	Question       string           `json:"question" example:"점심 메뉴는?"`
+	MultipleChoice bool             `json:"multipleChoice" example:"false"`
+	Anonymous      bool             `json:"anonymous" example:"false"`
+	Closed         bool             `json:"closed" example:"false"`
+	ClosesAt       string           `json:"closesAt,omitempty" example:"2024-03-23T03:00:00Z"`
+	ClosedAt       string           `json:"closedAt,omitempty" example:"2024-03-23T03:00:00Z"`
+	TotalVoters    int              `json:"totalVoters" example:"3"`
+	Options        []PollOptionData `json:"options"`
+	MyVotes        []int            `json:"myVotes" example:"1"`
+}
+
+// PollOptionData represents a poll option; voters are only listed on named polls
+type PollOptionData struct {
+	OptionID  int             `json:"optionId" example:"1"`
+	Text      string          `json:"text" example:"김치찌개"`
+	VoteCount int             `json:"voteCount" example:"2"`
+	Voters    []PollVoterData `json:"voters,omitempty"`
+}
+
+// PollVoterData represents a voter of a named poll
+type PollVoterData struct {
+	UserID   int    `json:"userId" example:"2"`
+	Nickname string `json:"nickname" example:"김철수"`
+}
+
+// SystemEventData represents the chat activity described by a system message
+type SystemEventData struct {
+	Action       string                `json:"action" example:"members.added" enums:"chat.created,chat.renamed,topic.updated,members.added,member.removed,member.left,member.role_updated,message.pinned,message.unpinned,disappearing.updated,slow_mode.updated,retention.updated"`
+	Actor        SystemEventUserData   `json:"actor"`
+	Targets      []SystemEventUserData `json:"targets,omitempty"`
+	ChatName     string                `json:"chatName,omitempty" example:"개발팀"`
+	PreviousName string                `json:"previousName,omitempty" example:"Team Chat"`
+	Topic        string                `json:"topic,omitempty" example:"이번 주 배포 일정"`
+	MessageID    int                   `json:"messageId,omitempty" example:"10"`
+	TTLSeconds   *int                  `json:"ttlSeconds,omitempty" example:"86400"`
+	// Set for slow_mode.updated, 0 when slow mode was turned off
+	SlowModeSeconds *int `json:"slowModeSeconds,omitempty" example:"30"`
+	// Set for retention.updated
+	Retention *RetentionPolicyData `json:"retention,omitempty"`
+	// Set for member.role_updated
+	Role string `json:"role,omitempty" example:"admin"`
+}
+
+// SystemEventUserData represents a user taking part in a system event
+type SystemEventUserData struct {
+	UserID   int    `json:"userId" example:"1"`
+	Nickname string `json:"nickname" example:"홍길동"`
+}
+
+// DeliveryData represents the aggregated delivery status of the sender's message
+type DeliveryData struct {
+	Status         string `json:"status" example:"delivered" enums:"sent,delivered,read"`
+	RecipientCount int    `json:"recipientCount" example:"3"`
+	DeliveredCount int    `json:"deliveredCount" example:"3"`
+	ReadCount      int    `json:"readCount" example:"1"`
+}
+
+// RichTextBlockData represents a paragraph, quote or code block of a formatted message
+type RichTextBlockData struct {
+	Type     string             `json:"type" example:"paragraph" enums:"paragraph,quote,code_block"`
+	Language string             `json:"language,omitempty" example:"go"`
+	Text     string             `json:"text,omitempty"`
+	Children []RichTextNodeData `json:"children,omitempty"`
+}
+
+// RichTextNodeData represents an inline span of a formatted message
+type RichTextNodeData struct {
+	Type     string             `json:"type" example:"bold" enums:"text,bold,italic,code,link,spoiler,line_break"`
+	Text     string             `json:"text,omitempty" example:"안녕하세요"`
+	URL      string             `json:"url,omitempty"`
+	Children []RichTextNodeData `json:"children,omitempty"`
+}
+
+// ForwardedFromData represents the original message of a forwarded message
+type ForwardedFromData struct {
+	MessageID      int    `json:"messageId" example:"10"`
+	ChatID         int    `json:"chatId,omitempty" example:"2"`
+	SenderID       int    `json:"senderId" example:"3"`
+	SenderNickname string `json:"senderNickname" example:"김철수"`
+}
+
+// LinkPreviewData represents the preview of a URL in a message
+type LinkPreviewData struct {
+	URL         string `json:"url" example:"https://github.com"`
+	Title       string `json:"title,omitempty" example:"GitHub"`
+	Description string `json:"description,omitempty" example:"Where the world builds software"`
+	ImageURL    string `json:"imageUrl,omitempty" example:"https://github.githubassets.com/images/og.png"`
+	SiteName    string `json:"siteName,omitempty" example:"GitHub"`
 }
 
 // LastMessage represents last message in chat
 type LastMessage struct {
 	MessageID      int    `json:"messageId" example:"1"` // Added messageId field
-	Content        string `json:"content" example:"안녕하세요"`
+	MessageType    string `json:"messageType" example:"user" enums:"user,system"`
+	Content        string `json:"content" example:"**안녕하세요**"`
+	PlainText      string `json:"plainText" example:"안녕하세요"`
 	SenderID       int    `json:"senderId" example:"1"`
 	SenderNickname string `json:"senderNickname" example:"홍길동"`
 	CreatedAt      string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
 }
 
+// DraftInfo represents the user's unsent draft in a chat
+type DraftInfo struct {
+	Content   string `json:"content" example:"내일 회의는"`
+	UpdatedAt string `json:"updatedAt" example:"2024-03-23T12:00:00Z"`
+}
+
+// DraftData represents the result of saving a draft
+type DraftData struct {
+	ChatID    int    `json:"chatId" example:"1"`
+	Content   string `json:"content" example:"내일 회의는"`
+	UpdatedAt string `json:"updatedAt" example:"2024-03-23T12:00:00Z"`
+	Applied   bool   `json:"applied" example:"true"`
+}
+
 // Predefined errors
 var (
 	// ErrInvalidRequest 잘못된 요청
@@ -215,6 +393,24 @@ type ErrUnauthorizedMessage struct {
 	Data    string `json:"data" example:"메시지에 대한 권한이 없습니다"`
 }
 
+type ErrPollNotFound struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4003"`
+	Data    string `json:"data" example:"투표를 찾을 수 없습니다"`
+}
+
+type ErrMessageRejected struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4010"`
+	Data    string `json:"data" example:"메시지를 보낼 수 없습니다: 금지어가 포함되어 있습니다"`
+}
+
+type ErrMessageRateLimited struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4009"`
+	Data    string `json:"data" example:"슬로우 모드가 켜져 있어 10초 후에 메시지를 보낼 수 있습니다"`
+}
+
 type ErrInternalServer struct {
 	Success bool   `json:"success" example:"false"`
 	Code    int    `json:"code" example:"5000"`
@@ -246,6 +442,12 @@ type ChatResponse struct {
 	Data    ChatData `json:"data"`
 }
 
+type ChatMembersResponse struct {
+	Success bool       `json:"success" example:"true"`
+	Code    int        `json:"code" example:"2000"`
+	Data    []UserInfo `json:"data"`
+}
+
 type ChatListResponse struct {
 	Success bool           `json:"success" example:"true"`
 	Code    int            `json:"code" example:"2000"`
@@ -258,12 +460,33 @@ type MessageResponse struct {
 	Data    MessageData `json:"data"`
 }
 
+type ForwardMessagesResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Code    int           `json:"code" example:"2001"`
+	Data    []MessageData `json:"data"`
+}
+
+type PollResponse struct {
+	Success bool     `json:"success" example:"true"`
+	Code    int      `json:"code" example:"2000"`
+	Data    PollData `json:"data"`
+}
+
+type DraftResponse struct {
+	Success bool      `json:"success" example:"true"`
+	Code    int       `json:"code" example:"2000"`
+	Data    DraftData `json:"data"`
+}
+
 type MessageListData struct {
 	ChatId        int           `json:"chatId" example:"1"`
 	Messages      []MessageData `json:"messages"`
 	LastMessageId int           `json:"lastMessageId" example:"100"`
 	HasMore       bool          `json:"hasMore" example:"true"`
 	NextCursor    int           `json:"nextCursor" example:"50"`
+	HasNewer      bool          `json:"hasNewer" example:"false"`
+	PrevCursor    int           `json:"prevCursor" example:"100"`
+	Pins          []PinData     `json:"pins,omitempty"`
 }
 
 type MessageListResponse struct {
@@ -272,13 +495,499 @@ type MessageListResponse struct {
 	Data    MessageListData `json:"data"`
 }
 
+// PinData represents a pinned message
+type PinData struct {
+	ChatID           int         `json:"chatId" example:"1"`
+	PinnedBy         int         `json:"pinnedBy" example:"1"`
+	PinnedByNickname string      `json:"pinnedByNickname" example:"홍길동"`
+	PinnedAt         string      `json:"pinnedAt" example:"2024-03-23T12:00:00Z"`
+	Message          MessageData `json:"message"`
+}
+
+type PinResponse struct {
+	Success bool    `json:"success" example:"true"`
+	Code    int     `json:"code" example:"2001"`
+	Data    PinData `json:"data"`
+}
+
+type PinListResponse struct {
+	Success bool      `json:"success" example:"true"`
+	Code    int       `json:"code" example:"2000"`
+	Data    []PinData `json:"data"`
+}
+
+// MessageSearchResultData represents a message matched by a search
+type MessageSearchResultData struct {
+	MessageData
+	ChatName string `json:"chatName" example:"개발팀 채팅방"`
+	Snippet  string `json:"snippet" example:"오늘 <mark>회의록</mark> 공유드립니다"`
+}
+
+type MessageSearchData struct {
+	Results    []MessageSearchResultData `json:"results"`
+	HasMore    bool                      `json:"hasMore" example:"true"`
+	NextCursor int                       `json:"nextCursor" example:"50"`
+}
+
+type MessageSearchResponse struct {
+	Success bool              `json:"success" example:"true"`
+	Code    int               `json:"code" example:"2000"`
+	Data    MessageSearchData `json:"data"`
+}
+
+// ScheduledMessageData represents a message scheduled to be posted later
+type ScheduledMessageData struct {
+	ScheduledMessageID int    `json:"scheduledMessageId" example:"1"`
+	ChatID             int    `json:"chatId" example:"1"`
+	Content            string `json:"content" example:"회의 5분 전입니다"`
+	ScheduledAt        string `json:"scheduledAt" example:"2024-03-23T03:00:00Z"`
+	Status             string `json:"status" example:"pending"`
+	MessageID          int    `json:"messageId,omitempty" example:"10"`
+	CreatedAt          string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	UpdatedAt          string `json:"updatedAt" example:"2024-03-23T12:00:00Z"`
+}
+
+type ScheduledMessageResponse struct {
+	Success bool                 `json:"success" example:"true"`
+	Code    int                  `json:"code" example:"2000"`
+	Data    ScheduledMessageData `json:"data"`
+}
+
+type ScheduledMessageListResponse struct {
+	Success bool                   `json:"success" example:"true"`
+	Code    int                    `json:"code" example:"2000"`
+	Data    []ScheduledMessageData `json:"data"`
+}
+
+// BookmarkData represents a message saved by the user
+type BookmarkData struct {
+	BookmarkID int          `json:"bookmarkId" example:"1"`
+	ChatID     int          `json:"chatId" example:"1"`
+	ChatName   string       `json:"chatName,omitempty" example:"개발팀 채팅방"`
+	MessageID  int          `json:"messageId" example:"10"`
+	Note       string       `json:"note" example:"회의 전에 다시 읽기"`
+	RemindAt   string       `json:"remindAt,omitempty" example:"2024-03-24T00:00:00Z"`
+	RemindedAt string       `json:"remindedAt,omitempty" example:"2024-03-24T00:00:00Z"`
+	Available  bool         `json:"available" example:"true"`
+	Message    *MessageData `json:"message,omitempty"`
+	CreatedAt  string       `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	UpdatedAt  string       `json:"updatedAt" example:"2024-03-23T12:00:00Z"`
+}
+
+type BookmarkResponse struct {
+	Success bool         `json:"success" example:"true"`
+	Code    int          `json:"code" example:"2000"`
+	Data    BookmarkData `json:"data"`
+}
+
+type BookmarkListData struct {
+	Bookmarks  []BookmarkData `json:"bookmarks"`
+	HasMore    bool           `json:"hasMore" example:"true"`
+	NextCursor int            `json:"nextCursor" example:"21"`
+}
+
+type BookmarkListResponse struct {
+	Success bool             `json:"success" example:"true"`
+	Code    int              `json:"code" example:"2000"`
+	Data    BookmarkListData `json:"data"`
+}
+
+// CommandArgData represents an argument of a slash command
+type CommandArgData struct {
+	Name        string `json:"name" example:"닉네임"`
+	Description string `json:"description" example:"초대할 사용자 (@닉네임)"`
+	Required    bool   `json:"required" example:"true"`
+	Repeated    bool   `json:"repeated" example:"true"`
+}
+
+// CommandData represents a slash command
+type CommandData struct {
+	Name        string           `json:"name" example:"invite"`
+	Description string           `json:"description" example:"사용자를 채팅방에 초대합니다"`
+	Usage       string           `json:"usage" example:"/invite <@닉네임...>"`
+	Args        []CommandArgData `json:"args"`
+}
+
+type CommandListResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Code    int           `json:"code" example:"2000"`
+	Data    []CommandData `json:"data"`
+}
+
+// WebhookData represents an incoming webhook of a chat
+type WebhookData struct {
+	WebhookID  int    `json:"webhookId" example:"1"`
+	ChatID     int    `json:"chatId" example:"1"`
+	Name       string `json:"name" example:"CI"`
+	CreatedBy  int    `json:"createdBy" example:"1"`
+	CreatedAt  string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	LastUsedAt string `json:"lastUsedAt,omitempty" example:"2024-03-23T12:00:00Z"`
+	// Token and URL are only returned when the webhook is created
+	Token string `json:"token,omitempty" example:"3f1c0e6b9a7d4e2f8c5b1a0d9e8f7c6b5a4d3e2f1c0b9a8d7e6f5c4b3a2d1e0f"`
+	URL   string `json:"url,omitempty" example:"/hooks/3f1c0e6b9a7d4e2f8c5b1a0d9e8f7c6b5a4d3e2f1c0b9a8d7e6f5c4b3a2d1e0f"`
+}
+
+type WebhookResponse struct {
+	Success bool        `json:"success" example:"true"`
+	Code    int         `json:"code" example:"2000"`
+	Data    WebhookData `json:"data"`
+}
+
+type WebhookListResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Code    int           `json:"code" example:"2000"`
+	Data    []WebhookData `json:"data"`
+}
+
+// BotData represents a bot account
+type BotData struct {
+	UserID    int    `json:"userId" example:"7"`
+	Nickname  string `json:"nickname" example:"deploy_bot"`
+	OwnerID   int    `json:"ownerId" example:"1"`
+	IsBot     bool   `json:"isBot" example:"true"`
+	CreatedAt string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	// Token is only returned when it is issued
+	Token string `json:"token,omitempty" example:"bot_5e2d8c1a9f0b4e7d3c6a2f1e8b9d0c4a7e5f3b2d1c0a9e8f7d6c5b4a3e2f1d0c"`
+}
+
+type BotResponse struct {
+	Success bool    `json:"success" example:"true"`
+	Code    int     `json:"code" example:"2000"`
+	Data    BotData `json:"data"`
+}
+
+type BotListResponse struct {
+	Success bool      `json:"success" example:"true"`
+	Code    int       `json:"code" example:"2000"`
+	Data    []BotData `json:"data"`
+}
+
+// OutgoingWebhookData represents an HTTP endpoint subscribed to chat events
+type OutgoingWebhookData struct {
+	OutgoingWebhookID int `json:"outgoingWebhookId" example:"1"`
+	// Omitted for webhooks receiving the events of every chat of the owner
+	ChatID    int      `json:"chatId,omitempty" example:"1"`
+	URL       string   `json:"url" example:"https://example.com/chat-events"`
+	Events    []string `json:"events" example:"message.created,member.added"`
+	CreatedAt string   `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret,omitempty" example:"whsec_9b1f0c7e2d4a6b8c0e1f3a5b7d9c2e4f6a8b0c1d3e5f7a9b2c4d6e8f0a1b3c5d"`
+}
+
+type OutgoingWebhookResponse struct {
+	Success bool                `json:"success" example:"true"`
+	Code    int                 `json:"code" example:"2000"`
+	Data    OutgoingWebhookData `json:"data"`
+}
+
+type OutgoingWebhookListResponse struct {
+	Success bool                  `json:"success" example:"true"`
+	Code    int                   `json:"code" example:"2000"`
+	Data    []OutgoingWebhookData `json:"data"`
+}
+
+// WebhookDeliveryData represents an event queued for or sent to an outgoing webhook
+type WebhookDeliveryData struct {
+	DeliveryID        int    `json:"deliveryId" example:"1"`
+	OutgoingWebhookID int    `json:"outgoingWebhookId" example:"1"`
+	Event             string `json:"event" example:"message.created"`
+	ChatID            int    `json:"chatId" example:"1"`
+	Status            string `json:"status" example:"pending" enums:"pending,succeeded,dead"`
+	Attempts          int    `json:"attempts" example:"2"`
+	// The body posted to the endpoint
+	Payload        map[string]interface{} `json:"payload"`
+	ResponseStatus int                    `json:"responseStatus,omitempty" example:"503"`
+	LastError      string                 `json:"lastError,omitempty" example:"unexpected status code 503"`
+	NextAttemptAt  string                 `json:"nextAttemptAt,omitempty" example:"2024-03-23T12:00:20Z"`
+	LastAttemptAt  string                 `json:"lastAttemptAt,omitempty" example:"2024-03-23T12:00:00Z"`
+	DeliveredAt    string                 `json:"deliveredAt,omitempty" example:"2024-03-23T12:00:00Z"`
+	CreatedAt      string                 `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+}
+
+type WebhookDeliveryResponse struct {
+	Success bool                `json:"success" example:"true"`
+	Code    int                 `json:"code" example:"2000"`
+	Data    WebhookDeliveryData `json:"data"`
+}
+
+type WebhookDeliveryListData struct {
+	Deliveries []WebhookDeliveryData `json:"deliveries"`
+	HasMore    bool                  `json:"hasMore" example:"false"`
+	NextCursor int                   `json:"nextCursor" example:"1"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Success bool                    `json:"success" example:"true"`
+	Code    int                     `json:"code" example:"2000"`
+	Data    WebhookDeliveryListData `json:"data"`
+}
+
+// ModerationReasonData explains why a filter flagged a message
+type ModerationReasonData struct {
+	Filter string `json:"filter" example:"words" enums:"words,regex,links,classifier"`
+	Reason string `json:"reason" example:"금지어가 포함되어 있습니다"`
+}
+
+// ModerationFlagData represents a message waiting for review
+type ModerationFlagData struct {
+	FlagID         int    `json:"flagId" example:"1"`
+	MessageID      int    `json:"messageId" example:"42"`
+	ChatID         int    `json:"chatId" example:"1"`
+	SenderID       int    `json:"senderId" example:"2"`
+	SenderNickname string `json:"senderNickname" example:"johndoe"`
+	// The content as flagged, after masking
+	Content    string                 `json:"content" example:"010-＊＊＊＊-＊＊＊＊ 로 연락 주세요"`
+	Reasons    []ModerationReasonData `json:"reasons"`
+	Status     string                 `json:"status" example:"pending" enums:"pending,approved,removed"`
+	ReviewedBy int                    `json:"reviewedBy,omitempty" example:"1"`
+	ReviewedAt string                 `json:"reviewedAt,omitempty" example:"2024-03-23T12:10:00Z"`
+	CreatedAt  string                 `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+}
+
+type ModerationFlagResponse struct {
+	Success bool               `json:"success" example:"true"`
+	Code    int                `json:"code" example:"2000"`
+	Data    ModerationFlagData `json:"data"`
+}
+
+type ModerationFlagListData struct {
+	Flags      []ModerationFlagData `json:"flags"`
+	HasMore    bool                 `json:"hasMore" example:"false"`
+	NextCursor int                  `json:"nextCursor" example:"1"`
+}
+
+type ModerationFlagListResponse struct {
+	Success bool                   `json:"success" example:"true"`
+	Code    int                    `json:"code" example:"2000"`
+	Data    ModerationFlagListData `json:"data"`
+}
+
+// ChatExportData represents a chat transcript export
+type ChatExportData struct {
+	ExportID     int    `json:"exportId" example:"1"`
+	ChatID       int    `json:"chatId" example:"1"`
+	RequestedBy  int    `json:"requestedBy" example:"1"`
+	Format       string `json:"format" example:"html" enums:"json,csv,html"`
+	Status       string `json:"status" example:"completed" enums:"pending,running,completed,failed,expired"`
+	MessageCount int    `json:"messageCount" example:"1523"`
+	FileSize     int64  `json:"fileSize" example:"482113"`
+	// Set once the file is ready
+	DownloadURL string `json:"downloadUrl,omitempty" example:"/api/exports/1/download"`
+	CreatedAt   string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	StartedAt   string `json:"startedAt,omitempty" example:"2024-03-23T12:00:01Z"`
+	CompletedAt string `json:"completedAt,omitempty" example:"2024-03-23T12:00:04Z"`
+	// The file can be downloaded until this time
+	ExpiresAt string `json:"expiresAt,omitempty" example:"2024-03-30T12:00:04Z"`
+}
+
+type ChatExportResponse struct {
+	Success bool           `json:"success" example:"true"`
+	Code    int            `json:"code" example:"2000"`
+	Data    ChatExportData `json:"data"`
+}
+
+type ChatExportListResponse struct {
+	Success bool             `json:"success" example:"true"`
+	Code    int              `json:"code" example:"2000"`
+	Data    []ChatExportData `json:"data"`
+}
+
+type ErrExportNotFound struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4003"`
+	Data    string `json:"data" example:"내보내기를 찾을 수 없습니다"`
+}
+
+// ChatImportData represents a Slack archive import
+type ChatImportData struct {
+	ImportID    int    `json:"importId" example:"1"`
+	RequestedBy int    `json:"requestedBy" example:"1"`
+	Source      string `json:"source" example:"upload" enums:"upload,command"`
+	Status      string `json:"status" example:"running" enums:"pending,running,completed,failed"`
+	// Users, chats and messages created by the import so far
+	UserCount    int `json:"userCount" example:"42"`
+	ChatCount    int `json:"chatCount" example:"12"`
+	MessageCount int `json:"messageCount" example:"18230"`
+	// Set when the import failed
+	Error       string `json:"error,omitempty" example:"invalid slack export archive: users.json not found"`
+	CreatedAt   string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	StartedAt   string `json:"startedAt,omitempty" example:"2024-03-23T12:00:01Z"`
+	CompletedAt string `json:"completedAt,omitempty" example:"2024-03-23T12:03:12Z"`
+}
+
+type ChatImportResponse struct {
+	Success bool           `json:"success" example:"true"`
+	Code    int            `json:"code" example:"2000"`
+	Data    ChatImportData `json:"data"`
+}
+
+type ErrImportNotFound struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4003"`
+	Data    string `json:"data" example:"가져오기를 찾을 수 없습니다"`
+}
+
+// ChatRetentionData represents the retention policies that apply to a chat
+type ChatRetentionData struct {
+	ChatID int `json:"chatId" example:"1"`
+	// The chat's own policy
+	Chat RetentionPolicyData `json:"chat"`
+	// The server-wide policy
+	Server RetentionPolicyData `json:"server"`
+	// The policy applied, the stricter of the two
+	Effective RetentionPolicyData `json:"effective"`
+	// Whether the chat is on legal hold, only returned to server administrators
+	LegalHold bool `json:"legalHold,omitempty" example:"false"`
+}
+
+type ChatRetentionResponse struct {
+	Success bool              `json:"success" example:"true"`
+	Code    int               `json:"code" example:"2000"`
+	Data    ChatRetentionData `json:"data"`
+}
+
+// LegalHoldData represents a legal hold keeping a chat's messages from deletion
+type LegalHoldData struct {
+	ChatID    int    `json:"chatId" example:"1"`
+	Reason    string `json:"reason" example:"2024-민-1234 소송 관련 보존"`
+	PlacedBy  int    `json:"placedBy" example:"1"`
+	CreatedAt string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+}
+
+type LegalHoldResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Code    int           `json:"code" example:"2000"`
+	Data    LegalHoldData `json:"data"`
+}
+
+type LegalHoldListResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Code    int             `json:"code" example:"2000"`
+	Data    []LegalHoldData `json:"data"`
+}
+
+type ErrLegalHoldNotFound struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4003"`
+	Data    string `json:"data" example:"법적 보존 조치를 찾을 수 없습니다"`
+}
+
+// RetentionRunChatData represents the messages a retention run deleted from a chat
+type RetentionRunChatData struct {
+	ChatID int `json:"chatId" example:"1"`
+	// The policy applied to the chat
+	Days         int `json:"days" example:"365"`
+	MaxMessages  int `json:"maxMessages" example:"0"`
+	DeletedCount int `json:"deletedCount" example:"1200"`
+	// The deleted messages span these IDs and creation times
+	FirstMessageID  int    `json:"firstMessageId" example:"1"`
+	LastMessageID   int    `json:"lastMessageId" example:"1534"`
+	OldestCreatedAt string `json:"oldestCreatedAt" example:"2022-01-04T09:12:00Z"`
+	NewestCreatedAt string `json:"newestCreatedAt" example:"2023-03-22T23:59:10Z"`
+}
+
+// RetentionRunData represents a run of the retention purge
+type RetentionRunData struct {
+	RunID  int    `json:"runId" example:"1"`
+	Status string `json:"status" example:"completed" enums:"running,completed,failed"`
+	// Chats messages were deleted from
+	ChatCount int `json:"chatCount" example:"3"`
+	// Chats skipped for a legal hold
+	HeldChatCount int    `json:"heldChatCount" example:"1"`
+	DeletedCount  int    `json:"deletedCount" example:"4210"`
+	Error         string `json:"error,omitempty" example:"interrupted by a restart"`
+	StartedAt     string `json:"startedAt" example:"2024-03-23T12:00:00Z"`
+	CompletedAt   string `json:"completedAt,omitempty" example:"2024-03-23T12:00:08Z"`
+	// Only returned for a single run
+	Chats []RetentionRunChatData `json:"chats,omitempty"`
+}
+
+type RetentionRunResponse struct {
+	Success bool             `json:"success" example:"true"`
+	Code    int              `json:"code" example:"2000"`
+	Data    RetentionRunData `json:"data"`
+}
+
+type RetentionRunListResponse struct {
+	Success bool               `json:"success" example:"true"`
+	Code    int                `json:"code" example:"2000"`
+	Data    []RetentionRunData `json:"data"`
+}
+
+type ErrRetentionRunNotFound struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4003"`
+	Data    string `json:"data" example:"정리 기록을 찾을 수 없습니다"`
+}
+
+// DeviceData represents a device registered for end-to-end encryption
+type DeviceData struct {
+	DeviceID    int    `json:"deviceId" example:"1"`
+	Name        string `json:"name" example:"내 노트북"`
+	IdentityKey string `json:"identityKey" example:"mQ0RZ9l1g3cYz6Jt1V1xw2o0pQmGk3J0T8Vh1o9aN2U="`
+	// One-time prekeys left; upload more before they run out
+	PrekeyCount int    `json:"prekeyCount" example:"42"`
+	CreatedAt   string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+}
+
+type DeviceResponse struct {
+	Success bool       `json:"success" example:"true"`
+	Code    int        `json:"code" example:"2000"`
+	Data    DeviceData `json:"data"`
+}
+
+type DeviceListResponse struct {
+	Success bool         `json:"success" example:"true"`
+	Code    int          `json:"code" example:"2000"`
+	Data    []DeviceData `json:"data"`
+}
+
+// PrekeyData represents a one-time prekey of a device
+type PrekeyData struct {
+	KeyID     int    `json:"keyId" example:"17"`
+	PublicKey string `json:"publicKey" example:"b3V0Ym91bmQtcHJla2V5LWV4YW1wbGUtMzItYnl0ZXM="`
+}
+
+// DeviceKeyBundleData represents the keys needed to encrypt for a device
+type DeviceKeyBundleData struct {
+	DeviceID    int    `json:"deviceId" example:"1"`
+	IdentityKey string `json:"identityKey" example:"mQ0RZ9l1g3cYz6Jt1V1xw2o0pQmGk3J0T8Vh1o9aN2U="`
+	// Omitted when the device has run out of one-time prekeys
+	Prekey *PrekeyData `json:"prekey,omitempty"`
+}
+
+// UserKeysData represents the key bundles of a user's devices
+type UserKeysData struct {
+	UserID  int                   `json:"userId" example:"2"`
+	Devices []DeviceKeyBundleData `json:"devices"`
+}
+
+type UserKeysResponse struct {
+	Success bool         `json:"success" example:"true"`
+	Code    int          `json:"code" example:"2000"`
+	Data    UserKeysData `json:"data"`
+}
+
+type ErrDeviceNotFound struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4003"`
+	Data    string `json:"data" example:"기기를 찾을 수 없습니다"`
+}
+
+type ErrUserNotFound struct {
+	Success bool   `json:"success" example:"false"`
+	Code    int    `json:"code" example:"4003"`
+	Data    string `json:"data" example:"사용자를 찾을 수 없습니다"`
+}
+
 type CreateChatRequest struct {
 	Name    string `json:"name" example:"Team Chat" validate:"required"`
 	UserIDs []int  `json:"user_ids" example:"[1,2,3]" validate:"required"`
 }
 
 type CreatePrivateChatRequest struct {
-	TargetId int `json:"targetId" example:"1"`
+	TargetId  int  `json:"targetId" example:"1"`
+	Encrypted bool `json:"encrypted,omitempty" example:"false"`
 }
 
 type UserListData struct {
@@ -286,6 +995,7 @@ type UserListData struct {
 	Email     string `json:"email" example:"user@example.com"`
 	Nickname  string `json:"nickname" example:"홍길동"`
 	CreatedAt string `json:"createdAt" example:"2024-03-23T12:00:00Z"`
+	IsBot     bool   `json:"isBot" example:"false"`
 }
 
 // UserListResponse represents the response for user list endpoints
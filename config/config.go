@@ -4,6 +4,8 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type DatabaseConfig struct {
@@ -15,6 +17,27 @@ type Config struct {
 	ServerPort string
 	Database   DatabaseConfig
 	JWTSecret  string
+	// WebhookAllowPrivateNetworks lets outgoing webhooks reach private addresses
+	WebhookAllowPrivateNetworks bool
+	// ModerationConfigPath is the JSON file configuring the moderation filters
+	ModerationConfigPath string
+	// ExportDir is where chat export files are kept until they expire
+	ExportDir string
+	// ImportDir is where uploaded Slack archives are kept until imported
+	ImportDir string
+	// AdminEmails lists the server administrators, who may import Slack
+	// archives, place legal holds and read retention audits
+	AdminEmails []string
+	// RetentionDays and RetentionMaxMessages are the server-wide retention
+	// policy; zero keeps messages forever
+	RetentionDays        int
+	RetentionMaxMessages int
+	// EncryptionKey or the file at EncryptionKeyFile holds the base64 master
+	// key encrypting message content at rest. Without one, messages are stored
+	// in plaintext; messages stored before a key was set stay in plaintext
+	// until the encrypt-messages command is run.
+	EncryptionKey     string
+	EncryptionKeyFile string
 }
 
 func LoadConfig() (*Config, error) {
@@ -27,7 +50,16 @@ func LoadConfig() (*Config, error) {
 		Database: DatabaseConfig{
 			DSN: getEnv("DATABASE_DSN", "sqlite.db"),
 		},
-		JWTSecret: getEnv("JWT_SECRET", "test"),
+		JWTSecret:                   getEnv("JWT_SECRET", "test"),
+		WebhookAllowPrivateNetworks: getEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false") == "true",
+		ModerationConfigPath:        getEnv("MODERATION_CONFIG", ""),
+		ExportDir:                   getEnv("EXPORT_DIR", "exports"),
+		ImportDir:                   getEnv("IMPORT_DIR", "imports"),
+		AdminEmails:                 getEnvList("ADMIN_EMAILS"),
+		RetentionDays:               getEnvInt("MESSAGE_RETENTION_DAYS", 0),
+		RetentionMaxMessages:        getEnvInt("MESSAGE_RETENTION_MAX_MESSAGES", 0),
+		EncryptionKey:               getEnv("ENCRYPTION_KEY", ""),
+		EncryptionKeyFile:           getEnv("ENCRYPTION_KEY_FILE", ""),
 	}, nil
 }
 
@@ -37,3 +69,23 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt parses an integer variable, using the default when it is not a number
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList splits a comma-separated variable, dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// BookmarkResponse is a DTO for a saved message. Unavailable bookmarks, whose
+// message was deleted or whose chat the user left, carry no message.
+type BookmarkResponse struct {
+	BookmarkID int              `json:"bookmarkId"`
+	ChatID     int              `json:"chatId"`
+	ChatName   string           `json:"chatName,omitempty"`
+	MessageID  int              `json:"messageId"`
+	Note       string           `json:"note"`
+	RemindAt   *time.Time       `json:"remindAt,omitempty"`
+	RemindedAt *time.Time       `json:"remindedAt,omitempty"`
+	Available  bool             `json:"available"`
+	Message    *MessageResponse `json:"message,omitempty"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	UpdatedAt  time.Time        `json:"updatedAt"`
+}
+
+// BookmarkListResponse represents a page of bookmarks
+type BookmarkListResponse struct {
+	Bookmarks  []BookmarkResponse `json:"bookmarks"`
+	HasMore    bool               `json:"hasMore"`
+	NextCursor int                `json:"nextCursor"`
+}
+
+// NewBookmarkResponse creates a BookmarkResponse from a Bookmark model
+func NewBookmarkResponse(bookmark *models.Bookmark) *BookmarkResponse {
+	response := &BookmarkResponse{
+		BookmarkID: bookmark.ID,
+		ChatID:     bookmark.ChatId,
+		MessageID:  bookmark.MessageId,
+		Note:       bookmark.Note,
+		RemindAt:   bookmark.RemindAt,
+		RemindedAt: bookmark.RemindedAt,
+		Available:  bookmark.Available && bookmark.Message != nil,
+		CreatedAt:  bookmark.CreatedAt,
+		UpdatedAt:  bookmark.UpdatedAt,
+	}
+
+	if response.Available {
+		response.ChatName = bookmark.ChatName
+		response.Message = NewMessageResponse(bookmark.Message)
+	}
+
+	return response
+}
+
+// NewBookmarkResponseList creates a list of BookmarkResponse from Bookmark models
+func NewBookmarkResponseList(bookmarks []models.Bookmark) []BookmarkResponse {
+	responses := make([]BookmarkResponse, len(bookmarks))
+	for i := range bookmarks {
+		responses[i] = *NewBookmarkResponse(&bookmarks[i])
+	}
+	return responses
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// BotResponse is a DTO for a bot account. The API token is only known when
+// it is issued, so it is only set in that response.
+type BotResponse struct {
+	UserID    int       `json:"userId"`
+	Nickname  string    `json:"nickname"`
+	OwnerID   int       `json:"ownerId"`
+	IsBot     bool      `json:"isBot"`
+	CreatedAt time.Time `json:"createdAt"`
+	Token     string    `json:"token,omitempty"`
+}
+
+// NewBotResponse creates a BotResponse from a bot User model
+func NewBotResponse(bot *models.User) *BotResponse {
+	response := &BotResponse{
+		UserID:    bot.ID,
+		Nickname:  bot.Nickname,
+		IsBot:     bot.IsBot,
+		CreatedAt: bot.CreatedAt,
+	}
+	if bot.OwnerId != nil {
+		response.OwnerID = *bot.OwnerId
+	}
+	return response
+}
+
+// NewBotResponseList creates a list of BotResponse from bot User models
+func NewBotResponseList(bots []models.User) []BotResponse {
+	responses := make([]BotResponse, len(bots))
+	for i := range bots {
+		responses[i] = *NewBotResponse(&bots[i])
+	}
+	return responses
+}
@@ -8,25 +8,50 @@ import (
 type UserInfo struct {
 	UserID   int    `json:"userId"` // Changed from id to userId for consistency
 	Nickname string `json:"nickname"`
+	// IsBot marks bot accounts, which clients badge next to the nickname
+	IsBot bool `json:"isBot"`
+}
+
+// NewUserInfo creates a UserInfo from a User model
+func NewUserInfo(user *models.User) UserInfo {
+	return UserInfo{
+		UserID:   user.ID,
+		Nickname: user.Nickname,
+		IsBot:    user.IsBot,
+	}
 }
 
 type ChatResponse struct {
-	ChatID    int       `json:"chatId"` // Changed from id to chatId
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
+	ChatID            int    `json:"chatId"` // Changed from id to chatId
+	Name              string `json:"name"`
+	Topic             string `json:"topic"`
+	MessageTTLSeconds int    `json:"messageTtlSeconds"`
+	SlowModeSeconds   int    `json:"slowModeSeconds"`
+	// Retention is the chat's own policy; see the retention API for the one applied
+	Retention models.RetentionPolicy `json:"retention"`
+	Encrypted bool                   `json:"encrypted"`
+	CreatedAt time.Time              `json:"createdAt"`
 }
 
 type ChatListResponse struct {
-	ChatID      int              `json:"chatId"` // Changed from id to chatId
-	Name        string           `json:"name"`
-	CreatedAt   time.Time        `json:"createdAt"`
-	LastMessage *LastMessageInfo `json:"lastMessage,omitempty"`
-	Users       []UserInfo       `json:"users"`
+	ChatID            int                    `json:"chatId"` // Changed from id to chatId
+	Name              string                 `json:"name"`
+	Topic             string                 `json:"topic"`
+	MessageTTLSeconds int                    `json:"messageTtlSeconds"`
+	SlowModeSeconds   int                    `json:"slowModeSeconds"`
+	Retention         models.RetentionPolicy `json:"retention"`
+	Encrypted         bool                   `json:"encrypted"`
+	CreatedAt         time.Time              `json:"createdAt"`
+	LastMessage       *LastMessageInfo       `json:"lastMessage,omitempty"`
+	Users             []UserInfo             `json:"users"`
+	Draft             *DraftInfo             `json:"draft,omitempty"`
 }
 
 type LastMessageInfo struct {
 	MessageID      int       `json:"messageId"`
+	MessageType    string    `json:"messageType"`
 	Content        string    `json:"content"`
+	PlainText      string    `json:"plainText"`
 	SenderID       int       `json:"senderId"`
 	SenderNickname string    `json:"senderNickname"`
 	CreatedAt      time.Time `json:"createdAt"`
@@ -34,30 +59,37 @@ type LastMessageInfo struct {
 
 func NewChatResponse(chat *models.Chat) *ChatResponse {
 	return &ChatResponse{
-		ChatID:    chat.ID,
-		Name:      chat.Name,
-		CreatedAt: chat.CreatedAt,
+		ChatID:            chat.ID,
+		Name:              chat.Name,
+		Topic:             chat.Topic,
+		MessageTTLSeconds: chat.MessageTTLSeconds,
+		SlowModeSeconds:   chat.SlowModeSeconds,
+		Retention:         chat.RetentionPolicy(),
+		Encrypted:         chat.Encrypted,
+		CreatedAt:         chat.CreatedAt,
 	}
 }
 
-func NewChatListResponse(chats []models.Chat, lastMessages map[int]*models.Message, usersMap map[int][]models.User) []ChatListResponse {
+func NewChatListResponse(chats []models.Chat, lastMessages map[int]*models.Message, usersMap map[int][]models.User, drafts map[int]*models.Draft) []ChatListResponse {
 	responses := make([]ChatListResponse, len(chats))
 	for i, chat := range chats {
 		response := ChatListResponse{
-			ChatID:    chat.ID,
-			Name:      chat.Name,
-			CreatedAt: chat.CreatedAt,
-			Users:     make([]UserInfo, 0),
+			ChatID:            chat.ID,
+			Name:              chat.Name,
+			Topic:             chat.Topic,
+			MessageTTLSeconds: chat.MessageTTLSeconds,
+			SlowModeSeconds:   chat.SlowModeSeconds,
+			Retention:         chat.RetentionPolicy(),
+			Encrypted:         chat.Encrypted,
+			CreatedAt:         chat.CreatedAt,
+			Users:             make([]UserInfo, 0),
 		}
 
 		// Add users if available
 		if users, ok := usersMap[chat.ID]; ok {
 			response.Users = make([]UserInfo, len(users))
-			for j, user := range users {
-				response.Users[j] = UserInfo{
-					UserID:   user.ID,
-					Nickname: user.Nickname,
-				}
+			for j := range users {
+				response.Users[j] = NewUserInfo(&users[j])
 			}
 		}
 
@@ -65,13 +97,23 @@ func NewChatListResponse(chats []models.Chat, lastMessages map[int]*models.Messa
 		if lastMessage, ok := lastMessages[chat.ID]; ok {
 			response.LastMessage = &LastMessageInfo{
 				MessageID:      lastMessage.ID,
+				MessageType:    messageType(lastMessage),
 				Content:        lastMessage.Content,
+				PlainText:      lastMessage.PlainText,
 				SenderID:       lastMessage.SenderId,
 				SenderNickname: lastMessage.SenderNickname,
 				CreatedAt:      lastMessage.CreatedAt,
 			}
 		}
 
+		// Add the user's unsent draft if available
+		if draft, ok := drafts[chat.ID]; ok {
+			response.Draft = &DraftInfo{
+				Content:   draft.Content,
+				UpdatedAt: draft.UpdatedAt,
+			}
+		}
+
 		responses[i] = response
 	}
 	return responses
@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/f1rstid/realtime-chat/domain/models"
+
+// CommandResponse is a DTO for a slash command listed to clients
+type CommandResponse struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Usage       string              `json:"usage"`
+	Args        []models.CommandArg `json:"args"`
+}
+
+// NewCommandResponseList creates a list of CommandResponse from Command models
+func NewCommandResponseList(commands []models.Command) []CommandResponse {
+	responses := make([]CommandResponse, len(commands))
+	for i := range commands {
+		args := commands[i].Args
+		if args == nil {
+			args = []models.CommandArg{}
+		}
+		responses[i] = CommandResponse{
+			Name:        commands[i].Name,
+			Description: commands[i].Description,
+			Usage:       commands[i].Usage(),
+			Args:        args,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// DeviceResponse is a DTO for a device registered for end-to-end encryption
+type DeviceResponse struct {
+	DeviceID    int    `json:"deviceId"`
+	Name        string `json:"name"`
+	IdentityKey string `json:"identityKey"`
+	// PrekeyCount tells the device when to upload more one-time prekeys
+	PrekeyCount int       `json:"prekeyCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// NewDeviceResponse creates a DeviceResponse from a Device model
+func NewDeviceResponse(device *models.Device) *DeviceResponse {
+	return &DeviceResponse{
+		DeviceID:    device.ID,
+		Name:        device.Name,
+		IdentityKey: device.IdentityKey,
+		PrekeyCount: device.PrekeyCount,
+		CreatedAt:   device.CreatedAt,
+	}
+}
+
+// NewDeviceResponseList creates a list of DeviceResponse from Device models
+func NewDeviceResponseList(devices []models.Device) []DeviceResponse {
+	responses := make([]DeviceResponse, len(devices))
+	for i := range devices {
+		responses[i] = *NewDeviceResponse(&devices[i])
+	}
+	return responses
+}
+
+// UserKeysResponse is a DTO for the key bundles of a user's devices
+type UserKeysResponse struct {
+	UserID  int                      `json:"userId"`
+	Devices []models.DeviceKeyBundle `json:"devices"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// DraftResponse is a DTO for the result of saving a draft
+type DraftResponse struct {
+	ChatID    int       `json:"chatId"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Applied is false when a newer draft from another device was kept instead
+	Applied bool `json:"applied"`
+}
+
+// DraftInfo is a DTO for the draft shown in the chat list
+type DraftInfo struct {
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NewDraftResponse creates a DraftResponse from a Draft model
+func NewDraftResponse(draft *models.Draft, applied bool) *DraftResponse {
+	return &DraftResponse{
+		ChatID:    draft.ChatId,
+		Content:   draft.Content,
+		UpdatedAt: draft.UpdatedAt,
+		Applied:   applied,
+	}
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// ChatExportResponse is a DTO for a chat export. DownloadURL is set once the
+// file is ready.
+type ChatExportResponse struct {
+	ExportID     int        `json:"exportId"`
+	ChatID       int        `json:"chatId"`
+	RequestedBy  int        `json:"requestedBy"`
+	Format       string     `json:"format"`
+	Status       string     `json:"status"`
+	MessageCount int        `json:"messageCount"`
+	FileSize     int64      `json:"fileSize"`
+	DownloadURL  string     `json:"downloadUrl,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	StartedAt    *time.Time `json:"startedAt,omitempty"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+// NewChatExportResponse creates a ChatExportResponse from a ChatExport model
+func NewChatExportResponse(chatExport *models.ChatExport) *ChatExportResponse {
+	response := &ChatExportResponse{
+		ExportID:     chatExport.ID,
+		ChatID:       chatExport.ChatId,
+		RequestedBy:  chatExport.RequestedBy,
+		Format:       chatExport.Format,
+		Status:       chatExport.Status,
+		MessageCount: chatExport.MessageCount,
+		FileSize:     chatExport.FileSize,
+		CreatedAt:    chatExport.CreatedAt,
+		StartedAt:    chatExport.StartedAt,
+		CompletedAt:  chatExport.CompletedAt,
+		ExpiresAt:    chatExport.ExpiresAt,
+	}
+	if chatExport.Status == models.ExportStatusCompleted {
+		response.DownloadURL = fmt.Sprintf("/api/exports/%d/download", chatExport.ID)
+	}
+	return response
+}
+
+// NewChatExportResponseList creates a list of ChatExportResponse from ChatExport models
+func NewChatExportResponseList(exports []models.ChatExport) []ChatExportResponse {
+	responses := make([]ChatExportResponse, len(exports))
+	for i := range exports {
+		responses[i] = *NewChatExportResponse(&exports[i])
+	}
+	return responses
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// ChatImportResponse is a DTO for a Slack import. The counts grow while the
+// import runs and only include objects created by it.
+type ChatImportResponse struct {
+	ImportID     int        `json:"importId"`
+	RequestedBy  int        `json:"requestedBy"`
+	Source       string     `json:"source"`
+	Status       string     `json:"status"`
+	UserCount    int        `json:"userCount"`
+	ChatCount    int        `json:"chatCount"`
+	MessageCount int        `json:"messageCount"`
+	Error        *string    `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	StartedAt    *time.Time `json:"startedAt,omitempty"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+}
+
+// NewChatImportResponse creates a ChatImportResponse from a ChatImport model
+func NewChatImportResponse(chatImport *models.ChatImport) *ChatImportResponse {
+	return &ChatImportResponse{
+		ImportID:     chatImport.ID,
+		RequestedBy:  chatImport.RequestedBy,
+		Source:       chatImport.Source,
+		Status:       chatImport.Status,
+		UserCount:    chatImport.UserCount,
+		ChatCount:    chatImport.ChatCount,
+		MessageCount: chatImport.MessageCount,
+		Error:        chatImport.Error,
+		CreatedAt:    chatImport.CreatedAt,
+		StartedAt:    chatImport.StartedAt,
+		CompletedAt:  chatImport.CompletedAt,
+	}
+}
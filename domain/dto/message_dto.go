@@ -8,13 +8,64 @@ import (
 
 // MessageResponse is a DTO for message responses
 type MessageResponse struct {
-	MessageID      int       `json:"messageId"` // Changed from "id" to "messageId"
-	ChatID         int       `json:"chatId"`
-	SenderID       int       `json:"senderId"`
-	SenderNickname string    `json:"senderNickname"`
-	Content        string    `json:"content"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	MessageID      int        `json:"messageId"` // Changed from "id" to "messageId"
+	ChatID         int        `json:"chatId"`
+	SenderID       int        `json:"senderId"`
+	SenderNickname string     `json:"senderNickname"`
+	Content        string     `json:"content"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+
+	// MessageType is user or system; system messages describe chat activity in SystemEvent
+	MessageType string              `json:"messageType"`
+	SystemEvent *models.SystemEvent `json:"systemEvent,omitempty"`
+
+	// Formatted is the sanitized structure clients render instead of Content
+	Formatted models.RichText `json:"formatted"`
+	// ClientMessageID echoes the sender's idempotency key
+	ClientMessageID *string `json:"clientMessageId,omitempty"`
+	// WebhookID is set on messages posted through an incoming webhook
+	WebhookID *int `json:"webhookId,omitempty"`
+	// Attachments are cards sent by bots
+	Attachments models.MessageAttachments `json:"attachments,omitempty"`
+	// ThreadRootID is set on replies in a thread
+	ThreadRootID *int `json:"threadRootId,omitempty"`
+	// Encrypted messages carry an encrypted envelope as their content
+	Encrypted bool `json:"encrypted,omitempty"`
+
+	ForwardedFrom *ForwardedFromResponse `json:"forwardedFrom,omitempty"`
+	LinkPreviews  []LinkPreviewResponse  `json:"linkPreviews,omitempty"`
+	Poll          *PollResponse          `json:"poll,omitempty"`
+	Reactions     []models.Reaction      `json:"reactions,omitempty"`
+	// Delivery is only set on the sender's own messages
+	Delivery *DeliveryResponse `json:"delivery,omitempty"`
+}
+
+// DeliveryResponse is a DTO for the aggregated delivery status of a message
+type DeliveryResponse struct {
+	Status         string `json:"status"`
+	RecipientCount int    `json:"recipientCount"`
+	DeliveredCount int    `json:"deliveredCount"`
+	ReadCount      int    `json:"readCount"`
+}
+
+// ForwardedFromResponse is a DTO for the original message of a forwarded message.
+// ChatID is omitted when the original chat is hidden from the forwarding user.
+type ForwardedFromResponse struct {
+	MessageID      int    `json:"messageId"`
+	ChatID         int    `json:"chatId,omitempty"`
+	SenderID       int    `json:"senderId"`
+	SenderNickname string `json:"senderNickname"`
+}
+
+// LinkPreviewResponse is a DTO for the preview of a URL in a message
+type LinkPreviewResponse struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
 }
 
 // ChatMessagesResponse represents the response for chat messages with pagination
@@ -22,23 +73,93 @@ type ChatMessagesResponse struct {
 	ChatId        int               `json:"chatId"`
 	Messages      []MessageResponse `json:"messages"`
 	LastMessageId int               `json:"lastMessageId"`
-	HasMore       bool              `json:"hasMore"`
-	NextCursor    int               `json:"nextCursor"`
+	// HasMore and NextCursor page back to older messages
+	HasMore    bool `json:"hasMore"`
+	NextCursor int  `json:"nextCursor"`
+	// HasNewer and PrevCursor page forward to newer messages
+	HasNewer   bool `json:"hasNewer"`
+	PrevCursor int  `json:"prevCursor"`
+	// Pins is only populated on the first page, when the chat is opened
+	Pins []PinnedMessageResponse `json:"pins,omitempty"`
 }
 
 // NewMessageResponse creates a new MessageResponse from a Message model
 func NewMessageResponse(message *models.Message) *MessageResponse {
 	return &MessageResponse{
-		MessageID:      message.ID, // Changed from ID to MessageID
-		ChatID:         message.ChatId,
-		SenderID:       message.SenderId,
-		SenderNickname: message.SenderNickname,
-		Content:        message.Content,
-		CreatedAt:      message.CreatedAt,
-		UpdatedAt:      message.UpdatedAt,
+		MessageID:       message.ID, // Changed from ID to MessageID
+		ChatID:          message.ChatId,
+		SenderID:        message.SenderId,
+		SenderNickname:  message.SenderNickname,
+		Content:         message.Content,
+		MessageType:     messageType(message),
+		SystemEvent:     message.SystemEvent,
+		Formatted:       message.Formatted,
+		CreatedAt:       message.CreatedAt,
+		UpdatedAt:       message.UpdatedAt,
+		ExpiresAt:       message.ExpiresAt,
+		ClientMessageID: message.ClientMessageId,
+		WebhookID:       message.WebhookId,
+		ForwardedFrom:   newForwardedFromResponse(message.Origin()),
+		LinkPreviews:    newLinkPreviewResponseList(message.LinkPreviews),
+		Poll:            NewPollResponse(message.Poll),
+		Attachments:     message.Attachments,
+		ThreadRootID:    message.ThreadRootId,
+		Encrypted:       message.Encrypted,
+		Reactions:       message.Reactions,
+		Delivery:        NewDeliveryResponse(message.Delivery),
+	}
+}
+
+// NewDeliveryResponse creates a DeliveryResponse from a DeliverySummary, or nil
+func NewDeliveryResponse(summary *models.DeliverySummary) *DeliveryResponse {
+	if summary == nil {
+		return nil
+	}
+	return &DeliveryResponse{
+		Status:         summary.Status(),
+		RecipientCount: summary.Recipients,
+		DeliveredCount: summary.Delivered,
+		ReadCount:      summary.Read,
 	}
 }
 
+// messageType defaults messages built before being stored to user messages
+func messageType(message *models.Message) string {
+	if message.Type == "" {
+		return models.MessageTypeUser
+	}
+	return message.Type
+}
+
+func newForwardedFromResponse(origin *models.MessageOrigin) *ForwardedFromResponse {
+	if origin == nil {
+		return nil
+	}
+	return &ForwardedFromResponse{
+		MessageID:      origin.MessageID,
+		ChatID:         origin.ChatID,
+		SenderID:       origin.SenderID,
+		SenderNickname: origin.SenderNickname,
+	}
+}
+
+func newLinkPreviewResponseList(previews []models.LinkPreview) []LinkPreviewResponse {
+	if len(previews) == 0 {
+		return nil
+	}
+	responses := make([]LinkPreviewResponse, len(previews))
+	for i, preview := range previews {
+		responses[i] = LinkPreviewResponse{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+			SiteName:    preview.SiteName,
+		}
+	}
+	return responses
+}
+
 // NewMessageResponseList creates a list of MessageResponse from Message models
 func NewMessageResponseList(messages []models.Message) []MessageResponse {
 	responses := make([]MessageResponse, len(messages))
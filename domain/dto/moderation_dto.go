@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// ModerationFlagResponse is a DTO for a message flagged for review
+type ModerationFlagResponse struct {
+	FlagID         int                       `json:"flagId"`
+	MessageID      int                       `json:"messageId"`
+	ChatID         int                       `json:"chatId"`
+	SenderID       int                       `json:"senderId"`
+	SenderNickname string                    `json:"senderNickname"`
+	Content        string                    `json:"content"`
+	Reasons        []models.ModerationReason `json:"reasons"`
+	Status         string                    `json:"status"`
+	ReviewedBy     *int                      `json:"reviewedBy,omitempty"`
+	ReviewedAt     *time.Time                `json:"reviewedAt,omitempty"`
+	CreatedAt      time.Time                 `json:"createdAt"`
+}
+
+// ModerationFlagListResponse represents a page of the review queue
+type ModerationFlagListResponse struct {
+	Flags      []ModerationFlagResponse `json:"flags"`
+	HasMore    bool                     `json:"hasMore"`
+	NextCursor int                      `json:"nextCursor"`
+}
+
+// NewModerationFlagResponse creates a ModerationFlagResponse from a ModerationFlag model
+func NewModerationFlagResponse(flag *models.ModerationFlag) *ModerationFlagResponse {
+	return &ModerationFlagResponse{
+		FlagID:         flag.ID,
+		MessageID:      flag.MessageId,
+		ChatID:         flag.ChatId,
+		SenderID:       flag.SenderId,
+		SenderNickname: flag.SenderNickname,
+		Content:        flag.Content,
+		Reasons:        flag.Reasons,
+		Status:         flag.Status,
+		ReviewedBy:     flag.ReviewedBy,
+		ReviewedAt:     flag.ReviewedAt,
+		CreatedAt:      flag.CreatedAt,
+	}
+}
+
+// NewModerationFlagResponseList creates a list of ModerationFlagResponse from ModerationFlag models
+func NewModerationFlagResponseList(flags []models.ModerationFlag) []ModerationFlagResponse {
+	responses := make([]ModerationFlagResponse, len(flags))
+	for i := range flags {
+		responses[i] = *NewModerationFlagResponse(&flags[i])
+	}
+	return responses
+}
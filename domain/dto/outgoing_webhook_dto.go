@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// OutgoingWebhookResponse is a DTO for an outgoing webhook. The signing
+// secret is only known when the webhook is created, so it is only set in that response.
+type OutgoingWebhookResponse struct {
+	OutgoingWebhookID int       `json:"outgoingWebhookId"`
+	ChatID            *int      `json:"chatId,omitempty"`
+	URL               string    `json:"url"`
+	Events            []string  `json:"events"`
+	CreatedAt         time.Time `json:"createdAt"`
+	Secret            string    `json:"secret,omitempty"`
+}
+
+// WebhookDeliveryResponse is a DTO for a queued or sent webhook delivery
+type WebhookDeliveryResponse struct {
+	DeliveryID        int             `json:"deliveryId"`
+	OutgoingWebhookID int             `json:"outgoingWebhookId"`
+	Event             string          `json:"event"`
+	ChatID            int             `json:"chatId"`
+	Status            string          `json:"status"`
+	Attempts          int             `json:"attempts"`
+	Payload           json.RawMessage `json:"payload"`
+	ResponseStatus    *int            `json:"responseStatus,omitempty"`
+	LastError         *string         `json:"lastError,omitempty"`
+	NextAttemptAt     *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt     *time.Time      `json:"lastAttemptAt,omitempty"`
+	DeliveredAt       *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt         time.Time       `json:"createdAt"`
+}
+
+// WebhookDeliveryListResponse represents a page of webhook deliveries
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	HasMore    bool                      `json:"hasMore"`
+	NextCursor int                       `json:"nextCursor"`
+}
+
+// NewOutgoingWebhookResponse creates an OutgoingWebhookResponse from an OutgoingWebhook model
+func NewOutgoingWebhookResponse(webhook *models.OutgoingWebhook) *OutgoingWebhookResponse {
+	return &OutgoingWebhookResponse{
+		OutgoingWebhookID: webhook.ID,
+		ChatID:            webhook.ChatId,
+		URL:               webhook.URL,
+		Events:            webhook.EventTypes(),
+		CreatedAt:         webhook.CreatedAt,
+	}
+}
+
+// NewOutgoingWebhookResponseList creates a list of OutgoingWebhookResponse from OutgoingWebhook models
+func NewOutgoingWebhookResponseList(webhooks []models.OutgoingWebhook) []OutgoingWebhookResponse {
+	responses := make([]OutgoingWebhookResponse, len(webhooks))
+	for i := range webhooks {
+		responses[i] = *NewOutgoingWebhookResponse(&webhooks[i])
+	}
+	return responses
+}
+
+// NewWebhookDeliveryResponse creates a WebhookDeliveryResponse from a WebhookDelivery model
+func NewWebhookDeliveryResponse(delivery *models.WebhookDelivery) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		DeliveryID:        delivery.ID,
+		OutgoingWebhookID: delivery.OutgoingWebhookId,
+		Event:             delivery.EventType,
+		ChatID:            delivery.ChatId,
+		Status:            delivery.Status,
+		Attempts:          delivery.Attempts,
+		Payload:           json.RawMessage(delivery.Payload),
+		ResponseStatus:    delivery.ResponseStatus,
+		LastError:         delivery.LastError,
+		NextAttemptAt:     delivery.NextAttemptAt,
+		LastAttemptAt:     delivery.LastAttemptAt,
+		DeliveredAt:       delivery.DeliveredAt,
+		CreatedAt:         delivery.CreatedAt,
+	}
+}
+
+// NewWebhookDeliveryResponseList creates a list of WebhookDeliveryResponse from WebhookDelivery models
+func NewWebhookDeliveryResponseList(deliveries []models.WebhookDelivery) []WebhookDeliveryResponse {
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = *NewWebhookDeliveryResponse(&deliveries[i])
+	}
+	return responses
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// PinnedMessageResponse is a DTO for pinned message responses
+type PinnedMessageResponse struct {
+	ChatID           int             `json:"chatId"`
+	PinnedBy         int             `json:"pinnedBy"`
+	PinnedByNickname string          `json:"pinnedByNickname"`
+	PinnedAt         time.Time       `json:"pinnedAt"`
+	Message          MessageResponse `json:"message"`
+}
+
+// NewPinnedMessageResponse creates a new PinnedMessageResponse from a PinnedMessage model
+func NewPinnedMessageResponse(pin *models.PinnedMessage) *PinnedMessageResponse {
+	return &PinnedMessageResponse{
+		ChatID:           pin.ChatId,
+		PinnedBy:         pin.PinnedBy,
+		PinnedByNickname: pin.PinnedByNickname,
+		PinnedAt:         pin.PinnedAt,
+		Message: MessageResponse{
+			MessageID:      pin.MessageId,
+			ChatID:         pin.ChatId,
+			SenderID:       pin.SenderId,
+			SenderNickname: pin.SenderNickname,
+			Content:        pin.Content,
+			Formatted:      pin.Formatted,
+			CreatedAt:      pin.MessageCreatedAt,
+			UpdatedAt:      pin.MessageUpdatedAt,
+		},
+	}
+}
+
+// NewPinnedMessageResponseList creates a list of PinnedMessageResponse from PinnedMessage models
+func NewPinnedMessageResponseList(pins []models.PinnedMessage) []PinnedMessageResponse {
+	responses := make([]PinnedMessageResponse, len(pins))
+	for i, pin := range pins {
+		responses[i] = *NewPinnedMessageResponse(&pin)
+	}
+	return responses
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// PollResponse is a DTO for a poll with its current tallies
+type PollResponse struct {
+	PollID         int                  `json:"pollId"`
+	MessageID      int                  `json:"messageId"`
+	ChatID         int                  `json:"chatId"`
+	CreatorID      int                  `json:"creatorId"`
+	Question       string               `json:"question"`
+	MultipleChoice bool                 `json:"multipleChoice"`
+	Anonymous      bool                 `json:"anonymous"`
+	Closed         bool                 `json:"closed"`
+	ClosesAt       *time.Time           `json:"closesAt,omitempty"`
+	ClosedAt       *time.Time           `json:"closedAt,omitempty"`
+	TotalVoters    int                  `json:"totalVoters"`
+	Options        []PollOptionResponse `json:"options"`
+	// MyVotes holds the options chosen by the requesting user
+	MyVotes []int `json:"myVotes"`
+}
+
+// PollOptionResponse is a DTO for a poll option and its vote count
+type PollOptionResponse struct {
+	OptionID  int                 `json:"optionId"`
+	Text      string              `json:"text"`
+	VoteCount int                 `json:"voteCount"`
+	Voters    []PollVoterResponse `json:"voters,omitempty"`
+}
+
+// PollVoterResponse is a DTO for a voter of a named poll
+type PollVoterResponse struct {
+	UserID   int    `json:"userId"`
+	Nickname string `json:"nickname"`
+}
+
+// NewPollResponse creates a PollResponse from a tallied Poll model, or nil
+func NewPollResponse(poll *models.Poll) *PollResponse {
+	if poll == nil {
+		return nil
+	}
+
+	options := make([]PollOptionResponse, len(poll.Options))
+	for i, option := range poll.Options {
+		options[i] = PollOptionResponse{
+			OptionID:  option.ID,
+			Text:      option.Text,
+			VoteCount: option.VoteCount,
+		}
+		for _, voter := range option.Voters {
+			options[i].Voters = append(options[i].Voters, PollVoterResponse{
+				UserID:   voter.UserId,
+				Nickname: voter.Nickname,
+			})
+		}
+	}
+
+	myVotes := poll.MyVotes
+	if myVotes == nil {
+		myVotes = []int{}
+	}
+
+	return &PollResponse{
+		PollID:         poll.ID,
+		MessageID:      poll.MessageId,
+		ChatID:         poll.ChatId,
+		CreatorID:      poll.CreatorId,
+		Question:       poll.Question,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		Closed:         poll.Closed,
+		ClosesAt:       poll.ClosesAt,
+		ClosedAt:       poll.ClosedAt,
+		TotalVoters:    poll.TotalVoters,
+		Options:        options,
+		MyVotes:        myVotes,
+	}
+}
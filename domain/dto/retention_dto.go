@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// ChatRetentionResponse is a DTO for the retention of a chat. Effective is the
+// policy the purge applies, the stricter of the chat's and the server's.
+type ChatRetentionResponse struct {
+	ChatID    int                    `json:"chatId"`
+	Chat      models.RetentionPolicy `json:"chat"`
+	Server    models.RetentionPolicy `json:"server"`
+	Effective models.RetentionPolicy `json:"effective"`
+	// LegalHold is only set for server administrators
+	LegalHold *bool `json:"legalHold,omitempty"`
+}
+
+// LegalHoldResponse is a DTO for a legal hold
+type LegalHoldResponse struct {
+	ChatID    int       `json:"chatId"`
+	Reason    string    `json:"reason"`
+	PlacedBy  int       `json:"placedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewLegalHoldResponse creates a LegalHoldResponse from a LegalHold model
+func NewLegalHoldResponse(hold *models.LegalHold) *LegalHoldResponse {
+	return &LegalHoldResponse{
+		ChatID:    hold.ChatId,
+		Reason:    hold.Reason,
+		PlacedBy:  hold.PlacedBy,
+		CreatedAt: hold.CreatedAt,
+	}
+}
+
+// NewLegalHoldResponseList creates a list of LegalHoldResponse from LegalHold models
+func NewLegalHoldResponseList(holds []models.LegalHold) []LegalHoldResponse {
+	responses := make([]LegalHoldResponse, len(holds))
+	for i := range holds {
+		responses[i] = *NewLegalHoldResponse(&holds[i])
+	}
+	return responses
+}
+
+// RetentionRunResponse is a DTO for a run of the retention purge. Chats is
+// only set when a single run is requested.
+type RetentionRunResponse struct {
+	RunID         int                       `json:"runId"`
+	Status        string                    `json:"status"`
+	ChatCount     int                       `json:"chatCount"`
+	HeldChatCount int                       `json:"heldChatCount"`
+	DeletedCount  int                       `json:"deletedCount"`
+	Error         *string                   `json:"error,omitempty"`
+	StartedAt     time.Time                 `json:"startedAt"`
+	CompletedAt   *time.Time                `json:"completedAt,omitempty"`
+	Chats         []models.RetentionRunChat `json:"chats,omitempty"`
+}
+
+// NewRetentionRunResponse creates a RetentionRunResponse from a RetentionRun model
+func NewRetentionRunResponse(run *models.RetentionRun) *RetentionRunResponse {
+	return &RetentionRunResponse{
+		RunID:         run.ID,
+		Status:        run.Status,
+		ChatCount:     run.ChatCount,
+		HeldChatCount: run.HeldChatCount,
+		DeletedCount:  run.DeletedCount,
+		Error:         run.Error,
+		StartedAt:     run.StartedAt,
+		CompletedAt:   run.CompletedAt,
+	}
+}
+
+// NewRetentionRunResponseList creates a list of RetentionRunResponse from RetentionRun models
+func NewRetentionRunResponseList(runs []models.RetentionRun) []RetentionRunResponse {
+	responses := make([]RetentionRunResponse, len(runs))
+	for i := range runs {
+		responses[i] = *NewRetentionRunResponse(&runs[i])
+	}
+	return responses
+}
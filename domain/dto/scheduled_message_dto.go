@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// ScheduledMessageResponse is a DTO for scheduled message responses
+type ScheduledMessageResponse struct {
+	ScheduledMessageID int       `json:"scheduledMessageId"`
+	ChatID             int       `json:"chatId"`
+	Content            string    `json:"content"`
+	ScheduledAt        time.Time `json:"scheduledAt"`
+	Status             string    `json:"status"`
+	MessageID          *int      `json:"messageId,omitempty"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// NewScheduledMessageResponse creates a new ScheduledMessageResponse from a ScheduledMessage model
+func NewScheduledMessageResponse(scheduled *models.ScheduledMessage) *ScheduledMessageResponse {
+	return &ScheduledMessageResponse{
+		ScheduledMessageID: scheduled.ID,
+		ChatID:             scheduled.ChatId,
+		Content:            scheduled.Content,
+		ScheduledAt:        scheduled.ScheduledAt,
+		Status:             scheduled.Status,
+		MessageID:          scheduled.MessageId,
+		CreatedAt:          scheduled.CreatedAt,
+		UpdatedAt:          scheduled.UpdatedAt,
+	}
+}
+
+// NewScheduledMessageResponseList creates a list of ScheduledMessageResponse from ScheduledMessage models
+func NewScheduledMessageResponseList(scheduled []models.ScheduledMessage) []ScheduledMessageResponse {
+	responses := make([]ScheduledMessageResponse, len(scheduled))
+	for i, s := range scheduled {
+		responses[i] = *NewScheduledMessageResponse(&s)
+	}
+	return responses
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"html"
+	"strings"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// MessageSearchResultResponse is a DTO for a single search hit
+type MessageSearchResultResponse struct {
+	MessageResponse
+	ChatName string `json:"chatName"`
+	// Snippet is an HTML-escaped excerpt with matched text wrapped in <mark> tags
+	Snippet string `json:"snippet"`
+}
+
+// MessageSearchResponse represents the response for a message search with pagination
+type MessageSearchResponse struct {
+	Results    []MessageSearchResultResponse `json:"results"`
+	HasMore    bool                          `json:"hasMore"`
+	NextCursor int                           `json:"nextCursor"`
+}
+
+// NewMessageSearchResultList creates a list of MessageSearchResultResponse from search results
+func NewMessageSearchResultList(results []models.MessageSearchResult) []MessageSearchResultResponse {
+	responses := make([]MessageSearchResultResponse, len(results))
+	for i, result := range results {
+		responses[i] = MessageSearchResultResponse{
+			MessageResponse: *NewMessageResponse(&result.Message),
+			ChatName:        result.ChatName,
+			Snippet:         highlightSnippet(result.Snippet),
+		}
+	}
+	return responses
+}
+
+// highlightSnippet escapes the snippet and turns match markers into <mark> tags
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, models.SnippetMatchStart, "<mark>")
+	return strings.ReplaceAll(escaped, models.SnippetMatchEnd, "</mark>")
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// WebhookResponse is a DTO for an incoming webhook. The token is only known
+// when the webhook is created, so it is only set in that response.
+type WebhookResponse struct {
+	WebhookID  int        `json:"webhookId"`
+	ChatID     int        `json:"chatId"`
+	Name       string     `json:"name"`
+	CreatedBy  int        `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Token      string     `json:"token,omitempty"`
+	URL        string     `json:"url,omitempty"`
+}
+
+// NewWebhookResponse creates a WebhookResponse from an IncomingWebhook model
+func NewWebhookResponse(webhook *models.IncomingWebhook) *WebhookResponse {
+	return &WebhookResponse{
+		WebhookID:  webhook.ID,
+		ChatID:     webhook.ChatId,
+		Name:       webhook.Name,
+		CreatedBy:  webhook.CreatedBy,
+		CreatedAt:  webhook.CreatedAt,
+		LastUsedAt: webhook.LastUsedAt,
+	}
+}
+
+// NewWebhookResponseList creates a list of WebhookResponse from IncomingWebhook models
+func NewWebhookResponseList(webhooks []models.IncomingWebhook) []WebhookResponse {
+	responses := make([]WebhookResponse, len(webhooks))
+	for i := range webhooks {
+		responses[i] = *NewWebhookResponse(&webhooks[i])
+	}
+	return responses
+}
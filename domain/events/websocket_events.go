@@ -3,6 +3,8 @@ package events
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
 )
 
 // Event types
@@ -10,6 +12,36 @@ const (
 	EventMessageCreated = "message.created"
 	EventMessageUpdated = "message.updated"
 	EventMessageDeleted = "message.deleted"
+
+	EventMessagePinned   = "message.pinned"
+	EventMessageUnpinned = "message.unpinned"
+	EventMessageExpired  = "message.expired"
+	// EventMessagePurged removes messages deleted by the retention policy
+	EventMessagePurged = "message.purged"
+
+	EventMessageDelivered = "message.delivered"
+	EventMessageRead      = "message.read"
+
+	EventPollUpdated = "poll.updated"
+	EventPollClosed  = "poll.closed"
+
+	EventChatUpdated = "chat.updated"
+
+	EventDraftUpdated = "draft.updated"
+
+	EventConnectionReady = "connection.ready"
+
+	EventBookmarkReminder = "bookmark.reminder"
+
+	// Device events tell a user's encrypted chat contacts to start or drop
+	// sessions with the device
+	EventDeviceAdded   = "device.added"
+	EventDeviceRemoved = "device.removed"
+
+	// EventMessageSent answers a message.send frame with the stored message
+	EventMessageSent = "message.sent"
+	// EventError answers a frame the server could not handle
+	EventError = "error"
 )
 
 // Common response codes
@@ -28,6 +60,126 @@ type MessageEventData struct {
 	Content        string    `json:"content,omitempty"`
 	CreatedAt      time.Time `json:"createdAt,omitempty"`
 	UpdatedAt      time.Time `json:"updatedAt,omitempty"`
+
+	MessageType string              `json:"messageType,omitempty"`
+	SystemEvent *models.SystemEvent `json:"systemEvent,omitempty"`
+
+	Formatted       models.RichText           `json:"formatted,omitempty"`
+	ClientMessageID string                    `json:"clientMessageId,omitempty"`
+	WebhookID       *int                      `json:"webhookId,omitempty"`
+	ExpiresAt       *time.Time                `json:"expiresAt,omitempty"`
+	ForwardedFrom   *models.MessageOrigin     `json:"forwardedFrom,omitempty"`
+	LinkPreviews    []models.LinkPreview      `json:"linkPreviews,omitempty"`
+	Poll            *models.Poll              `json:"poll,omitempty"`
+	Attachments     models.MessageAttachments `json:"attachments,omitempty"`
+	Encrypted       bool                      `json:"encrypted,omitempty"`
+}
+
+// MessagesExpiredEventData represents the messages of a chat removed by expiry
+// or by the retention policy
+type MessagesExpiredEventData struct {
+	Type       string `json:"type"`
+	ChatID     int    `json:"chatId"`
+	MessageIDs []int  `json:"messageIds"`
+}
+
+// ChatEventData represents the data structure for chat events
+type ChatEventData struct {
+	Type              string                 `json:"type"`
+	ChatID            int                    `json:"chatId"`
+	Name              string                 `json:"name"`
+	Topic             string                 `json:"topic"`
+	MessageTTLSeconds int                    `json:"messageTtlSeconds"`
+	SlowModeSeconds   int                    `json:"slowModeSeconds"`
+	Retention         models.RetentionPolicy `json:"retention"`
+	UpdatedBy         int                    `json:"updatedBy"`
+}
+
+// ReceiptEventData reports the delivery progress of a message to its sender.
+// Group chats only carry counts, not the recipients themselves.
+type ReceiptEventData struct {
+	Type           string `json:"type"`
+	ChatID         int    `json:"chatId"`
+	MessageID      int    `json:"messageId"`
+	Status         string `json:"status"`
+	RecipientCount int    `json:"recipientCount"`
+	DeliveredCount int    `json:"deliveredCount"`
+	ReadCount      int    `json:"readCount"`
+}
+
+// PollEventData carries the current tallies of a poll. It is shared by every
+// member, so the viewer's own votes are left out.
+type PollEventData struct {
+	Type      string       `json:"type"`
+	ChatID    int          `json:"chatId"`
+	MessageID int          `json:"messageId"`
+	Poll      *models.Poll `json:"poll"`
+}
+
+// DraftEventData carries a draft saved on another of the user's devices.
+// Empty content means the draft was cleared.
+type DraftEventData struct {
+	Type      string    `json:"type"`
+	ChatID    int       `json:"chatId"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BookmarkEventData reminds a user of a message they bookmarked
+type BookmarkEventData struct {
+	Type           string    `json:"type"`
+	BookmarkID     int       `json:"bookmarkId"`
+	ChatID         int       `json:"chatId"`
+	ChatName       string    `json:"chatName"`
+	MessageID      int       `json:"messageId"`
+	SenderNickname string    `json:"senderNickname"`
+	PlainText      string    `json:"plainText"`
+	Note           string    `json:"note"`
+	RemindAt       time.Time `json:"remindAt"`
+}
+
+// DeviceEventData describes a device a user registered or removed for
+// end-to-end encryption. IdentityKey is only set when the device is added.
+type DeviceEventData struct {
+	Type        string `json:"type"`
+	UserID      int    `json:"userId"`
+	DeviceID    int    `json:"deviceId"`
+	IdentityKey string `json:"identityKey,omitempty"`
+}
+
+// ConnectionEventData tells a client the ID of its connection, which it sends
+// back in the X-Connection-Id header of its HTTP requests
+type ConnectionEventData struct {
+	Type         string `json:"type"`
+	ConnectionID string `json:"connectionId"`
+}
+
+// MessageSentEventData answers a message sent over the WebSocket. RequestID
+// echoes the ID the client gave the frame.
+type MessageSentEventData struct {
+	Type      string      `json:"type"`
+	RequestID string      `json:"requestId,omitempty"`
+	Message   interface{} `json:"message"`
+}
+
+// ErrorEventData reports why a frame failed, with the message the REST API
+// would give. RetryAfter is set on rate limited sends, in seconds.
+type ErrorEventData struct {
+	Type       string `json:"type"`
+	RequestID  string `json:"requestId,omitempty"`
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retryAfter,omitempty"`
+}
+
+// PinEventData represents the data structure for pin events
+type PinEventData struct {
+	Type             string    `json:"type"`
+	ChatID           int       `json:"chatId"`
+	MessageID        int       `json:"messageId"`
+	PinnedBy         int       `json:"pinnedBy"`
+	PinnedByNickname string    `json:"pinnedByNickname"`
+	Content          string    `json:"content,omitempty"`
+	PinnedAt         time.Time `json:"pinnedAt,omitempty"`
 }
 
 // WebSocketResponse represents the unified response structure
@@ -40,25 +192,74 @@ type WebSocketResponse struct {
 
 // NewWebSocketEvent creates a new WebSocket event with unified response format
 func NewWebSocketEvent(eventType string, chatID int, data interface{}) *WebSocketResponse {
-	eventData := MessageEventData{
+	var payload interface{} = MessageEventData{
 		Type: eventType,
 	}
 
 	// Type assertion for different event types
 	switch v := data.(type) {
 	case *MessageEventData:
-		eventData.MessageID = v.MessageID
-		eventData.ChatID = v.ChatID
-		eventData.SenderID = v.SenderID
-		eventData.SenderNickname = v.SenderNickname
-		eventData.Content = v.Content
-		eventData.CreatedAt = v.CreatedAt
-		eventData.UpdatedAt = v.UpdatedAt
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *PinEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *MessagesExpiredEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *ChatEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *ReceiptEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *PollEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *DraftEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *ConnectionEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *BookmarkEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *DeviceEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
+	case *MessageSentEventData:
+		eventData := *v
+		eventData.Type = eventType
+		payload = eventData
 	}
 
 	return &WebSocketResponse{
 		Success:   true,
 		Code:      StatusSuccess,
+		Data:      payload,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewWebSocketError creates an error frame carrying the response code the
+// REST API uses for the same error
+func NewWebSocketError(code int, data *ErrorEventData) *WebSocketResponse {
+	eventData := *data
+	eventData.Type = EventError
+	return &WebSocketResponse{
+		Success:   false,
+		Code:      code,
 		Data:      eventData,
 		Timestamp: time.Now(),
 	}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// Bookmark limits
+const (
+	MaxBookmarkNoteLength = 500
+	MaxReminderAhead      = 365 * 24 * time.Hour
+)
+
+// Bookmark is a message saved by a user, with an optional note and reminder
+type Bookmark struct {
+	ID        int        `json:"bookmarkId" db:"id"`
+	UserId    int        `json:"userId" db:"userId"`
+	MessageId int        `json:"messageId" db:"messageId"`
+	ChatId    int        `json:"chatId" db:"chatId"`
+	Note      string     `json:"note" db:"note"`
+	RemindAt  *time.Time `json:"remindAt,omitempty" db:"remindAt"`
+	// RemindedAt is set once the reminder was sent
+	RemindedAt *time.Time `json:"remindedAt,omitempty" db:"remindedAt"`
+	CreatedAt  time.Time  `json:"createdAt" db:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updatedAt"`
+
+	// ChatName and Available are loaded with the bookmark. A bookmark is
+	// unavailable once its message is gone or the user left the chat.
+	ChatName  string `json:"chatName" db:"chatName"`
+	Available bool   `json:"available" db:"available"`
+	// Message is only loaded for available bookmarks
+	Message *Message `json:"message,omitempty" db:"-"`
+}
+
+// ValidateBookmark checks the note and reminder time of a bookmark
+func ValidateBookmark(note string, remindAt *time.Time, now time.Time) error {
+	if utf8.RuneCountInString(note) > MaxBookmarkNoteLength {
+		return errors.New("bookmark note too long")
+	}
+	if remindAt != nil && (!remindAt.After(now) || remindAt.After(now.Add(MaxReminderAhead))) {
+		return errors.New("invalid reminder time")
+	}
+	return nil
+}
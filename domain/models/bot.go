@@ -0,0 +1,36 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// BotTokenPrefix tells bot API tokens apart from user session tokens
+const BotTokenPrefix = "bot_"
+
+// botEmailDomain holds the placeholder addresses of bots, which have no email.
+// The .invalid top-level domain can never be registered.
+const botEmailDomain = "@bots.invalid"
+
+// IsBotToken reports whether a bearer token is a bot API token
+func IsBotToken(token string) bool {
+	return strings.HasPrefix(token, BotTokenPrefix)
+}
+
+// HashBotToken derives the stored form of a bot API token
+func HashBotToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BotEmail returns the placeholder email stored for a bot. Nicknames are
+// unique, so the address is too.
+func BotEmail(nickname string) string {
+	return "bot." + nickname + botEmailDomain
+}
+
+// IsBotEmail reports whether an email is reserved for bots
+func IsBotEmail(email string) bool {
+	return strings.HasSuffix(strings.ToLower(email), botEmailDomain)
+}
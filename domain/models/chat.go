@@ -1,12 +1,47 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// MaxChatTopicLength bounds the length of a chat topic in characters
+const MaxChatTopicLength = 250
+
+// MaxSlowModeSeconds bounds the slow mode interval
+const MaxSlowModeSeconds = 6 * 60 * 60
 
 type Chat struct {
 	ID        int       `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
+	Topic     string    `json:"topic" db:"topic"`
 	CreatedAt time.Time `json:"createdAt" db:"createdAt"`
 
+	// MessageTTLSeconds is the default lifetime of new messages; zero keeps them forever
+	MessageTTLSeconds int `json:"messageTtlSeconds" db:"messageTtlSeconds"`
+	// SlowModeSeconds is how long members wait between messages; zero turns slow mode off
+	SlowModeSeconds int `json:"slowModeSeconds" db:"slowModeSeconds"`
+	// RetentionDays and RetentionMaxMessages are the chat's retention policy;
+	// the server-wide policy applies where it is stricter
+	RetentionDays        int `json:"retentionDays" db:"retentionDays"`
+	RetentionMaxMessages int `json:"retentionMaxMessages" db:"retentionMaxMessages"`
+	// Encrypted chats only carry end-to-end encrypted messages. It is chosen
+	// when the chat is created and cannot be turned off.
+	Encrypted bool `json:"encrypted" db:"encrypted"`
+
 	ChatGroups []ChatGroup `json:"chatGroups" gorm:"many2many:chat_group_chats;"`
 	Messages   []Message   `json:"messages" gorm:"foreignKey:chatId;"`
 }
+
+// RetentionPolicy returns the chat's own retention policy
+func (c *Chat) RetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{Days: c.RetentionDays, MaxMessages: c.RetentionMaxMessages}
+}
+
+// ValidateSlowMode checks a slow mode interval in seconds; zero turns it off
+func ValidateSlowMode(seconds int) error {
+	if seconds < 0 || seconds > MaxSlowModeSeconds {
+		return errors.New("invalid slow mode interval")
+	}
+	return nil
+}
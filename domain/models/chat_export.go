@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Export formats
+const (
+	ExportFormatJSON = "json"
+	// ExportFormatCSV is a zip archive of messages.csv and members.csv
+	ExportFormatCSV = "csv"
+	// ExportFormatHTML is a single page readable without the app
+	ExportFormatHTML = "html"
+)
+
+// Export statuses
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	// Expired exports had their file removed after ExportRetention
+	ExportStatusExpired = "expired"
+)
+
+// ExportRetention is how long the file of a finished export can be downloaded
+const ExportRetention = 7 * 24 * time.Hour
+
+// ChatExport is a transcript of a chat generated in the background
+type ChatExport struct {
+	ID          int    `json:"exportId" db:"id"`
+	ChatId      int    `json:"chatId" db:"chatId"`
+	RequestedBy int    `json:"requestedBy" db:"requestedBy"`
+	Format      string `json:"format" db:"format"`
+	Status      string `json:"status" db:"status"`
+	// FileName is the name of the file in the export store
+	FileName     *string    `json:"-" db:"fileName"`
+	FileSize     int64      `json:"fileSize" db:"fileSize"`
+	MessageCount int        `json:"messageCount" db:"messageCount"`
+	CreatedAt    time.Time  `json:"createdAt" db:"createdAt"`
+	StartedAt    *time.Time `json:"startedAt,omitempty" db:"startedAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty" db:"completedAt"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty" db:"expiresAt"`
+}
+
+// IsExportFormat reports whether format is a known export format
+func IsExportFormat(format string) bool {
+	switch format {
+	case ExportFormatJSON, ExportFormatCSV, ExportFormatHTML:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExportExtension returns the file extension of an export format
+func ExportExtension(format string) string {
+	if format == ExportFormatCSV {
+		return "zip"
+	}
+	return format
+}
+
+// TranscriptHeader describes the chat at the top of a transcript
+type TranscriptHeader struct {
+	ExportID   int
+	Chat       *Chat
+	Members    []ChatMember
+	ExportedAt time.Time
+}
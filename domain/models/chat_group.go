@@ -2,12 +2,34 @@ package models
 
 import "time"
 
+// Chat member roles
+const (
+	ChatRoleOwner  = "owner"
+	ChatRoleAdmin  = "admin"
+	ChatRoleMember = "member"
+)
+
 type ChatGroup struct {
 	Name      string    `json:"name" db:"name"`
 	UserId    int       `json:"userId" db:"userId"`
 	ChatId    int       `json:"chatId" db:"chatId"`
+	Role      string    `json:"role" db:"role"`
 	CreatedAt time.Time `json:"createdAt" db:"createdAt"`
 
 	Users []User `json:"users" gorm:"many2many:chat_group_users;"`
 	Chats []Chat `json:"chats" gorm:"many2many:chat_group_chats;"`
 }
+
+// ChatMember is a user of a chat with their role in it
+type ChatMember struct {
+	UserId   int       `json:"userId" db:"userId"`
+	Nickname string    `json:"nickname" db:"nickname"`
+	Role     string    `json:"role" db:"role"`
+	IsBot    bool      `json:"isBot" db:"isBot"`
+	JoinedAt time.Time `json:"joinedAt" db:"joinedAt"`
+}
+
+// CanManageChat reports whether the role may manage chat state such as pins
+func CanManageChat(role string) bool {
+	return role == ChatRoleOwner || role == ChatRoleAdmin
+}
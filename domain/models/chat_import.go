@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Import sources
+const (
+	// ImportSourceUpload archives were uploaded through the API and are
+	// removed once imported
+	ImportSourceUpload = "upload"
+	// ImportSourceCommand archives were given to the import command and are
+	// left where they are
+	ImportSourceCommand = "command"
+)
+
+// Import statuses
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	// Failed imports can be resumed; what was imported is skipped
+	ImportStatusFailed = "failed"
+)
+
+// Kinds of source objects mapped by an import
+const (
+	ImportKindUser    = "user"
+	ImportKindChannel = "channel"
+)
+
+// importedUserEmailDomain holds the placeholder addresses of imported users
+// with no matching account. The .invalid top-level domain can never be registered.
+const importedUserEmailDomain = "@slack-import.invalid"
+
+// ChatImport is a Slack export archive imported in the background
+type ChatImport struct {
+	ID          int    `json:"importId" db:"id"`
+	RequestedBy int    `json:"requestedBy" db:"requestedBy"`
+	Source      string `json:"source" db:"source"`
+	// ArchivePath is the path of the archive on the server
+	ArchivePath  string     `json:"-" db:"archivePath"`
+	Status       string     `json:"status" db:"status"`
+	UserCount    int        `json:"userCount" db:"userCount"`
+	ChatCount    int        `json:"chatCount" db:"chatCount"`
+	MessageCount int        `json:"messageCount" db:"messageCount"`
+	Error        *string    `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time  `json:"createdAt" db:"createdAt"`
+	StartedAt    *time.Time `json:"startedAt,omitempty" db:"startedAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty" db:"completedAt"`
+}
+
+// ImportedUserEmail returns the placeholder email of an imported user with
+// no matching account. Source user IDs are unique, so the address is too.
+func ImportedUserEmail(sourceUserID string) string {
+	return "slack." + sourceUserID + importedUserEmailDomain
+}
+
+// IsImportedUserEmail reports whether an email is reserved for imported users
+func IsImportedUserEmail(email string) bool {
+	return strings.HasSuffix(strings.ToLower(email), importedUserEmailDomain)
+}
@@ -0,0 +1,63 @@
+package models
+
+import "strings"
+
+// Command describes a slash command that can be typed as a message
+type Command struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Args        []CommandArg `json:"args"`
+}
+
+// CommandArg describes an argument of a slash command
+type CommandArg struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+	// Repeated arguments may be given more than once
+	Repeated bool `json:"repeated"`
+}
+
+// Usage renders the command line, with required arguments in angle brackets
+// and optional ones in square brackets
+func (c *Command) Usage() string {
+	parts := []string{"/" + c.Name}
+	for _, arg := range c.Args {
+		name := arg.Name
+		if arg.Repeated {
+			name += "..."
+		}
+		if arg.Required {
+			parts = append(parts, "<"+name+">")
+		} else {
+			parts = append(parts, "["+name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseCommand splits a message starting with a slash into the command name and
+// its arguments. Messages like "/usr/bin" or "/ hi" are not commands, and "//"
+// escapes a leading slash.
+func ParseCommand(content string) (name, args string, ok bool) {
+	if !strings.HasPrefix(content, "/") || strings.HasPrefix(content, "//") {
+		return "", "", false
+	}
+
+	line := content[1:]
+	end := strings.IndexAny(line, " \t\n")
+	if end < 0 {
+		end = len(line)
+	}
+	name = line[:end]
+	if name == "" {
+		return "", "", false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return "", "", false
+		}
+	}
+
+	return strings.ToLower(name), strings.TrimSpace(line[end:]), true
+}
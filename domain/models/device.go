@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Key directory limits
+const (
+	MaxDevicesPerUser   = 10
+	MaxPrekeysPerDevice = 100
+	MaxDeviceNameLength = 50
+	// Public keys are accepted as base64 of this many bytes, which covers
+	// Curve25519 keys with or without a type prefix
+	MinPublicKeySize = 32
+	MaxPublicKeySize = 128
+)
+
+// Device is a client registered for end-to-end encryption. The server only
+// keeps public keys; private keys never leave the device.
+type Device struct {
+	ID     int    `json:"deviceId" db:"id"`
+	UserId int    `json:"userId" db:"userId"`
+	Name   string `json:"name" db:"name"`
+	// IdentityKey is the device's long-term public key, base64 encoded
+	IdentityKey string `json:"identityKey" db:"identityKey"`
+	// PrekeyCount is the number of one-time prekeys left to hand out
+	PrekeyCount int       `json:"prekeyCount" db:"prekeyCount"`
+	CreatedAt   time.Time `json:"createdAt" db:"createdAt"`
+}
+
+// Prekey is a one-time public key of a device. Each is handed out to a single
+// sender to start a session and then deleted.
+type Prekey struct {
+	DeviceId  int    `json:"-" db:"deviceId"`
+	KeyId     int    `json:"keyId" db:"keyId"`
+	PublicKey string `json:"publicKey" db:"publicKey"`
+}
+
+// DeviceKeyBundle is what a sender needs to start a session with a device.
+// Prekey is nil once the device ran out of one-time prekeys.
+type DeviceKeyBundle struct {
+	DeviceId    int     `json:"deviceId"`
+	IdentityKey string  `json:"identityKey"`
+	Prekey      *Prekey `json:"prekey,omitempty"`
+}
+
+// NormalizeDeviceName trims a device name and checks its length
+func NormalizeDeviceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxDeviceNameLength {
+		return "", errors.New("invalid device name")
+	}
+	return name, nil
+}
+
+// ValidatePublicKey checks that a key is base64 of a plausible public key size.
+// The key itself is opaque to the server.
+func ValidatePublicKey(key string) error {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) < MinPublicKeySize || len(decoded) > MaxPublicKeySize {
+		return errors.New("invalid public key")
+	}
+	return nil
+}
+
+// ValidatePrekeys checks a batch of one-time prekeys
+func ValidatePrekeys(prekeys []Prekey) error {
+	if len(prekeys) > MaxPrekeysPerDevice {
+		return errors.New("too many prekeys")
+	}
+
+	seen := make(map[int]bool, len(prekeys))
+	for _, prekey := range prekeys {
+		if prekey.KeyId < 0 || seen[prekey.KeyId] || ValidatePublicKey(prekey.PublicKey) != nil {
+			return errors.New("invalid prekey")
+		}
+		seen[prekey.KeyId] = true
+	}
+	return nil
+}
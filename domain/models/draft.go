@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxDraftLength bounds the length of a draft in characters
+const MaxDraftLength = 10000
+
+// Draft is a user's unsent message in a chat. Cleared drafts keep their row
+// with empty content, so an older save from another device cannot restore them.
+type Draft struct {
+	UserId    int       `json:"userId" db:"userId"`
+	ChatId    int       `json:"chatId" db:"chatId"`
+	Content   string    `json:"content" db:"content"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updatedAt"`
+}
+
+// IsEmpty reports whether the draft was cleared
+func (d *Draft) IsEmpty() bool {
+	return d.Content == ""
+}
+
+// ValidateDraft checks the content of a draft
+func ValidateDraft(content string) error {
+	if utf8.RuneCountInString(content) > MaxDraftLength {
+		return errors.New("draft too long")
+	}
+	return nil
+}
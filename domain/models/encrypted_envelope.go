@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// EncryptedEnvelopeVersion is the envelope format clients must send
+const EncryptedEnvelopeVersion = 1
+
+// Envelope limits. Encrypted chats are private, so an envelope is addressed
+// to at most every device of both members.
+const (
+	MaxEncryptedEnvelopeLength = 64 * 1024
+	MaxEnvelopeRecipients      = 2 * MaxDevicesPerUser
+)
+
+// EncryptedEnvelope is the content of a message in an encrypted chat. The
+// message is encrypted once with a message key, which is encrypted for each
+// recipient device. The server checks the shape of the envelope but cannot
+// read the ciphertext or the keys.
+type EncryptedEnvelope struct {
+	Version int `json:"version"`
+	// SenderDeviceId is the device that encrypted the message
+	SenderDeviceId int `json:"senderDeviceId"`
+	// Ciphertext is the encrypted message, base64 encoded
+	Ciphertext string              `json:"ciphertext"`
+	Recipients []EnvelopeRecipient `json:"recipients"`
+}
+
+// EnvelopeRecipient carries the message key encrypted for one device
+type EnvelopeRecipient struct {
+	DeviceId int `json:"deviceId"`
+	// Key is the encrypted message key, base64 encoded
+	Key string `json:"key"`
+}
+
+// ParseEncryptedEnvelope reads the envelope sent as a message's content
+func ParseEncryptedEnvelope(content string) (*EncryptedEnvelope, error) {
+	invalid := errors.New("invalid encrypted envelope")
+	if len(content) > MaxEncryptedEnvelopeLength {
+		return nil, invalid
+	}
+
+	var envelope EncryptedEnvelope
+	if err := json.Unmarshal([]byte(content), &envelope); err != nil {
+		return nil, invalid
+	}
+	if envelope.Version != EncryptedEnvelopeVersion || envelope.SenderDeviceId <= 0 {
+		return nil, invalid
+	}
+	if !isBase64(envelope.Ciphertext) {
+		return nil, invalid
+	}
+	if len(envelope.Recipients) == 0 || len(envelope.Recipients) > MaxEnvelopeRecipients {
+		return nil, invalid
+	}
+
+	seen := make(map[int]bool, len(envelope.Recipients))
+	for _, recipient := range envelope.Recipients {
+		if recipient.DeviceId <= 0 || seen[recipient.DeviceId] || !isBase64(recipient.Key) {
+			return nil, invalid
+		}
+		seen[recipient.DeviceId] = true
+	}
+	return &envelope, nil
+}
+
+func isBase64(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, err := base64.StdEncoding.DecodeString(s)
+	return err == nil
+}
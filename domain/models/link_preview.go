@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LinkPreview holds the OpenGraph/Twitter card metadata of a URL
+type LinkPreview struct {
+	URL         string    `json:"url" db:"url"`
+	Title       string    `json:"title,omitempty" db:"title"`
+	Description string    `json:"description,omitempty" db:"description"`
+	ImageURL    string    `json:"imageUrl,omitempty" db:"imageUrl"`
+	SiteName    string    `json:"siteName,omitempty" db:"siteName"`
+	Failed      bool      `json:"-" db:"failed"` // cached fetch failure
+	FetchedAt   time.Time `json:"fetchedAt" db:"fetchedAt"`
+}
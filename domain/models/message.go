@@ -1,6 +1,18 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// MaxClientMessageIDLength bounds the client-generated ID used to deduplicate retries
+const MaxClientMessageIDLength = 64
+
+// Bounds of a disappearing message lifetime
+const (
+	MinMessageTTLSeconds = 5
+	MaxMessageTTLSeconds = 30 * 24 * 60 * 60
+)
 
 type Message struct {
 	ID             int       `json:"id" db:"id"`
@@ -11,6 +23,127 @@ type Message struct {
 	CreatedAt      time.Time `json:"createdAt" db:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt" db:"updatedAt"`
 
+	// Type tells user messages from system messages, which carry SystemEvent
+	Type        string       `json:"type" db:"type"`
+	SystemEvent *SystemEvent `json:"systemEvent,omitempty" db:"systemEvent"`
+
+	// Formatted is the parsed Markdown of Content; PlainText is its unformatted projection
+	Formatted RichText `json:"formatted" db:"formatted"`
+	PlainText string   `json:"plainText" db:"plainText"`
+
+	// ExpiresAt is set on disappearing messages
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" db:"expiresAt"`
+	// ScheduledMessageId is set when the message was posted by the scheduler
+	ScheduledMessageId *int `json:"-" db:"scheduledMessageId"`
+	// ClientMessageId is the sender's idempotency key for the message
+	ClientMessageId *string `json:"clientMessageId,omitempty" db:"clientMessageId"`
+
+	// ForwardedFrom* reference the original message of a forwarded message
+	ForwardedFromMessageId *int    `json:"forwardedFromMessageId,omitempty" db:"forwardedFromMessageId"`
+	ForwardedFromChatId    *int    `json:"forwardedFromChatId,omitempty" db:"forwardedFromChatId"`
+	ForwardedFromSenderId  *int    `json:"forwardedFromSenderId,omitempty" db:"forwardedFromSenderId"`
+	ForwardedFromNickname  *string `json:"forwardedFromNickname,omitempty" db:"forwardedFromNickname"`
+
+	// WebhookId is set on messages posted through an incoming webhook. They are
+	// stored as sent by the webhook's creator and shown under BotName.
+	WebhookId *int    `json:"webhookId,omitempty" db:"webhookId"`
+	BotName   *string `json:"botName,omitempty" db:"botName"`
+
+	// Attachments are cards sent by bots along with the content
+	Attachments MessageAttachments `json:"attachments,omitempty" db:"attachments"`
+	// ThreadRootId is set on replies in a thread, imported from other chat services
+	ThreadRootId *int `json:"threadRootId,omitempty" db:"threadRootId"`
+	// Encrypted messages have an EncryptedEnvelope as their content, which is
+	// neither formatted, indexed nor moderated
+	Encrypted bool `json:"encrypted,omitempty" db:"encrypted"`
+
+	LinkPreviews []LinkPreview `json:"linkPreviews,omitempty" db:"-"`
+	Poll         *Poll         `json:"poll,omitempty" db:"-"`
+	Reactions    []Reaction    `json:"reactions,omitempty" db:"-"`
+	// Delivery is only loaded for the sender's view of the message
+	Delivery *DeliverySummary `json:"delivery,omitempty" db:"-"`
+
 	Chat   Chat `json:"chat" gorm:"foreignKey:chatId;"`
 	Sender User `json:"sender" gorm:"foreignKey:senderId;"`
 }
+
+// IsSystem reports whether the message was written by the server for chat activity
+func (m *Message) IsSystem() bool {
+	return m.Type == MessageTypeSystem
+}
+
+// MessageOrigin identifies where a forwarded message was first posted
+type MessageOrigin struct {
+	MessageID      int    `json:"messageId"`
+	ChatID         int    `json:"chatId,omitempty"`
+	SenderID       int    `json:"senderId"`
+	SenderNickname string `json:"senderNickname"`
+}
+
+// Origin returns the original message of a forwarded message, or nil
+func (m *Message) Origin() *MessageOrigin {
+	if m.ForwardedFromMessageId == nil {
+		return nil
+	}
+
+	origin := &MessageOrigin{MessageID: *m.ForwardedFromMessageId}
+	if m.ForwardedFromChatId != nil {
+		origin.ChatID = *m.ForwardedFromChatId
+	}
+	if m.ForwardedFromSenderId != nil {
+		origin.SenderID = *m.ForwardedFromSenderId
+	}
+	if m.ForwardedFromNickname != nil {
+		origin.SenderNickname = *m.ForwardedFromNickname
+	}
+	return origin
+}
+
+// ForwardOf builds a message forwarding source into another chat. Forwarding a
+// forwarded message keeps pointing at the original message.
+func ForwardOf(source *Message, chatID, senderID int) *Message {
+	message := &Message{
+		ChatId:      chatID,
+		SenderId:    senderID,
+		Content:     source.Content,
+		Formatted:   source.Formatted,
+		PlainText:   source.PlainText,
+		Attachments: source.Attachments,
+	}
+
+	if source.ForwardedFromMessageId != nil {
+		message.ForwardedFromMessageId = source.ForwardedFromMessageId
+		message.ForwardedFromChatId = source.ForwardedFromChatId
+		message.ForwardedFromSenderId = source.ForwardedFromSenderId
+		message.ForwardedFromNickname = source.ForwardedFromNickname
+		return message
+	}
+
+	messageID, sourceChatID, sourceSenderID, nickname := source.ID, source.ChatId, source.SenderId, source.SenderNickname
+	message.ForwardedFromMessageId = &messageID
+	message.ForwardedFromChatId = &sourceChatID
+	message.ForwardedFromSenderId = &sourceSenderID
+	message.ForwardedFromNickname = &nickname
+	return message
+}
+
+// ValidateMessageTTL checks a disappearing message lifetime in seconds
+func ValidateMessageTTL(seconds int) error {
+	if seconds < MinMessageTTLSeconds || seconds > MaxMessageTTLSeconds {
+		return errors.New("invalid message ttl")
+	}
+	return nil
+}
+
+// ValidateClientMessageID checks a client-generated message ID
+func ValidateClientMessageID(id string) error {
+	if id == "" || len(id) > MaxClientMessageIDLength {
+		return errors.New("invalid client message id")
+	}
+	for _, r := range id {
+		if r <= 0x20 || r == 0x7f {
+			return errors.New("invalid client message id")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Attachment limits per message
+const (
+	MaxAttachments          = 10
+	MaxAttachmentFields     = 20
+	MaxAttachmentTextLength = 4000
+	MaxAttachmentFieldText  = 300
+)
+
+var attachmentColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// MessageAttachment is a card shown below a bot's message, for structured
+// content such as build results or alerts
+type MessageAttachment struct {
+	// Color is the accent of the card as #RRGGBB
+	Color    string `json:"color,omitempty"`
+	Title    string `json:"title,omitempty"`
+	TitleURL string `json:"titleUrl,omitempty"`
+	// Text is Markdown; Formatted is its parsed form
+	Text      string            `json:"text,omitempty"`
+	Formatted RichText          `json:"formatted,omitempty"`
+	Fields    []AttachmentField `json:"fields,omitempty"`
+	ImageURL  string            `json:"imageUrl,omitempty"`
+	Footer    string            `json:"footer,omitempty"`
+}
+
+// AttachmentField is a labelled value of a card. Short fields may be laid out side by side.
+type AttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short,omitempty"`
+}
+
+// MessageAttachments is stored as JSON
+type MessageAttachments []MessageAttachment
+
+// ValidateAttachments checks the size of the cards and that their links are http(s)
+func ValidateAttachments(attachments []MessageAttachment) error {
+	if len(attachments) > MaxAttachments {
+		return errors.New("too many attachments")
+	}
+
+	for _, attachment := range attachments {
+		if attachment.Color != "" && !attachmentColorPattern.MatchString(attachment.Color) {
+			return errors.New("invalid attachment")
+		}
+		if !isWebURL(attachment.TitleURL) || !isWebURL(attachment.ImageURL) {
+			return errors.New("invalid attachment")
+		}
+		if utf8.RuneCountInString(attachment.Text) > MaxAttachmentTextLength ||
+			utf8.RuneCountInString(attachment.Title) > MaxAttachmentFieldText ||
+			utf8.RuneCountInString(attachment.Footer) > MaxAttachmentFieldText {
+			return errors.New("invalid attachment")
+		}
+		if attachment.Title == "" && attachment.Text == "" && len(attachment.Fields) == 0 && attachment.ImageURL == "" {
+			return errors.New("invalid attachment")
+		}
+
+		if len(attachment.Fields) > MaxAttachmentFields {
+			return errors.New("invalid attachment")
+		}
+		for _, field := range attachment.Fields {
+			if field.Title == "" ||
+				utf8.RuneCountInString(field.Title) > MaxAttachmentFieldText ||
+				utf8.RuneCountInString(field.Value) > MaxAttachmentFieldText {
+				return errors.New("invalid attachment")
+			}
+		}
+	}
+	return nil
+}
+
+// isWebURL accepts an empty value or an absolute http(s) URL
+func isWebURL(raw string) bool {
+	if raw == "" {
+		return true
+	}
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// Value stores the attachments as JSON
+func (a MessageAttachments) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan loads attachments stored as JSON
+func (a *MessageAttachments) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case []byte:
+		return json.Unmarshal(v, a)
+	default:
+		return fmt.Errorf("cannot scan %T into MessageAttachments", src)
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// MessageHistoryQuery selects a page of a chat's history. At most one anchor is
+// set; without one the most recent messages are returned.
+type MessageHistoryQuery struct {
+	UserId int // the viewer; delivery status is loaded for their own messages
+	ChatId int
+	Before int        // messages with an ID lower than Before
+	After  int        // messages with an ID higher than After
+	Around int        // messages centered on this message ID
+	At     *time.Time // messages centered on the first one sent at or after At
+	Limit  int
+}
+
+// Anchored reports whether the query pages from a cursor instead of the latest messages
+func (q MessageHistoryQuery) Anchored() bool {
+	return q.Before != 0 || q.After != 0 || q.Around != 0 || q.At != nil
+}
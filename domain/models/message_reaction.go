@@ -0,0 +1,11 @@
+package models
+
+// Reaction counts the users who reacted to a message with one emoji
+type Reaction struct {
+	MessageId int `json:"-" db:"messageId"`
+	// Emoji is a shortcode such as thumbsup
+	Emoji string `json:"emoji" db:"emoji"`
+	Count int    `json:"count" db:"count"`
+	// Reacted tells whether the viewer is one of them
+	Reacted bool `json:"reacted" db:"reacted"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// Delivery statuses of a message for one recipient
+const (
+	DeliveryStatusSent      = "sent"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusRead      = "read"
+)
+
+// DeliverySummary aggregates the delivery status of a message over its recipients
+type DeliverySummary struct {
+	MessageId  int `json:"messageId" db:"messageId"`
+	ChatId     int `json:"chatId" db:"chatId"`
+	SenderId   int `json:"senderId" db:"senderId"`
+	Recipients int `json:"recipients" db:"recipients"`
+	Delivered  int `json:"delivered" db:"delivered"` // includes recipients who read it
+	Read       int `json:"read" db:"read"`
+}
+
+// Status is the least advanced status among the recipients
+func (s DeliverySummary) Status() string {
+	switch {
+	case s.Recipients > 0 && s.Read == s.Recipients:
+		return DeliveryStatusRead
+	case s.Recipients > 0 && s.Delivered == s.Recipients:
+		return DeliveryStatusDelivered
+	default:
+		return DeliveryStatusSent
+	}
+}
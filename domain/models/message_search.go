@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// MessageSearchFilter holds the criteria for a message search
+type MessageSearchFilter struct {
+	UserId   int // only chats this user belongs to are searched
+	Query    string
+	ChatId   int
+	SenderId int
+	From     *time.Time
+	To       *time.Time
+	Cursor   int // only messages with an ID lower than the cursor
+	Limit    int
+}
+
+// MessageSearchResult is a message matched by a search, with a highlighted excerpt
+type MessageSearchResult struct {
+	Message
+	ChatName string `json:"chatName" db:"chatName"`
+	// Snippet marks matched text with SnippetMatchStart and SnippetMatchEnd
+	Snippet string `json:"snippet" db:"snippet"`
+}
+
+// Markers delimiting matched text in MessageSearchResult.Snippet
+const (
+	SnippetMatchStart = "\uE000"
+	SnippetMatchEnd   = "\uE001"
+)
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Moderation actions, from the mildest to the strictest
+const (
+	ModerationActionAllow = "allow"
+	// ModerationActionMask hides the offending text
+	ModerationActionMask = "mask"
+	// ModerationActionFlag posts the message and queues it for review
+	ModerationActionFlag = "flag"
+	// ModerationActionReject refuses to post the message
+	ModerationActionReject = "reject"
+)
+
+// Review statuses of a flagged message
+const (
+	FlagStatusPending  = "pending"
+	FlagStatusApproved = "approved"
+	FlagStatusRemoved  = "removed"
+)
+
+// IsModerationAction reports whether the action is known
+func IsModerationAction(action string) bool {
+	switch action {
+	case ModerationActionAllow, ModerationActionMask, ModerationActionFlag, ModerationActionReject:
+		return true
+	}
+	return false
+}
+
+// ModerationInput is the message a filter inspects
+type ModerationInput struct {
+	ChatId   int
+	SenderId int
+	Content  string
+}
+
+// ModerationVerdict is the decision of one filter
+type ModerationVerdict struct {
+	Action string
+	Filter string
+	Reason string
+	// Content, when set, replaces the message content, e.g. with masked words
+	Content string
+}
+
+// ModerationError is returned when a filter rejects a message. Reason is shown
+// to the sender.
+type ModerationError struct {
+	Filter string
+	Reason string
+}
+
+func (e *ModerationError) Error() string {
+	return "message rejected by moderation"
+}
+
+// ModerationReason records why a filter flagged a message
+type ModerationReason struct {
+	Filter string `json:"filter"`
+	Reason string `json:"reason"`
+}
+
+// ModerationReasons is stored as JSON
+type ModerationReasons []ModerationReason
+
+// ModerationFlag is a message waiting for review. Content is the flagged
+// text, which stays reviewable after the message is edited or deleted.
+type ModerationFlag struct {
+	ID             int               `json:"flagId" db:"id"`
+	MessageId      int               `json:"messageId" db:"messageId"`
+	ChatId         int               `json:"chatId" db:"chatId"`
+	SenderId       int               `json:"senderId" db:"senderId"`
+	SenderNickname string            `json:"senderNickname" db:"senderNickname"`
+	Content        string            `json:"content" db:"content"`
+	Reasons        ModerationReasons `json:"reasons" db:"reasons"`
+	Status         string            `json:"status" db:"status"`
+	ReviewedBy     *int              `json:"reviewedBy,omitempty" db:"reviewedBy"`
+	ReviewedAt     *time.Time        `json:"reviewedAt,omitempty" db:"reviewedAt"`
+	CreatedAt      time.Time         `json:"createdAt" db:"createdAt"`
+}
+
+// Value stores the reasons as JSON
+func (r ModerationReasons) Value() (driver.Value, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan loads reasons stored as JSON
+func (r *ModerationReasons) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	case []byte:
+		return json.Unmarshal(v, r)
+	default:
+		return fmt.Errorf("cannot scan %T into ModerationReasons", src)
+	}
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Events an outgoing webhook can subscribe to
+const (
+	OutgoingEventMessageCreated = "message.created"
+	OutgoingEventMessageUpdated = "message.updated"
+	OutgoingEventMessageDeleted = "message.deleted"
+	OutgoingEventMemberAdded    = "member.added"
+	OutgoingEventMemberRemoved  = "member.removed"
+)
+
+// OutgoingEvents lists every event an outgoing webhook can subscribe to
+var OutgoingEvents = []string{
+	OutgoingEventMessageCreated,
+	OutgoingEventMessageUpdated,
+	OutgoingEventMessageDeleted,
+	OutgoingEventMemberAdded,
+	OutgoingEventMemberRemoved,
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	// Dead deliveries failed MaxDeliveryAttempts times and are only sent again when replayed
+	DeliveryStatusDead = "dead"
+)
+
+// Delivery retry policy
+const (
+	MaxDeliveryAttempts = 8
+	deliveryBaseBackoff = 10 * time.Second
+	deliveryMaxBackoff  = time.Hour
+)
+
+const MaxWebhookURLLength = 2048
+
+// OutgoingWebhook subscribes an HTTP endpoint to chat events. A webhook bound
+// to a chat receives that chat's events; one without a chat receives the
+// events of every chat its owner belongs to.
+type OutgoingWebhook struct {
+	ID      int    `json:"outgoingWebhookId" db:"id"`
+	OwnerId int    `json:"ownerId" db:"ownerId"`
+	ChatId  *int   `json:"chatId,omitempty" db:"chatId"`
+	URL     string `json:"url" db:"url"`
+	Secret  string `json:"-" db:"secret"`
+	// Events holds the subscribed events separated by commas
+	Events    string    `json:"-" db:"events"`
+	CreatedAt time.Time `json:"createdAt" db:"createdAt"`
+}
+
+// EventTypes returns the events the webhook subscribes to
+func (w *OutgoingWebhook) EventTypes() []string {
+	return strings.Split(w.Events, ",")
+}
+
+// Subscribes reports whether the webhook receives the given event
+func (w *OutgoingWebhook) Subscribes(eventType string) bool {
+	for _, subscribed := range w.EventTypes() {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a queued request of an event to an outgoing webhook
+type WebhookDelivery struct {
+	ID                int        `json:"deliveryId" db:"id"`
+	OutgoingWebhookId int        `json:"outgoingWebhookId" db:"outgoingWebhookId"`
+	EventType         string     `json:"event" db:"eventType"`
+	ChatId            int        `json:"chatId" db:"chatId"`
+	MessageId         *int       `json:"messageId,omitempty" db:"messageId"`
+	Payload           string     `json:"payload" db:"payload"`
+	Status            string     `json:"status" db:"status"`
+	Attempts          int        `json:"attempts" db:"attempts"`
+	NextAttemptAt     *time.Time `json:"nextAttemptAt,omitempty" db:"nextAttemptAt"`
+	LastAttemptAt     *time.Time `json:"lastAttemptAt,omitempty" db:"lastAttemptAt"`
+	ResponseStatus    *int       `json:"responseStatus,omitempty" db:"responseStatus"`
+	LastError         *string    `json:"lastError,omitempty" db:"lastError"`
+	DeliveredAt       *time.Time `json:"deliveredAt,omitempty" db:"deliveredAt"`
+	CreatedAt         time.Time  `json:"createdAt" db:"createdAt"`
+}
+
+// WebhookEvent is the body posted to an outgoing webhook
+type WebhookEvent struct {
+	Event      string      `json:"event"`
+	ChatId     int         `json:"chatId"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookMemberData is the data of member events. Left members are their own actor.
+type WebhookMemberData struct {
+	Actor   SystemEventUser   `json:"actor"`
+	Members []SystemEventUser `json:"members"`
+}
+
+// ValidateOutgoingWebhook checks the endpoint and the subscribed events
+func ValidateOutgoingWebhook(rawURL string, eventTypes []string) error {
+	if len(rawURL) > MaxWebhookURLLength {
+		return errors.New("invalid webhook url")
+	}
+	endpoint, err := url.Parse(rawURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return errors.New("invalid webhook url")
+	}
+
+	for _, eventType := range eventTypes {
+		if !isOutgoingEvent(eventType) {
+			return errors.New("invalid webhook event")
+		}
+	}
+	return nil
+}
+
+func isOutgoingEvent(eventType string) bool {
+	for _, known := range OutgoingEvents {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryBackoff returns how long to wait after a delivery failed the given
+// number of times. The wait doubles with every failure up to an hour.
+func DeliveryBackoff(attempts int) time.Duration {
+	backoff := deliveryBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= deliveryMaxBackoff {
+			return deliveryMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// SignWebhookPayload computes the signature sent with a delivery. Receivers
+// recompute the HMAC-SHA256 of "<timestamp>.<body>" with the webhook secret.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type PinnedMessage struct {
+	ChatId           int       `json:"chatId" db:"chatId"`
+	MessageId        int       `json:"messageId" db:"messageId"`
+	PinnedBy         int       `json:"pinnedBy" db:"pinnedBy"`
+	PinnedByNickname string    `json:"pinnedByNickname" db:"pinnedByNickname"`
+	PinnedAt         time.Time `json:"pinnedAt" db:"pinnedAt"`
+
+	// Fields of the pinned message
+	SenderId         int       `json:"senderId" db:"senderId"`
+	SenderNickname   string    `json:"senderNickname" db:"senderNickname"`
+	Content          string    `json:"content" db:"content"`
+	Formatted        RichText  `json:"formatted" db:"formatted"`
+	MessageCreatedAt time.Time `json:"messageCreatedAt" db:"messageCreatedAt"`
+	MessageUpdatedAt time.Time `json:"messageUpdatedAt" db:"messageUpdatedAt"`
+}
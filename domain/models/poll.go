@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Poll limits
+const (
+	MinPollOptions        = 2
+	MaxPollOptions        = 10
+	MaxPollQuestionLength = 300
+	MaxPollOptionLength   = 100
+)
+
+// Poll is a question attached to a message. The message content holds the
+// question so previews and search work as for any other message.
+type Poll struct {
+	ID             int        `json:"pollId" db:"id"`
+	MessageId      int        `json:"messageId" db:"messageId"`
+	ChatId         int        `json:"chatId" db:"chatId"`
+	CreatorId      int        `json:"creatorId" db:"creatorId"`
+	Question       string     `json:"question" db:"question"`
+	MultipleChoice bool       `json:"multipleChoice" db:"multipleChoice"`
+	Anonymous      bool       `json:"anonymous" db:"anonymous"`
+	ClosesAt       *time.Time `json:"closesAt,omitempty" db:"closesAt"`
+	ClosedAt       *time.Time `json:"closedAt,omitempty" db:"closedAt"`
+	CreatedAt      time.Time  `json:"createdAt" db:"createdAt"`
+
+	// Options, tallies and Closed are loaded with the poll
+	Options     []PollOption `json:"options" db:"-"`
+	TotalVoters int          `json:"totalVoters" db:"-"`
+	Closed      bool         `json:"closed" db:"-"`
+	// MyVotes holds the options chosen by the user viewing the poll
+	MyVotes []int `json:"myVotes,omitempty" db:"-"`
+}
+
+// PollOption is one of the answers of a poll
+type PollOption struct {
+	ID        int    `json:"optionId" db:"id"`
+	PollId    int    `json:"-" db:"pollId"`
+	Position  int    `json:"-" db:"position"`
+	Text      string `json:"text" db:"text"`
+	VoteCount int    `json:"voteCount" db:"-"`
+	// Voters is only listed on named polls
+	Voters []PollVoter `json:"voters,omitempty" db:"-"`
+}
+
+// PollVoter identifies a user who chose an option of a named poll
+type PollVoter struct {
+	UserId   int    `json:"userId" db:"userId"`
+	Nickname string `json:"nickname" db:"nickname"`
+}
+
+// PollVote is a user's vote for one option
+type PollVote struct {
+	PollId   int    `db:"pollId"`
+	OptionId int    `db:"optionId"`
+	UserId   int    `db:"userId"`
+	Nickname string `db:"nickname"`
+}
+
+// IsClosed reports whether the poll stopped accepting votes at the given time
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
+
+// Tally counts the votes of the poll and the choices of the viewer.
+// Voter names are only kept on named polls.
+func (p *Poll) Tally(votes []PollVote, viewerID int, now time.Time) {
+	p.Closed = p.IsClosed(now)
+	p.MyVotes = nil
+
+	byOption := make(map[int]int, len(p.Options))
+	for i := range p.Options {
+		p.Options[i].VoteCount = 0
+		p.Options[i].Voters = nil
+		byOption[p.Options[i].ID] = i
+	}
+
+	voters := make(map[int]bool)
+	for _, vote := range votes {
+		i, ok := byOption[vote.OptionId]
+		if !ok || vote.PollId != p.ID {
+			continue
+		}
+		p.Options[i].VoteCount++
+		if !p.Anonymous {
+			p.Options[i].Voters = append(p.Options[i].Voters, PollVoter{UserId: vote.UserId, Nickname: vote.Nickname})
+		}
+		voters[vote.UserId] = true
+		if vote.UserId == viewerID {
+			p.MyVotes = append(p.MyVotes, vote.OptionId)
+		}
+	}
+	p.TotalVoters = len(voters)
+}
+
+// ValidatePoll checks the question and options of a new poll
+func ValidatePoll(question string, options []string, closesAt *time.Time, now time.Time) error {
+	if question == "" || utf8.RuneCountInString(question) > MaxPollQuestionLength {
+		return errors.New("invalid poll question")
+	}
+	if len(options) < MinPollOptions || len(options) > MaxPollOptions {
+		return errors.New("invalid poll options")
+	}
+
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if option == "" || utf8.RuneCountInString(option) > MaxPollOptionLength {
+			return errors.New("invalid poll options")
+		}
+		key := strings.ToLower(option)
+		if seen[key] {
+			return errors.New("duplicate poll option")
+		}
+		seen[key] = true
+	}
+
+	if closesAt != nil && !closesAt.After(now) {
+		return errors.New("invalid poll close time")
+	}
+	return nil
+}
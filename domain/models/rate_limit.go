@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Limits a message send may exceed
+const (
+	RateLimitScopeUser = "user"
+	RateLimitScopeChat = "chat"
+	// RateLimitScopeSlowMode is the per-member interval of a chat in slow mode
+	RateLimitScopeSlowMode = "slow_mode"
+)
+
+// RateLimitError is returned when a message is sent faster than allowed
+type RateLimitError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return "rate limited"
+}
+
+// RetryAfterSeconds rounds the wait up to whole seconds, as sent in Retry-After
+func (e *RateLimitError) RetryAfterSeconds() int {
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Bounds of a retention policy
+const (
+	MaxRetentionDays     = 10 * 365
+	MaxRetentionMessages = 1000000
+)
+
+// Retention run statuses
+const (
+	RetentionRunRunning   = "running"
+	RetentionRunCompleted = "completed"
+	RetentionRunFailed    = "failed"
+)
+
+// RetentionPolicy limits how long messages are kept. Messages older than Days
+// and messages beyond the latest MaxMessages are deleted; zero disables a limit.
+type RetentionPolicy struct {
+	Days        int `json:"days"`
+	MaxMessages int `json:"maxMessages"`
+}
+
+// ValidateRetentionPolicy checks the limits of a retention policy
+func ValidateRetentionPolicy(policy RetentionPolicy) error {
+	if policy.Days < 0 || policy.Days > MaxRetentionDays ||
+		policy.MaxMessages < 0 || policy.MaxMessages > MaxRetentionMessages {
+		return errors.New("invalid retention policy")
+	}
+	return nil
+}
+
+// IsZero reports whether the policy keeps messages forever
+func (p RetentionPolicy) IsZero() bool {
+	return p.Days == 0 && p.MaxMessages == 0
+}
+
+// Stricter combines two policies, keeping the tighter of each limit
+func (p RetentionPolicy) Stricter(other RetentionPolicy) RetentionPolicy {
+	return RetentionPolicy{
+		Days:        stricterLimit(p.Days, other.Days),
+		MaxMessages: stricterLimit(p.MaxMessages, other.MaxMessages),
+	}
+}
+
+func stricterLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// ChatRetention is the retention state of a chat read by the purge
+type ChatRetention struct {
+	ChatId               int  `db:"id"`
+	RetentionDays        int  `db:"retentionDays"`
+	RetentionMaxMessages int  `db:"retentionMaxMessages"`
+	LegalHold            bool `db:"legalHold"`
+}
+
+// Policy returns the chat's own retention policy
+func (c *ChatRetention) Policy() RetentionPolicy {
+	return RetentionPolicy{Days: c.RetentionDays, MaxMessages: c.RetentionMaxMessages}
+}
+
+// RetentionRun is the audit record of one pass of the retention purge
+type RetentionRun struct {
+	ID     int    `json:"runId" db:"id"`
+	Status string `json:"status" db:"status"`
+	// ChatCount counts the chats messages were deleted from
+	ChatCount int `json:"chatCount" db:"chatCount"`
+	// HeldChatCount counts the chats skipped for a legal hold
+	HeldChatCount int        `json:"heldChatCount" db:"heldChatCount"`
+	DeletedCount  int        `json:"deletedCount" db:"deletedCount"`
+	Error         *string    `json:"error,omitempty" db:"error"`
+	StartedAt     time.Time  `json:"startedAt" db:"startedAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty" db:"completedAt"`
+}
+
+// RetentionRunChat records the messages a run deleted from one chat and the
+// policy it applied
+type RetentionRunChat struct {
+	RunId        int `json:"-" db:"runId"`
+	ChatId       int `json:"chatId" db:"chatId"`
+	Days         int `json:"days" db:"days"`
+	MaxMessages  int `json:"maxMessages" db:"maxMessages"`
+	DeletedCount int `json:"deletedCount" db:"deletedCount"`
+	// The deleted messages span these IDs and creation times
+	FirstMessageId  int       `json:"firstMessageId" db:"firstMessageId"`
+	LastMessageId   int       `json:"lastMessageId" db:"lastMessageId"`
+	OldestCreatedAt time.Time `json:"oldestCreatedAt" db:"oldestCreatedAt"`
+	NewestCreatedAt time.Time `json:"newestCreatedAt" db:"newestCreatedAt"`
+}
+
+// LegalHold keeps every message of a chat from the retention purge
+type LegalHold struct {
+	ChatId    int       `json:"chatId" db:"chatId"`
+	Reason    string    `json:"reason" db:"reason"`
+	PlacedBy  int       `json:"placedBy" db:"placedBy"`
+	CreatedAt time.Time `json:"createdAt" db:"createdAt"`
+}
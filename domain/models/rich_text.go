@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Rich text block types
+const (
+	BlockParagraph = "paragraph"
+	BlockCode      = "code_block"
+	BlockQuote     = "quote"
+)
+
+// Rich text inline node types
+const (
+	NodeText      = "text"
+	NodeBold      = "bold"
+	NodeItalic    = "italic"
+	NodeCode      = "code"
+	NodeLink      = "link"
+	NodeSpoiler   = "spoiler"
+	NodeLineBreak = "line_break"
+)
+
+// SpoilerPlaceholder replaces spoiler text in the plain-text projection
+const SpoilerPlaceholder = "▒▒▒"
+
+// RichText is the parsed form of a message's Markdown. It only holds text and
+// vetted link URLs, so clients can render it without interpreting any HTML.
+type RichText []RichTextBlock
+
+// RichTextBlock is a paragraph, quote or code block
+type RichTextBlock struct {
+	Type     string         `json:"type"`
+	Language string         `json:"language,omitempty"`
+	Text     string         `json:"text,omitempty"`
+	Children []RichTextNode `json:"children,omitempty"`
+}
+
+// RichTextNode is an inline span of a paragraph or quote
+type RichTextNode struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	URL      string         `json:"url,omitempty"`
+	Children []RichTextNode `json:"children,omitempty"`
+}
+
+// PlainRichText wraps unformatted text in a single paragraph
+func PlainRichText(text string) RichText {
+	return RichText{{
+		Type:     BlockParagraph,
+		Children: []RichTextNode{{Type: NodeText, Text: text}},
+	}}
+}
+
+// PlainText projects the document to unformatted text for previews and search.
+// Spoilers are masked so previews do not reveal them.
+func (r RichText) PlainText() string {
+	lines := make([]string, len(r))
+	for i, block := range r {
+		if block.Type == BlockCode {
+			lines[i] = block.Text
+			continue
+		}
+		var sb strings.Builder
+		writePlainText(&sb, block.Children)
+		lines[i] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writePlainText(sb *strings.Builder, nodes []RichTextNode) {
+	for _, node := range nodes {
+		switch node.Type {
+		case NodeText, NodeCode:
+			sb.WriteString(node.Text)
+		case NodeLineBreak:
+			sb.WriteString("\n")
+		case NodeSpoiler:
+			sb.WriteString(SpoilerPlaceholder)
+		default:
+			writePlainText(sb, node.Children)
+		}
+	}
+}
+
+// Value stores the document as JSON
+func (r RichText) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan loads a document stored as JSON
+func (r *RichText) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	case []byte:
+		return json.Unmarshal(v, r)
+	default:
+		return fmt.Errorf("cannot scan %T into RichText", src)
+	}
+}
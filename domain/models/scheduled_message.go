@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Scheduled message statuses
+const (
+	ScheduledStatusPending     = "pending"
+	ScheduledStatusDispatching = "dispatching"
+	ScheduledStatusSent        = "sent"
+	ScheduledStatusFailed      = "failed"
+)
+
+type ScheduledMessage struct {
+	ID          int       `json:"id" db:"id"`
+	ChatId      int       `json:"chatId" db:"chatId"`
+	SenderId    int       `json:"senderId" db:"senderId"`
+	Content     string    `json:"content" db:"content"`
+	ScheduledAt time.Time `json:"scheduledAt" db:"scheduledAt"`
+	Status      string    `json:"status" db:"status"`
+	MessageId   *int      `json:"messageId,omitempty" db:"messageId"`
+	LastError   string    `json:"lastError,omitempty" db:"lastError"`
+	CreatedAt   time.Time `json:"createdAt" db:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updatedAt"`
+}
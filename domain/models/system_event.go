@@ -0,0 +1,141 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Message types
+const (
+	MessageTypeUser   = "user"
+	MessageTypeSystem = "system"
+	// Ephemeral messages are command replies shown only to the caller and never stored
+	MessageTypeEphemeral = "ephemeral"
+)
+
+// System message actions
+const (
+	SystemActionChatCreated         = "chat.created"
+	SystemActionChatRenamed         = "chat.renamed"
+	SystemActionTopicUpdated        = "topic.updated"
+	SystemActionMembersAdded        = "members.added"
+	SystemActionMemberRemoved       = "member.removed"
+	SystemActionMemberLeft          = "member.left"
+	SystemActionMemberRoleUpdated   = "member.role_updated"
+	SystemActionMessagePinned       = "message.pinned"
+	SystemActionMessageUnpinned     = "message.unpinned"
+	SystemActionDisappearingUpdated = "disappearing.updated"
+	SystemActionSlowModeUpdated     = "slow_mode.updated"
+	SystemActionRetentionUpdated    = "retention.updated"
+)
+
+// SystemEvent is the structured payload of a system message. Clients render it
+// from the action; the message content only holds a fallback summary.
+type SystemEvent struct {
+	Action  string            `json:"action"`
+	Actor   SystemEventUser   `json:"actor"`
+	Targets []SystemEventUser `json:"targets,omitempty"`
+
+	// Set depending on the action
+	ChatName     string `json:"chatName,omitempty"`
+	PreviousName string `json:"previousName,omitempty"`
+	Topic        string `json:"topic,omitempty"`
+	MessageId    int    `json:"messageId,omitempty"`
+	TTLSeconds   *int   `json:"ttlSeconds,omitempty"`
+	// SlowModeSeconds is set for slow_mode.updated, zero when turned off
+	SlowModeSeconds *int `json:"slowModeSeconds,omitempty"`
+	// Retention is set for retention.updated
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+	// Role is the new role of the target for member.role_updated
+	Role string `json:"role,omitempty"`
+}
+
+// SystemEventUser identifies a user taking part in a system event
+type SystemEventUser struct {
+	UserId   int    `json:"userId"`
+	Nickname string `json:"nickname"`
+}
+
+// Summary describes the event in a sentence, for clients that do not render
+// the action and for chat list previews
+func (e *SystemEvent) Summary() string {
+	actor := e.Actor.Nickname + "님이"
+	targets := make([]string, len(e.Targets))
+	for i, target := range e.Targets {
+		targets[i] = target.Nickname + "님"
+	}
+
+	switch e.Action {
+	case SystemActionChatCreated:
+		return fmt.Sprintf("%s 채팅방 '%s'을(를) 만들었습니다", actor, e.ChatName)
+	case SystemActionChatRenamed:
+		return fmt.Sprintf("%s 채팅방 이름을 '%s'(으)로 변경했습니다", actor, e.ChatName)
+	case SystemActionTopicUpdated:
+		if e.Topic == "" {
+			return fmt.Sprintf("%s 채팅방 주제를 지웠습니다", actor)
+		}
+		return fmt.Sprintf("%s 채팅방 주제를 '%s'(으)로 변경했습니다", actor, e.Topic)
+	case SystemActionMembersAdded:
+		return fmt.Sprintf("%s %s을(를) 초대했습니다", actor, strings.Join(targets, ", "))
+	case SystemActionMemberRemoved:
+		return fmt.Sprintf("%s %s을(를) 내보냈습니다", actor, strings.Join(targets, ", "))
+	case SystemActionMemberLeft:
+		return fmt.Sprintf("%s 나갔습니다", actor)
+	case SystemActionMemberRoleUpdated:
+		if e.Role == ChatRoleAdmin {
+			return fmt.Sprintf("%s %s을(를) 관리자로 지정했습니다", actor, strings.Join(targets, ", "))
+		}
+		return fmt.Sprintf("%s %s의 관리자 권한을 해제했습니다", actor, strings.Join(targets, ", "))
+	case SystemActionMessagePinned:
+		return fmt.Sprintf("%s 메시지를 고정했습니다", actor)
+	case SystemActionMessageUnpinned:
+		return fmt.Sprintf("%s 메시지 고정을 해제했습니다", actor)
+	case SystemActionDisappearingUpdated:
+		if e.TTLSeconds == nil || *e.TTLSeconds == 0 {
+			return fmt.Sprintf("%s 사라지는 메시지를 껐습니다", actor)
+		}
+		return fmt.Sprintf("%s 사라지는 메시지를 %d초로 설정했습니다", actor, *e.TTLSeconds)
+	case SystemActionSlowModeUpdated:
+		if e.SlowModeSeconds == nil || *e.SlowModeSeconds == 0 {
+			return fmt.Sprintf("%s 슬로우 모드를 껐습니다", actor)
+		}
+		return fmt.Sprintf("%s 슬로우 모드를 %d초로 설정했습니다", actor, *e.SlowModeSeconds)
+	case SystemActionRetentionUpdated:
+		if e.Retention == nil || e.Retention.IsZero() {
+			return fmt.Sprintf("%s 메시지 보관 기한을 없앴습니다", actor)
+		}
+		var limits []string
+		if e.Retention.Days > 0 {
+			limits = append(limits, fmt.Sprintf("%d일이 지난 메시지", e.Retention.Days))
+		}
+		if e.Retention.MaxMessages > 0 {
+			limits = append(limits, fmt.Sprintf("최근 %d개를 넘는 메시지", e.Retention.MaxMessages))
+		}
+		return fmt.Sprintf("%s %s를 삭제하도록 설정했습니다", actor, strings.Join(limits, "와 "))
+	default:
+		return ""
+	}
+}
+
+// Value stores the event as JSON
+func (e SystemEvent) Value() (driver.Value, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan loads an event stored as JSON
+func (e *SystemEvent) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	case []byte:
+		return json.Unmarshal(v, e)
+	default:
+		return fmt.Errorf("cannot scan %T into SystemEvent", src)
+	}
+}
@@ -12,6 +12,11 @@ type User struct {
 	Nickname  string    `json:"nickname" db:"nickname"`
 	Password  string    `json:"-" db:"password"` // "-" prevents password from being included in JSON
 	CreatedAt time.Time `json:"createdAt" db:"createdAt"`
+
+	// Bots sign in with an API token instead of a password and are managed by their owner
+	IsBot        bool    `json:"isBot" db:"isBot"`
+	OwnerId      *int    `json:"ownerId,omitempty" db:"ownerId"`
+	APITokenHash *string `json:"-" db:"apiTokenHash"`
 }
 
 // Validate performs validation on user fields
@@ -23,12 +28,8 @@ func (u *User) Validate() error {
 	}
 
 	// Nickname validation
-	if len(u.Nickname) < 2 || len(u.Nickname) > 20 {
-		return errors.New("nickname must be between 2 and 20 characters")
-	}
-	nicknameRegex := regexp.MustCompile(`^[a-zA-Z0-9가-힣_.]+$`)
-	if !nicknameRegex.MatchString(u.Nickname) {
-		return errors.New("nickname can only contain letters, numbers and underscores")
+	if err := ValidateNickname(u.Nickname); err != nil {
+		return err
 	}
 
 	// Password validation (performed before hashing)
@@ -38,3 +39,15 @@ func (u *User) Validate() error {
 
 	return nil
 }
+
+// ValidateNickname checks the nickname of a user or bot
+func ValidateNickname(nickname string) error {
+	if len(nickname) < 2 || len(nickname) > 20 {
+		return errors.New("nickname must be between 2 and 20 characters")
+	}
+	nicknameRegex := regexp.MustCompile(`^[a-zA-Z0-9가-힣_.]+$`)
+	if !nicknameRegex.MatchString(nickname) {
+		return errors.New("nickname can only contain letters, numbers and underscores")
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxBotNameLength bounds the name messages posted by a webhook are shown under
+const MaxBotNameLength = 80
+
+// IncomingWebhook lets an external system post into a chat with a secret token.
+// Only a hash of the token is stored.
+type IncomingWebhook struct {
+	ID         int        `json:"webhookId" db:"id"`
+	ChatId     int        `json:"chatId" db:"chatId"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  string     `json:"-" db:"tokenHash"`
+	CreatedBy  int        `json:"createdBy" db:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt" db:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" db:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revokedAt"`
+}
+
+// HashWebhookToken derives the stored form of a webhook token
+func HashWebhookToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateBotName checks the name of a webhook or the name a payload posts under
+func ValidateBotName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxBotNameLength {
+		return errors.New("invalid bot name")
+	}
+	return nil
+}
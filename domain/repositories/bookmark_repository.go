@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type BookmarkRepository interface {
+	Create(bookmark *models.Bookmark) error
+	FindById(id int) (*models.Bookmark, error)
+	FindByUserAndMessage(userId, messageId int) (*models.Bookmark, error)
+	// FindByUserId pages the user's bookmarks newest first, optionally for one chat
+	FindByUserId(userId, chatId, cursor, limit int) ([]models.Bookmark, error)
+	Update(bookmark *models.Bookmark) error
+	Delete(id int) error
+
+	FindDueReminders(now time.Time, limit int) ([]models.Bookmark, error)
+	// MarkReminded records a sent reminder, returning false if the reminder was changed or already sent
+	MarkReminded(bookmark *models.Bookmark, at time.Time) (bool, error)
+}
@@ -6,11 +6,21 @@ type ChatRepository interface {
 	Create(chat *models.Chat) error
 	FindById(id int) (*models.Chat, error)
 	Update(chat *models.Chat) error
+	UpdateMessageTTL(chatID int, seconds int) error
+	UpdateSlowMode(chatID int, seconds int) error
+	UpdateRetention(chatID int, policy models.RetentionPolicy) error
+	UpdateTopic(chatID int, topic string) error
 	Delete(id int) error
 
 	AddUserToChat(chatID, userID int) error
 	RemoveUserFromChat(chatID, userID int) error
+	GetUserRole(chatID, userID int) (string, error)
+	SetUserRole(chatID, userID int, role string) error
+	EnsureOwner(chatID int) error
 	GetChatUsers(chatID int) ([]models.User, error)
+	GetChatMembers(chatID int) ([]models.ChatMember, error)
 	GetUserChats(userID int) ([]models.Chat, error)
+	// GetEncryptedChatContacts returns the other members of the user's encrypted chats
+	GetEncryptedChatContacts(userID int) ([]models.User, error)
 	GetLastMessages(chatIDs []int) (map[int]*models.Message, error)
 }
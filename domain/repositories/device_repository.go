@@ -0,0 +1,19 @@
+package repositories
+
+import "github.com/f1rstid/realtime-chat/domain/models"
+
+type DeviceRepository interface {
+	// Create stores a device with its first one-time prekeys
+	Create(device *models.Device, prekeys []models.Prekey) error
+	FindById(id int) (*models.Device, error)
+	FindByUserId(userId int) ([]models.Device, error)
+	CountByUserId(userId int) (int, error)
+	// Delete removes a device and its remaining prekeys
+	Delete(id int) (bool, error)
+
+	// AddPrekeys stores more one-time prekeys; key IDs already stored are skipped
+	AddPrekeys(deviceId int, prekeys []models.Prekey) error
+	// ClaimPrekey hands out and deletes one prekey of a device, or returns nil
+	// when none are left
+	ClaimPrekey(deviceId int) (*models.Prekey, error)
+}
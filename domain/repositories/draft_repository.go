@@ -0,0 +1,9 @@
+package repositories
+
+import "github.com/f1rstid/realtime-chat/domain/models"
+
+type DraftRepository interface {
+	// Save stores the draft unless a newer one is stored; it returns the draft kept
+	Save(draft *models.Draft) (*models.Draft, bool, error)
+	FindByUserId(userId int) ([]models.Draft, error)
+}
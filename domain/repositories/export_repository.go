@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type ExportRepository interface {
+	Create(export *models.ChatExport) error
+	FindById(id int) (*models.ChatExport, error)
+	// FindByChatId returns the latest exports of a chat, newest first
+	FindByChatId(chatId int, limit int) ([]models.ChatExport, error)
+	// ClaimNext marks the oldest pending export running and returns it, or nil when none is pending
+	ClaimNext(at time.Time) (*models.ChatExport, error)
+	// Complete stores the file, counts and times of a finished export
+	Complete(export *models.ChatExport) error
+	Fail(id int, at time.Time) error
+	// ResetRunning queues again the exports interrupted by a restart
+	ResetRunning() error
+	// FindExpired returns completed exports whose file is past its expiry
+	FindExpired(now time.Time, limit int) ([]models.ChatExport, error)
+	MarkExpired(id int) error
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type ImportRepository interface {
+	Create(chatImport *models.ChatImport) error
+	FindById(id int) (*models.ChatImport, error)
+	// FindUnfinishedByArchive returns the latest import of an archive that did
+	// not complete, or nil when there is none
+	FindUnfinishedByArchive(archivePath string) (*models.ChatImport, error)
+	// ClaimNext marks the oldest pending import running and returns it, or nil when none is pending
+	ClaimNext(at time.Time) (*models.ChatImport, error)
+	// Claim marks an import running unless it is running or completed
+	Claim(id int, at time.Time) (bool, error)
+	// Requeue marks a failed import pending again
+	Requeue(id int) (bool, error)
+	UpdateCounts(chatImport *models.ChatImport) error
+	Complete(chatImport *models.ChatImport) error
+	Fail(id int, at time.Time, message string) error
+	// ResetRunning queues again the imports interrupted by a restart
+	ResetRunning() error
+
+	// FindMapping returns the ID of the user or chat created or matched for a
+	// source object, or sql.ErrNoRows when it was not imported yet
+	FindMapping(kind, sourceId string) (int, error)
+	SaveMapping(kind, sourceId string, targetId, importId int) error
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import "github.com/f1rstid/realtime-chat/domain/models"
+
+type LinkPreviewRepository interface {
+	// FindByURL and Save access the preview cache of a chat, which is shared
+	// by all chats unless messages are encrypted
+	FindByURL(chatId int, url string) (*models.LinkPreview, error)
+	Save(chatId int, preview *models.LinkPreview) error
+	SetMessageLinks(chatId, messageId int, urls []string) error
+	FindByMessageIds(messageIds []int) (map[int][]models.LinkPreview, error)
+}
@@ -1,12 +1,37 @@
 package repositories
 
-import "github.com/f1rstid/realtime-chat/domain/models"
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
 
 type MessageRepository interface {
 	Create(message *models.Message) error
 	FindById(id int) (*models.Message, error)
+	FindByIds(ids []int) ([]models.Message, error)
+	FindByScheduledMessageId(scheduledMessageId int) (*models.Message, error)
+	FindByClientMessageId(senderId int, clientMessageId string) (*models.Message, error)
 	Update(message *models.Message) error
 	Delete(id int) error
 	FindByChatId(chatId int, cursor int, limit int) ([]models.Message, error)
+	FindAfterId(chatId int, cursor int, limit int) ([]models.Message, error)
+	// StreamByChatId calls fn for every message of a chat, oldest first,
+	// without loading them all at once. It stops at the first error of fn.
+	StreamByChatId(chatId int, fn func(*models.Message) error) error
+	FindFirstIdAt(chatId int, at time.Time) (int, error)
 	GetLastMessageId(chatId int) (int, error)
+	FindExpired(now time.Time, limit int) ([]models.Message, error)
+	// DeleteByIds deletes messages and the rows referring to them
+	DeleteByIds(ids []int) error
+	// FindRetentionCutoffId returns the ID of the keep-th newest message of a
+	// chat, or zero when the chat has fewer messages
+	FindRetentionCutoffId(chatId int, keep int) (int, error)
+	// FindPurgeable returns the oldest messages of a chat created before the
+	// given time or with an ID below belowId. A nil time or zero ID disables
+	// that condition.
+	FindPurgeable(chatId int, before *time.Time, belowId int, limit int) ([]models.Message, error)
+	// Search returns the messages matching a search, newest first. A search
+	// stopping before the page is full returns a nonzero cursor to continue from.
+	Search(filter models.MessageSearchFilter) ([]models.MessageSearchResult, int, error)
 }
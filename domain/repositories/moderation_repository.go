@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type ModerationRepository interface {
+	CreateFlag(flag *models.ModerationFlag) error
+	FindFlagById(id int) (*models.ModerationFlag, error)
+	// FindFlags returns flags of the chats the reviewer owns or administers,
+	// newest first. A zero chatId covers all of them.
+	FindFlags(reviewerId, chatId int, status string, cursor, limit int) ([]models.ModerationFlag, error)
+	// ResolveFlag reviews a pending flag. It returns false when the flag was already reviewed.
+	ResolveFlag(id int, status string, reviewerId int, at time.Time) (bool, error)
+	// ResolveMessageFlags reviews every pending flag of a message
+	ResolveMessageFlags(messageId int, status string, reviewerId int, at time.Time) error
+}
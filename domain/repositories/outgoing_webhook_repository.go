@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type OutgoingWebhookRepository interface {
+	Create(webhook *models.OutgoingWebhook) error
+	FindById(id int) (*models.OutgoingWebhook, error)
+	FindByOwnerId(ownerId int) ([]models.OutgoingWebhook, error)
+	// FindSubscribers returns the webhooks receiving the events of a chat,
+	// limited to those whose owner is still a member of it, and still
+	// manages it for webhooks of that chat only
+	FindSubscribers(chatId int) ([]models.OutgoingWebhook, error)
+	// Delete removes the webhook with its deliveries
+	Delete(id int) error
+
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	FindDeliveryById(id int) (*models.WebhookDelivery, error)
+	FindDeliveries(webhookId int, status string, cursor, limit int) ([]models.WebhookDelivery, error)
+	FindDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+	// RecordAttempt stores the outcome of a delivery attempt
+	RecordAttempt(delivery *models.WebhookDelivery) error
+	// Requeue makes a delivery pending again with no attempts made
+	Requeue(id int, at time.Time) error
+}
@@ -0,0 +1,11 @@
+package repositories
+
+import "github.com/f1rstid/realtime-chat/domain/models"
+
+type PinRepository interface {
+	Create(pin *models.PinnedMessage) error
+	Delete(chatId, messageId int) error
+	Exists(chatId, messageId int) (bool, error)
+	CountByChatId(chatId int) (int, error)
+	FindByChatId(chatId int) ([]models.PinnedMessage, error)
+}
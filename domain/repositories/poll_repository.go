@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type PollRepository interface {
+	// Create stores the poll with its options and sets their IDs
+	Create(poll *models.Poll) error
+	FindById(id int) (*models.Poll, error)
+	FindByMessageIds(messageIds []int) ([]models.Poll, error)
+	FindVotes(pollIds []int) ([]models.PollVote, error)
+	// ReplaceVotes replaces the user's votes on a poll; no options retracts them
+	ReplaceVotes(pollId, userId int, optionIds []int, at time.Time) error
+	Close(id int, at time.Time) (bool, error)
+}
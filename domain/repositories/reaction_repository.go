@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type ReactionRepository interface {
+	// Add records a reaction; adding the same reaction again does nothing
+	Add(messageId, userId int, emoji string, at time.Time) error
+	// Summarize counts the reactions to each message, flagging the viewer's own
+	Summarize(messageIds []int, viewerId int) ([]models.Reaction, error)
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type ReceiptRepository interface {
+	CreateForMessage(messageId int, recipientIds []int) error
+	// MarkDelivered and MarkReadUpTo return the IDs of the messages whose status changed
+	MarkDelivered(userId int, messageIds []int, at time.Time) ([]int, error)
+	MarkReadUpTo(userId, chatId, messageId int, at time.Time) ([]int, error)
+	Summarize(messageIds []int) ([]models.DeliverySummary, error)
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type RetentionRepository interface {
+	// FindChats returns the retention state of the chats with an ID above
+	// afterId, in ID order
+	FindChats(afterId int, limit int) ([]models.ChatRetention, error)
+
+	FindLegalHold(chatId int) (*models.LegalHold, error)
+	FindLegalHolds() ([]models.LegalHold, error)
+	// PlaceLegalHold creates the hold of a chat or replaces its reason
+	PlaceLegalHold(hold *models.LegalHold) error
+	ReleaseLegalHold(chatId int) (bool, error)
+
+	CreateRun(run *models.RetentionRun) error
+	// FinishRun stores the counts, status and end of a run
+	FinishRun(run *models.RetentionRun) error
+	// FailInterrupted marks the runs left running by a restart failed
+	FailInterrupted(at time.Time) error
+	FindRunById(id int) (*models.RetentionRun, error)
+	// FindRuns returns the latest runs, newest first
+	FindRuns(limit int) ([]models.RetentionRun, error)
+	// SaveRunChat records or updates what a run deleted from a chat so far
+	SaveRunChat(entry *models.RetentionRunChat) error
+	FindRunChats(runId int) ([]models.RetentionRunChat, error)
+}
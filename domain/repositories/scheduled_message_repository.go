@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type ScheduledMessageRepository interface {
+	Create(scheduled *models.ScheduledMessage) error
+	FindById(id int) (*models.ScheduledMessage, error)
+	FindPendingBySenderId(senderId int, chatId int) ([]models.ScheduledMessage, error)
+	// UpdatePending changes a scheduled message that has not been dispatched yet
+	UpdatePending(scheduled *models.ScheduledMessage) (bool, error)
+	DeletePending(id int) (bool, error)
+
+	FindDue(now time.Time, limit int) ([]models.ScheduledMessage, error)
+	// Claim moves a pending message to dispatching, returning false if another dispatcher got it first
+	Claim(id int) (bool, error)
+	MarkSent(id int, messageId *int) error
+	MarkFailed(id int, reason string) error
+	Release(id int) error
+	// ReleaseDispatching returns messages left dispatching by a previous run to pending
+	ReleaseDispatching() (int64, error)
+}
@@ -9,5 +9,10 @@ type UserRepository interface {
 	FindByNickname(nickname string) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id int) error
-	FindAllExcept(excludeUserId int) ([]models.User, error)
+	// FindAllExcept lists the other users; bots are only listed when includeBots is set
+	FindAllExcept(excludeUserId int, includeBots bool) ([]models.User, error)
+
+	FindBotsByOwnerId(ownerId int) ([]models.User, error)
+	FindBotByTokenHash(tokenHash string) (*models.User, error)
+	UpdateAPITokenHash(id int, tokenHash string) error
 }
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+type WebhookRepository interface {
+	Create(webhook *models.IncomingWebhook) error
+	FindById(id int) (*models.IncomingWebhook, error)
+	// FindActiveByTokenHash returns the webhook holding the token unless it was revoked
+	FindActiveByTokenHash(tokenHash string) (*models.IncomingWebhook, error)
+	FindActiveByChatId(chatId int) ([]models.IncomingWebhook, error)
+	Revoke(id int, at time.Time) (bool, error)
+	TouchLastUsed(id int, at time.Time) error
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"io"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// TranscriptWriter writes a chat transcript in one export format. Begin is
+// called first, WriteMessage once per message in order and End last.
+// Writers must not keep the messages, so transcripts of any length stream.
+type TranscriptWriter interface {
+	Begin(header *models.TranscriptHeader) error
+	WriteMessage(message *models.Message) error
+	End() error
+}
+
+// ExportStore keeps the files of finished chat exports
+type ExportStore interface {
+	Create(name string) (io.WriteCloser, error)
+	// Open returns the file and its size
+	Open(name string) (io.ReadCloser, int64, error)
+	Remove(name string) error
+}
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// LinkPreviewFetcher retrieves the preview metadata of a URL
+type LinkPreviewFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*models.LinkPreview, error)
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// ModerationFilter inspects a message before it is stored
+type ModerationFilter interface {
+	// Name identifies the filter in review reasons
+	Name() string
+	// Moderate returns nil to allow the message unchanged
+	Moderate(ctx context.Context, input models.ModerationInput) (*models.ModerationVerdict, error)
+}
+
+// ContentClassifier scores text with an external model
+type ContentClassifier interface {
+	// Classify returns a score between 0 and 1 per category, such as "hate" or "spam"
+	Classify(ctx context.Context, text string) (map[string]float64, error)
+}
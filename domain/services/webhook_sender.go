@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+	"net/http"
+)
+
+// WebhookSender posts event payloads to outgoing webhook endpoints
+type WebhookSender interface {
+	// Send posts body to rawURL and returns the response status code. Any
+	// status other than 2xx is returned together with an error.
+	Send(ctx context.Context, rawURL string, header http.Header, body []byte) (int, error)
+}
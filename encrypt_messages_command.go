@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/f1rstid/realtime-chat/config"
+)
+
+// encryptMessagesCommand is the subcommand encrypting the messages stored
+// before a master key was configured
+const encryptMessagesCommand = "encrypt-messages"
+
+// runEncryptMessagesCommand seals the message content, drafts, scheduled
+// messages, moderation flags, webhook payloads, polls and link previews still
+// stored in plaintext:
+//
+//	ENCRYPTION_KEY_FILE=master.key realtime-chat encrypt-messages
+//
+// Run it while the server is stopped after setting a key for the first time.
+// Rows already sealed are skipped, so it can be run again safely.
+func runEncryptMessagesCommand(config *config.Config, args []string) error {
+	flags := flag.NewFlagSet(encryptMessagesCommand, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s\n", os.Args[0], encryptMessagesCommand)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	contentCipher, err := newContentCipher(config)
+	if err != nil {
+		return err
+	}
+	counts, err := contentCipher.EncryptExisting()
+	if err != nil {
+		return err
+	}
+
+	tables := make([]string, 0, len(counts))
+	for table := range counts {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Printf("Encrypted %d rows in %s\n", counts[table], table)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/config"
+	"github.com/f1rstid/realtime-chat/infrastructure/sqlite"
+	"github.com/f1rstid/realtime-chat/interfaces/repositories"
+)
+
+// importCommand is the subcommand importing a Slack export archive
+const importCommand = "import-slack"
+
+// runImportCommand imports a Slack export archive in the foreground:
+//
+//	realtime-chat import-slack -as admin@example.com slack-export.zip
+//
+// Running it again with the same archive resumes an interrupted import.
+func runImportCommand(config *config.Config, args []string) error {
+	flags := flag.NewFlagSet(importCommand, flag.ExitOnError)
+	as := flags.String("as", "", "email of the user importing; messages of Slack integrations are stored as this user")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s -as EMAIL ARCHIVE\n", os.Args[0], importCommand)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *as == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("an importing user and one archive are required")
+	}
+
+	contentCipher, err := newContentCipher(config)
+	if err != nil {
+		return err
+	}
+
+	userRepo := repositories.NewUserRepository(sqlite.DB)
+	user, err := userRepo.FindByEmail(strings.ToLower(strings.TrimSpace(*as)))
+	if err != nil {
+		return fmt.Errorf("user %s not found", *as)
+	}
+
+	importUseCase := usecase.NewImportUsecase(
+		repositories.NewImportRepository(sqlite.DB),
+		userRepo,
+		repositories.NewChatRepository(sqlite.DB, contentCipher),
+		repositories.NewMessageRepository(sqlite.DB, contentCipher),
+		repositories.NewReactionRepository(sqlite.DB),
+		config.ImportDir,
+		config.AdminEmails,
+	)
+	chatImport, err := importUseCase.ImportArchive(flags.Arg(0), user.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Import %d completed: %d users, %d chats and %d messages created\n",
+		chatImport.ID, chatImport.UserCount, chatImport.ChatCount, chatImport.MessageCount)
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package encryption seals values stored in the database with AES-256-GCM.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySize is the size in bytes of master and data keys
+const KeySize = 32
+
+// lookupPrefix marks values returned by Lookup
+const lookupPrefix = "mac:v1:"
+
+// sealedPrefix marks sealed values, so they can be told from values stored
+// before encryption was turned on. The rest is base64 of nonce and ciphertext.
+const sealedPrefix = "enc:v1:"
+
+// ErrInvalidKey is returned for keys that are not base64 of KeySize bytes
+var ErrInvalidKey = errors.New("invalid encryption key")
+
+// LoadMasterKey reads the master key from the config value or, when it is
+// empty, from the key file. It returns nil when neither is set.
+func LoadMasterKey(key, keyFile string) ([]byte, error) {
+	if key != "" && keyFile != "" {
+		return nil, errors.New("set either an encryption key or a key file, not both")
+	}
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		key = string(data)
+	}
+	if key == "" {
+		return nil, nil
+	}
+	return ParseKey(key)
+}
+
+// ParseKey decodes a base64 key, e.g. the output of `openssl rand -base64 32`
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// GenerateKey returns a new random key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// KeyID identifies a key without revealing it, so stored values can record
+// which master key wrapped them
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// IsSealed reports whether a stored value was sealed
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal encrypts plaintext with the key. additionalData is authenticated but
+// not stored; the same data must be given to Open.
+func Seal(key, plaintext, additionalData []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, additionalData)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal
+func Open(key []byte, value string, additionalData []byte) ([]byte, error) {
+	if !IsSealed(value) {
+		return nil, errors.New("value is not sealed")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed sealed value: %w", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed sealed value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// Lookup returns a keyed digest of value, so rows can be found by a value
+// that is stored sealed. The same key and value always give the same digest.
+func Lookup(key []byte, value string) string {
+	// Derived so the data key is not used directly for both sealing and MACs
+	subkey := hmac.New(sha256.New, key)
+	subkey.Write([]byte("lookup"))
+
+	mac := hmac.New(sha256.New, subkey.Sum(nil))
+	mac.Write([]byte(value))
+	return lookupPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsLookup reports whether a stored value is a digest returned by Lookup
+func IsLookup(value string) bool {
+	return strings.HasPrefix(value, lookupPrefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,136 @@
+// infrastructure/export/csv_writer.go
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// utf8BOM lets spreadsheet applications detect the encoding of Korean text
+const utf8BOM = "\ufeff"
+
+var memberColumns = []string{"userId", "nickname", "role", "isBot", "joinedAt"}
+
+var messageColumns = []string{
+	"messageId", "createdAt", "editedAt", "senderId", "senderNickname", "type", "content",
+	"forwardedFromMessageId", "forwardedFromNickname", "expiresAt", "attachments",
+}
+
+// csvWriter writes a zip archive of members.csv and messages.csv. The members
+// are written first, so the messages stream into the last entry of the archive.
+type csvWriter struct {
+	archive  *zip.Writer
+	messages *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{archive: zip.NewWriter(w)}
+}
+
+func (cw *csvWriter) Begin(header *models.TranscriptHeader) error {
+	members, err := cw.createCSV("members.csv", header.ExportedAt, memberColumns)
+	if err != nil {
+		return err
+	}
+	for _, member := range header.Members {
+		members.Write([]string{
+			strconv.Itoa(member.UserId),
+			csvText(member.Nickname),
+			member.Role,
+			strconv.FormatBool(member.IsBot),
+			csvTime(&member.JoinedAt),
+		})
+	}
+	members.Flush()
+	if err := members.Error(); err != nil {
+		return err
+	}
+
+	cw.messages, err = cw.createCSV("messages.csv", header.ExportedAt, messageColumns)
+	return err
+}
+
+func (cw *csvWriter) WriteMessage(message *models.Message) error {
+	record := newMessageRecord(message)
+
+	var forwardedFromMessageID, forwardedFromNickname string
+	if record.ForwardedFrom != nil {
+		forwardedFromMessageID = strconv.Itoa(record.ForwardedFrom.MessageID)
+		forwardedFromNickname = csvText(record.ForwardedFrom.SenderNickname)
+	}
+
+	var attachments string
+	if len(record.Attachments) > 0 {
+		data, err := json.Marshal(record.Attachments)
+		if err != nil {
+			return err
+		}
+		attachments = string(data)
+	}
+
+	return cw.messages.Write([]string{
+		strconv.Itoa(record.MessageID),
+		csvTime(&record.CreatedAt),
+		csvTime(record.EditedAt),
+		strconv.Itoa(record.SenderID),
+		csvText(record.SenderNickname),
+		record.Type,
+		csvText(record.Content),
+		forwardedFromMessageID,
+		forwardedFromNickname,
+		csvTime(record.ExpiresAt),
+		attachments,
+	})
+}
+
+func (cw *csvWriter) End() error {
+	cw.messages.Flush()
+	if err := cw.messages.Error(); err != nil {
+		return err
+	}
+	return cw.archive.Close()
+}
+
+// createCSV starts an entry of the archive and writes its header row
+func (cw *csvWriter) createCSV(name string, modified time.Time, columns []string) (*csv.Writer, error) {
+	entry, err := cw.archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(entry, utf8BOM); err != nil {
+		return nil, err
+	}
+
+	w := csv.NewWriter(entry)
+	if err := w.Write(columns); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// csvText keeps spreadsheet applications from running text written by users
+// as a formula
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,104 @@
+// infrastructure/export/html_writer.go
+package export
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// htmlTemplates render a transcript page in three parts, so messages are
+// written as they are read. The page has no scripts and loads nothing, so it
+// can be archived and opened offline; card images are linked, not embedded.
+var htmlTemplates = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+}).Parse(`
+{{define "begin"}}<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'">
+<title>{{.Chat.Name}} - 대화 내보내기</title>
+<style>
+body { font-family: -apple-system, "Apple SD Gothic Neo", "Malgun Gothic", sans-serif; max-width: 860px; margin: 0 auto; padding: 24px; color: #1f2328; }
+header { border-bottom: 1px solid #d0d7de; margin-bottom: 16px; }
+h1 { font-size: 22px; margin: 0 0 4px; }
+.meta, .time, .note { color: #656d76; font-size: 12px; }
+details { margin: 12px 0; }
+table { border-collapse: collapse; font-size: 13px; }
+td, th { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; }
+.message { padding: 8px 0; border-bottom: 1px solid #f0f0f0; }
+.sender { font-weight: 600; margin-right: 6px; }
+.content { white-space: pre-wrap; word-break: break-word; margin-top: 2px; }
+.system { text-align: center; color: #656d76; font-size: 13px; }
+.card { border-left: 4px solid #d0d7de; padding: 4px 10px; margin-top: 6px; font-size: 13px; }
+.card .title { font-weight: 600; }
+.card dl { margin: 4px 0; }
+.card dt { font-weight: 600; }
+.card dd { margin: 0 0 4px; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Chat.Name}}</h1>
+{{with .Chat.Topic}}<p>{{.}}</p>{{end}}
+<p class="meta">채팅방 ID {{.Chat.ID}} · 내보낸 시각 {{time .ExportedAt}} · 내보내기 ID {{.ExportID}}</p>
+<details>
+<summary>멤버 {{len .Members}}명</summary>
+<table>
+<tr><th>닉네임</th><th>역할</th><th>참여 시각</th></tr>
+{{range .Members}}<tr><td>{{.Nickname}}{{if .IsBot}} (봇){{end}}</td><td>{{.Role}}</td><td>{{time .JoinedAt}}</td></tr>
+{{end}}</table>
+</details>
+</header>
+<main>
+{{end}}
+
+{{define "message"}}{{if eq .Type "system"}}<div class="message system" id="m{{.MessageID}}">{{.Content}} <span class="time">{{time .CreatedAt}}</span></div>
+{{else}}<div class="message" id="m{{.MessageID}}">
+<span class="sender">{{.SenderNickname}}</span><span class="time">{{time .CreatedAt}}{{with .EditedAt}} (수정됨 {{time .}}){{end}}</span>
+{{with .ForwardedFrom}}<div class="note">{{.SenderNickname}}님의 메시지를 전달함</div>{{end}}
+<div class="content">{{.Content}}</div>
+{{range .Attachments}}<div class="card"{{with .Color}} style="border-left-color: {{.}}"{{end}}>
+{{with .Title}}<div class="title">{{.}}</div>{{end}}
+{{with .TitleURL}}<div><a href="{{.}}">{{.}}</a></div>{{end}}
+{{with .Text}}<div class="content">{{.}}</div>{{end}}
+{{with .Fields}}<dl>{{range .}}<dt>{{.Title}}</dt><dd>{{.Value}}</dd>{{end}}</dl>{{end}}
+{{with .ImageURL}}<div>이미지: <a href="{{.}}">{{.}}</a></div>{{end}}
+{{with .Footer}}<div class="note">{{.}}</div>{{end}}
+</div>
+{{end}}</div>
+{{end}}{{end}}
+
+{{define "end"}}</main>
+<p class="meta">메시지 {{.}}개</p>
+</body>
+</html>
+{{end}}
+`))
+
+// htmlWriter writes the transcript as a self-contained HTML page
+type htmlWriter struct {
+	w        io.Writer
+	messages int
+}
+
+func newHTMLWriter(w io.Writer) *htmlWriter {
+	return &htmlWriter{w: w}
+}
+
+func (hw *htmlWriter) Begin(header *models.TranscriptHeader) error {
+	return htmlTemplates.ExecuteTemplate(hw.w, "begin", header)
+}
+
+func (hw *htmlWriter) WriteMessage(message *models.Message) error {
+	hw.messages++
+	return htmlTemplates.ExecuteTemplate(hw.w, "message", newMessageRecord(message))
+}
+
+func (hw *htmlWriter) End() error {
+	return htmlTemplates.ExecuteTemplate(hw.w, "end", hw.messages)
+}
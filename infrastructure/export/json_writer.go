@@ -0,0 +1,61 @@
+// infrastructure/export/json_writer.go
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// jsonWriter writes the transcript as one JSON document. Messages are encoded
+// one at a time into the "messages" array, which is written last.
+type jsonWriter struct {
+	w        io.Writer
+	messages int
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: w}
+}
+
+func (jw *jsonWriter) Begin(header *models.TranscriptHeader) error {
+	head, err := json.Marshal(struct {
+		ExportID   int                 `json:"exportId"`
+		ExportedAt time.Time           `json:"exportedAt"`
+		Chat       chatRecord          `json:"chat"`
+		Members    []models.ChatMember `json:"members"`
+	}{header.ExportID, header.ExportedAt, newChatRecord(header.Chat), header.Members})
+	if err != nil {
+		return err
+	}
+
+	// Reopen the object to append the messages
+	_, err = fmt.Fprintf(jw.w, "%s,\"messages\":[", head[:len(head)-1])
+	return err
+}
+
+func (jw *jsonWriter) WriteMessage(message *models.Message) error {
+	data, err := json.Marshal(newMessageRecord(message))
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n"
+	if jw.messages == 0 {
+		separator = "\n"
+	}
+	jw.messages++
+	if _, err := io.WriteString(jw.w, separator); err != nil {
+		return err
+	}
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonWriter) End() error {
+	_, err := io.WriteString(jw.w, "\n]}\n")
+	return err
+}
@@ -0,0 +1,69 @@
+// infrastructure/export/store.go
+package export
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/f1rstid/realtime-chat/domain/services"
+)
+
+// FileStore keeps export files in a local directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates the directory if needed. Transcripts are private, so
+// it is only readable by the server's user.
+func NewFileStore(dir string) (services.ExportStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Create(name string) (io.WriteCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+}
+
+func (s *FileStore) Open(name string) (io.ReadCloser, int64, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+func (s *FileStore) Remove(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path keeps names inside the store's directory
+func (s *FileStore) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) {
+		return "", errors.New("invalid export file name")
+	}
+	return filepath.Join(s.dir, name), nil
+}
@@ -0,0 +1,87 @@
+// infrastructure/export/writer.go
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/services"
+)
+
+// NewTranscriptWriter returns the writer of an export format
+func NewTranscriptWriter(format string, w io.Writer) (services.TranscriptWriter, error) {
+	switch format {
+	case models.ExportFormatJSON:
+		return newJSONWriter(w), nil
+	case models.ExportFormatCSV:
+		return newCSVWriter(w), nil
+	case models.ExportFormatHTML:
+		return newHTMLWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// chatRecord is the chat as written to transcripts
+type chatRecord struct {
+	ChatID    int       `json:"chatId"`
+	Name      string    `json:"name"`
+	Topic     string    `json:"topic,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newChatRecord(chat *models.Chat) chatRecord {
+	return chatRecord{
+		ChatID:    chat.ID,
+		Name:      chat.Name,
+		Topic:     chat.Topic,
+		CreatedAt: chat.CreatedAt,
+	}
+}
+
+// messageRecord is a message as written to transcripts. Only the last edit
+// is known, so edits are exported as the time the message last changed.
+type messageRecord struct {
+	MessageID      int                        `json:"messageId"`
+	SenderID       int                        `json:"senderId"`
+	SenderNickname string                     `json:"senderNickname"`
+	Type           string                     `json:"type"`
+	Content        string                     `json:"content"`
+	SystemEvent    *models.SystemEvent        `json:"systemEvent,omitempty"`
+	CreatedAt      time.Time                  `json:"createdAt"`
+	EditedAt       *time.Time                 `json:"editedAt,omitempty"`
+	ExpiresAt      *time.Time                 `json:"expiresAt,omitempty"`
+	ForwardedFrom  *models.MessageOrigin      `json:"forwardedFrom,omitempty"`
+	Attachments    []models.MessageAttachment `json:"attachments,omitempty"`
+	// Encrypted messages are exported as their envelope, which only the
+	// members' devices can decrypt
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+func newMessageRecord(message *models.Message) *messageRecord {
+	record := &messageRecord{
+		MessageID:      message.ID,
+		SenderID:       message.SenderId,
+		SenderNickname: message.SenderNickname,
+		Type:           message.Type,
+		Content:        message.Content,
+		SystemEvent:    message.SystemEvent,
+		CreatedAt:      message.CreatedAt,
+		ExpiresAt:      message.ExpiresAt,
+		ForwardedFrom:  message.Origin(),
+		Encrypted:      message.Encrypted,
+	}
+	if message.UpdatedAt.After(message.CreatedAt) {
+		editedAt := message.UpdatedAt
+		record.EditedAt = &editedAt
+	}
+
+	// The parsed Markdown of cards is only needed to display them in the app
+	for _, attachment := range message.Attachments {
+		attachment.Formatted = nil
+		record.Attachments = append(record.Attachments, attachment)
+	}
+	return record
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"testing"
 	"time"
 )
 
@@ -15,6 +16,9 @@ var (
 	infoLogger    *log.Logger
 	errorLogger   *log.Logger
 	requestLogger *log.Logger
+
+	// logFiles are the files the loggers write to besides the console
+	logFiles []*os.File
 )
 
 const (
@@ -47,55 +51,69 @@ func getLogPrefix(level string) string {
 }
 
 func init() {
-	// 로그 파일 디렉토리 생성
-	logDir := "logs"
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		log.Fatal("Failed to create log directory:", err)
+	// 테스트 실행 중에는 패키지 디렉토리에 로그 파일을 만들지 않음
+	if testing.Testing() {
+		SetDir("")
+		return
 	}
-
-	// 현재 날짜로 로그 파일 생성
-	currentTime := time.Now()
-	date := currentTime.Format("2006-01-02")
-
-	// 일반 로그 파일
-	infoLogFile, err := os.OpenFile(
-		filepath.Join(logDir, fmt.Sprintf("app_%s.log", date)),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		0644,
-	)
-	if err != nil {
-		log.Fatal("Failed to open info log file:", err)
-	}
-
-	// 에러 로그 파일
-	errorLogFile, err := os.OpenFile(
-		filepath.Join(logDir, fmt.Sprintf("error_%s.log", date)),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		0644,
-	)
-	if err != nil {
-		log.Fatal("Failed to open error log file:", err)
+	if err := SetDir("logs"); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	// HTTP 요청 로그 파일
-	requestLogFile, err := os.OpenFile(
-		filepath.Join(logDir, fmt.Sprintf("request_%s.log", date)),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		0644,
-	)
-	if err != nil {
-		log.Fatal("Failed to open request log file:", err)
+// SetDir writes the logs to dated files in logDir besides the console,
+// replacing the files used so far. An empty logDir logs to the console only.
+func SetDir(logDir string) error {
+	var files []*os.File
+	if logDir != "" {
+		// 로그 파일 디렉토리 생성
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+
+		// 현재 날짜로 일반, 에러, HTTP 요청 로그 파일 생성
+		date := time.Now().Format("2006-01-02")
+		for _, name := range []string{"app", "error", "request"} {
+			file, err := os.OpenFile(
+				filepath.Join(logDir, fmt.Sprintf("%s_%s.log", name, date)),
+				os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+				0644,
+			)
+			if err != nil {
+				for _, file := range files {
+					file.Close()
+				}
+				return fmt.Errorf("failed to open %s log file: %w", name, err)
+			}
+			files = append(files, file)
+		}
 	}
 
 	// MultiWriter를 사용하여 파일과 콘솔에 동시에 출력
-	infoWriter := io.MultiWriter(os.Stdout, infoLogFile)
-	errorWriter := io.MultiWriter(os.Stdout, errorLogFile)
-	requestWriter := io.MultiWriter(os.Stdout, requestLogFile)
+	writers := make([]io.Writer, 3)
+	for i := range writers {
+		writers[i] = os.Stdout
+		if files != nil {
+			writers[i] = io.MultiWriter(os.Stdout, files[i])
+		}
+	}
 
 	// 로거 초기화
-	infoLogger = log.New(infoWriter, "", 0)
-	errorLogger = log.New(errorWriter, "", 0)
-	requestLogger = log.New(requestWriter, "", 0)
+	if infoLogger == nil {
+		infoLogger = log.New(writers[0], "", 0)
+		errorLogger = log.New(writers[1], "", 0)
+		requestLogger = log.New(writers[2], "", 0)
+	} else {
+		infoLogger.SetOutput(writers[0])
+		errorLogger.SetOutput(writers[1])
+		requestLogger.SetOutput(writers[2])
+	}
+
+	for _, file := range logFiles {
+		file.Close()
+	}
+	logFiles = files
+	return nil
 }
 
 // formatLog formats the log message with file info and color
@@ -0,0 +1,145 @@
+// infrastructure/moderation/classifier_filter.go
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/services"
+)
+
+// ClassifierConfig configures the external classifier
+type ClassifierConfig struct {
+	// URL receives {"text": "..."} and answers {"categories": {"<name>": <0..1>}}
+	URL string `json:"url"`
+	// Timeout in milliseconds; 3 seconds by default
+	TimeoutMs int `json:"timeoutMs,omitempty"`
+	// Scores at or above FlagThreshold flag the message, at or above
+	// RejectThreshold reject it. A zero threshold is disabled.
+	FlagThreshold   float64 `json:"flagThreshold,omitempty"`
+	RejectThreshold float64 `json:"rejectThreshold,omitempty"`
+	// Categories limits the scores considered; all categories by default
+	Categories []string `json:"categories,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+}
+
+// ClassifierFilter turns the scores of a ContentClassifier into a verdict
+type ClassifierFilter struct {
+	classifier      services.ContentClassifier
+	flagThreshold   float64
+	rejectThreshold float64
+	categories      map[string]bool
+	reason          string
+}
+
+func NewClassifierFilter(classifier services.ContentClassifier, config ClassifierConfig) *ClassifierFilter {
+	filter := &ClassifierFilter{
+		classifier:      classifier,
+		flagThreshold:   config.FlagThreshold,
+		rejectThreshold: config.RejectThreshold,
+		reason:          config.Reason,
+	}
+	if filter.reason == "" {
+		filter.reason = "부적절한 내용으로 판단되었습니다"
+	}
+	if len(config.Categories) > 0 {
+		filter.categories = make(map[string]bool, len(config.Categories))
+		for _, category := range config.Categories {
+			filter.categories[category] = true
+		}
+	}
+	return filter
+}
+
+func (f *ClassifierFilter) Name() string {
+	return "classifier"
+}
+
+func (f *ClassifierFilter) Moderate(ctx context.Context, input models.ModerationInput) (*models.ModerationVerdict, error) {
+	scores, err := f.classifier.Classify(ctx, input.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Visit categories in a fixed order so the reason is stable
+	categories := make([]string, 0, len(scores))
+	for category := range scores {
+		if f.categories == nil || f.categories[category] {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+
+	var verdict *models.ModerationVerdict
+	for _, category := range categories {
+		score := scores[category]
+		action := models.ModerationActionAllow
+		switch {
+		case f.rejectThreshold > 0 && score >= f.rejectThreshold:
+			action = models.ModerationActionReject
+		case f.flagThreshold > 0 && score >= f.flagThreshold:
+			action = models.ModerationActionFlag
+		}
+		if action == models.ModerationActionAllow || (verdict != nil && !stricter(action, verdict.Action)) {
+			continue
+		}
+
+		verdict = &models.ModerationVerdict{Action: action, Filter: f.Name(), Reason: f.reason}
+		if action == models.ModerationActionFlag {
+			// Reviewers see which category triggered the flag
+			verdict.Reason = fmt.Sprintf("%s (%s %.2f)", f.reason, category, score)
+		}
+	}
+	return verdict, nil
+}
+
+// HTTPClassifier asks a classification service over HTTP
+type HTTPClassifier struct {
+	client *http.Client
+	url    string
+}
+
+func NewHTTPClassifier(url string, timeout time.Duration) services.ContentClassifier {
+	return &HTTPClassifier{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+	}
+}
+
+func (c *HTTPClassifier) Classify(ctx context.Context, text string) (map[string]float64, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Categories map[string]float64 `json:"categories"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Categories, nil
+}
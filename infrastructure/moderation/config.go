@@ -0,0 +1,85 @@
+// infrastructure/moderation/config.go
+package moderation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/services"
+)
+
+const defaultClassifierTimeout = 3 * time.Second
+
+// Config is the moderation configuration file. Filters run in the order word
+// lists, regex rules, links, classifier; each sees the content masked by the
+// previous ones.
+type Config struct {
+	WordLists  []WordList        `json:"wordLists,omitempty"`
+	Rules      []RegexRule       `json:"rules,omitempty"`
+	Links      *LinkPolicy       `json:"links,omitempty"`
+	Classifier *ClassifierConfig `json:"classifier,omitempty"`
+}
+
+// LoadFilters builds the filters configured in the JSON file at path. An
+// empty path configures no filters.
+func LoadFilters(path string) ([]services.ModerationFilter, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("moderation config %s: %w", path, err)
+	}
+	return config.Filters()
+}
+
+// Filters builds the configured filters
+func (c *Config) Filters() ([]services.ModerationFilter, error) {
+	var filters []services.ModerationFilter
+
+	if len(c.WordLists) > 0 {
+		filter, err := NewWordFilter(c.WordLists)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(c.Rules) > 0 {
+		filter, err := NewRegexFilter(c.Rules)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if c.Links != nil {
+		filter, err := NewLinkFilter(*c.Links)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if c.Classifier != nil {
+		if c.Classifier.URL == "" {
+			return nil, fmt.Errorf("classifier: url is required")
+		}
+		timeout := defaultClassifierTimeout
+		if c.Classifier.TimeoutMs > 0 {
+			timeout = time.Duration(c.Classifier.TimeoutMs) * time.Millisecond
+		}
+		classifier := NewHTTPClassifier(c.Classifier.URL, timeout)
+		filters = append(filters, NewClassifierFilter(classifier, *c.Classifier))
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,105 @@
+// infrastructure/moderation/link_filter.go
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// LinkPolicy restricts the domains messages may link to. A domain covers its
+// subdomains. When Allow is set, links to any other domain are handled with
+// Action; links to a domain in Deny always are.
+type LinkPolicy struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+	// Action is mask, flag or reject; reject by default
+	Action string `json:"action,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// LinkFilter applies a LinkPolicy to the links of a message
+type LinkFilter struct {
+	policy LinkPolicy
+}
+
+func NewLinkFilter(policy LinkPolicy) (*LinkFilter, error) {
+	if policy.Action == "" {
+		policy.Action = models.ModerationActionReject
+	}
+	if policy.Action == models.ModerationActionAllow || !models.IsModerationAction(policy.Action) {
+		return nil, fmt.Errorf("link policy: invalid action %q", policy.Action)
+	}
+	if policy.Reason == "" {
+		policy.Reason = "허용되지 않는 링크가 포함되어 있습니다"
+	}
+	policy.Allow = normalizeDomains(policy.Allow)
+	policy.Deny = normalizeDomains(policy.Deny)
+	return &LinkFilter{policy: policy}, nil
+}
+
+func (f *LinkFilter) Name() string {
+	return "links"
+}
+
+func (f *LinkFilter) Moderate(ctx context.Context, input models.ModerationInput) (*models.ModerationVerdict, error) {
+	found := false
+	content := linkPattern.ReplaceAllStringFunc(input.Content, func(link string) string {
+		if f.permits(link) {
+			return link
+		}
+		found = true
+		return maskString(link)
+	})
+	if !found {
+		return nil, nil
+	}
+
+	verdict := &models.ModerationVerdict{Action: f.policy.Action, Filter: f.Name(), Reason: f.policy.Reason}
+	if f.policy.Action == models.ModerationActionMask {
+		verdict.Content = content
+	}
+	return verdict, nil
+}
+
+func (f *LinkFilter) permits(link string) bool {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(strings.TrimRight(link, ".,;:!?)]}"))
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+
+	if matchesDomain(host, f.policy.Deny) {
+		return false
+	}
+	return len(f.policy.Allow) == 0 || matchesDomain(host, f.policy.Allow)
+}
+
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
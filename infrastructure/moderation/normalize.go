@@ -0,0 +1,167 @@
+// infrastructure/moderation/normalize.go
+package moderation
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maskRune hides masked text. Unlike '*', the full-width asterisk is not
+// read as Markdown emphasis when two masked words share a line.
+const maskRune = '＊'
+
+// Hangul composition constants (Unicode 3.12)
+const (
+	hangulSyllableBase = 0xAC00
+	hangulLeadBase     = 0x1100
+	hangulVowelBase    = 0x1161
+	hangulTailBase     = 0x11A7
+	hangulLeadCount    = 19
+	hangulVowelCount   = 21
+	hangulTailCount    = 28
+)
+
+// normalizedText is content prepared for matching. Case is folded, full-width
+// forms are narrowed, decomposed Hangul as sent by some keyboards is composed
+// into syllables, and spaces, punctuation and symbols are dropped so that
+// "바 보" or "b.a.d" still match. Each normalized rune remembers the span of
+// original runes it came from, so matches can be masked in the original text.
+type normalizedText struct {
+	original []rune
+	runes    []rune
+	start    []int
+	end      []int
+}
+
+func normalize(text string) *normalizedText {
+	original := []rune(text)
+	n := &normalizedText{original: original}
+
+	for i := 0; i < len(original); i++ {
+		r := original[i]
+		if isSeparator(r) {
+			continue
+		}
+
+		from := i
+		if isHangulLead(r) && i+1 < len(original) && isHangulVowel(original[i+1]) {
+			lead, vowel, tail := r-hangulLeadBase, original[i+1]-hangulVowelBase, rune(0)
+			i++
+			if i+1 < len(original) && isHangulTail(original[i+1]) {
+				tail = original[i+1] - hangulTailBase
+				i++
+			}
+			r = hangulSyllableBase + (lead*hangulVowelCount+vowel)*hangulTailCount + tail
+		}
+
+		// Full-width ASCII variants such as "ＢＡＤ"
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+
+		n.runes = append(n.runes, unicode.ToLower(r))
+		n.start = append(n.start, from)
+		n.end = append(n.end, i+1)
+	}
+	return n
+}
+
+// normalizeWord prepares a list entry the same way as the content
+func normalizeWord(word string) []rune {
+	return normalize(word).runes
+}
+
+// isSeparator reports runes that do not change the meaning of a word
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.Is(unicode.Cf, r)
+}
+
+func isHangulLead(r rune) bool {
+	return r >= hangulLeadBase && r < hangulLeadBase+hangulLeadCount
+}
+
+func isHangulVowel(r rune) bool {
+	return r >= hangulVowelBase && r < hangulVowelBase+hangulVowelCount
+}
+
+func isHangulTail(r rune) bool {
+	return r > hangulTailBase && r < hangulTailBase+hangulTailCount
+}
+
+func isHangul(r rune) bool {
+	return unicode.Is(unicode.Hangul, r)
+}
+
+// find returns the normalized spans [from, to) where word occurs. Words
+// written in Hangul match inside longer words, as Korean attaches particles
+// and endings to them ("바보야"). Other words must stand alone, so that
+// "class" does not match "ass".
+func (n *normalizedText) find(word []rune) [][2]int {
+	if len(word) == 0 || len(word) > len(n.runes) {
+		return nil
+	}
+
+	hangul := false
+	for _, r := range word {
+		if isHangul(r) {
+			hangul = true
+			break
+		}
+	}
+
+	var spans [][2]int
+	for i := 0; i+len(word) <= len(n.runes); i++ {
+		matched := true
+		for j, r := range word {
+			if n.runes[i+j] != r {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		to := i + len(word)
+		if !hangul && (n.continuesBefore(n.start[i]) || n.continuesAfter(n.end[to-1])) {
+			continue
+		}
+		spans = append(spans, [2]int{i, to})
+		i = to - 1
+	}
+	return spans
+}
+
+// continuesBefore reports whether a letter or digit directly precedes the original rune
+func (n *normalizedText) continuesBefore(index int) bool {
+	return index > 0 && isWordRune(n.original[index-1])
+}
+
+// continuesAfter reports whether a letter or digit sits at the original index
+func (n *normalizedText) continuesAfter(index int) bool {
+	return index < len(n.original) && isWordRune(n.original[index])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// mask replaces the original runes of the normalized spans with maskRune,
+// keeping the whitespace between them
+func (n *normalizedText) mask(spans [][2]int) string {
+	masked := make([]rune, len(n.original))
+	copy(masked, n.original)
+	for _, span := range spans {
+		for i := n.start[span[0]]; i < n.end[span[1]-1]; i++ {
+			if !unicode.IsSpace(masked[i]) {
+				masked[i] = maskRune
+			}
+		}
+	}
+	return string(masked)
+}
+
+// maskString hides all of text
+func maskString(text string) string {
+	return strings.Repeat(string(maskRune), utf8.RuneCountInString(text))
+}
@@ -0,0 +1,81 @@
+// infrastructure/moderation/regex_filter.go
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// RegexRule applies an action to content matching a pattern, e.g. phone
+// numbers or resident registration numbers
+type RegexRule struct {
+	// Pattern uses RE2 syntax; prefix it with (?i) to ignore case
+	Pattern string `json:"pattern"`
+	// Action is mask, flag or reject
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// RegexFilter matches rules against the content as written
+type RegexFilter struct {
+	rules []compiledRegexRule
+}
+
+type compiledRegexRule struct {
+	RegexRule
+	pattern *regexp.Regexp
+}
+
+func NewRegexFilter(rules []RegexRule) (*RegexFilter, error) {
+	filter := &RegexFilter{}
+	for _, rule := range rules {
+		if rule.Action == models.ModerationActionAllow || !models.IsModerationAction(rule.Action) {
+			return nil, fmt.Errorf("regex rule %q: invalid action %q", rule.Pattern, rule.Action)
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("regex rule %q: %w", rule.Pattern, err)
+		}
+		if rule.Reason == "" {
+			rule.Reason = "허용되지 않는 내용이 포함되어 있습니다"
+		}
+		filter.rules = append(filter.rules, compiledRegexRule{RegexRule: rule, pattern: pattern})
+	}
+	return filter, nil
+}
+
+func (f *RegexFilter) Name() string {
+	return "regex"
+}
+
+// Moderate returns the strictest action of the matched rules and masks the
+// matches of mask rules
+func (f *RegexFilter) Moderate(ctx context.Context, input models.ModerationInput) (*models.ModerationVerdict, error) {
+	var verdict *models.ModerationVerdict
+	content := input.Content
+	masked := false
+
+	for _, rule := range f.rules {
+		if !rule.pattern.MatchString(content) {
+			continue
+		}
+
+		if rule.Action == models.ModerationActionMask {
+			content = rule.pattern.ReplaceAllStringFunc(content, func(match string) string {
+				return maskString(match)
+			})
+			masked = true
+		}
+		if verdict == nil || stricter(rule.Action, verdict.Action) {
+			verdict = &models.ModerationVerdict{Action: rule.Action, Filter: f.Name(), Reason: rule.Reason}
+		}
+	}
+
+	if verdict != nil && masked {
+		verdict.Content = content
+	}
+	return verdict, nil
+}
@@ -0,0 +1,102 @@
+// infrastructure/moderation/word_filter.go
+package moderation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// WordList is a list of words handled with the same action
+type WordList struct {
+	// Action is mask, flag or reject
+	Action string   `json:"action"`
+	Words  []string `json:"words"`
+	// Reason is recorded for review and shown to the sender of rejected messages
+	Reason string `json:"reason,omitempty"`
+}
+
+// WordFilter matches word lists against the normalized content, so spacing,
+// punctuation, letter case and decomposed Hangul do not hide a word
+type WordFilter struct {
+	lists []compiledWordList
+}
+
+type compiledWordList struct {
+	WordList
+	words [][]rune
+}
+
+func NewWordFilter(lists []WordList) (*WordFilter, error) {
+	filter := &WordFilter{}
+	for _, list := range lists {
+		if list.Action == models.ModerationActionAllow || !models.IsModerationAction(list.Action) {
+			return nil, fmt.Errorf("word list: invalid action %q", list.Action)
+		}
+		if list.Reason == "" {
+			list.Reason = "금지어가 포함되어 있습니다"
+		}
+
+		compiled := compiledWordList{WordList: list}
+		for _, word := range list.Words {
+			if normalized := normalizeWord(word); len(normalized) > 0 {
+				compiled.words = append(compiled.words, normalized)
+			}
+		}
+		filter.lists = append(filter.lists, compiled)
+	}
+	return filter, nil
+}
+
+func (f *WordFilter) Name() string {
+	return "words"
+}
+
+// Moderate returns the strictest action of the matched lists. Words of mask
+// lists are masked even when another list flags the message.
+func (f *WordFilter) Moderate(ctx context.Context, input models.ModerationInput) (*models.ModerationVerdict, error) {
+	text := normalize(input.Content)
+
+	var verdict *models.ModerationVerdict
+	var maskSpans [][2]int
+	for _, list := range f.lists {
+		var spans [][2]int
+		for _, word := range list.words {
+			spans = append(spans, text.find(word)...)
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		if list.Action == models.ModerationActionMask {
+			maskSpans = append(maskSpans, spans...)
+		}
+		if verdict == nil || stricter(list.Action, verdict.Action) {
+			verdict = &models.ModerationVerdict{Action: list.Action, Filter: f.Name(), Reason: list.Reason}
+		}
+	}
+
+	if verdict != nil && len(maskSpans) > 0 {
+		verdict.Content = text.mask(maskSpans)
+	}
+	return verdict, nil
+}
+
+// stricter reports whether action a overrides action b
+func stricter(a, b string) bool {
+	return actionRank(a) > actionRank(b)
+}
+
+func actionRank(action string) int {
+	switch action {
+	case models.ModerationActionMask:
+		return 1
+	case models.ModerationActionFlag:
+		return 2
+	case models.ModerationActionReject:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Cooldown lets each key act once per interval. Unlike Limiter the interval
+// is given per call, so keys sharing a Cooldown may use different intervals,
+// and changing the interval of a key applies to its running cooldown.
+type Cooldown struct {
+	mu          sync.Mutex
+	maxInterval time.Duration
+	last        map[string]time.Time
+}
+
+// NewCooldown creates a Cooldown for intervals up to maxInterval
+func NewCooldown(maxInterval time.Duration) *Cooldown {
+	return &Cooldown{
+		maxInterval: maxInterval,
+		last:        make(map[string]time.Time),
+	}
+}
+
+// Take starts the cooldown of the key. While a cooldown runs it returns false
+// and how long until it ends.
+func (c *Cooldown) Take(key string, interval time.Duration) (bool, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := c.last[key]; ok {
+		if next := last.Add(interval); now.Before(next) {
+			return false, next.Sub(now)
+		}
+	}
+
+	if len(c.last) >= maxIdleBuckets {
+		c.prune(now)
+	}
+	c.last[key] = now
+	return true, 0
+}
+
+// Cancel ends the cooldown of the key started by Take, for an action that was
+// not carried out. Take only starts a cooldown after the previous one ended,
+// so the key is left as if it never acted.
+func (c *Cooldown) Cancel(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.last, key)
+}
+
+// prune drops the keys whose cooldown ended whatever their interval
+func (c *Cooldown) prune(now time.Time) {
+	for key, last := range c.last {
+		if now.Sub(last) >= c.maxInterval {
+			delete(c.last, key)
+		}
+	}
+}
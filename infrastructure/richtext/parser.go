@@ -0,0 +1,280 @@
+// infrastructure/richtext/parser.go
+package richtext
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+// maxDepth bounds nested inline formatting
+const maxDepth = 5
+
+// escapable lists the characters a backslash makes literal
+const escapable = "\\`*_|[]()>"
+
+var (
+	autolinkPattern = regexp.MustCompile(`^https?://[^\s<>"'` + "`" + `]+`)
+	languagePattern = regexp.MustCompile(`^[A-Za-z0-9_+#.-]{1,20}$`)
+)
+
+// Parse converts the supported Markdown subset into a rich text document:
+//
+//	**bold**, *italic* or _italic_, `code`, ||spoiler||, [label](url),
+//	bare http(s) URLs, > quotes and ``` fenced code blocks
+//
+// Anything else, including HTML, stays literal text. Only http, https and
+// mailto links are kept.
+func Parse(raw string) models.RichText {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	lines := strings.Split(raw, "\n")
+
+	doc := models.RichText{}
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			doc = append(doc, models.RichTextBlock{Type: models.BlockParagraph, Children: parseLines(paragraph)})
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			// A fence closed on the same line is a one-line code block
+			if len(trimmed) > 6 && strings.HasSuffix(trimmed, "```") {
+				doc = append(doc, models.RichTextBlock{Type: models.BlockCode, Text: trimmed[3 : len(trimmed)-3]})
+				continue
+			}
+
+			block := models.RichTextBlock{Type: models.BlockCode}
+			if language := strings.TrimSpace(trimmed[3:]); languagePattern.MatchString(language) {
+				block.Language = language
+			}
+			var code []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "```"; i++ {
+				code = append(code, lines[i])
+			}
+			block.Text = strings.Join(code, "\n")
+			doc = append(doc, block)
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				line := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(line, ">") {
+					break
+				}
+				quoted = append(quoted, strings.TrimPrefix(line[1:], " "))
+			}
+			i--
+			doc = append(doc, models.RichTextBlock{Type: models.BlockQuote, Children: parseLines(quoted)})
+
+		case trimmed == "":
+			flush()
+
+		default:
+			paragraph = append(paragraph, lines[i])
+		}
+	}
+	flush()
+
+	return doc
+}
+
+// parseLines parses the lines of a paragraph or quote, keeping line breaks
+func parseLines(lines []string) []models.RichTextNode {
+	var nodes []models.RichTextNode
+	for i, line := range lines {
+		if i > 0 {
+			nodes = append(nodes, models.RichTextNode{Type: models.NodeLineBreak})
+		}
+		nodes = append(nodes, parseInline(line, 0, false)...)
+	}
+	return nodes
+}
+
+// parseInline parses inline formatting. Delimiters are ASCII, so scanning bytes
+// never splits a multi-byte character.
+func parseInline(s string, depth int, inLink bool) []models.RichTextNode {
+	var nodes []models.RichTextNode
+	var text strings.Builder
+	emit := func(node models.RichTextNode) {
+		if text.Len() > 0 {
+			nodes = append(nodes, models.RichTextNode{Type: models.NodeText, Text: text.String()})
+			text.Reset()
+		}
+		nodes = append(nodes, node)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		nested := depth < maxDepth
+
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(escapable, s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				emit(models.RichTextNode{Type: models.NodeCode, Text: s[i+1 : i+1+end]})
+				i += end + 2
+				continue
+			}
+
+		case nested && (strings.HasPrefix(s[i:], "**") || strings.HasPrefix(s[i:], "||")):
+			delim := s[i : i+2]
+			if inner, next, ok := delimited(s, i, delim); ok {
+				nodeType := models.NodeBold
+				if delim == "||" {
+					nodeType = models.NodeSpoiler
+				}
+				emit(models.RichTextNode{Type: nodeType, Children: parseInline(inner, depth+1, inLink)})
+				i = next
+				continue
+			}
+
+		case nested && (c == '*' || c == '_'):
+			if inner, next, ok := delimited(s, i, s[i:i+1]); ok {
+				emit(models.RichTextNode{Type: models.NodeItalic, Children: parseInline(inner, depth+1, inLink)})
+				i = next
+				continue
+			}
+
+		case nested && c == '[' && !inLink:
+			if label, target, next, ok := link(s, i); ok {
+				emit(models.RichTextNode{Type: models.NodeLink, URL: target, Children: parseInline(label, depth+1, true)})
+				i = next
+				continue
+			}
+
+		case !inLink && (c == 'h' || c == 'H') && (i == 0 || !isWordByte(s[i-1])):
+			if match := autolinkPattern.FindString(s[i:]); match != "" {
+				match = strings.TrimRight(match, ".,;:!?)]}'\"")
+				if target, ok := sanitizeURL(match); ok {
+					emit(models.RichTextNode{
+						Type:     models.NodeLink,
+						URL:      target,
+						Children: []models.RichTextNode{{Type: models.NodeText, Text: match}},
+					})
+					i += len(match)
+					continue
+				}
+			}
+		}
+
+		text.WriteByte(c)
+		i++
+	}
+
+	if text.Len() > 0 {
+		nodes = append(nodes, models.RichTextNode{Type: models.NodeText, Text: text.String()})
+	}
+	return nodes
+}
+
+// delimited finds the span closed by delim, starting at the opening delimiter at i.
+// Like Markdown, the content cannot start or end with a space and underscores
+// inside words do not count.
+func delimited(s string, i int, delim string) (string, int, bool) {
+	start := i + len(delim)
+	if start >= len(s) || s[start] == ' ' {
+		return "", 0, false
+	}
+	if delim == "_" && i > 0 && isWordByte(s[i-1]) {
+		return "", 0, false
+	}
+
+	for j := start; j+len(delim) <= len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+			continue
+		case s[j] == '`':
+			if end := strings.IndexByte(s[j+1:], '`'); end >= 0 {
+				j += end + 1
+			}
+			continue
+		case !strings.HasPrefix(s[j:], delim):
+			continue
+		}
+
+		// A single delimiter does not close on a double one
+		if len(delim) == 1 && j+1 < len(s) && s[j+1] == delim[0] {
+			j++
+			continue
+		}
+		if j == start || s[j-1] == ' ' {
+			continue
+		}
+		if delim == "_" && j+1 < len(s) && isWordByte(s[j+1]) {
+			continue
+		}
+		return s[start:j], j + len(delim), true
+	}
+	return "", 0, false
+}
+
+// link parses [label](url) starting at i
+func link(s string, i int) (string, string, int, bool) {
+	closeLabel := strings.Index(s[i:], "](")
+	if closeLabel <= 1 {
+		return "", "", 0, false
+	}
+	closeLabel += i
+
+	closeTarget := strings.IndexByte(s[closeLabel+2:], ')')
+	if closeTarget < 0 {
+		return "", "", 0, false
+	}
+	closeTarget += closeLabel + 2
+
+	target, ok := sanitizeURL(strings.TrimSpace(s[closeLabel+2 : closeTarget]))
+	if !ok {
+		return "", "", 0, false
+	}
+	return s[i+1 : closeLabel], target, closeTarget + 1, true
+}
+
+// sanitizeURL accepts absolute http, https and mailto URLs only, which keeps
+// javascript: and data: links out of rendered messages
+func sanitizeURL(raw string) (string, bool) {
+	if raw == "" || strings.ContainsAny(raw, " \t\n\"'<>`") {
+		return "", false
+	}
+	for _, r := range raw {
+		if r < 0x20 || r == 0x7f {
+			return "", false
+		}
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		if parsed.Host == "" {
+			return "", false
+		}
+	case "mailto":
+		if parsed.Opaque == "" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
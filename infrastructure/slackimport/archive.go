@@ -0,0 +1,251 @@
+// infrastructure/slackimport/archive.go
+package slackimport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxFileSize bounds the uncompressed size of a JSON file in the archive, so
+// a crafted archive cannot exhaust memory
+const maxFileSize = 64 << 20
+
+// ErrInvalidArchive is returned for archives that are not Slack exports
+var ErrInvalidArchive = errors.New("invalid slack export archive")
+
+// Channel kinds, each listed in its own file of the export
+const (
+	ChannelKindPublic  = "public"
+	ChannelKindPrivate = "private"
+	// ChannelKindGroupDM is a direct message between more than two users
+	ChannelKindGroupDM = "mpim"
+	ChannelKindDM      = "dm"
+)
+
+var channelFiles = []struct {
+	name string
+	kind string
+}{
+	{"channels.json", ChannelKindPublic},
+	{"groups.json", ChannelKindPrivate},
+	{"mpims.json", ChannelKindGroupDM},
+	{"dms.json", ChannelKindDM},
+}
+
+// User is an entry of users.json
+type User struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+	IsBot   bool   `json:"is_bot"`
+	Profile struct {
+		Email       string `json:"email"`
+		DisplayName string `json:"display_name"`
+		RealName    string `json:"real_name"`
+	} `json:"profile"`
+}
+
+// Channel is an entry of channels.json, groups.json, mpims.json or dms.json
+type Channel struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Created int64    `json:"created"`
+	Creator string   `json:"creator"`
+	Members []string `json:"members"`
+	Topic   struct {
+		Value string `json:"value"`
+	} `json:"topic"`
+	Purpose struct {
+		Value string `json:"value"`
+	} `json:"purpose"`
+
+	Kind string `json:"-"`
+}
+
+// IsDirect reports whether the channel is a direct or group direct message
+func (c *Channel) IsDirect() bool {
+	return c.Kind == ChannelKindDM || c.Kind == ChannelKindGroupDM
+}
+
+// folder is the directory holding the messages of the channel. Direct
+// messages have no name and are stored under their ID.
+func (c *Channel) folder() string {
+	if c.Kind == ChannelKindDM {
+		return c.ID
+	}
+	return c.Name
+}
+
+// Message is an entry of a daily message file
+type Message struct {
+	Type     string `json:"type"`
+	Subtype  string `json:"subtype"`
+	User     string `json:"user"`
+	BotID    string `json:"bot_id"`
+	Username string `json:"username"`
+	Text     string `json:"text"`
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts"`
+	Edited   *struct {
+		User string `json:"user"`
+		TS   string `json:"ts"`
+	} `json:"edited"`
+	Reactions []struct {
+		Name  string   `json:"name"`
+		Users []string `json:"users"`
+	} `json:"reactions"`
+	Files []struct {
+		Name       string `json:"name"`
+		Title      string `json:"title"`
+		URLPrivate string `json:"url_private"`
+	} `json:"files"`
+}
+
+// IsContent reports whether the message was written by a user or bot.
+// Notices such as joins and topic changes are left out of imports, as the
+// chat they describe is imported in its final state.
+func (m *Message) IsContent() bool {
+	if m.Type != "message" {
+		return false
+	}
+	switch m.Subtype {
+	case "", "bot_message", "me_message", "thread_broadcast", "file_share":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsReply reports whether the message is a reply in a thread
+func (m *Message) IsReply() bool {
+	return m.ThreadTS != "" && m.ThreadTS != m.TS
+}
+
+// Archive reads a Slack export zip file. Files are read on demand, so only
+// one daily message file is held in memory at a time.
+type Archive struct {
+	zip *zip.ReadCloser
+	// root is the directory holding users.json, for archives zipped from
+	// their parent directory
+	root  string
+	files map[string]*zip.File
+}
+
+// Open opens a Slack export and checks that it has a user list
+func Open(name string) (*Archive, error) {
+	reader, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	archive := &Archive{zip: reader, files: make(map[string]*zip.File, len(reader.File))}
+	for _, file := range reader.File {
+		archive.files[file.Name] = file
+	}
+	if _, ok := archive.files["users.json"]; !ok {
+		root := ""
+		for name := range archive.files {
+			if path.Base(name) == "users.json" && strings.Count(name, "/") == 1 {
+				root = path.Dir(name) + "/"
+				break
+			}
+		}
+		if root == "" {
+			reader.Close()
+			return nil, fmt.Errorf("%w: users.json not found", ErrInvalidArchive)
+		}
+		archive.root = root
+	}
+	return archive, nil
+}
+
+func (a *Archive) Close() error {
+	return a.zip.Close()
+}
+
+// Users returns the users of the workspace
+func (a *Archive) Users() ([]User, error) {
+	var users []User
+	if err := a.decode("users.json", &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Channels returns the channels and direct messages of the export. The
+// listing files of channel kinds missing from the export are skipped.
+func (a *Archive) Channels() ([]Channel, error) {
+	var channels []Channel
+	for _, listing := range channelFiles {
+		if _, ok := a.files[a.root+listing.name]; !ok {
+			continue
+		}
+		var entries []Channel
+		if err := a.decode(listing.name, &entries); err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			entries[i].Kind = listing.kind
+		}
+		channels = append(channels, entries...)
+	}
+	return channels, nil
+}
+
+// Messages calls fn for every message of a channel, oldest first. It stops at
+// the first error of fn.
+func (a *Archive) Messages(channel *Channel, fn func(*Message) error) error {
+	prefix := a.root + channel.folder() + "/"
+	var days []string
+	for name := range a.files {
+		if strings.HasPrefix(name, prefix) && path.Ext(name) == ".json" && !strings.Contains(name[len(prefix):], "/") {
+			days = append(days, name[len(a.root):])
+		}
+	}
+	// Daily files are named by date, so they sort chronologically
+	sort.Strings(days)
+
+	for _, day := range days {
+		var messages []Message
+		if err := a.decode(day, &messages); err != nil {
+			return err
+		}
+		sort.SliceStable(messages, func(i, j int) bool {
+			return compareTimestamps(messages[i].TS, messages[j].TS) < 0
+		})
+		for i := range messages {
+			if err := fn(&messages[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Archive) decode(name string, v interface{}) error {
+	file, ok := a.files[a.root+name]
+	if !ok {
+		return fmt.Errorf("%w: %s not found", ErrInvalidArchive, name)
+	}
+	if file.UncompressedSize64 > maxFileSize {
+		return fmt.Errorf("%w: %s is too large", ErrInvalidArchive, name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// The declared size can be forged, so the read is bounded as well
+	if err := json.NewDecoder(io.LimitReader(reader, maxFileSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+	}
+	return nil
+}
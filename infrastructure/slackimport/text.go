@@ -0,0 +1,122 @@
+// infrastructure/slackimport/text.go
+package slackimport
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// entityPattern matches Slack's angle-bracketed mentions and links
+	entityPattern = regexp.MustCompile(`<([^<>\n]+)>`)
+	boldPattern   = regexp.MustCompile(`(^|[\s(])\*([^*\n]+)\*`)
+	textEscaper   = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+)
+
+// ParseTimestamp converts a message timestamp such as 1355517523.000005,
+// seconds with microseconds, to a time. The time is in local time, like the
+// creation times of messages sent on the server, as they are compared as text.
+func ParseTimestamp(ts string) (time.Time, error) {
+	seconds, micros, err := splitTimestamp(ts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, micros*int64(time.Microsecond)).In(time.Local), nil
+}
+
+func splitTimestamp(ts string) (int64, int64, error) {
+	secondsPart, microsPart, _ := strings.Cut(ts, ".")
+	seconds, err := strconv.ParseInt(secondsPart, 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid slack timestamp")
+	}
+	var micros int64
+	if microsPart != "" {
+		if len(microsPart) > 6 {
+			microsPart = microsPart[:6]
+		}
+		microsPart += strings.Repeat("0", 6-len(microsPart))
+		if micros, err = strconv.ParseInt(microsPart, 10, 64); err != nil {
+			return 0, 0, errors.New("invalid slack timestamp")
+		}
+	}
+	return seconds, micros, nil
+}
+
+// compareTimestamps orders message timestamps, putting invalid ones first
+func compareTimestamps(a, b string) int {
+	as, am, aErr := splitTimestamp(a)
+	bs, bm, bErr := splitTimestamp(b)
+	switch {
+	case aErr != nil || bErr != nil:
+		if aErr != nil && bErr == nil {
+			return -1
+		}
+		if aErr == nil {
+			return 1
+		}
+		return 0
+	case as != bs:
+		if as < bs {
+			return -1
+		}
+		return 1
+	case am != bm:
+		if am < bm {
+			return -1
+		}
+		return 1
+	default:
+		return 0
+	}
+}
+
+// FormatText converts Slack message markup to the Markdown of this server.
+// User mentions are resolved to nicknames with nickname, links keep their
+// label and single-asterisk bold becomes double-asterisk bold. Text in code
+// spans and blocks is kept as written.
+func FormatText(text string, nickname func(userID string) string) string {
+	segments := strings.Split(text, "`")
+	for i := range segments {
+		if i%2 == 0 {
+			segments[i] = boldPattern.ReplaceAllString(segments[i], "$1**$2**")
+		}
+		segments[i] = entityPattern.ReplaceAllStringFunc(segments[i], func(entity string) string {
+			return formatEntity(entity[1:len(entity)-1], nickname)
+		})
+	}
+	return textEscaper.Replace(strings.Join(segments, "`"))
+}
+
+func formatEntity(entity string, nickname func(userID string) string) string {
+	target, label, hasLabel := strings.Cut(entity, "|")
+	switch {
+	case strings.HasPrefix(target, "@"):
+		if name := nickname(target[1:]); name != "" {
+			return "@" + name
+		}
+		if hasLabel {
+			return "@" + strings.TrimPrefix(label, "@")
+		}
+		return "@" + target[1:]
+	case strings.HasPrefix(target, "#"):
+		if hasLabel {
+			return "#" + label
+		}
+		return target
+	case strings.HasPrefix(target, "!"):
+		// Special mentions such as <!here> and user groups such as <!subteam^ID|@team>
+		if hasLabel {
+			return label
+		}
+		name, _, _ := strings.Cut(target[1:], "^")
+		return "@" + name
+	case hasLabel && label != target:
+		return "[" + label + "](" + target + ")"
+	default:
+		return target
+	}
+}
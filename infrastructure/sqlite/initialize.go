@@ -1,10 +1,12 @@
 package sqlite
 
 import (
+	"fmt"
 	"github.com/gofiber/websocket/v2"
 	"log"
 	"time"
 
+	"github.com/f1rstid/realtime-chat/infrastructure/richtext"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -71,11 +73,331 @@ func Migrate() error {
 		FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE
 	);
 
+	-- Pinned messages table
+	CREATE TABLE IF NOT EXISTS pinned_messages (
+		chatId INTEGER NOT NULL,
+		messageId INTEGER NOT NULL,
+		pinnedBy INTEGER NOT NULL,
+		pinnedAt DATETIME NOT NULL,
+		PRIMARY KEY (chatId, messageId),
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE,
+		FOREIGN KEY (messageId) REFERENCES messages(id) ON DELETE CASCADE,
+		FOREIGN KEY (pinnedBy) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Link preview cache keyed by URL. The previews of encrypted chats are
+	-- cached per chat, keyed by the lookup key of the URL (chatId, sealedUrl).
+	CREATE TABLE IF NOT EXISTS link_previews (
+		url TEXT PRIMARY KEY,
+		title TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		imageUrl TEXT NOT NULL DEFAULT '',
+		siteName TEXT NOT NULL DEFAULT '',
+		failed BOOLEAN NOT NULL DEFAULT 0,
+		fetchedAt DATETIME NOT NULL
+	);
+
+	-- Links found in messages
+	CREATE TABLE IF NOT EXISTS message_link_previews (
+		messageId INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (messageId, url),
+		FOREIGN KEY (messageId) REFERENCES messages(id) ON DELETE CASCADE,
+		FOREIGN KEY (url) REFERENCES link_previews(url) ON DELETE CASCADE
+	);
+
+	-- Messages scheduled to be posted later
+	CREATE TABLE IF NOT EXISTS scheduled_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chatId INTEGER NOT NULL,
+		senderId INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		scheduledAt DATETIME NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		messageId INTEGER,
+		lastError TEXT NOT NULL DEFAULT '',
+		createdAt DATETIME NOT NULL,
+		updatedAt DATETIME NOT NULL,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE,
+		FOREIGN KEY (senderId) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Per-recipient delivery status of messages
+	CREATE TABLE IF NOT EXISTS message_receipts (
+		messageId INTEGER NOT NULL,
+		userId INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'sent',
+		deliveredAt DATETIME,
+		readAt DATETIME,
+		PRIMARY KEY (messageId, userId),
+		FOREIGN KEY (messageId) REFERENCES messages(id) ON DELETE CASCADE,
+		FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Polls attached to messages
+	CREATE TABLE IF NOT EXISTS polls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		messageId INTEGER NOT NULL UNIQUE,
+		chatId INTEGER NOT NULL,
+		creatorId INTEGER NOT NULL,
+		question TEXT NOT NULL,
+		multipleChoice BOOLEAN NOT NULL DEFAULT 0,
+		anonymous BOOLEAN NOT NULL DEFAULT 0,
+		closesAt DATETIME,
+		closedAt DATETIME,
+		createdAt DATETIME NOT NULL,
+		FOREIGN KEY (messageId) REFERENCES messages(id) ON DELETE CASCADE,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE,
+		FOREIGN KEY (creatorId) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS poll_options (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		pollId INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		FOREIGN KEY (pollId) REFERENCES polls(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS poll_votes (
+		pollId INTEGER NOT NULL,
+		optionId INTEGER NOT NULL,
+		userId INTEGER NOT NULL,
+		createdAt DATETIME NOT NULL,
+		PRIMARY KEY (pollId, optionId, userId),
+		FOREIGN KEY (pollId) REFERENCES polls(id) ON DELETE CASCADE,
+		FOREIGN KEY (optionId) REFERENCES poll_options(id) ON DELETE CASCADE,
+		FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Unsent messages per user and chat
+	CREATE TABLE IF NOT EXISTS drafts (
+		userId INTEGER NOT NULL,
+		chatId INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		updatedAt DATETIME NOT NULL,
+		PRIMARY KEY (userId, chatId),
+		FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE
+	);
+
+	-- Messages saved by users
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		userId INTEGER NOT NULL,
+		messageId INTEGER NOT NULL,
+		chatId INTEGER NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		remindAt DATETIME,
+		remindedAt DATETIME,
+		createdAt DATETIME NOT NULL,
+		updatedAt DATETIME NOT NULL,
+		UNIQUE (userId, messageId),
+		FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (messageId) REFERENCES messages(id) ON DELETE CASCADE,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE
+	);
+
+	-- Tokens letting external systems post into a chat
+	CREATE TABLE IF NOT EXISTS incoming_webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chatId INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		tokenHash TEXT NOT NULL UNIQUE,
+		createdBy INTEGER NOT NULL,
+		createdAt DATETIME NOT NULL,
+		lastUsedAt DATETIME,
+		revokedAt DATETIME,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE,
+		FOREIGN KEY (createdBy) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS outgoing_webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ownerId INTEGER NOT NULL,
+		chatId INTEGER,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL,
+		createdAt DATETIME NOT NULL,
+		FOREIGN KEY (ownerId) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		outgoingWebhookId INTEGER NOT NULL,
+		eventType TEXT NOT NULL,
+		chatId INTEGER NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		nextAttemptAt DATETIME,
+		lastAttemptAt DATETIME,
+		responseStatus INTEGER,
+		lastError TEXT,
+		deliveredAt DATETIME,
+		createdAt DATETIME NOT NULL,
+		FOREIGN KEY (outgoingWebhookId) REFERENCES outgoing_webhooks(id) ON DELETE CASCADE
+	);
+
+	-- Messages flagged by moderation filters, kept after the message is gone
+	CREATE TABLE IF NOT EXISTS moderation_flags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		messageId INTEGER NOT NULL,
+		chatId INTEGER NOT NULL,
+		senderId INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		reasons TEXT NOT NULL,
+		status TEXT NOT NULL,
+		reviewedBy INTEGER,
+		reviewedAt DATETIME,
+		createdAt DATETIME NOT NULL,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE
+	);
+
+	-- Chat transcripts generated in the background
+	CREATE TABLE IF NOT EXISTS chat_exports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chatId INTEGER NOT NULL,
+		requestedBy INTEGER NOT NULL,
+		format TEXT NOT NULL,
+		status TEXT NOT NULL,
+		fileName TEXT,
+		fileSize INTEGER NOT NULL DEFAULT 0,
+		messageCount INTEGER NOT NULL DEFAULT 0,
+		createdAt DATETIME NOT NULL,
+		startedAt DATETIME,
+		completedAt DATETIME,
+		expiresAt DATETIME,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE
+	);
+
+	-- Emoji reactions to messages
+	CREATE TABLE IF NOT EXISTS message_reactions (
+		messageId INTEGER NOT NULL,
+		userId INTEGER NOT NULL,
+		emoji TEXT NOT NULL,
+		createdAt DATETIME NOT NULL,
+		PRIMARY KEY (messageId, userId, emoji),
+		FOREIGN KEY (messageId) REFERENCES messages(id) ON DELETE CASCADE,
+		FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Slack export archives imported in the background
+	CREATE TABLE IF NOT EXISTS chat_imports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		requestedBy INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		archivePath TEXT NOT NULL,
+		status TEXT NOT NULL,
+		userCount INTEGER NOT NULL DEFAULT 0,
+		chatCount INTEGER NOT NULL DEFAULT 0,
+		messageCount INTEGER NOT NULL DEFAULT 0,
+		error TEXT,
+		createdAt DATETIME NOT NULL,
+		startedAt DATETIME,
+		completedAt DATETIME
+	);
+
+	-- Users and chats created or matched for imported objects, so imports
+	-- can be repeated and resumed without duplicating them
+	CREATE TABLE IF NOT EXISTS import_mappings (
+		kind TEXT NOT NULL,
+		sourceId TEXT NOT NULL,
+		targetId INTEGER NOT NULL,
+		importId INTEGER NOT NULL,
+		createdAt DATETIME NOT NULL,
+		PRIMARY KEY (kind, sourceId)
+	);
+
+	-- Chats whose messages are kept from the retention purge
+	CREATE TABLE IF NOT EXISTS legal_holds (
+		chatId INTEGER PRIMARY KEY,
+		reason TEXT NOT NULL,
+		placedBy INTEGER NOT NULL,
+		createdAt DATETIME NOT NULL,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE
+	);
+
+	-- Audit of the retention purge: one row per run and per chat purged.
+	-- Rows outlive the chats they describe.
+	CREATE TABLE IF NOT EXISTS retention_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		status TEXT NOT NULL,
+		chatCount INTEGER NOT NULL DEFAULT 0,
+		heldChatCount INTEGER NOT NULL DEFAULT 0,
+		deletedCount INTEGER NOT NULL DEFAULT 0,
+		error TEXT,
+		startedAt DATETIME NOT NULL,
+		completedAt DATETIME
+	);
+
+	CREATE TABLE IF NOT EXISTS retention_run_chats (
+		runId INTEGER NOT NULL,
+		chatId INTEGER NOT NULL,
+		days INTEGER NOT NULL,
+		maxMessages INTEGER NOT NULL,
+		deletedCount INTEGER NOT NULL,
+		firstMessageId INTEGER NOT NULL,
+		lastMessageId INTEGER NOT NULL,
+		oldestCreatedAt DATETIME NOT NULL,
+		newestCreatedAt DATETIME NOT NULL,
+		PRIMARY KEY (runId, chatId),
+		FOREIGN KEY (runId) REFERENCES retention_runs(id) ON DELETE CASCADE
+	);
+
+	-- Devices registered for end-to-end encryption, with their public keys
+	CREATE TABLE IF NOT EXISTS devices (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		userId INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		identityKey TEXT NOT NULL,
+		createdAt DATETIME NOT NULL,
+		FOREIGN KEY (userId) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- One-time prekeys, deleted as they are handed out
+	CREATE TABLE IF NOT EXISTS device_prekeys (
+		deviceId INTEGER NOT NULL,
+		keyId INTEGER NOT NULL,
+		publicKey TEXT NOT NULL,
+		PRIMARY KEY (deviceId, keyId),
+		FOREIGN KEY (deviceId) REFERENCES devices(id) ON DELETE CASCADE
+	);
+
+	-- Per-chat keys encrypting message content at rest, wrapped with the master key
+	CREATE TABLE IF NOT EXISTS chat_data_keys (
+		chatId INTEGER PRIMARY KEY,
+		wrappedKey TEXT NOT NULL,
+		masterKeyId TEXT NOT NULL,
+		createdAt DATETIME NOT NULL,
+		rotatedAt DATETIME,
+		FOREIGN KEY (chatId) REFERENCES chats(id) ON DELETE CASCADE
+	);
+
 	-- Create indexes
 	CREATE INDEX IF NOT EXISTS idx_messages_chatId ON messages(chatId);
 	CREATE INDEX IF NOT EXISTS idx_messages_senderId ON messages(senderId);
 	CREATE INDEX IF NOT EXISTS idx_chat_groups_chatId ON chat_groups(chatId);
 	CREATE INDEX IF NOT EXISTS idx_chat_groups_userId ON chat_groups(userId);
+	CREATE INDEX IF NOT EXISTS idx_pinned_messages_chatId ON pinned_messages(chatId, pinnedAt);
+	CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due ON scheduled_messages(status, scheduledAt);
+	CREATE INDEX IF NOT EXISTS idx_scheduled_messages_senderId ON scheduled_messages(senderId);
+	CREATE INDEX IF NOT EXISTS idx_message_receipts_userId ON message_receipts(userId, status);
+	CREATE INDEX IF NOT EXISTS idx_poll_options_pollId ON poll_options(pollId, position);
+	CREATE INDEX IF NOT EXISTS idx_incoming_webhooks_chatId ON incoming_webhooks(chatId);
+	CREATE INDEX IF NOT EXISTS idx_outgoing_webhooks_chatId ON outgoing_webhooks(chatId);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(outgoingWebhookId, id);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(nextAttemptAt) WHERE status = 'pending';
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_remindAt ON bookmarks(remindAt) WHERE remindedAt IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_moderation_flags_chat ON moderation_flags(chatId, status, id);
+	CREATE INDEX IF NOT EXISTS idx_moderation_flags_messageId ON moderation_flags(messageId);
+	CREATE INDEX IF NOT EXISTS idx_chat_exports_chatId ON chat_exports(chatId, id);
+	CREATE INDEX IF NOT EXISTS idx_chat_exports_status ON chat_exports(status, id);
+	CREATE INDEX IF NOT EXISTS idx_chat_imports_status ON chat_imports(status, id);
+	CREATE INDEX IF NOT EXISTS idx_devices_userId ON devices(userId);
 	`
 
 	_, err := DB.Exec(sql)
@@ -83,10 +405,274 @@ func Migrate() error {
 		return err
 	}
 
+	// Columns added after the initial schema
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"chat_groups", "role", "TEXT NOT NULL DEFAULT 'member'"},
+		{"messages", "scheduledMessageId", "INTEGER"},
+		{"messages", "expiresAt", "DATETIME"},
+		{"chats", "messageTtlSeconds", "INTEGER NOT NULL DEFAULT 0"},
+		{"messages", "forwardedFromMessageId", "INTEGER"},
+		{"messages", "forwardedFromChatId", "INTEGER"},
+		{"messages", "forwardedFromSenderId", "INTEGER"},
+		{"messages", "forwardedFromNickname", "TEXT"},
+		{"messages", "formatted", "TEXT"},
+		{"messages", "plainText", "TEXT NOT NULL DEFAULT ''"},
+		{"messages", "clientMessageId", "TEXT"},
+		{"messages", "type", "TEXT NOT NULL DEFAULT 'user'"},
+		{"messages", "systemEvent", "TEXT"},
+		{"chats", "topic", "TEXT NOT NULL DEFAULT ''"},
+		{"messages", "webhookId", "INTEGER"},
+		{"messages", "botName", "TEXT"},
+		{"users", "isBot", "INTEGER NOT NULL DEFAULT 0"},
+		{"users", "ownerId", "INTEGER"},
+		{"users", "apiTokenHash", "TEXT"},
+		{"messages", "attachments", "TEXT"},
+		{"chats", "slowModeSeconds", "INTEGER NOT NULL DEFAULT 0"},
+		{"messages", "threadRootId", "INTEGER"},
+		{"chats", "retentionDays", "INTEGER NOT NULL DEFAULT 0"},
+		{"chats", "retentionMaxMessages", "INTEGER NOT NULL DEFAULT 0"},
+		{"chats", "encrypted", "INTEGER NOT NULL DEFAULT 0"},
+		{"messages", "encrypted", "INTEGER NOT NULL DEFAULT 0"},
+		{"webhook_deliveries", "messageId", "INTEGER"},
+		{"link_previews", "chatId", "INTEGER"},
+		{"link_previews", "sealedUrl", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfNotExists(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	// Indexes on added columns
+	sql = `
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_scheduledMessageId
+		ON messages(scheduledMessageId) WHERE scheduledMessageId IS NOT NULL;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_clientMessageId
+		ON messages(senderId, clientMessageId) WHERE clientMessageId IS NOT NULL;
+	CREATE INDEX IF NOT EXISTS idx_messages_expiresAt
+		ON messages(expiresAt) WHERE expiresAt IS NOT NULL;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_users_apiTokenHash
+		ON users(apiTokenHash) WHERE apiTokenHash IS NOT NULL;
+	CREATE INDEX IF NOT EXISTS idx_messages_threadRootId
+		ON messages(threadRootId) WHERE threadRootId IS NOT NULL;
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_messageId
+		ON webhook_deliveries(messageId) WHERE messageId IS NOT NULL;
+	`
+	if _, err := DB.Exec(sql); err != nil {
+		return err
+	}
+
+	if err := backfillChatRoles(); err != nil {
+		return err
+	}
+
+	if err := backfillRichText(); err != nil {
+		return err
+	}
+
+	if err := backfillDeliveryMessageIds(); err != nil {
+		return err
+	}
+
+	// Full-text search index over the plain text of messages
+	if err := migrateSearchIndex(); err != nil {
+		log.Printf("Full-text search index unavailable, falling back to LIKE search: %v", err)
+		if err := dropSearchTriggers(); err != nil {
+			return err
+		}
+	}
+
 	log.Println("Database migration completed successfully")
 	return nil
 }
 
+// backfillChatRoles gives managers to chats created before member roles,
+// where everyone got the default member role. Two-member chats are treated
+// like private chats, where both members are admins; in other chats the
+// earliest member becomes owner. Chats that already have a manager are left
+// alone, so this only changes legacy chats.
+func backfillChatRoles() error {
+	sql := `
+	UPDATE chat_groups SET role = 'admin'
+	WHERE chatId IN (
+		SELECT chatId FROM chat_groups
+		GROUP BY chatId
+		HAVING COUNT(*) = 2 AND SUM(role IN ('owner', 'admin')) = 0
+	);
+
+	UPDATE chat_groups SET role = 'owner'
+	WHERE rowid IN (
+		SELECT (
+			SELECT first.rowid FROM chat_groups first
+			WHERE first.chatId = cg.chatId
+			ORDER BY first.createdAt, first.rowid
+			LIMIT 1
+		)
+		FROM chat_groups cg
+		GROUP BY cg.chatId
+		HAVING SUM(cg.role IN ('owner', 'admin')) = 0
+	);
+	`
+	_, err := DB.Exec(sql)
+	return err
+}
+
+// backfillDeliveryMessageIds records the message of message event deliveries
+// queued before deliveries referred to their message, so deleting the message
+// also deletes its copies in the payloads
+func backfillDeliveryMessageIds() error {
+	sql := `
+	UPDATE webhook_deliveries SET messageId = json_extract(payload, '$.data.messageId')
+	WHERE messageId IS NULL AND eventType LIKE 'message.%' AND json_valid(payload)
+	`
+	_, err := DB.Exec(sql)
+	return err
+}
+
+// backfillRichText parses messages stored before rich text support.
+// End-to-end encrypted messages are never formatted, and messages encrypted
+// at rest keep their formatting inside the sealed content.
+func backfillRichText() error {
+	const batchSize = 500
+
+	for {
+		var rows []struct {
+			ID      int    `db:"id"`
+			Content string `db:"content"`
+		}
+		query := `
+			SELECT id, content FROM messages
+			WHERE formatted IS NULL AND encrypted = 0 AND content NOT LIKE 'enc:v1:%'
+			LIMIT $1
+		`
+		if err := DB.Select(&rows, query, batchSize); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		tx, err := DB.Beginx()
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			formatted := richtext.Parse(row.Content)
+			query := `UPDATE messages SET formatted = $1, plainText = $2 WHERE id = $3`
+			if _, err := tx.Exec(query, formatted, formatted.PlainText(), row.ID); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("Parsed rich text of %d messages", len(rows))
+	}
+}
+
+// migrateSearchIndex creates the FTS5 index over messages.plainText and the triggers
+// keeping it in sync. The trigram tokenizer matches substrings, which works for
+// Korean text without a morphological analyzer. FTS5 requires the sqlite_fts5 build tag.
+func migrateSearchIndex() error {
+	var triggers int
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'messages_fts_%'`
+	if err := DB.Get(&triggers, query); err != nil {
+		return err
+	}
+
+	// Older versions indexed the raw Markdown in content
+	var legacy int
+	query = `SELECT COUNT(*) FROM sqlite_master WHERE name = 'messages_fts' AND instr(sql, 'plainText') = 0`
+	if err := DB.Get(&legacy, query); err != nil {
+		return err
+	}
+	if legacy > 0 {
+		if err := dropSearchTriggers(); err != nil {
+			return err
+		}
+		if _, err := DB.Exec(`DROP TABLE messages_fts`); err != nil {
+			return err
+		}
+		triggers = 0
+	}
+
+	sql := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
+		plainText,
+		content='messages',
+		content_rowid='id',
+		tokenize='trigram'
+	);
+	`
+	if _, err := DB.Exec(sql); err != nil {
+		return err
+	}
+
+	// An existing index cannot be read when FTS5 is not compiled in
+	if _, err := DB.Exec(`SELECT rowid FROM messages_fts LIMIT 0`); err != nil {
+		return err
+	}
+
+	sql = `
+	CREATE TRIGGER IF NOT EXISTS messages_fts_ai AFTER INSERT ON messages BEGIN
+		INSERT INTO messages_fts(rowid, plainText) VALUES (new.id, new.plainText);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS messages_fts_ad AFTER DELETE ON messages BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, plainText) VALUES ('delete', old.id, old.plainText);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS messages_fts_au AFTER UPDATE OF plainText ON messages BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, plainText) VALUES ('delete', old.id, old.plainText);
+		INSERT INTO messages_fts(rowid, plainText) VALUES (new.id, new.plainText);
+	END;
+	`
+	if _, err := DB.Exec(sql); err != nil {
+		return err
+	}
+
+	// The index is stale if it was just created or the triggers were dropped
+	if triggers < 3 {
+		if _, err := DB.Exec(`INSERT INTO messages_fts(messages_fts) VALUES ('rebuild')`); err != nil {
+			return err
+		}
+		log.Println("Full-text search index rebuilt")
+	}
+
+	return nil
+}
+
+// dropSearchTriggers removes the FTS sync triggers so writes to messages keep
+// working when the binary was built without FTS5 support
+func dropSearchTriggers() error {
+	_, err := DB.Exec(`
+	DROP TRIGGER IF EXISTS messages_fts_ai;
+	DROP TRIGGER IF EXISTS messages_fts_ad;
+	DROP TRIGGER IF EXISTS messages_fts_au;
+	`)
+	return err
+}
+
+// addColumnIfNotExists adds a column to a table created by an older schema version
+func addColumnIfNotExists(table, column, definition string) error {
+	var count int
+	query := `SELECT COUNT(*) FROM pragma_table_info($1) WHERE name = $2`
+	if err := DB.Get(&count, query, table, column); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err := DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 func CloseDB() {
 	if err := DB.Close(); err != nil {
 		log.Printf("Error closing database connection: %v", err)
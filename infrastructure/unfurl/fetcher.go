@@ -0,0 +1,184 @@
+// infrastructure/unfurl/fetcher.go
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/services"
+)
+
+var (
+	ErrBlockedAddress = errors.New("destination address is not allowed")
+	ErrUnsupportedURL = errors.New("only http and https URLs are supported")
+	ErrNotHTML        = errors.New("response is not an HTML document")
+)
+
+// Options configures an HTTPFetcher
+type Options struct {
+	// Timeout bounds the whole request including redirects and reading the body
+	Timeout time.Duration
+	// MaxBodyBytes is the maximum number of bytes read from a response
+	MaxBodyBytes int64
+	// MaxRedirects is the maximum number of redirects followed
+	MaxRedirects int
+	// UserAgent is sent with every request
+	UserAgent string
+	// AllowPrivateNetworks disables the private address check, for tests against local servers
+	AllowPrivateNetworks bool
+}
+
+// DefaultOptions returns the options used in production
+func DefaultOptions() Options {
+	return Options{
+		Timeout:      5 * time.Second,
+		MaxBodyBytes: 512 * 1024,
+		MaxRedirects: 3,
+		UserAgent:    "RealtimeChatBot/1.0 (+link preview)",
+	}
+}
+
+// HTTPFetcher fetches link previews over HTTP with SSRF protections.
+// Every connection, including those made for redirects, is checked against
+// the resolved IP address so DNS rebinding cannot reach internal hosts.
+type HTTPFetcher struct {
+	client  *http.Client
+	options Options
+}
+
+func NewHTTPFetcher(options Options) services.LinkPreviewFetcher {
+	dialer := &net.Dialer{
+		Timeout: options.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if options.AllowPrivateNetworks {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || IsBlockedIP(ip) {
+				return ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // a proxy would bypass the address check
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   options.Timeout,
+		ResponseHeaderTimeout: options.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   options.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > options.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", options.MaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrUnsupportedURL
+			}
+			return nil
+		},
+	}
+
+	return &HTTPFetcher{client: client, options: options}
+}
+
+// Fetch downloads the page at rawURL and extracts its preview metadata
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (*models.LinkPreview, error) {
+	pageURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if pageURL.Scheme != "http" && pageURL.Scheme != "https" {
+		return nil, ErrUnsupportedURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.options.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return nil, ErrNotHTML
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.options.MaxBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	preview := parseMetadata(body, resp.Request.URL)
+	preview.URL = rawURL
+	preview.FetchedAt = time.Now()
+	return preview, nil
+}
+
+// Carrier-grade NAT and other ranges not covered by the net.IP helpers
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+// IsBlockedIP reports whether ip is loopback, private, link-local or otherwise non-public
+func IsBlockedIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
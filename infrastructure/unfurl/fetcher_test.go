@@ -0,0 +1,216 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testPage = `<html><head>
+<meta property="og:title" content="Test Title">
+<meta property="og:description" content="Test description">
+</head><body></body></html>`
+
+// testOptions allows the loopback address httptest listens on
+func testOptions() Options {
+	options := DefaultOptions()
+	options.Timeout = time.Second
+	options.AllowPrivateNetworks = true
+	return options
+}
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func serveHTML(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, body)
+	}
+}
+
+func TestFetchParsesMetadata(t *testing.T) {
+	server := newTestServer(t, serveHTML(testPage))
+
+	preview, err := NewHTTPFetcher(testOptions()).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if preview.Title != "Test Title" || preview.Description != "Test description" {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+	if preview.URL != server.URL {
+		t.Errorf("URL = %q, want %q", preview.URL, server.URL)
+	}
+}
+
+func TestFetchRejectsLoopbackByDefault(t *testing.T) {
+	server := newTestServer(t, serveHTML(testPage))
+
+	options := testOptions()
+	options.AllowPrivateNetworks = false
+
+	_, err := NewHTTPFetcher(options).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("Fetch error = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"title":"not a page"}`)
+	})
+
+	_, err := NewHTTPFetcher(testOptions()).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrNotHTML) {
+		t.Fatalf("Fetch error = %v, want ErrNotHTML", err)
+	}
+}
+
+func TestFetchRejectsErrorStatus(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	})
+
+	if _, err := NewHTTPFetcher(testOptions()).Fetch(context.Background(), server.URL); err == nil {
+		t.Fatal("Fetch succeeded for a 404 response")
+	}
+}
+
+func TestFetchReadsAtMostMaxBodyBytes(t *testing.T) {
+	padding := strings.Repeat("<!-- padding -->", 1024)
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		// The metadata only appears after the limit
+		fmt.Fprint(w, "<html><head>"+padding+`<meta property="og:title" content="Too Far">`+"</head></html>")
+	})
+
+	options := testOptions()
+	options.MaxBodyBytes = int64(len(padding) / 2)
+
+	preview, err := NewHTTPFetcher(options).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if preview.Title != "" {
+		t.Errorf("Title = %q, want metadata past the limit to be ignored", preview.Title)
+	}
+}
+
+func TestFetchTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	options := testOptions()
+	options.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := NewHTTPFetcher(options).Fetch(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Fetch succeeded against a server that never responds")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Fetch took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestFetchFollowsRedirects(t *testing.T) {
+	target := newTestServer(t, serveHTML(testPage))
+	redirector := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusMovedPermanently)
+	})
+
+	preview, err := NewHTTPFetcher(testOptions()).Fetch(context.Background(), redirector.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if preview.Title != "Test Title" {
+		t.Errorf("Title = %q, want the redirect target's title", preview.Title)
+	}
+	if preview.URL != redirector.URL {
+		t.Errorf("URL = %q, want the original URL %q", preview.URL, redirector.URL)
+	}
+}
+
+func TestFetchStopsAfterMaxRedirects(t *testing.T) {
+	var hops atomic.Int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, fmt.Sprintf("/hop/%d", hops.Add(1)), http.StatusFound)
+	})
+
+	options := testOptions()
+	options.MaxRedirects = 2
+
+	if _, err := NewHTTPFetcher(options).Fetch(context.Background(), server.URL); err == nil {
+		t.Fatal("Fetch followed an endless redirect chain")
+	}
+	if got := int(hops.Load()); got != options.MaxRedirects+1 {
+		t.Errorf("server saw %d requests, want %d", got, options.MaxRedirects+1)
+	}
+}
+
+func TestFetchRejectsRedirectToOtherSchemes(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "ftp://example.com/file", http.StatusFound)
+	})
+
+	_, err := NewHTTPFetcher(testOptions()).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrUnsupportedURL) {
+		t.Fatalf("Fetch error = %v, want ErrUnsupportedURL", err)
+	}
+}
+
+func TestFetchRejectsUnsupportedURL(t *testing.T) {
+	_, err := NewHTTPFetcher(testOptions()).Fetch(context.Background(), "file:///etc/passwd")
+	if !errors.Is(err, ErrUnsupportedURL) {
+		t.Fatalf("Fetch error = %v, want ErrUnsupportedURL", err)
+	}
+}
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"fe80::1", true},
+		{"fc00::1", true},
+		{"224.0.0.1", true},
+		{"::ffff:127.0.0.1", true},
+		{"64:ff9b::a00:1", true},
+		{"93.184.216.34", false},
+		{"8.8.8.8", false},
+		{"2606:4700:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := IsBlockedIP(net.ParseIP(tt.ip)); got != tt.blocked {
+			t.Errorf("IsBlockedIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
+		}
+	}
+}
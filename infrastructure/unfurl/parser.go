@@ -0,0 +1,100 @@
+// infrastructure/unfurl/parser.go
+package unfurl
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+)
+
+const maxFieldLength = 300
+
+var (
+	metaTagPattern   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attributePattern = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titlePattern     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	headEndPattern   = regexp.MustCompile(`(?i)</head>`)
+)
+
+// parseMetadata extracts OpenGraph and Twitter card metadata from an HTML document.
+// OpenGraph properties take precedence over Twitter cards, which take precedence
+// over the plain <title> and description.
+func parseMetadata(body []byte, pageURL *url.URL) *models.LinkPreview {
+	document := string(body)
+	if loc := headEndPattern.FindStringIndex(document); loc != nil {
+		document = document[:loc[0]]
+	}
+
+	meta := make(map[string]string)
+	for _, tag := range metaTagPattern.FindAllString(document, -1) {
+		attributes := make(map[string]string)
+		for _, match := range attributePattern.FindAllStringSubmatch(tag, -1) {
+			attributes[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+		}
+
+		key := attributes["property"]
+		if key == "" {
+			key = attributes["name"]
+		}
+		key = strings.ToLower(key)
+		if _, exists := meta[key]; key != "" && !exists {
+			meta[key] = attributes["content"]
+		}
+	}
+
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := cleanText(meta[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	preview := &models.LinkPreview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		SiteName:    first("og:site_name", "twitter:site"),
+		ImageURL:    resolveURL(pageURL, first("og:image", "og:image:url", "twitter:image", "twitter:image:src")),
+	}
+
+	if preview.Title == "" {
+		if match := titlePattern.FindStringSubmatch(document); match != nil {
+			preview.Title = cleanText(match[1])
+		}
+	}
+	if preview.SiteName == "" && pageURL != nil {
+		preview.SiteName = pageURL.Hostname()
+	}
+
+	return preview
+}
+
+// cleanText unescapes entities, collapses whitespace and truncates the value
+func cleanText(value string) string {
+	value = strings.Join(strings.Fields(html.UnescapeString(value)), " ")
+	if !utf8.ValidString(value) {
+		value = strings.ToValidUTF8(value, "")
+	}
+	if utf8.RuneCountInString(value) > maxFieldLength {
+		value = string([]rune(value)[:maxFieldLength]) + "…"
+	}
+	return value
+}
+
+// resolveURL resolves a possibly relative reference against the page URL,
+// keeping only http and https results
+func resolveURL(pageURL *url.URL, ref string) string {
+	if ref == "" || pageURL == nil {
+		return ""
+	}
+	resolved, err := pageURL.Parse(ref)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+	return resolved.String()
+}
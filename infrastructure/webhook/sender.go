@@ -0,0 +1,117 @@
+// infrastructure/webhook/sender.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/services"
+	"github.com/f1rstid/realtime-chat/infrastructure/unfurl"
+)
+
+// Options configures an HTTPSender
+type Options struct {
+	// Timeout bounds the whole request including reading the response
+	Timeout time.Duration
+	// UserAgent is sent with every request
+	UserAgent string
+	// AllowPrivateNetworks disables the private address check, for receivers on the local network
+	AllowPrivateNetworks bool
+}
+
+// DefaultOptions returns the options used in production
+func DefaultOptions() Options {
+	return Options{
+		Timeout:   10 * time.Second,
+		UserAgent: "RealtimeChatWebhook/1.0",
+	}
+}
+
+// HTTPSender posts webhook payloads with the same address checks as link
+// previews. Redirects are not followed, so a receiver cannot bounce a signed
+// payload to an internal host.
+type HTTPSender struct {
+	client  *http.Client
+	options Options
+}
+
+func NewHTTPSender(options Options) services.WebhookSender {
+	dialer := &net.Dialer{
+		Timeout: options.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if options.AllowPrivateNetworks {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || unfurl.IsBlockedIP(ip) {
+				return unfurl.ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // a proxy would bypass the address check
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   options.Timeout,
+		ResponseHeaderTimeout: options.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   options.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return &HTTPSender{client: client, options: options}
+}
+
+// Send posts a JSON body to the endpoint
+func (s *HTTPSender) Send(ctx context.Context, rawURL string, header http.Header, body []byte) (int, error) {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, err
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return 0, unfurl.ErrUnsupportedURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.options.UserAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
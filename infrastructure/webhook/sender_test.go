@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/infrastructure/unfurl"
+)
+
+// testOptions allows the loopback address httptest listens on
+func testOptions() Options {
+	options := DefaultOptions()
+	options.Timeout = time.Second
+	options.AllowPrivateNetworks = true
+	return options
+}
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSendPostsBodyWithHeaders(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	header := http.Header{}
+	header.Set("X-Webhook-Signature", "sha256=abc")
+
+	status, err := NewHTTPSender(testOptions()).Send(context.Background(), server.URL, header, []byte(`{"event":"message.created"}`))
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", status, http.StatusNoContent)
+	}
+	if got.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", got.Method)
+	}
+	if string(body) != `{"event":"message.created"}` {
+		t.Errorf("body = %s", body)
+	}
+	if ct := got.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if ua := got.Header.Get("User-Agent"); ua != DefaultOptions().UserAgent {
+		t.Errorf("User-Agent = %q, want %q", ua, DefaultOptions().UserAgent)
+	}
+	if sig := got.Header.Get("X-Webhook-Signature"); sig != "sha256=abc" {
+		t.Errorf("X-Webhook-Signature = %q, want the given header", sig)
+	}
+}
+
+func TestSendReportsErrorStatus(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	})
+
+	status, err := NewHTTPSender(testOptions()).Send(context.Background(), server.URL, http.Header{}, []byte(`{}`))
+	if err == nil {
+		t.Fatal("Send succeeded for a 503 response")
+	}
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	target := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the redirect target received the payload")
+	})
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	})
+
+	status, err := NewHTTPSender(testOptions()).Send(context.Background(), server.URL, http.Header{}, []byte(`{}`))
+	if err == nil {
+		t.Fatal("Send succeeded for a redirect")
+	}
+	if status != http.StatusTemporaryRedirect {
+		t.Errorf("status = %d, want %d", status, http.StatusTemporaryRedirect)
+	}
+}
+
+func TestSendTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	options := testOptions()
+	options.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	status, err := NewHTTPSender(options).Send(context.Background(), server.URL, http.Header{}, []byte(`{}`))
+	if err == nil {
+		t.Fatal("Send succeeded against a server that never responds")
+	}
+	if status != 0 {
+		t.Errorf("status = %d, want 0 without a response", status)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestSendRejectsLoopbackByDefault(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the loopback server received the payload")
+	})
+
+	options := testOptions()
+	options.AllowPrivateNetworks = false
+
+	_, err := NewHTTPSender(options).Send(context.Background(), server.URL, http.Header{}, []byte(`{}`))
+	if !errors.Is(err, unfurl.ErrBlockedAddress) {
+		t.Fatalf("Send error = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestSendRejectsUnsupportedURL(t *testing.T) {
+	_, err := NewHTTPSender(testOptions()).Send(context.Background(), "ftp://example.com/hook", http.Header{}, []byte(`{}`))
+	if !errors.Is(err, unfurl.ErrUnsupportedURL) {
+		t.Fatalf("Send error = %v, want ErrUnsupportedURL", err)
+	}
+}
@@ -2,39 +2,68 @@
 package websocket
 
 import (
+	"encoding/json"
 	"github.com/f1rstid/realtime-chat/infrastructure/logger"
 	"github.com/gofiber/websocket/v2"
 	"sync"
 )
 
+// ackType is the type of frames clients send to acknowledge received messages
+const ackType = "message.ack"
+
 // Hub maintains the set of active clients and broadcasts messages
 type Hub struct {
 	// Registered clients mapped by user ID
 	clients map[int]map[*Client]bool
 
-	// Register requests from the clients
-	register chan *Client
-
 	// Unregister requests from clients
 	unregister chan *Client
 
-	// Mutex for thread-safe operations on the clients map
+	// Mutex for thread-safe operations on the clients map. Send channels are
+	// only closed under the write lock, so a client found in the map under the
+	// read lock can be sent to.
 	mu sync.RWMutex
+
+	// Called when a message reached one of the user's devices
+	onDelivered func(userID, messageID int)
 }
 
 // Client represents a connected websocket client
 type Client struct {
 	Hub    *Hub
 	Conn   *websocket.Conn
-	Send   chan []byte
+	Send   chan Frame
 	UserID int
+	// ConnectionID lets HTTP requests from the same device be told apart
+	ConnectionID string
+	// OnFrame handles frames other than acknowledgements, e.g. messages sent
+	// over the connection. It runs on the read loop, so frames of a client
+	// are handled in order.
+	OnFrame func(frameType string, data []byte)
+}
+
+// Frame is a payload queued for a client. MessageID is set on frames carrying
+// a new message, so the delivery is reported once the frame is written.
+type Frame struct {
+	Data      []byte
+	MessageID int
+}
+
+// ackFrame is sent by clients to acknowledge messages they received
+type ackFrame struct {
+	Type       string `json:"type"`
+	MessageIDs []int  `json:"messageIds"`
+}
+
+// frameHeader reads the type of a frame sent by a client
+type frameHeader struct {
+	Type string `json:"type"`
 }
 
 // NewHub creates a new Hub instance
 func NewHub() *Hub {
 	return &Hub{
 		clients:    make(map[int]map[*Client]bool),
-		register:   make(chan *Client),
 		unregister: make(chan *Client),
 	}
 }
@@ -43,59 +72,101 @@ func NewHub() *Hub {
 func (h *Hub) Run() {
 	for {
 		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			if _, ok := h.clients[client.UserID]; !ok {
-				h.clients[client.UserID] = make(map[*Client]bool)
-			}
-			h.clients[client.UserID][client] = true
-			h.mu.Unlock()
-			logger.Info("Client registered - UserID: %d", client.UserID)
-
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if clients, ok := h.clients[client.UserID]; ok {
-				if _, ok := clients[client]; ok {
-					delete(clients, client)
-					close(client.Send)
-					if len(clients) == 0 {
-						delete(h.clients, client.UserID)
-					}
-				}
-			}
+			h.removeClient(client)
 			h.mu.Unlock()
 			logger.Info("Client unregistered - UserID: %d", client.UserID)
 		}
 	}
 }
 
+// OnDelivered sets the callback reporting that a message reached a user's device
+func (h *Hub) OnDelivered(handler func(userID, messageID int)) {
+	h.onDelivered = handler
+}
+
 // BroadcastToUsers sends a message to specified users
 func (h *Hub) BroadcastToUsers(userIDs []int, message []byte) {
+	h.sendToUsers(userIDs, Frame{Data: message})
+}
+
+// DeliverToUsers sends a new message to specified users and reports its
+// delivery to each user once written to one of their connections
+func (h *Hub) DeliverToUsers(userIDs []int, messageID int, message []byte) {
+	h.sendToUsers(userIDs, Frame{Data: message, MessageID: messageID})
+}
+
+// SendToOtherConnections sends a message to the user's connections except the
+// one with the given ID, typically the device that made the change
+func (h *Hub) SendToOtherConnections(userID int, connectionID string, message []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
+	for client := range h.clients[userID] {
+		if connectionID != "" && client.ConnectionID == connectionID {
+			continue
+		}
+		select {
+		case client.Send <- Frame{Data: message}:
+		default:
+			logger.Error("Failed to send message to UserID: %d", userID)
+		}
+	}
+}
+
+func (h *Hub) sendToUsers(userIDs []int, frame Frame) {
+	var slow []*Client
+
+	h.mu.RLock()
 	for _, userID := range userIDs {
-		if clients, ok := h.clients[userID]; ok {
-			for client := range clients {
-				select {
-				case client.Send <- message:
-					logger.Info("Message sent to UserID: %d", userID)
-				default:
-					close(client.Send)
-					delete(clients, client)
-					if len(clients) == 0 {
-						delete(h.clients, userID)
-					}
-					logger.Error("Failed to send message to UserID: %d", userID)
-				}
+		for client := range h.clients[userID] {
+			select {
+			case client.Send <- frame:
+				logger.Info("Message sent to UserID: %d", userID)
+			default:
+				slow = append(slow, client)
+				logger.Error("Failed to send message to UserID: %d", userID)
 			}
 		}
 	}
+	h.mu.RUnlock()
+
+	// Clients whose buffer is full are dropped under the write lock, so no
+	// sender holding the read lock sees their Send channel closed
+	if len(slow) > 0 {
+		h.mu.Lock()
+		for _, client := range slow {
+			h.removeClient(client)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// removeClient deletes a client and closes its Send channel, which ends its
+// write pump. The caller must hold the write lock.
+func (h *Hub) removeClient(client *Client) {
+	clients, ok := h.clients[client.UserID]
+	if !ok || !clients[client] {
+		return
+	}
+	delete(clients, client)
+	close(client.Send)
+	if len(clients) == 0 {
+		delete(h.clients, client.UserID)
+	}
 }
 
-// RegisterClient adds a new client to the hub
+// RegisterClient adds a new client to the hub. The client is added before
+// returning, so replies to the first frames it sends are not dropped.
 func (h *Hub) RegisterClient(client *Client) {
-	h.register <- client
+	h.mu.Lock()
+	if _, ok := h.clients[client.UserID]; !ok {
+		h.clients[client.UserID] = make(map[*Client]bool)
+	}
+	h.clients[client.UserID][client] = true
+	h.mu.Unlock()
+	logger.Info("Client registered - UserID: %d", client.UserID)
 }
 
 // UnregisterClient removes a client from the hub
@@ -111,16 +182,20 @@ func (c *Client) WritePump() {
 
 	for {
 		select {
-		case message, ok := <-c.Send:
+		case frame, ok := <-c.Send:
 			if !ok {
 				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
 
-			if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			if err := c.Conn.WriteMessage(websocket.TextMessage, frame.Data); err != nil {
 				logger.Error("Failed to send message to client %d: %v", c.UserID, err)
 				return
 			}
+
+			if frame.MessageID != 0 {
+				c.Hub.delivered(c.UserID, frame.MessageID)
+			}
 		}
 	}
 }
@@ -133,12 +208,55 @@ func (c *Client) ReadPump() {
 	}()
 
 	for {
-		_, _, err := c.Conn.ReadMessage()
+		_, data, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				logger.Error("WebSocket read error: %v", err)
 			}
 			break
 		}
+
+		var header frameHeader
+		if json.Unmarshal(data, &header) != nil {
+			continue
+		}
+
+		// Clients acknowledge messages they received, e.g. ones fetched over HTTP
+		if header.Type == ackType {
+			var ack ackFrame
+			if json.Unmarshal(data, &ack) == nil {
+				for _, messageID := range ack.MessageIDs {
+					c.Hub.delivered(c.UserID, messageID)
+				}
+			}
+			continue
+		}
+
+		if c.OnFrame != nil {
+			c.OnFrame(header.Type, data)
+		}
+	}
+}
+
+// Reply queues a frame for this connection only. The hub closes the Send
+// channel of clients it drops under the write lock, so the client is sent to
+// only while it is still registered, under the read lock.
+func (c *Client) Reply(data []byte) {
+	c.Hub.mu.RLock()
+	defer c.Hub.mu.RUnlock()
+
+	if !c.Hub.clients[c.UserID][c] {
+		return
+	}
+	select {
+	case c.Send <- Frame{Data: data}:
+	default:
+		logger.Error("Failed to send reply to UserID: %d", c.UserID)
+	}
+}
+
+func (h *Hub) delivered(userID, messageID int) {
+	if h.onDelivered != nil && messageID > 0 {
+		h.onDelivered(userID, messageID)
 	}
 }
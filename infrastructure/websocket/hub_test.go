@@ -0,0 +1,57 @@
+package websocket
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+)
+
+// useTempLogDir writes the logs of a test to its temporary directory
+func useTempLogDir(t *testing.T) {
+	t.Helper()
+	if err := logger.SetDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.SetDir("") })
+}
+
+// TestSlowClientEvictionDoesNotRaceReplies fills the buffer of clients whose
+// write pump is not running, so broadcasts drop them while replies and
+// per-connection sends are queued concurrently. Sending on a channel the hub
+// closed would panic.
+func TestSlowClientEvictionDoesNotRaceReplies(t *testing.T) {
+	useTempLogDir(t)
+	hub := NewHub()
+	go hub.Run()
+
+	for round := 0; round < 200; round++ {
+		client := &Client{Hub: hub, Send: make(chan Frame, 1), UserID: 1, ConnectionID: "a"}
+		hub.RegisterClient(client)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				hub.BroadcastToUsers([]int{1}, []byte(`{}`))
+			}()
+			go func() {
+				defer wg.Done()
+				client.Reply([]byte(`{}`))
+			}()
+			go func() {
+				defer wg.Done()
+				hub.SendToOtherConnections(1, "b", []byte(`{}`))
+			}()
+		}
+		wg.Wait()
+
+		hub.mu.RLock()
+		registered := hub.clients[1][client]
+		hub.mu.RUnlock()
+		if registered {
+			t.Fatalf("round %d: client with a full buffer is still registered", round)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateBookmarkRequest represents the request for bookmarking a message
+type CreateBookmarkRequest struct {
+	MessageID int    `json:"messageId" example:"10" validate:"required"`
+	Note      string `json:"note,omitempty" example:"회의 전에 다시 읽기"`
+	// 알림 받을 시각. 생략하면 알림이 없습니다.
+	RemindAt *time.Time `json:"remindAt,omitempty" example:"2024-03-24T09:00:00+09:00"`
+}
+
+// UpdateBookmarkRequest represents the request for changing a bookmark
+type UpdateBookmarkRequest struct {
+	Note string `json:"note" example:"회의 전에 다시 읽기"`
+	// 알림 받을 시각. 생략하면 알림이 해제됩니다.
+	RemindAt *time.Time `json:"remindAt,omitempty" example:"2024-03-24T09:00:00+09:00"`
+}
+
+type BookmarkController struct {
+	bookmarkUseCase *usecase.BookmarkUsecase
+}
+
+func NewBookmarkController(bookmarkUseCase *usecase.BookmarkUsecase) *BookmarkController {
+	return &BookmarkController{
+		bookmarkUseCase: bookmarkUseCase,
+	}
+}
+
+// CreateBookmark godoc
+// @Summary      메시지 북마크
+// @Description  볼 수 있는 메시지를 북마크합니다. 메모와 알림 시각을 함께 저장할 수 있으며, 알림 시각이 되면 bookmark.reminder 이벤트가 전달됩니다.
+// @Tags         Bookmark
+// @Accept       json
+// @Produce      json
+// @Param        request body CreateBookmarkRequest true "북마크 정보"
+// @Success      201  {object}  common.BookmarkResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bookmarks [post]
+func (bc *BookmarkController) CreateBookmark(c *fiber.Ctx) error {
+	var req CreateBookmarkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	if req.MessageID <= 0 {
+		return interfaces.SendBadRequest(c, "메시지 ID는 필수 항목입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	bookmark, err := bc.bookmarkUseCase.CreateBookmark(userID, req.MessageID, req.Note, req.RemindAt)
+	if err != nil {
+		return sendBookmarkError(c, err)
+	}
+
+	return interfaces.SendCreated(c, bookmark)
+}
+
+// GetBookmarks godoc
+// @Summary      북마크 목록 조회
+// @Description  모든 채팅방의 북마크를 최근에 저장한 순으로 조회합니다. 메시지가 삭제되었거나 채팅방에서 나간 북마크는 available=false로 메시지 없이 표시됩니다.
+// @Tags         Bookmark
+// @Accept       json
+// @Produce      json
+// @Param        chatId  query     int  false  "채팅방 ID"
+// @Param        cursor  query     int  false  "커서 (이전 페이지의 nextCursor, 첫 페이지는 0 또는 생략)"
+// @Param        limit   query     int  false  "페이지 크기 (기본 20, 최대 50)"
+// @Success      200  {object}  common.BookmarkListResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bookmarks [get]
+func (bc *BookmarkController) GetBookmarks(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	bookmarks, err := bc.bookmarkUseCase.GetBookmarks(userID, c.QueryInt("chatId", 0), c.QueryInt("cursor", 0), c.QueryInt("limit", 0))
+	if err != nil {
+		return interfaces.SendInternalError(c)
+	}
+
+	return interfaces.SendSuccess(c, bookmarks)
+}
+
+// GetBookmark godoc
+// @Summary      북마크 조회
+// @Description  북마크 하나를 조회합니다
+// @Tags         Bookmark
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "북마크 ID"
+// @Success      200  {object}  common.BookmarkResponse
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bookmarks/{id} [get]
+func (bc *BookmarkController) GetBookmark(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 북마크 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	bookmark, err := bc.bookmarkUseCase.GetBookmark(id, userID)
+	if err != nil {
+		return sendBookmarkError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, bookmark)
+}
+
+// UpdateBookmark godoc
+// @Summary      북마크 수정
+// @Description  북마크의 메모와 알림 시각을 변경합니다. 알림 시각을 바꾸면 이미 알림을 받은 북마크도 다시 알림을 받습니다.
+// @Tags         Bookmark
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "북마크 ID"
+// @Param        request body UpdateBookmarkRequest true "수정할 북마크 정보"
+// @Success      200  {object}  common.BookmarkResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bookmarks/{id} [put]
+func (bc *BookmarkController) UpdateBookmark(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 북마크 ID입니다")
+	}
+
+	var req UpdateBookmarkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	bookmark, err := bc.bookmarkUseCase.UpdateBookmark(id, userID, req.Note, req.RemindAt)
+	if err != nil {
+		return sendBookmarkError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, bookmark)
+}
+
+// DeleteBookmark godoc
+// @Summary      북마크 삭제
+// @Description  북마크를 삭제합니다
+// @Tags         Bookmark
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "북마크 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bookmarks/{id} [delete]
+func (bc *BookmarkController) DeleteBookmark(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 북마크 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := bc.bookmarkUseCase.DeleteBookmark(id, userID); err != nil {
+		return sendBookmarkError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, "북마크가 삭제되었습니다")
+}
+
+func sendBookmarkError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "message not found":
+		return interfaces.SendNotFound(c, "메시지")
+	case "bookmark not found":
+		return interfaces.SendNotFound(c, "북마크")
+	case "user is not a member of this chat":
+		return interfaces.SendForbidden(c)
+	case "message already bookmarked":
+		return interfaces.SendBadRequest(c, "이미 북마크한 메시지입니다")
+	case "bookmark note too long":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("메모는 최대 %d자까지 입력할 수 있습니다", models.MaxBookmarkNoteLength))
+	case "invalid reminder time":
+		return interfaces.SendBadRequest(c, "알림 시각은 현재 이후 1년 이내여야 합니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/common"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateBotRequest represents the request for creating a bot account
+type CreateBotRequest struct {
+	// 봇의 닉네임 (사용자 닉네임과 같은 규칙)
+	Nickname string `json:"nickname" example:"deploy_bot" validate:"required"`
+}
+
+type BotController struct {
+	botUseCase *usecase.BotUsecase
+}
+
+func NewBotController(botUseCase *usecase.BotUsecase) *BotController {
+	return &BotController{
+		botUseCase: botUseCase,
+	}
+}
+
+// CreateBot godoc
+// @Summary      봇 생성
+// @Description  봇 계정을 생성합니다. 봇은 비밀번호로 로그인할 수 없으며, 생성 시에만 반환되는 API 토큰을 Authorization: Bearer 헤더나 /ws의 token 파라미터로 사용합니다. 채팅방에 초대된 봇은 일반 사용자와 같은 API로 메시지를 보내고 /ws 또는 발신 웹훅으로 이벤트를 받습니다.
+// @Tags         Bot
+// @Accept       json
+// @Produce      json
+// @Param        request body CreateBotRequest true "봇 정보"
+// @Success      201  {object}  common.BotResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      409  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bots [post]
+func (bc *BotController) CreateBot(c *fiber.Ctx) error {
+	var req CreateBotRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	bot, err := bc.botUseCase.CreateBot(userID, req.Nickname)
+	if err != nil {
+		return sendBotError(c, err)
+	}
+
+	return interfaces.SendCreated(c, bot)
+}
+
+// GetBots godoc
+// @Summary      봇 목록 조회
+// @Description  사용자가 만든 봇 목록을 조회합니다
+// @Tags         Bot
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  common.BotListResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bots [get]
+func (bc *BotController) GetBots(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	bots, err := bc.botUseCase.GetBots(userID)
+	if err != nil {
+		return interfaces.SendInternalError(c)
+	}
+
+	return interfaces.SendSuccess(c, bots)
+}
+
+// RotateBotToken godoc
+// @Summary      봇 토큰 재발급
+// @Description  봇의 API 토큰을 새로 발급합니다. 이전 토큰은 즉시 사용할 수 없게 되며, 이미 연결된 WebSocket은 유지됩니다.
+// @Tags         Bot
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "봇 사용자 ID"
+// @Success      200  {object}  common.BotResponse
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/bots/{id}/token [post]
+func (bc *BotController) RotateBotToken(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 봇 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	bot, err := bc.botUseCase.RotateToken(id, userID)
+	if err != nil {
+		return sendBotError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, bot)
+}
+
+func sendBotError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "bot not found":
+		return interfaces.SendNotFound(c, "봇")
+	case "bots cannot manage bots", "user not found":
+		return interfaces.SendForbidden(c)
+	case "nickname already exists":
+		return c.Status(fiber.StatusConflict).JSON(common.NicknameExists)
+	case "nickname must be between 2 and 20 characters":
+		return interfaces.SendBadRequest(c, "닉네임은 2자 이상 20자 이하여야 합니다")
+	case "nickname can only contain letters, numbers and underscores":
+		return interfaces.SendBadRequest(c, "닉네임은 문자, 숫자, 밑줄(_)과 마침표(.)만 사용할 수 있습니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
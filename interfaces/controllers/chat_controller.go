@@ -1,13 +1,20 @@
 package controllers
 
 import (
+	"fmt"
+
 	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/interfaces"
 	"github.com/gofiber/fiber/v2"
 )
 
+// @Description 1:1 채팅 생성 요청
 type CreatePrivateChatRequest struct {
+	// 상대 사용자 ID
 	TargetId int `json:"targetId" example:"1"`
+	// 종단 간 암호화 채팅방으로 만듭니다. 생성 후에는 변경할 수 없습니다.
+	Encrypted bool `json:"encrypted,omitempty" example:"false"`
 }
 
 // @Description 그룹 채팅방 생성 요청
@@ -18,6 +25,44 @@ type CreateGroupChatRequest struct {
 	UserIDs []int `json:"userIds" example:"1,2,3"`
 }
 
+// @Description 사라지는 메시지 설정 요청
+type SetDisappearingMessagesRequest struct {
+	// 새 메시지가 유지되는 시간(초). 0이면 사라지는 메시지를 끕니다.
+	TTLSeconds int `json:"ttlSeconds" example:"86400"`
+}
+
+// @Description 슬로우 모드 설정 요청
+type SetSlowModeRequest struct {
+	// 참여자가 메시지를 보낸 뒤 다음 메시지까지 기다려야 하는 시간(초). 0이면 슬로우 모드를 끕니다.
+	Seconds int `json:"seconds" example:"30"`
+}
+
+// @Description 메시지 보관 정책 설정 요청
+type SetRetentionRequest struct {
+	// 작성된 지 이 일수가 지난 메시지를 삭제합니다. 0이면 기간 제한이 없습니다.
+	Days int `json:"days" example:"365"`
+	// 최근 이 개수를 넘는 오래된 메시지를 삭제합니다. 0이면 개수 제한이 없습니다.
+	MaxMessages int `json:"maxMessages" example:"0"`
+}
+
+// @Description 채팅방 이름 변경 요청
+type RenameChatRequest struct {
+	// 새 채팅방 이름
+	Name string `json:"name" example:"개발팀"`
+}
+
+// @Description 채팅방 멤버 초대 요청
+type AddMembersRequest struct {
+	// 초대할 사용자 ID 목록
+	UserIDs []int `json:"userIds" example:"4,5"`
+}
+
+// @Description 채팅방 멤버 역할 변경 요청
+type SetMemberRoleRequest struct {
+	// 새 역할 (admin 또는 member)
+	Role string `json:"role" example:"admin"`
+}
+
 type ChatController struct {
 	chatUseCase    *usecase.ChatUsecase
 	messageUseCase *usecase.MessageUsecase
@@ -57,7 +102,7 @@ func (cc *ChatController) GetUserChats(c *fiber.Ctx) error {
 
 // CreatePrivateChat godoc
 // @Summary      1:1 채팅 생성
-// @Description  두 사용자 간의 1:1 채팅을 생성합니다
+// @Description  두 사용자 간의 1:1 채팅을 생성합니다. encrypted를 지정하면 종단 간 암호화 채팅방이 되며, 메시지 content에는 각 기기에서 암호화한 봉투(envelope)를 보내야 합니다. 서버는 봉투를 해독하지 않고 저장·전달하므로 모더레이션, 검색, 링크 미리보기, 투표, 전달, 멤버 초대를 사용할 수 없습니다. 봇과는 암호화 채팅을 만들 수 없습니다.
 // @Tags         Chat
 // @Accept       json
 // @Produce      json
@@ -75,11 +120,13 @@ func (cc *ChatController) CreatePrivateChat(c *fiber.Ctx) error {
 
 	userID := c.Locals("userId").(int)
 
-	chat, err := cc.chatUseCase.CreatePrivateChat(userID, req.TargetId)
+	chat, err := cc.chatUseCase.CreatePrivateChat(userID, req.TargetId, req.Encrypted)
 	if err != nil {
 		switch err.Error() {
 		case "user1 not found", "user2 not found":
 			return interfaces.SendNotFound(c, "사용자")
+		case "not supported in encrypted chats":
+			return interfaces.SendBadRequest(c, "봇과는 암호화 채팅방을 만들 수 없습니다")
 		default:
 			return interfaces.SendInternalError(c)
 		}
@@ -130,7 +177,7 @@ func (cc *ChatController) CreateGroupChat(c *fiber.Ctx) error {
 		req.UserIDs = append(req.UserIDs, currentUserID)
 	}
 
-	chat, err := cc.chatUseCase.CreateGroupChat(req.Name, req.UserIDs)
+	chat, err := cc.chatUseCase.CreateGroupChat(currentUserID, req.Name, req.UserIDs)
 	if err != nil {
 		switch err.Error() {
 		case "user not found":
@@ -147,6 +194,318 @@ func (cc *ChatController) CreateGroupChat(c *fiber.Ctx) error {
 	return interfaces.SendCreated(c, chat)
 }
 
+// SetDisappearingMessages godoc
+// @Summary      사라지는 메시지 설정
+// @Description  채팅방의 새 메시지가 지정한 시간 후 사라지도록 설정합니다. 채팅방 관리자만 변경할 수 있으며, 이미 보낸 메시지에는 적용되지 않습니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body SetDisappearingMessagesRequest true "메시지 유지 시간(초)"
+// @Success      200  {object}  common.ChatResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/disappearing [put]
+func (cc *ChatController) SetDisappearingMessages(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req SetDisappearingMessagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chat, err := cc.chatUseCase.SetMessageTTL(chatID, userID, req.TTLSeconds)
+	if err != nil {
+		switch err.Error() {
+		case "invalid message ttl":
+			return interfaces.SendBadRequest(c, fmt.Sprintf("메시지 유지 시간은 0 또는 %d초에서 %d초 사이여야 합니다", models.MinMessageTTLSeconds, models.MaxMessageTTLSeconds))
+		case "chat not found":
+			return interfaces.SendNotFound(c, "채팅방")
+		case "user is not a member of this chat", "unauthorized to update this chat":
+			return interfaces.SendForbidden(c)
+		default:
+			return interfaces.SendInternalError(c)
+		}
+	}
+
+	return interfaces.SendSuccess(c, chat)
+}
+
+// SetSlowMode godoc
+// @Summary      슬로우 모드 설정
+// @Description  채팅방 참여자가 지정한 시간(초)마다 한 번만 메시지를 보낼 수 있도록 합니다. 채팅방 관리자만 변경할 수 있으며, 소유자와 관리자에게는 적용되지 않습니다. 제한에 걸린 전송은 429와 Retry-After 헤더로 응답합니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body SetSlowModeRequest true "메시지 간격(초)"
+// @Success      200  {object}  common.ChatResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/slow-mode [put]
+func (cc *ChatController) SetSlowMode(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req SetSlowModeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chat, err := cc.chatUseCase.SetSlowMode(chatID, userID, req.Seconds)
+	if err != nil {
+		switch err.Error() {
+		case "invalid slow mode interval":
+			return interfaces.SendBadRequest(c, fmt.Sprintf("슬로우 모드 간격은 0초에서 %d초 사이여야 합니다", models.MaxSlowModeSeconds))
+		case "chat not found":
+			return interfaces.SendNotFound(c, "채팅방")
+		case "user is not a member of this chat", "unauthorized to update this chat":
+			return interfaces.SendForbidden(c)
+		default:
+			return interfaces.SendInternalError(c)
+		}
+	}
+
+	return interfaces.SendSuccess(c, chat)
+}
+
+// SetRetention godoc
+// @Summary      메시지 보관 정책 설정
+// @Description  채팅방 메시지의 보관 기간과 최대 개수를 설정합니다. 기한이 지나거나 개수를 넘는 메시지는 주기적인 정리 작업에서 삭제됩니다. 서버 보관 정책이 더 엄격하면 서버 정책이 적용되며, 법적 보존 조치가 걸린 채팅방은 삭제하지 않습니다. 채팅방 관리자만 변경할 수 있습니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body SetRetentionRequest true "보관 정책"
+// @Success      200  {object}  common.ChatResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/retention [put]
+func (cc *ChatController) SetRetention(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req SetRetentionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chat, err := cc.chatUseCase.SetRetention(chatID, userID, models.RetentionPolicy{Days: req.Days, MaxMessages: req.MaxMessages})
+	if err != nil {
+		switch err.Error() {
+		case "invalid retention policy":
+			return interfaces.SendBadRequest(c, fmt.Sprintf("보관 기간은 0일에서 %d일, 최대 메시지 수는 0개에서 %d개 사이여야 합니다",
+				models.MaxRetentionDays, models.MaxRetentionMessages))
+		case "chat not found":
+			return interfaces.SendNotFound(c, "채팅방")
+		case "user is not a member of this chat", "unauthorized to update this chat":
+			return interfaces.SendForbidden(c)
+		default:
+			return interfaces.SendInternalError(c)
+		}
+	}
+
+	return interfaces.SendSuccess(c, chat)
+}
+
+// RenameChat godoc
+// @Summary      채팅방 이름 변경
+// @Description  채팅방 이름을 변경합니다. 채팅방 관리자만 변경할 수 있으며, 변경 내역이 시스템 메시지로 기록됩니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body RenameChatRequest true "새 채팅방 이름"
+// @Success      200  {object}  common.ChatResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId} [put]
+func (cc *ChatController) RenameChat(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req RenameChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chat, err := cc.chatUseCase.RenameChat(chatID, userID, req.Name)
+	if err != nil {
+		return sendChatMemberError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, chat)
+}
+
+// AddMembers godoc
+// @Summary      채팅방 멤버 초대
+// @Description  채팅방에 사용자를 초대합니다. 채팅방 관리자만 초대할 수 있으며, 이미 참여중인 사용자는 제외됩니다. 초대 내역이 시스템 메시지로 기록됩니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body AddMembersRequest true "초대할 사용자 ID 목록"
+// @Success      200  {object}  common.ChatMembersResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/members [post]
+func (cc *ChatController) AddMembers(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req AddMembersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	users, err := cc.chatUseCase.AddMembers(chatID, userID, req.UserIDs)
+	if err != nil {
+		return sendChatMemberError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, users)
+}
+
+// RemoveMember godoc
+// @Summary      채팅방 멤버 내보내기 / 나가기
+// @Description  채팅방에서 사용자를 내보냅니다. 자신의 ID를 지정하면 채팅방에서 나갑니다. 다른 멤버는 채팅방 관리자만 내보낼 수 있으며, 방장은 내보낼 수 없습니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        userId   path      int  true  "사용자 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/members/{userId} [delete]
+func (cc *ChatController) RemoveMember(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	targetID, err := c.ParamsInt("userId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 사용자 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := cc.chatUseCase.RemoveMember(chatID, userID, targetID); err != nil {
+		return sendChatMemberError(c, err)
+	}
+
+	if targetID == userID {
+		return interfaces.SendSuccess(c, "채팅방에서 나갔습니다")
+	}
+	return interfaces.SendSuccess(c, "멤버를 내보냈습니다")
+}
+
+// SetMemberRole godoc
+// @Summary      채팅방 멤버 역할 변경
+// @Description  멤버를 채팅방 관리자로 지정하거나 관리자 권한을 해제합니다. 방장만 변경할 수 있으며, 방장의 역할은 변경할 수 없습니다. 변경 내역이 시스템 메시지로 기록됩니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        userId   path      int  true  "사용자 ID"
+// @Param        request body SetMemberRoleRequest true "새 역할"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/members/{userId}/role [put]
+func (cc *ChatController) SetMemberRole(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	targetID, err := c.ParamsInt("userId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 사용자 ID입니다")
+	}
+
+	var req SetMemberRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := cc.chatUseCase.SetMemberRole(chatID, userID, targetID, req.Role); err != nil {
+		return sendChatMemberError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, "멤버 역할을 변경했습니다")
+}
+
+func sendChatMemberError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "user not found":
+		return interfaces.SendNotFound(c, "사용자")
+	case "member not found":
+		return interfaces.SendNotFound(c, "멤버")
+	case "user is not a member of this chat", "unauthorized to update this chat":
+		return interfaces.SendForbidden(c)
+	case "chat name is required":
+		return interfaces.SendBadRequest(c, "채팅방 이름은 필수 항목입니다")
+	case "at least one other user is required":
+		return interfaces.SendBadRequest(c, "초대할 사용자가 한 명 이상 필요합니다")
+	case "users already in chat":
+		return interfaces.SendBadRequest(c, "이미 채팅방에 참여중인 사용자입니다")
+	case "invalid role":
+		return interfaces.SendBadRequest(c, "역할은 admin 또는 member만 지정할 수 있습니다")
+	case "encrypted chats are private":
+		return interfaces.SendBadRequest(c, "암호화된 채팅방에는 멤버를 초대할 수 없습니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
+
 func (cc *ChatController) GetChats(c *fiber.Ctx) error {
 	userID := c.Locals("userId").(int)
 
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CommandController struct {
+	commandUseCase *usecase.CommandUsecase
+}
+
+func NewCommandController(commandUseCase *usecase.CommandUsecase) *CommandController {
+	return &CommandController{
+		commandUseCase: commandUseCase,
+	}
+}
+
+// GetCommands godoc
+// @Summary      명령어 목록 조회
+// @Description  메시지로 입력할 수 있는 슬래시 명령어와 인자 목록을 조회합니다. '/'로 시작하는 메시지는 명령어로 실행되며, '//'로 시작하면 '/'로 시작하는 일반 메시지로 전송됩니다.
+// @Tags         Message
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  common.CommandListResponse
+// @Security     Bearer
+// @Router       /api/commands [get]
+func (cc *CommandController) GetCommands(c *fiber.Ctx) error {
+	return interfaces.SendSuccess(c, cc.commandUseCase.GetCommands())
+}
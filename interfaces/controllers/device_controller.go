@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Description 일회용 프리키
+type PrekeyRequest struct {
+	// 기기 안에서 프리키를 구분하는 번호
+	KeyId int `json:"keyId" example:"17"`
+	// base64로 인코딩한 공개키
+	PublicKey string `json:"publicKey" example:"b3V0Ym91bmQtcHJla2V5LWV4YW1wbGUtMzItYnl0ZXM=" validate:"required"`
+}
+
+// @Description 암호화 기기 등록 요청
+type RegisterDeviceRequest struct {
+	// 기기 이름
+	Name string `json:"name" example:"내 노트북" validate:"required"`
+	// base64로 인코딩한 기기의 장기 공개키
+	IdentityKey string `json:"identityKey" example:"mQ0RZ9l1g3cYz6Jt1V1xw2o0pQmGk3J0T8Vh1o9aN2U=" validate:"required"`
+	// 일회용 프리키 목록
+	Prekeys []PrekeyRequest `json:"prekeys"`
+}
+
+// @Description 일회용 프리키 추가 요청
+type AddPrekeysRequest struct {
+	// 추가할 일회용 프리키 목록
+	Prekeys []PrekeyRequest `json:"prekeys" validate:"required"`
+}
+
+type DeviceController struct {
+	deviceUseCase *usecase.DeviceUsecase
+}
+
+func NewDeviceController(deviceUseCase *usecase.DeviceUsecase) *DeviceController {
+	return &DeviceController{
+		deviceUseCase: deviceUseCase,
+	}
+}
+
+// RegisterDevice godoc
+// @Summary      암호화 기기 등록
+// @Description  종단 간 암호화에 사용할 기기의 공개키와 일회용 프리키를 등록합니다. 개인키는 기기 밖으로 보내지 않습니다. 암호화 채팅방을 함께 쓰는 사용자에게 device.added 이벤트가 전송됩니다.
+// @Tags         Device
+// @Accept       json
+// @Produce      json
+// @Param        request body RegisterDeviceRequest true "기기 정보"
+// @Success      201  {object}  common.DeviceResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/devices [post]
+func (dc *DeviceController) RegisterDevice(c *fiber.Ctx) error {
+	var req RegisterDeviceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	device, err := dc.deviceUseCase.RegisterDevice(usecase.RegisterDeviceInput{
+		UserID:      userID,
+		Name:        req.Name,
+		IdentityKey: req.IdentityKey,
+		Prekeys:     toPrekeys(req.Prekeys),
+	})
+	if err != nil {
+		return sendDeviceError(c, err)
+	}
+
+	return interfaces.SendCreated(c, device)
+}
+
+// GetDevices godoc
+// @Summary      내 암호화 기기 목록 조회
+// @Description  등록한 기기 목록과 기기별로 남은 일회용 프리키 개수를 조회합니다.
+// @Tags         Device
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  common.DeviceListResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/devices [get]
+func (dc *DeviceController) GetDevices(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	devices, err := dc.deviceUseCase.GetDevices(userID)
+	if err != nil {
+		return sendDeviceError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, devices)
+}
+
+// AddPrekeys godoc
+// @Summary      일회용 프리키 추가
+// @Description  기기의 일회용 프리키를 추가합니다. 이미 등록된 번호의 프리키는 무시됩니다.
+// @Tags         Device
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "기기 ID"
+// @Param        request body AddPrekeysRequest true "추가할 프리키"
+// @Success      200  {object}  common.DeviceResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      404  {object}  common.ErrDeviceNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/devices/{id}/prekeys [post]
+func (dc *DeviceController) AddPrekeys(c *fiber.Ctx) error {
+	deviceID, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 기기 ID입니다")
+	}
+
+	var req AddPrekeysRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	device, err := dc.deviceUseCase.AddPrekeys(deviceID, userID, toPrekeys(req.Prekeys))
+	if err != nil {
+		return sendDeviceError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, device)
+}
+
+// RemoveDevice godoc
+// @Summary      암호화 기기 삭제
+// @Description  기기와 기기의 모든 키를 삭제합니다. 암호화 채팅방을 함께 쓰는 사용자에게 device.removed 이벤트가 전송되며, 이후 이 기기용으로 암호화하지 않습니다.
+// @Tags         Device
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "기기 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      404  {object}  common.ErrDeviceNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/devices/{id} [delete]
+func (dc *DeviceController) RemoveDevice(c *fiber.Ctx) error {
+	deviceID, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 기기 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := dc.deviceUseCase.RemoveDevice(deviceID, userID); err != nil {
+		return sendDeviceError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, nil)
+}
+
+// GetUserKeys godoc
+// @Summary      사용자 기기 키 조회
+// @Description  사용자의 기기별 공개키와 일회용 프리키를 하나씩 조회합니다. 조회된 프리키는 다시 사용되지 않습니다. 본인 또는 암호화 채팅방을 함께 쓰는 사용자만 조회할 수 있습니다.
+// @Tags         Device
+// @Accept       json
+// @Produce      json
+// @Param        userId   path      int  true  "사용자 ID"
+// @Success      200  {object}  common.UserKeysResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrUserNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/users/{userId}/keys [get]
+func (dc *DeviceController) GetUserKeys(c *fiber.Ctx) error {
+	targetID, err := c.ParamsInt("userId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 사용자 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	keys, err := dc.deviceUseCase.GetUserKeys(targetID, userID)
+	if err != nil {
+		return sendDeviceError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, keys)
+}
+
+func toPrekeys(requests []PrekeyRequest) []models.Prekey {
+	prekeys := make([]models.Prekey, len(requests))
+	for i, req := range requests {
+		prekeys[i] = models.Prekey{KeyId: req.KeyId, PublicKey: req.PublicKey}
+	}
+	return prekeys
+}
+
+func sendDeviceError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "device not found":
+		return interfaces.SendNotFound(c, "기기")
+	case "user not found":
+		return interfaces.SendNotFound(c, "사용자")
+	case "unauthorized to fetch keys":
+		return interfaces.SendForbidden(c)
+	case "invalid device name":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("기기 이름은 1자 이상 %d자 이하여야 합니다", models.MaxDeviceNameLength))
+	case "invalid public key":
+		return interfaces.SendBadRequest(c, "공개키 형식이 올바르지 않습니다")
+	case "invalid prekey":
+		return interfaces.SendBadRequest(c, "프리키 형식이 올바르지 않거나 번호가 중복되었습니다")
+	case "too many prekeys":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("기기당 프리키는 최대 %d개까지 등록할 수 있습니다", models.MaxPrekeysPerDevice))
+	case "too many devices":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("기기는 최대 %d대까지 등록할 수 있습니다", models.MaxDevicesPerUser))
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SaveDraftRequest represents the request for saving a draft
+type SaveDraftRequest struct {
+	// 임시 저장할 내용. 빈 문자열이면 임시 저장이 삭제됩니다.
+	Content string `json:"content" example:"내일 회의는"`
+	// 클라이언트에서 내용을 수정한 시각. 생략하면 서버 시각이 사용됩니다.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" example:"2024-03-23T12:00:00Z"`
+}
+
+type DraftController struct {
+	draftUseCase *usecase.DraftUsecase
+}
+
+func NewDraftController(draftUseCase *usecase.DraftUsecase) *DraftController {
+	return &DraftController{
+		draftUseCase: draftUseCase,
+	}
+}
+
+// SaveDraft godoc
+// @Summary      임시 저장 메시지 저장
+// @Description  채팅방에 작성 중인 메시지를 임시 저장하거나 삭제합니다. 더 최근에 저장된 내용이 있으면 저장하지 않고 기존 내용을 반환합니다(applied=false). 저장되면 사용자의 다른 연결에 draft.updated 이벤트가 전달됩니다.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Param        chatId           path    int               true   "채팅방 ID"
+// @Param        X-Connection-Id  header  string            false  "요청을 보낸 WebSocket 연결 ID (이 연결에는 이벤트를 보내지 않습니다)"
+// @Param        request          body    SaveDraftRequest  true   "임시 저장 내용"
+// @Success      200  {object}  common.DraftResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/draft [put]
+func (dc *DraftController) SaveDraft(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req SaveDraftRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	draft, err := dc.draftUseCase.SaveDraft(userID, chatID, req.Content, req.UpdatedAt, c.Get("X-Connection-Id"))
+	if err != nil {
+		switch err.Error() {
+		case "draft too long":
+			return interfaces.SendBadRequest(c, "임시 저장 내용이 너무 깁니다")
+		case "chat not found":
+			return interfaces.SendNotFound(c, "채팅방")
+		case "user is not a member of this chat":
+			return interfaces.SendForbidden(c)
+		default:
+			return interfaces.SendInternalError(c)
+		}
+	}
+
+	return interfaces.SendSuccess(c, draft)
+}
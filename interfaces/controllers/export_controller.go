@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateExportRequest represents the request for exporting a chat
+type CreateExportRequest struct {
+	// json: 하나의 JSON 문서, csv: members.csv와 messages.csv를 담은 zip 파일, html: 앱 없이 열 수 있는 HTML 페이지
+	Format string `json:"format" example:"html" enums:"json,csv,html" validate:"required"`
+}
+
+type ExportController struct {
+	exportUseCase *usecase.ExportUsecase
+}
+
+func NewExportController(exportUseCase *usecase.ExportUsecase) *ExportController {
+	return &ExportController{
+		exportUseCase: exportUseCase,
+	}
+}
+
+// CreateExport godoc
+// @Summary      대화 내보내기 요청
+// @Description  채팅방의 메시지(수정 시각, 카드 정보 포함)와 멤버 목록을 파일로 내보냅니다. 파일은 백그라운드에서 생성되므로 상태 조회 API로 완료 여부를 확인한 뒤 다운로드합니다. 채팅방의 소유자와 관리자만 요청할 수 있으며, 완료된 파일은 7일 동안 보관됩니다.
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body CreateExportRequest true "내보내기 형식"
+// @Success      201  {object}  common.ChatExportResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/exports [post]
+func (ec *ExportController) CreateExport(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req CreateExportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chatExport, err := ec.exportUseCase.CreateExport(chatID, userID, req.Format)
+	if err != nil {
+		return sendExportError(c, err)
+	}
+
+	return interfaces.SendCreated(c, chatExport)
+}
+
+// GetExports godoc
+// @Summary      대화 내보내기 목록 조회
+// @Description  채팅방의 최근 내보내기 20개를 최신순으로 조회합니다
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Success      200  {object}  common.ChatExportListResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/exports [get]
+func (ec *ExportController) GetExports(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	exports, err := ec.exportUseCase.GetExports(chatID, userID)
+	if err != nil {
+		return sendExportError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, exports)
+}
+
+// GetExport godoc
+// @Summary      대화 내보내기 상태 조회
+// @Description  내보내기의 진행 상태를 조회합니다. 완료되면 downloadUrl이 함께 반환됩니다.
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "내보내기 ID"
+// @Success      200  {object}  common.ChatExportResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrExportNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/exports/{id} [get]
+func (ec *ExportController) GetExport(c *fiber.Ctx) error {
+	exportID, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 내보내기 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chatExport, err := ec.exportUseCase.GetExport(exportID, userID)
+	if err != nil {
+		return sendExportError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, chatExport)
+}
+
+// DownloadExport godoc
+// @Summary      대화 내보내기 다운로드
+// @Description  완료된 내보내기 파일을 다운로드합니다
+// @Tags         Export
+// @Produce      application/json,application/zip,text/html
+// @Param        id   path      int  true  "내보내기 ID"
+// @Success      200  {file}    file
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrExportNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/exports/{id}/download [get]
+func (ec *ExportController) DownloadExport(c *fiber.Ctx) error {
+	exportID, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 내보내기 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	download, err := ec.exportUseCase.OpenExport(exportID, userID)
+	if err != nil {
+		return sendExportError(c, err)
+	}
+
+	// Sets Content-Disposition and the content type from the file extension
+	c.Attachment(download.FileName)
+	// The file is closed once the response is written
+	return c.SendStream(download.File, int(download.Size))
+}
+
+func sendExportError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "invalid export format":
+		return interfaces.SendBadRequest(c, "내보내기 형식은 json, csv, html 중 하나여야 합니다")
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "export not found":
+		return interfaces.SendNotFound(c, "내보내기")
+	case "export expired":
+		return interfaces.SendError(c, fiber.StatusNotFound, interfaces.StatusNotFound, "보관 기간이 지나 내보내기 파일이 삭제되었습니다")
+	case "export not ready":
+		return interfaces.SendBadRequest(c, "내보내기가 아직 완료되지 않았습니다")
+	case "export failed":
+		return interfaces.SendBadRequest(c, "내보내기에 실패했습니다. 다시 요청해주세요")
+	case "user is not a member of this chat", "unauthorized to export this chat":
+		return interfaces.SendForbidden(c)
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
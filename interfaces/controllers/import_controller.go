@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImportController struct {
+	importUseCase *usecase.ImportUsecase
+}
+
+func NewImportController(importUseCase *usecase.ImportUsecase) *ImportController {
+	return &ImportController{
+		importUseCase: importUseCase,
+	}
+}
+
+// CreateImport godoc
+// @Summary      Slack 내보내기 가져오기
+// @Description  Slack 워크스페이스 내보내기 zip 파일을 업로드해 사용자, 채널, 메시지(스레드, 반응 포함)를 가져옵니다. 가져오기는 백그라운드에서 진행되므로 상태 조회 API로 진행 상황을 확인합니다.
+// @Description  사용자는 이메일이 같은 계정에 연결되고, 없으면 로그인할 수 없는 대체 계정이 만들어집니다. 메시지는 원래 시각과 순서를 유지하며, 같은 파일을 다시 가져와도 이미 가져온 항목은 건너뜁니다.
+// @Description  서버에 설정된 관리자만 요청할 수 있습니다. 업로드 크기는 서버의 요청 크기 제한을 따르므로, 큰 파일은 서버에서 import-slack 명령으로 가져옵니다.
+// @Tags         Import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        archive  formData  file  true  "Slack 내보내기 zip 파일"
+// @Success      201  {object}  common.ChatImportResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/imports [post]
+func (ic *ImportController) CreateImport(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "가져올 파일을 archive 필드로 업로드해주세요")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Error("Failed to open uploaded archive: %v", err)
+		return interfaces.SendInternalError(c)
+	}
+	defer file.Close()
+
+	userID := c.Locals("userId").(int)
+
+	chatImport, err := ic.importUseCase.CreateImport(userID, file)
+	if err != nil {
+		return sendImportError(c, err)
+	}
+
+	return interfaces.SendCreated(c, chatImport)
+}
+
+// GetImport godoc
+// @Summary      Slack 가져오기 상태 조회
+// @Description  가져오기의 진행 상태와 지금까지 새로 만든 사용자, 채팅방, 메시지 수를 조회합니다
+// @Tags         Import
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "가져오기 ID"
+// @Success      200  {object}  common.ChatImportResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrImportNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/imports/{id} [get]
+func (ic *ImportController) GetImport(c *fiber.Ctx) error {
+	importID, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 가져오기 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chatImport, err := ic.importUseCase.GetImport(importID, userID)
+	if err != nil {
+		return sendImportError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, chatImport)
+}
+
+// ResumeImport godoc
+// @Summary      Slack 가져오기 재개
+// @Description  실패한 가져오기를 다시 시작합니다. 이미 가져온 사용자, 채팅방, 메시지는 건너뜁니다.
+// @Tags         Import
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "가져오기 ID"
+// @Success      200  {object}  common.ChatImportResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrImportNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/imports/{id}/resume [post]
+func (ic *ImportController) ResumeImport(c *fiber.Ctx) error {
+	importID, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 가져오기 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	chatImport, err := ic.importUseCase.ResumeImport(importID, userID)
+	if err != nil {
+		return sendImportError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, chatImport)
+}
+
+func sendImportError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "invalid import archive":
+		return interfaces.SendBadRequest(c, "Slack 내보내기 zip 파일이 아닙니다")
+	case "import not found":
+		return interfaces.SendNotFound(c, "가져오기")
+	case "import not resumable":
+		return interfaces.SendBadRequest(c, "실패한 가져오기만 재개할 수 있습니다")
+	case "user not found", "unauthorized to import":
+		return interfaces.SendForbidden(c)
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
@@ -1,7 +1,12 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/interfaces"
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,6 +15,20 @@ import (
 type SendMessageRequest struct {
 	ChatID  int    `json:"chatId" example:"1" validate:"required"`
 	Content string `json:"content" example:"Hello, how are you?" validate:"required"`
+	// 메시지가 사라지기까지의 시간(초). 생략하면 채팅방의 사라지는 메시지 설정을 따릅니다.
+	TTLSeconds int `json:"ttlSeconds,omitempty" example:"60"`
+	// 재전송 시 중복 전송을 막기 위한 클라이언트 생성 ID. Idempotency-Key 헤더로도 전달할 수 있습니다.
+	ClientMessageID string `json:"clientMessageId,omitempty" example:"7f9c2d1e-5b4a-4c3e-9a8b-1d2e3f4a5b6c"`
+	// 메시지 아래에 표시할 카드 (봇 전용, 최대 10개)
+	Attachments []models.MessageAttachment `json:"attachments,omitempty"`
+}
+
+// ForwardMessagesRequest represents the request for forwarding messages
+type ForwardMessagesRequest struct {
+	// 전달할 메시지 ID 목록
+	MessageIDs []int `json:"messageIds" example:"1,2" validate:"required"`
+	// 메시지를 전달받을 채팅방 ID 목록
+	ChatIDs []int `json:"chatIds" example:"3" validate:"required"`
 }
 
 // UpdateMessageRequest represents the request for updating a message
@@ -29,13 +48,18 @@ func NewMessageController(messageUseCase *usecase.MessageUsecase) *MessageContro
 
 // SendMessage godoc
 // @Summary      메시지 전송
-// @Description  채팅방에 새로운 메시지를 전송합니다
+// @Description  채팅방에 새로운 메시지를 전송합니다. 봇은 attachments로 카드를 함께 보낼 수 있습니다. 같은 클라이언트 메시지 ID로 재전송하면 처음 저장된 메시지를 반환합니다. '/'로 시작하는 메시지는 명령어로 실행되며, 명령어의 응답은 요청한 사용자에게만 messageType이 ephemeral인 메시지로 반환됩니다(200). 메시지는 저장 전에 모더레이션 필터를 거치며, 금지어가 가려지거나 검토 대기열에 오르거나 거부(422)될 수 있습니다. 사용자와 채팅방마다 전송 속도가 제한되며, 슬로우 모드인 채팅방에서는 관리자가 아닌 멤버가 설정된 간격마다 한 번만 보낼 수 있습니다. 제한을 넘으면 Retry-After 헤더와 함께 429를 반환합니다. WebSocket으로는 message.send 프레임으로 보낼 수 있습니다.
 // @Tags         Message
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "클라이언트 메시지 ID"
 // @Param        request body SendMessageRequest true "메시지 정보"
 // @Success      201  {object}  common.MessageResponse
+// @Success      200  {object}  common.MessageResponse
 // @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      422  {object}  common.ErrMessageRejected
+// @Failure      429  {object}  common.ErrMessageRateLimited
 // @Failure      500  {object}  common.ErrInternalServer
 // @Security     Bearer
 // @Router       /api/messages [post]
@@ -49,24 +73,150 @@ func (mc *MessageController) SendMessage(c *fiber.Ctx) error {
 		return interfaces.SendBadRequest(c, "메시지 내용은 필수 항목입니다")
 	}
 
+	clientMessageID := req.ClientMessageID
+	if key := c.Get("Idempotency-Key"); key != "" {
+		if clientMessageID != "" && clientMessageID != key {
+			return interfaces.SendBadRequest(c, "Idempotency-Key 헤더와 clientMessageId가 일치하지 않습니다")
+		}
+		clientMessageID = key
+	}
+
+	userID := c.Locals("userId").(int)
+
+	message, err := mc.messageUseCase.SendMessage(usecase.SendMessageInput{
+		ChatID:          req.ChatID,
+		SenderID:        userID,
+		Content:         req.Content,
+		TTLSeconds:      req.TTLSeconds,
+		ClientMessageID: clientMessageID,
+		Attachments:     req.Attachments,
+		SenderIsBot:     c.Locals("isBot") == true,
+	})
+	if err != nil {
+		var limited *models.RateLimitError
+		if errors.As(err, &limited) {
+			return interfaces.SendRateLimited(c, limited.RetryAfterSeconds(), rateLimitMessage(limited))
+		}
+		status, code, message := messageSendError(err)
+		return interfaces.SendError(c, status, code, message)
+	}
+
+	// Command replies are not stored
+	if message.MessageType == models.MessageTypeEphemeral {
+		return interfaces.SendSuccess(c, message)
+	}
+	return interfaces.SendCreated(c, message)
+}
+
+// messageSendError maps an error of sending a message to its HTTP status,
+// response code and message. WebSocket sends report the same code and message.
+func messageSendError(err error) (int, int, string) {
+	var rejected *models.ModerationError
+	if errors.As(err, &rejected) {
+		return fiber.StatusUnprocessableEntity, interfaces.StatusMessageRejected, interfaces.MessageRejectedMessage(rejected.Reason)
+	}
+
+	switch err.Error() {
+	case "chat not found":
+		return fiber.StatusNotFound, interfaces.StatusNotFound, interfaces.NotFoundMessage("채팅방")
+	case "only bots can send attachments":
+		return fiber.StatusForbidden, interfaces.StatusForbidden, interfaces.ForbiddenMessage
+	case "too many attachments":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, fmt.Sprintf("카드는 최대 %d개까지 보낼 수 있습니다", models.MaxAttachments)
+	case "invalid attachment":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, "잘못된 카드 형식입니다"
+	case "invalid message ttl":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, "잘못된 메시지 유지 시간입니다"
+	case "invalid client message id":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, fmt.Sprintf("클라이언트 메시지 ID는 공백 없이 %d자 이하여야 합니다", models.MaxClientMessageIDLength)
+	case "client message id already used":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, "다른 채팅방에서 이미 사용된 클라이언트 메시지 ID입니다"
+	case "user is not a member of this chat", "unauthorized to update this chat":
+		return fiber.StatusForbidden, interfaces.StatusForbidden, interfaces.ForbiddenMessage
+	case "chat topic too long":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, fmt.Sprintf("채팅방 주제는 최대 %d자까지 입력할 수 있습니다", models.MaxChatTopicLength)
+	case "invalid encrypted envelope":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, "암호화 봉투 형식이 올바르지 않습니다"
+	case "unknown sender device":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, "등록되지 않은 기기로 암호화한 메시지입니다"
+	case "not supported in encrypted chats":
+		return fiber.StatusBadRequest, interfaces.StatusBadRequest, "암호화된 채팅방에서는 사용할 수 없는 기능입니다"
+	default:
+		return fiber.StatusInternalServerError, interfaces.StatusInternalError, interfaces.InternalErrorMessage
+	}
+}
+
+// rateLimitMessage tells the sender how long to wait before sending again
+func rateLimitMessage(err *models.RateLimitError) string {
+	if err.Scope == models.RateLimitScopeSlowMode {
+		return fmt.Sprintf("슬로우 모드가 켜져 있어 %d초 후에 메시지를 보낼 수 있습니다", err.RetryAfterSeconds())
+	}
+	return fmt.Sprintf("메시지를 너무 빠르게 보내고 있습니다. %d초 후 다시 시도해주세요", err.RetryAfterSeconds())
+}
+
+// ForwardMessages godoc
+// @Summary      메시지 전달
+// @Description  참여중인 채팅방의 메시지를 다른 채팅방으로 전달합니다. 전달된 메시지에는 원본 메시지 정보가 함께 표시됩니다. 전달되는 내용도 받는 채팅방의 모더레이션 필터를 거칩니다.
+// @Tags         Message
+// @Accept       json
+// @Produce      json
+// @Param        request body ForwardMessagesRequest true "전달할 메시지와 채팅방"
+// @Success      201  {object}  common.ForwardMessagesResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      422  {object}  common.ErrMessageRejected
+// @Failure      429  {object}  common.ErrMessageRateLimited
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/messages/forward [post]
+func (mc *MessageController) ForwardMessages(c *fiber.Ctx) error {
+	var req ForwardMessagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
 	userID := c.Locals("userId").(int)
 
-	message, err := mc.messageUseCase.SendMessage(req.ChatID, userID, req.Content)
+	messages, err := mc.messageUseCase.ForwardMessages(userID, req.MessageIDs, req.ChatIDs)
 	if err != nil {
+		var limited *models.RateLimitError
+		if errors.As(err, &limited) {
+			return interfaces.SendRateLimited(c, limited.RetryAfterSeconds(), rateLimitMessage(limited))
+		}
+		var rejected *models.ModerationError
+		if errors.As(err, &rejected) {
+			return interfaces.SendMessageRejected(c, rejected.Reason)
+		}
+
 		switch err.Error() {
+		case "no messages to forward":
+			return interfaces.SendBadRequest(c, "전달할 메시지를 선택해주세요")
+		case "no chats to forward to":
+			return interfaces.SendBadRequest(c, "메시지를 전달할 채팅방을 선택해주세요")
+		case "system messages cannot be modified":
+			return interfaces.SendBadRequest(c, "시스템 메시지는 전달할 수 없습니다")
+		case "encrypted messages cannot be forwarded":
+			return interfaces.SendBadRequest(c, "암호화된 채팅방의 메시지는 전달하거나 암호화된 채팅방으로 전달할 수 없습니다")
+		case "too many messages to forward":
+			return interfaces.SendBadRequest(c, fmt.Sprintf("메시지는 최대 %d개, 채팅방은 최대 %d개까지 전달할 수 있습니다", usecase.MaxForwardMessages, usecase.MaxForwardChats))
+		case "message not found":
+			return interfaces.SendNotFound(c, "메시지")
 		case "chat not found":
 			return interfaces.SendNotFound(c, "채팅방")
+		case "user is not a member of this chat":
+			return interfaces.SendForbidden(c)
 		default:
 			return interfaces.SendInternalError(c)
 		}
 	}
 
-	return interfaces.SendCreated(c, message)
+	return interfaces.SendCreated(c, messages)
 }
 
 // UpdateMessage godoc
 // @Summary      메시지 수정
-// @Description  기존 메시지의 내용을 수정합니다
+// @Description  기존 메시지의 내용을 수정합니다. 투표 메시지는 수정할 수 없습니다. 수정한 내용도 모더레이션 필터를 거칩니다.
 // @Tags         Message
 // @Accept       json
 // @Produce      json
@@ -75,6 +225,7 @@ func (mc *MessageController) SendMessage(c *fiber.Ctx) error {
 // @Success      200  {object}  common.MessageResponse
 // @Failure      400  {object}  common.ErrInvalidRequest
 // @Failure      403  {object}  common.ErrUnauthorizedMessage
+// @Failure      422  {object}  common.ErrMessageRejected
 // @Failure      500  {object}  common.ErrInternalServer
 // @Security     Bearer
 // @Router       /api/messages/{id} [put]
@@ -97,11 +248,26 @@ func (mc *MessageController) UpdateMessage(c *fiber.Ctx) error {
 
 	message, err := mc.messageUseCase.UpdateMessage(messageID, userID, req.Content)
 	if err != nil {
+		var rejected *models.ModerationError
+		if errors.As(err, &rejected) {
+			return interfaces.SendMessageRejected(c, rejected.Reason)
+		}
+
 		switch err.Error() {
 		case "message not found":
 			return interfaces.SendNotFound(c, "메시지")
 		case "unauthorized to update this message":
 			return interfaces.SendForbidden(c)
+		case "system messages cannot be modified":
+			return interfaces.SendBadRequest(c, "시스템 메시지는 수정하거나 삭제할 수 없습니다")
+		case "poll messages cannot be edited":
+			return interfaces.SendBadRequest(c, "투표 메시지는 수정할 수 없습니다")
+		case "webhook messages cannot be edited":
+			return interfaces.SendBadRequest(c, "웹훅 메시지는 수정할 수 없습니다")
+		case "invalid encrypted envelope":
+			return interfaces.SendBadRequest(c, "암호화 봉투 형식이 올바르지 않습니다")
+		case "unknown sender device":
+			return interfaces.SendBadRequest(c, "등록되지 않은 기기로 암호화한 메시지입니다")
 		default:
 			return interfaces.SendInternalError(c)
 		}
@@ -137,6 +303,8 @@ func (mc *MessageController) DeleteMessage(c *fiber.Ctx) error {
 			return interfaces.SendNotFound(c, "메시지")
 		case "unauthorized to delete this message":
 			return interfaces.SendForbidden(c)
+		case "system messages cannot be modified":
+			return interfaces.SendBadRequest(c, "시스템 메시지는 수정하거나 삭제할 수 없습니다")
 		default:
 			return interfaces.SendInternalError(c)
 		}
@@ -147,12 +315,16 @@ func (mc *MessageController) DeleteMessage(c *fiber.Ctx) error {
 
 // GetChatMessages godoc
 // @Summary      채팅방 메시지 조회
-// @Description  채팅방의 메시지를 페이지네이션하여 조회합니다. 한 번에 50개의 메시지를 가져오며, 무한 스크롤을 지원합니다.
+// @Description  채팅방의 메시지를 최신순으로 페이지네이션하여 조회합니다. 기준을 생략하면 최근 메시지를 가져오며, before/after/around/at 중 하나로 이전·이후 메시지나 특정 메시지·일시 주변으로 이동할 수 있습니다.
 // @Tags         Message
 // @Accept       json
 // @Produce      json
-// @Param        chatId   path      int  true  "채팅방 ID"
-// @Param        cursor   query     int  false "커서 (이전 페이지의 마지막 메시지 ID, 첫 페이지는 0 또는 생략)"
+// @Param        chatId   path      int     true  "채팅방 ID"
+// @Param        before   query     int     false "이 메시지 ID보다 이전 메시지 (nextCursor). cursor도 같은 의미로 사용할 수 있습니다"
+// @Param        after    query     int     false "이 메시지 ID보다 이후 메시지 (prevCursor)"
+// @Param        around   query     int     false "이 메시지 ID 주변의 메시지"
+// @Param        at       query     string  false "해당 일시 주변의 메시지 (RFC3339 또는 YYYY-MM-DD)"
+// @Param        limit    query     int     false "페이지 크기 (기본 50, 최대 100)"
 // @Success      200  {object}  common.MessageListResponse
 // @Failure      400  {object}  common.ErrInvalidRequest
 // @Failure      404  {object}  common.ErrChatNotFound
@@ -165,9 +337,35 @@ func (mc *MessageController) GetChatMessages(c *fiber.Ctx) error {
 		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
 	}
 
-	cursor := c.QueryInt("cursor", 0)
+	query := models.MessageHistoryQuery{
+		UserId: c.Locals("userId").(int),
+		ChatId: chatId,
+		Before: c.QueryInt("before", c.QueryInt("cursor", 0)),
+		After:  c.QueryInt("after", 0),
+		Around: c.QueryInt("around", 0),
+		Limit:  c.QueryInt("limit", 0),
+	}
+	if query.Before < 0 || query.After < 0 || query.Around < 0 || query.Limit < 0 {
+		return interfaces.SendBadRequest(c, "잘못된 페이지 요청입니다")
+	}
 
-	messages, err := mc.messageUseCase.GetChatMessages(chatId, cursor)
+	at, err := parseTimeQuery(c.Query("at"), false)
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 일시입니다")
+	}
+	query.At = at
+
+	anchors := 0
+	for _, set := range []bool{query.Before != 0, query.After != 0, query.Around != 0, query.At != nil} {
+		if set {
+			anchors++
+		}
+	}
+	if anchors > 1 {
+		return interfaces.SendBadRequest(c, "before, after, around, at 중 하나만 지정할 수 있습니다")
+	}
+
+	messages, err := mc.messageUseCase.GetChatMessages(query)
 	if err != nil {
 		switch err.Error() {
 		case "chat not found":
@@ -179,3 +377,83 @@ func (mc *MessageController) GetChatMessages(c *fiber.Ctx) error {
 
 	return interfaces.SendSuccess(c, messages)
 }
+
+// SearchMessages godoc
+// @Summary      메시지 검색
+// @Description  참여중인 채팅방의 메시지를 전문 검색합니다. 채팅방, 보낸 사람, 기간으로 필터링할 수 있으며 최신 메시지 순으로 커서 기반 페이지네이션을 지원합니다. 일치한 부분은 snippet에서 <mark> 태그로 강조됩니다. 메시지가 암호화되어 저장된 경우 한 번에 최대 5000개의 메시지만 검사하므로, 결과가 limit보다 적어도 hasMore가 true이면 nextCursor로 검색을 이어가야 합니다.
+// @Tags         Message
+// @Accept       json
+// @Produce      json
+// @Param        q         query     string  true   "검색어 (공백으로 구분된 모든 단어를 포함하는 메시지)"
+// @Param        chatId    query     int     false  "채팅방 ID"
+// @Param        senderId  query     int     false  "보낸 사람 ID"
+// @Param        from      query     string  false  "시작 일시 (RFC3339 또는 YYYY-MM-DD)"
+// @Param        to        query     string  false  "종료 일시 (RFC3339 또는 YYYY-MM-DD, 해당 일자 포함)"
+// @Param        cursor    query     int     false  "커서 (이전 페이지의 nextCursor, 첫 페이지는 0 또는 생략)"
+// @Param        limit     query     int     false  "페이지 크기 (기본 20, 최대 50)"
+// @Success      200  {object}  common.MessageSearchResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/messages/search [get]
+func (mc *MessageController) SearchMessages(c *fiber.Ctx) error {
+	filter := models.MessageSearchFilter{
+		UserId:   c.Locals("userId").(int),
+		Query:    c.Query("q"),
+		ChatId:   c.QueryInt("chatId", 0),
+		SenderId: c.QueryInt("senderId", 0),
+		Cursor:   c.QueryInt("cursor", 0),
+		Limit:    c.QueryInt("limit", 0),
+	}
+
+	if filter.Query == "" {
+		return interfaces.SendBadRequest(c, "검색어는 필수 항목입니다")
+	}
+
+	from, err := parseTimeQuery(c.Query("from"), false)
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 시작 일시입니다")
+	}
+	to, err := parseTimeQuery(c.Query("to"), true)
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 종료 일시입니다")
+	}
+	filter.From = from
+	filter.To = to
+
+	results, err := mc.messageUseCase.SearchMessages(filter)
+	if err != nil {
+		switch err.Error() {
+		case "search query is required":
+			return interfaces.SendBadRequest(c, "검색어는 필수 항목입니다")
+		case "user is not a member of this chat":
+			return interfaces.SendForbidden(c)
+		default:
+			return interfaces.SendInternalError(c)
+		}
+	}
+
+	return interfaces.SendSuccess(c, results)
+}
+
+// parseTimeQuery parses an RFC3339 timestamp or a YYYY-MM-DD date.
+// A date used as an upper bound covers the whole day.
+func parseTimeQuery(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ModerationController struct {
+	moderationUseCase *usecase.ModerationUsecase
+}
+
+func NewModerationController(moderationUseCase *usecase.ModerationUsecase) *ModerationController {
+	return &ModerationController{
+		moderationUseCase: moderationUseCase,
+	}
+}
+
+// GetFlags godoc
+// @Summary      검토 대기열 조회
+// @Description  모더레이션 필터가 검토 대상으로 표시한 메시지를 최근 순으로 조회합니다. 사용자가 소유자나 관리자인 채팅방의 메시지만 조회됩니다.
+// @Tags         Moderation
+// @Accept       json
+// @Produce      json
+// @Param        chatId  query     int     false  "채팅방 ID (생략하면 관리하는 모든 채팅방)"
+// @Param        status  query     string  false  "검토 상태" Enums(pending, approved, removed)
+// @Param        cursor  query     int     false  "커서 (이전 페이지의 nextCursor, 첫 페이지는 0 또는 생략)"
+// @Param        limit   query     int     false  "페이지 크기 (기본 20, 최대 100)"
+// @Success      200  {object}  common.ModerationFlagListResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/moderation/flags [get]
+func (mc *ModerationController) GetFlags(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	flags, err := mc.moderationUseCase.GetFlags(userID, c.QueryInt("chatId", 0), c.Query("status"), c.QueryInt("cursor", 0), c.QueryInt("limit", 0))
+	if err != nil {
+		return sendModerationError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, flags)
+}
+
+// ApproveFlag godoc
+// @Summary      검토 메시지 승인
+// @Description  검토 대상으로 표시된 메시지를 그대로 둡니다
+// @Tags         Moderation
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "검토 항목 ID"
+// @Success      200  {object}  common.ModerationFlagResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/moderation/flags/{id}/approve [post]
+func (mc *ModerationController) ApproveFlag(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 검토 항목 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	flag, err := mc.moderationUseCase.ApproveFlag(id, userID)
+	if err != nil {
+		return sendModerationError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, flag)
+}
+
+// RemoveFlaggedMessage godoc
+// @Summary      검토 메시지 삭제
+// @Description  검토 대상으로 표시된 메시지를 모든 참여자에게서 삭제합니다. 같은 메시지의 다른 검토 항목도 함께 처리됩니다.
+// @Tags         Moderation
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "검토 항목 ID"
+// @Success      200  {object}  common.ModerationFlagResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/moderation/flags/{id}/remove [post]
+func (mc *ModerationController) RemoveFlaggedMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 검토 항목 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	flag, err := mc.moderationUseCase.RemoveFlaggedMessage(id, userID)
+	if err != nil {
+		return sendModerationError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, flag)
+}
+
+func sendModerationError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "flag not found":
+		return interfaces.SendNotFound(c, "검토 항목")
+	case "user is not a member of this chat", "unauthorized to review flags":
+		return interfaces.SendForbidden(c)
+	case "invalid flag status":
+		return interfaces.SendBadRequest(c, "잘못된 검토 상태입니다")
+	case "flag already reviewed":
+		return interfaces.SendBadRequest(c, "이미 검토된 항목입니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
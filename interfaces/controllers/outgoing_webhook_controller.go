@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateOutgoingWebhookRequest represents the request for subscribing an endpoint to events
+type CreateOutgoingWebhookRequest struct {
+	// 이벤트를 받을 주소 (http 또는 https)
+	URL string `json:"url" example:"https://example.com/chat-events" validate:"required"`
+	// 구독할 채팅방. 생략하면 사용자가 속한 모든 채팅방의 이벤트를 받습니다.
+	ChatID *int `json:"chatId,omitempty" example:"1"`
+	// 구독할 이벤트. 생략하면 모든 이벤트를 받습니다.
+	Events []string `json:"events,omitempty" example:"message.created,member.added" enums:"message.created,message.updated,message.deleted,member.added,member.removed"`
+}
+
+type OutgoingWebhookController struct {
+	outgoingWebhookUseCase *usecase.OutgoingWebhookUsecase
+}
+
+func NewOutgoingWebhookController(outgoingWebhookUseCase *usecase.OutgoingWebhookUsecase) *OutgoingWebhookController {
+	return &OutgoingWebhookController{
+		outgoingWebhookUseCase: outgoingWebhookUseCase,
+	}
+}
+
+// CreateOutgoingWebhook godoc
+// @Summary      발신 웹훅 생성
+// @Description  채팅 이벤트를 외부 HTTP 주소로 보내는 웹훅을 생성합니다. 채팅방을 지정하면 해당 채팅방의 관리자만 생성할 수 있고, 생략하면 사용자가 속한 모든 채팅방의 이벤트를 보냅니다. 요청 본문은 서명 비밀 키로 HMAC-SHA256 서명되어 X-Webhook-Signature 헤더("sha256=" + hex(HMAC("<X-Webhook-Timestamp>.<본문>")))로 전송되며, 비밀 키는 생성 시에만 반환됩니다. 실패한 전송은 지수 백오프로 최대 8번까지 재시도됩니다.
+// @Tags         OutgoingWebhook
+// @Accept       json
+// @Produce      json
+// @Param        request body CreateOutgoingWebhookRequest true "웹훅 정보"
+// @Success      201  {object}  common.OutgoingWebhookResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/outgoing-webhooks [post]
+func (oc *OutgoingWebhookController) CreateOutgoingWebhook(c *fiber.Ctx) error {
+	var req CreateOutgoingWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	webhook, err := oc.outgoingWebhookUseCase.CreateOutgoingWebhook(userID, req.ChatID, req.URL, req.Events)
+	if err != nil {
+		return sendOutgoingWebhookError(c, err)
+	}
+
+	return interfaces.SendCreated(c, webhook)
+}
+
+// GetOutgoingWebhooks godoc
+// @Summary      발신 웹훅 목록 조회
+// @Description  사용자가 만든 발신 웹훅 목록을 조회합니다
+// @Tags         OutgoingWebhook
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  common.OutgoingWebhookListResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/outgoing-webhooks [get]
+func (oc *OutgoingWebhookController) GetOutgoingWebhooks(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	webhooks, err := oc.outgoingWebhookUseCase.GetOutgoingWebhooks(userID)
+	if err != nil {
+		return interfaces.SendInternalError(c)
+	}
+
+	return interfaces.SendSuccess(c, webhooks)
+}
+
+// GetOutgoingWebhook godoc
+// @Summary      발신 웹훅 조회
+// @Description  발신 웹훅 하나를 조회합니다
+// @Tags         OutgoingWebhook
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "발신 웹훅 ID"
+// @Success      200  {object}  common.OutgoingWebhookResponse
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/outgoing-webhooks/{id} [get]
+func (oc *OutgoingWebhookController) GetOutgoingWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 웹훅 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	webhook, err := oc.outgoingWebhookUseCase.GetOutgoingWebhook(id, userID)
+	if err != nil {
+		return sendOutgoingWebhookError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, webhook)
+}
+
+// DeleteOutgoingWebhook godoc
+// @Summary      발신 웹훅 삭제
+// @Description  발신 웹훅을 삭제합니다. 아직 보내지 않은 전송도 함께 삭제됩니다.
+// @Tags         OutgoingWebhook
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "발신 웹훅 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/outgoing-webhooks/{id} [delete]
+func (oc *OutgoingWebhookController) DeleteOutgoingWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 웹훅 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := oc.outgoingWebhookUseCase.DeleteOutgoingWebhook(id, userID); err != nil {
+		return sendOutgoingWebhookError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, "웹훅이 삭제되었습니다")
+}
+
+// GetDeliveries godoc
+// @Summary      발신 웹훅 전송 기록 조회
+// @Description  발신 웹훅의 전송 기록을 최근 순으로 조회합니다. 상태로 걸러 재시도 대기 중(pending)이거나 재시도를 모두 실패한(dead) 전송만 볼 수 있습니다.
+// @Tags         OutgoingWebhook
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "발신 웹훅 ID"
+// @Param        status  query     string  false  "전송 상태" Enums(pending, succeeded, dead)
+// @Param        cursor  query     int     false  "커서 (이전 페이지의 nextCursor, 첫 페이지는 0 또는 생략)"
+// @Param        limit   query     int     false  "페이지 크기 (기본 20, 최대 100)"
+// @Success      200  {object}  common.WebhookDeliveryListResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/outgoing-webhooks/{id}/deliveries [get]
+func (oc *OutgoingWebhookController) GetDeliveries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 웹훅 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	deliveries, err := oc.outgoingWebhookUseCase.GetDeliveries(id, userID, c.Query("status"), c.QueryInt("cursor", 0), c.QueryInt("limit", 0))
+	if err != nil {
+		return sendOutgoingWebhookError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, deliveries)
+}
+
+// ReplayDelivery godoc
+// @Summary      발신 웹훅 재전송
+// @Description  성공했거나 재시도를 모두 실패한 전송을 즉시 다시 보냅니다. 재시도 횟수는 처음부터 다시 셉니다.
+// @Tags         OutgoingWebhook
+// @Accept       json
+// @Produce      json
+// @Param        id          path      int  true  "발신 웹훅 ID"
+// @Param        deliveryId  path      int  true  "전송 ID"
+// @Success      200  {object}  common.WebhookDeliveryResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/outgoing-webhooks/{id}/deliveries/{deliveryId}/replay [post]
+func (oc *OutgoingWebhookController) ReplayDelivery(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 웹훅 ID입니다")
+	}
+
+	deliveryID, err := c.ParamsInt("deliveryId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 전송 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	delivery, err := oc.outgoingWebhookUseCase.ReplayDelivery(id, deliveryID, userID)
+	if err != nil {
+		return sendOutgoingWebhookError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, delivery)
+}
+
+func sendOutgoingWebhookError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "webhook not found":
+		return interfaces.SendNotFound(c, "웹훅")
+	case "delivery not found":
+		return interfaces.SendNotFound(c, "전송 기록")
+	case "user is not a member of this chat", "unauthorized to manage webhooks":
+		return interfaces.SendForbidden(c)
+	case "invalid webhook url":
+		return interfaces.SendBadRequest(c, "웹훅 주소는 http 또는 https URL이어야 합니다")
+	case "invalid webhook event":
+		return interfaces.SendBadRequest(c, "지원하지 않는 이벤트입니다")
+	case "invalid delivery status":
+		return interfaces.SendBadRequest(c, "잘못된 전송 상태입니다")
+	case "delivery is already pending":
+		return interfaces.SendBadRequest(c, "이미 전송 대기 중입니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PinMessageRequest represents the request for pinning a message
+type PinMessageRequest struct {
+	MessageID int `json:"messageId" example:"1" validate:"required"`
+}
+
+type PinController struct {
+	pinUseCase *usecase.PinUsecase
+}
+
+func NewPinController(pinUseCase *usecase.PinUsecase) *PinController {
+	return &PinController{
+		pinUseCase: pinUseCase,
+	}
+}
+
+// GetPinnedMessages godoc
+// @Summary      고정 메시지 목록 조회
+// @Description  채팅방에 고정된 메시지를 최근 고정 순으로 조회합니다
+// @Tags         Pin
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Success      200  {object}  common.PinListResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/pins [get]
+func (pc *PinController) GetPinnedMessages(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	pins, err := pc.pinUseCase.GetPinnedMessages(chatID, userID)
+	if err != nil {
+		return sendPinError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, pins)
+}
+
+// PinMessage godoc
+// @Summary      메시지 고정
+// @Description  채팅방의 메시지를 고정합니다. 채팅방 관리자만 고정할 수 있으며, 채팅방당 고정 개수가 제한됩니다.
+// @Tags         Pin
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body PinMessageRequest true "고정할 메시지 ID"
+// @Success      201  {object}  common.PinResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/pins [post]
+func (pc *PinController) PinMessage(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req PinMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	if req.MessageID == 0 {
+		return interfaces.SendBadRequest(c, "메시지 ID는 필수 항목입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	pin, err := pc.pinUseCase.PinMessage(chatID, req.MessageID, userID)
+	if err != nil {
+		return sendPinError(c, err)
+	}
+
+	return interfaces.SendCreated(c, pin)
+}
+
+// UnpinMessage godoc
+// @Summary      메시지 고정 해제
+// @Description  채팅방의 메시지 고정을 해제합니다. 채팅방 관리자만 해제할 수 있습니다.
+// @Tags         Pin
+// @Accept       json
+// @Produce      json
+// @Param        chatId      path      int  true  "채팅방 ID"
+// @Param        messageId   path      int  true  "메시지 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/pins/{messageId} [delete]
+func (pc *PinController) UnpinMessage(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	messageID, err := c.ParamsInt("messageId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 메시지 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := pc.pinUseCase.UnpinMessage(chatID, messageID, userID); err != nil {
+		return sendPinError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, "메시지 고정이 해제되었습니다")
+}
+
+func sendPinError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "message not found":
+		return interfaces.SendNotFound(c, "메시지")
+	case "message not pinned":
+		return interfaces.SendNotFound(c, "고정 메시지")
+	case "user is not a member of this chat", "unauthorized to pin messages":
+		return interfaces.SendForbidden(c)
+	case "message already pinned":
+		return interfaces.SendBadRequest(c, "이미 고정된 메시지입니다")
+	case "pin limit reached":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("메시지는 최대 %d개까지 고정할 수 있습니다", usecase.MaxPinnedMessagesPerChat))
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreatePollRequest represents the request for posting a poll
+type CreatePollRequest struct {
+	Question string   `json:"question" example:"점심 메뉴는?" validate:"required"`
+	Options  []string `json:"options" example:"김치찌개,비빔밥" validate:"required"`
+	// 여러 항목을 선택할 수 있는지 여부
+	MultipleChoice bool `json:"multipleChoice" example:"false"`
+	// 익명 투표 여부. 익명이 아니면 항목별 투표자가 공개됩니다.
+	Anonymous bool `json:"anonymous" example:"false"`
+	// 투표 마감 시각 (선택)
+	ClosesAt *time.Time `json:"closesAt,omitempty" example:"2024-03-23T03:00:00Z"`
+}
+
+// VotePollRequest represents the request for voting on a poll
+type VotePollRequest struct {
+	// 선택한 항목 ID. 이전 투표를 대체합니다.
+	OptionIDs []int `json:"optionIds" example:"1" validate:"required"`
+}
+
+type PollController struct {
+	messageUseCase *usecase.MessageUsecase
+	pollUseCase    *usecase.PollUsecase
+}
+
+func NewPollController(messageUseCase *usecase.MessageUsecase, pollUseCase *usecase.PollUsecase) *PollController {
+	return &PollController{
+		messageUseCase: messageUseCase,
+		pollUseCase:    pollUseCase,
+	}
+}
+
+// CreatePoll godoc
+// @Summary      투표 생성
+// @Description  채팅방에 투표 메시지를 게시합니다. 단일/복수 선택, 익명/기명 투표와 마감 시각을 지정할 수 있습니다. 질문과 항목은 모더레이션 필터를 거칩니다.
+// @Tags         Poll
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body CreatePollRequest true "투표 정보"
+// @Success      201  {object}  common.MessageResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      422  {object}  common.ErrMessageRejected
+// @Failure      429  {object}  common.ErrMessageRateLimited
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/polls [post]
+func (pc *PollController) CreatePoll(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req CreatePollRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	message, err := pc.messageUseCase.CreatePoll(usecase.CreatePollInput{
+		ChatID:         chatID,
+		CreatorID:      userID,
+		Question:       req.Question,
+		Options:        req.Options,
+		MultipleChoice: req.MultipleChoice,
+		Anonymous:      req.Anonymous,
+		ClosesAt:       req.ClosesAt,
+	})
+	if err != nil {
+		return sendPollError(c, err)
+	}
+
+	return interfaces.SendCreated(c, message)
+}
+
+// GetPoll godoc
+// @Summary      투표 조회
+// @Description  투표의 현재 집계와 내가 선택한 항목을 조회합니다
+// @Tags         Poll
+// @Accept       json
+// @Produce      json
+// @Param        pollId   path      int  true  "투표 ID"
+// @Success      200  {object}  common.PollResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrPollNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/polls/{pollId} [get]
+func (pc *PollController) GetPoll(c *fiber.Ctx) error {
+	pollID, err := c.ParamsInt("pollId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 투표 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	poll, err := pc.pollUseCase.GetPoll(pollID, userID)
+	if err != nil {
+		return sendPollError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, poll)
+}
+
+// Vote godoc
+// @Summary      투표하기
+// @Description  투표 항목을 선택합니다. 다시 투표하면 이전 선택을 대체하며, 마감된 투표에는 투표할 수 없습니다.
+// @Tags         Poll
+// @Accept       json
+// @Produce      json
+// @Param        pollId   path      int  true  "투표 ID"
+// @Param        request body VotePollRequest true "선택한 항목"
+// @Success      200  {object}  common.PollResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrPollNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/polls/{pollId}/votes [post]
+func (pc *PollController) Vote(c *fiber.Ctx) error {
+	pollID, err := c.ParamsInt("pollId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 투표 ID입니다")
+	}
+
+	var req VotePollRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	poll, err := pc.pollUseCase.Vote(pollID, userID, req.OptionIDs)
+	if err != nil {
+		return sendPollError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, poll)
+}
+
+// RetractVote godoc
+// @Summary      투표 취소
+// @Description  투표에서 내 선택을 취소합니다
+// @Tags         Poll
+// @Accept       json
+// @Produce      json
+// @Param        pollId   path      int  true  "투표 ID"
+// @Success      200  {object}  common.PollResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrPollNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/polls/{pollId}/votes [delete]
+func (pc *PollController) RetractVote(c *fiber.Ctx) error {
+	pollID, err := c.ParamsInt("pollId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 투표 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	poll, err := pc.pollUseCase.RetractVote(pollID, userID)
+	if err != nil {
+		return sendPollError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, poll)
+}
+
+// ClosePoll godoc
+// @Summary      투표 마감
+// @Description  투표를 마감합니다. 투표를 만든 사용자와 채팅방 관리자만 마감할 수 있습니다.
+// @Tags         Poll
+// @Accept       json
+// @Produce      json
+// @Param        pollId   path      int  true  "투표 ID"
+// @Success      200  {object}  common.PollResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrPollNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/polls/{pollId}/close [post]
+func (pc *PollController) ClosePoll(c *fiber.Ctx) error {
+	pollID, err := c.ParamsInt("pollId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 투표 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	poll, err := pc.pollUseCase.ClosePoll(pollID, userID)
+	if err != nil {
+		return sendPollError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, poll)
+}
+
+func sendPollError(c *fiber.Ctx, err error) error {
+	var limited *models.RateLimitError
+	if errors.As(err, &limited) {
+		return interfaces.SendRateLimited(c, limited.RetryAfterSeconds(), rateLimitMessage(limited))
+	}
+	var rejected *models.ModerationError
+	if errors.As(err, &rejected) {
+		return interfaces.SendMessageRejected(c, rejected.Reason)
+	}
+
+	switch err.Error() {
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "poll not found":
+		return interfaces.SendNotFound(c, "투표")
+	case "user is not a member of this chat", "unauthorized to close this poll":
+		return interfaces.SendForbidden(c)
+	case "invalid poll question":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("투표 질문은 1자 이상 %d자 이하여야 합니다", models.MaxPollQuestionLength))
+	case "invalid poll options":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("투표 항목은 %d개 이상 %d개 이하, 각 %d자 이하여야 합니다", models.MinPollOptions, models.MaxPollOptions, models.MaxPollOptionLength))
+	case "duplicate poll option":
+		return interfaces.SendBadRequest(c, "중복된 투표 항목이 있습니다")
+	case "invalid poll close time":
+		return interfaces.SendBadRequest(c, "투표 마감 시각은 현재 이후여야 합니다")
+	case "invalid poll vote":
+		return interfaces.SendBadRequest(c, "잘못된 투표 항목입니다")
+	case "poll is closed":
+		return interfaces.SendBadRequest(c, "마감된 투표입니다")
+	case "not supported in encrypted chats":
+		return interfaces.SendBadRequest(c, "암호화된 채팅방에서는 투표를 사용할 수 없습니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
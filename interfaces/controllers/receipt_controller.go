@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MarkReadRequest represents the request for marking messages as read
+type MarkReadRequest struct {
+	// 마지막으로 읽은 메시지 ID. 이 메시지까지의 모든 메시지가 읽음 처리됩니다.
+	MessageID int `json:"messageId" example:"10" validate:"required"`
+}
+
+type ReceiptController struct {
+	receiptUseCase *usecase.ReceiptUsecase
+}
+
+func NewReceiptController(receiptUseCase *usecase.ReceiptUsecase) *ReceiptController {
+	return &ReceiptController{
+		receiptUseCase: receiptUseCase,
+	}
+}
+
+// MarkRead godoc
+// @Summary      메시지 읽음 처리
+// @Description  채팅방에서 지정한 메시지까지 읽음으로 표시합니다. 보낸 사람에게 message.read 이벤트로 읽음 수가 전달됩니다.
+// @Tags         Message
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body MarkReadRequest true "마지막으로 읽은 메시지 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/read [post]
+func (rc *ReceiptController) MarkRead(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req MarkReadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	if req.MessageID <= 0 {
+		return interfaces.SendBadRequest(c, "메시지 ID는 필수 항목입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := rc.receiptUseCase.MarkRead(chatID, userID, req.MessageID); err != nil {
+		switch err.Error() {
+		case "chat not found":
+			return interfaces.SendNotFound(c, "채팅방")
+		case "user is not a member of this chat":
+			return interfaces.SendForbidden(c)
+		default:
+			return interfaces.SendInternalError(c)
+		}
+	}
+
+	return interfaces.SendSuccess(c, "메시지를 읽음으로 표시했습니다")
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Description 법적 보존 조치 요청
+type PlaceLegalHoldRequest struct {
+	// 보존 사유 (최대 500자)
+	Reason string `json:"reason" example:"2024-민-1234 소송 관련 보존" validate:"required"`
+}
+
+type RetentionController struct {
+	retentionUseCase *usecase.RetentionUsecase
+}
+
+func NewRetentionController(retentionUseCase *usecase.RetentionUsecase) *RetentionController {
+	return &RetentionController{
+		retentionUseCase: retentionUseCase,
+	}
+}
+
+// GetChatRetention godoc
+// @Summary      메시지 보관 정책 조회
+// @Description  채팅방에 설정된 보관 정책과 서버 보관 정책, 실제로 적용되는 정책을 조회합니다. 두 정책 중 더 엄격한 제한이 적용됩니다. 법적 보존 조치 여부는 서버 관리자에게만 반환됩니다.
+// @Tags         Retention
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Success      200  {object}  common.ChatRetentionResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/retention [get]
+func (rc *RetentionController) GetChatRetention(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	retention, err := rc.retentionUseCase.GetChatRetention(chatID, userID)
+	if err != nil {
+		return sendRetentionError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, retention)
+}
+
+// PlaceLegalHold godoc
+// @Summary      법적 보존 조치
+// @Description  채팅방 메시지가 보관 정책에 따라 삭제되지 않도록 보존합니다. 진행 중인 정리 작업도 다음 삭제 단위부터 중단됩니다. 이미 보존 중이면 사유만 변경됩니다. 서버에 설정된 관리자만 요청할 수 있습니다.
+// @Tags         Retention
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body PlaceLegalHoldRequest true "보존 사유"
+// @Success      200  {object}  common.LegalHoldResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/legal-hold [put]
+func (rc *RetentionController) PlaceLegalHold(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req PlaceLegalHoldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	hold, err := rc.retentionUseCase.PlaceLegalHold(chatID, userID, req.Reason)
+	if err != nil {
+		return sendRetentionError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, hold)
+}
+
+// ReleaseLegalHold godoc
+// @Summary      법적 보존 해제
+// @Description  채팅방의 법적 보존 조치를 해제합니다. 다음 정리 작업부터 보관 정책이 다시 적용됩니다. 서버에 설정된 관리자만 요청할 수 있습니다.
+// @Tags         Retention
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrLegalHoldNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/legal-hold [delete]
+func (rc *RetentionController) ReleaseLegalHold(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := rc.retentionUseCase.ReleaseLegalHold(chatID, userID); err != nil {
+		return sendRetentionError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, "법적 보존 조치가 해제되었습니다")
+}
+
+// GetLegalHolds godoc
+// @Summary      법적 보존 목록 조회
+// @Description  법적 보존 중인 채팅방을 조치한 순서대로 조회합니다. 서버에 설정된 관리자만 요청할 수 있습니다.
+// @Tags         Retention
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  common.LegalHoldListResponse
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/retention/legal-holds [get]
+func (rc *RetentionController) GetLegalHolds(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	holds, err := rc.retentionUseCase.GetLegalHolds(userID)
+	if err != nil {
+		return sendRetentionError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, holds)
+}
+
+// GetRetentionRuns godoc
+// @Summary      보관 정책 정리 기록 목록
+// @Description  보관 정책에 따라 메시지를 삭제한 정리 작업의 최근 기록 50개를 최신순으로 조회합니다. 정리 작업은 한 시간마다 실행됩니다. 서버에 설정된 관리자만 요청할 수 있습니다.
+// @Tags         Retention
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  common.RetentionRunListResponse
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/retention/runs [get]
+func (rc *RetentionController) GetRetentionRuns(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	runs, err := rc.retentionUseCase.GetRuns(userID)
+	if err != nil {
+		return sendRetentionError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, runs)
+}
+
+// GetRetentionRun godoc
+// @Summary      보관 정책 정리 기록 조회
+// @Description  정리 작업 하나의 기록과 채팅방별로 적용한 정책, 삭제한 메시지 수와 범위를 조회합니다. 서버에 설정된 관리자만 요청할 수 있습니다.
+// @Tags         Retention
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "정리 기록 ID"
+// @Success      200  {object}  common.RetentionRunResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrRetentionRunNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/retention/runs/{id} [get]
+func (rc *RetentionController) GetRetentionRun(c *fiber.Ctx) error {
+	runID, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 정리 기록 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	run, err := rc.retentionUseCase.GetRun(runID, userID)
+	if err != nil {
+		return sendRetentionError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, run)
+}
+
+func sendRetentionError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "invalid legal hold reason":
+		return interfaces.SendBadRequest(c, "보존 사유는 1자 이상 500자 이하로 입력해주세요")
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "legal hold not found":
+		return interfaces.SendNotFound(c, "법적 보존 조치")
+	case "retention run not found":
+		return interfaces.SendNotFound(c, "정리 기록")
+	case "user not found", "user is not a member of this chat", "unauthorized to manage retention":
+		return interfaces.SendForbidden(c)
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateScheduledMessageRequest represents the request for scheduling a message
+type CreateScheduledMessageRequest struct {
+	ChatID      int       `json:"chatId" example:"1" validate:"required"`
+	Content     string    `json:"content" example:"회의 5분 전입니다" validate:"required"`
+	ScheduledAt time.Time `json:"scheduledAt" example:"2024-03-23T12:00:00+09:00" validate:"required"`
+}
+
+// UpdateScheduledMessageRequest represents the request for changing a scheduled message
+type UpdateScheduledMessageRequest struct {
+	Content     string    `json:"content" example:"회의 10분 전입니다" validate:"required"`
+	ScheduledAt time.Time `json:"scheduledAt" example:"2024-03-23T12:00:00+09:00" validate:"required"`
+}
+
+type ScheduledMessageController struct {
+	scheduledUseCase *usecase.ScheduledMessageUsecase
+}
+
+func NewScheduledMessageController(scheduledUseCase *usecase.ScheduledMessageUsecase) *ScheduledMessageController {
+	return &ScheduledMessageController{
+		scheduledUseCase: scheduledUseCase,
+	}
+}
+
+// CreateScheduledMessage godoc
+// @Summary      예약 메시지 생성
+// @Description  지정한 시각에 채팅방에 전송될 메시지를 예약합니다
+// @Tags         ScheduledMessage
+// @Accept       json
+// @Produce      json
+// @Param        request body CreateScheduledMessageRequest true "예약 메시지 정보"
+// @Success      201  {object}  common.ScheduledMessageResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/scheduled-messages [post]
+func (sc *ScheduledMessageController) CreateScheduledMessage(c *fiber.Ctx) error {
+	var req CreateScheduledMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	scheduled, err := sc.scheduledUseCase.CreateScheduledMessage(req.ChatID, userID, req.Content, req.ScheduledAt)
+	if err != nil {
+		return sendScheduledMessageError(c, err)
+	}
+
+	return interfaces.SendCreated(c, scheduled)
+}
+
+// GetScheduledMessages godoc
+// @Summary      예약 메시지 목록 조회
+// @Description  현재 사용자의 전송 대기중인 예약 메시지를 예약 시각 순으로 조회합니다
+// @Tags         ScheduledMessage
+// @Accept       json
+// @Produce      json
+// @Param        chatId   query     int  false  "채팅방 ID"
+// @Success      200  {object}  common.ScheduledMessageListResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/scheduled-messages [get]
+func (sc *ScheduledMessageController) GetScheduledMessages(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(int)
+
+	scheduled, err := sc.scheduledUseCase.GetScheduledMessages(userID, c.QueryInt("chatId", 0))
+	if err != nil {
+		return interfaces.SendInternalError(c)
+	}
+
+	return interfaces.SendSuccess(c, scheduled)
+}
+
+// GetScheduledMessage godoc
+// @Summary      예약 메시지 조회
+// @Description  예약 메시지의 상태를 조회합니다
+// @Tags         ScheduledMessage
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "예약 메시지 ID"
+// @Success      200  {object}  common.ScheduledMessageResponse
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/scheduled-messages/{id} [get]
+func (sc *ScheduledMessageController) GetScheduledMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 예약 메시지 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	scheduled, err := sc.scheduledUseCase.GetScheduledMessage(id, userID)
+	if err != nil {
+		return sendScheduledMessageError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, scheduled)
+}
+
+// UpdateScheduledMessage godoc
+// @Summary      예약 메시지 수정
+// @Description  전송 대기중인 예약 메시지의 내용과 예약 시각을 수정합니다
+// @Tags         ScheduledMessage
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "예약 메시지 ID"
+// @Param        request body UpdateScheduledMessageRequest true "수정할 예약 메시지 정보"
+// @Success      200  {object}  common.ScheduledMessageResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/scheduled-messages/{id} [put]
+func (sc *ScheduledMessageController) UpdateScheduledMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 예약 메시지 ID입니다")
+	}
+
+	var req UpdateScheduledMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	scheduled, err := sc.scheduledUseCase.UpdateScheduledMessage(id, userID, req.Content, req.ScheduledAt)
+	if err != nil {
+		return sendScheduledMessageError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, scheduled)
+}
+
+// DeleteScheduledMessage godoc
+// @Summary      예약 메시지 취소
+// @Description  전송 대기중인 예약 메시지를 취소합니다
+// @Tags         ScheduledMessage
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "예약 메시지 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      404  {object}  common.ErrMessageNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/scheduled-messages/{id} [delete]
+func (sc *ScheduledMessageController) DeleteScheduledMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 예약 메시지 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := sc.scheduledUseCase.DeleteScheduledMessage(id, userID); err != nil {
+		return sendScheduledMessageError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, "예약 메시지가 취소되었습니다")
+}
+
+func sendScheduledMessageError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "scheduled message not found":
+		return interfaces.SendNotFound(c, "예약 메시지")
+	case "user is not a member of this chat":
+		return interfaces.SendForbidden(c)
+	case "content is required":
+		return interfaces.SendBadRequest(c, "메시지 내용은 필수 항목입니다")
+	case "scheduled time must be in the future":
+		return interfaces.SendBadRequest(c, "예약 시각은 현재 이후여야 합니다")
+	case "scheduled time is too far in the future":
+		return interfaces.SendBadRequest(c, "예약 시각은 1년 이내여야 합니다")
+	case "scheduled message already sent":
+		return interfaces.SendBadRequest(c, "이미 전송된 예약 메시지입니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
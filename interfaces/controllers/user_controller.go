@@ -18,10 +18,11 @@ func NewUserController(userUseCase *usecase.UserUseCase) *UserController {
 
 // GetAllUsers godoc
 // @Summary      전체 사용자 목록 조회
-// @Description  현재 로그인한 사용자를 제외한 전체 사용자 목록을 조회합니다
+// @Description  현재 로그인한 사용자를 제외한 전체 사용자 목록을 조회합니다. 봇은 includeBots=true일 때만 포함됩니다.
 // @Tags         User
 // @Accept       json
 // @Produce      json
+// @Param        includeBots  query  bool  false  "봇 포함 여부"
 // @Success      200  {object}  common.UserListResponse
 // @Failure      401  {object}  common.ErrUnauthorized
 // @Failure      500  {object}  common.ErrInternalServer
@@ -30,7 +31,7 @@ func NewUserController(userUseCase *usecase.UserUseCase) *UserController {
 func (uc *UserController) GetAllUsers(c *fiber.Ctx) error {
 	currentUserId := c.Locals("userId").(int)
 
-	users, err := uc.userUseCase.GetAllUsersExcept(currentUserId)
+	users, err := uc.userUseCase.GetAllUsersExcept(currentUserId, c.QueryBool("includeBots", false))
 	if err != nil {
 		return interfaces.SendInternalError(c)
 	}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/interfaces"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateWebhookRequest represents the request for creating an incoming webhook
+type CreateWebhookRequest struct {
+	// 웹훅으로 보낸 메시지에 표시될 이름
+	Name string `json:"name" example:"CI" validate:"required"`
+}
+
+// ExecuteWebhookRequest represents the payload posted to an incoming webhook
+type ExecuteWebhookRequest struct {
+	// 보낼 메시지 (Markdown 지원)
+	Text string `json:"text" example:"**빌드 성공** main #128" validate:"required"`
+	// 이 메시지에만 사용할 표시 이름. 생략하면 웹훅 이름이 사용됩니다.
+	Username string `json:"username,omitempty" example:"배포 알림"`
+}
+
+type WebhookController struct {
+	webhookUseCase *usecase.WebhookUsecase
+}
+
+func NewWebhookController(webhookUseCase *usecase.WebhookUsecase) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary      수신 웹훅 생성
+// @Description  외부 시스템이 채팅방에 메시지를 보낼 수 있는 웹훅을 생성합니다. 채팅방 관리자만 생성할 수 있으며, 토큰은 생성 시에만 반환됩니다.
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Param        request body CreateWebhookRequest true "웹훅 정보"
+// @Success      201  {object}  common.WebhookResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/webhooks [post]
+func (wc *WebhookController) CreateWebhook(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	var req CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	webhook, err := wc.webhookUseCase.CreateWebhook(chatID, userID, req.Name)
+	if err != nil {
+		return sendWebhookError(c, err)
+	}
+
+	return interfaces.SendCreated(c, webhook)
+}
+
+// GetWebhooks godoc
+// @Summary      수신 웹훅 목록 조회
+// @Description  채팅방의 사용 중인 웹훅 목록을 조회합니다. 채팅방 관리자만 조회할 수 있습니다.
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        chatId   path      int  true  "채팅방 ID"
+// @Success      200  {object}  common.WebhookListResponse
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/webhooks [get]
+func (wc *WebhookController) GetWebhooks(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	webhooks, err := wc.webhookUseCase.GetWebhooks(chatID, userID)
+	if err != nil {
+		return sendWebhookError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, webhooks)
+}
+
+// RevokeWebhook godoc
+// @Summary      수신 웹훅 폐기
+// @Description  웹훅을 폐기합니다. 폐기된 웹훅의 토큰으로는 더 이상 메시지를 보낼 수 없습니다.
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        chatId     path      int  true  "채팅방 ID"
+// @Param        webhookId  path      int  true  "웹훅 ID"
+// @Success      200  {object}  common.BaseResponse
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrChatNotFound
+// @Failure      500  {object}  common.ErrInternalServer
+// @Security     Bearer
+// @Router       /api/chats/{chatId}/webhooks/{webhookId} [delete]
+func (wc *WebhookController) RevokeWebhook(c *fiber.Ctx) error {
+	chatID, err := c.ParamsInt("chatId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 채팅방 ID입니다")
+	}
+
+	webhookID, err := c.ParamsInt("webhookId")
+	if err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 웹훅 ID입니다")
+	}
+
+	userID := c.Locals("userId").(int)
+
+	if err := wc.webhookUseCase.RevokeWebhook(chatID, webhookID, userID); err != nil {
+		return sendWebhookError(c, err)
+	}
+
+	return interfaces.SendSuccess(c, "웹훅이 폐기되었습니다")
+}
+
+// ExecuteWebhook godoc
+// @Summary      수신 웹훅으로 메시지 전송
+// @Description  웹훅 토큰으로 채팅방에 메시지를 보냅니다. JWT 인증 없이 호출하며, 메시지는 웹훅 이름으로 표시되며, 멤버의 메시지와 같이 모더레이션 필터를 거쳐 거부(422)될 수 있습니다. 웹훅을 만든 사용자가 채팅방을 나가거나 관리자 권한을 잃으면 403을 반환합니다. 웹훅마다 분당 30개(순간 최대 10개)까지 보낼 수 있습니다.
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        token   path      string  true  "웹훅 토큰"
+// @Param        request body ExecuteWebhookRequest true "메시지 정보"
+// @Success      201  {object}  common.MessageResponse
+// @Failure      400  {object}  common.ErrInvalidRequest
+// @Failure      403  {object}  common.ErrUnauthorized
+// @Failure      404  {object}  common.ErrorResponse
+// @Failure      422  {object}  common.ErrMessageRejected
+// @Failure      429  {object}  common.ErrorResponse
+// @Failure      500  {object}  common.ErrInternalServer
+// @Router       /hooks/{token} [post]
+func (wc *WebhookController) ExecuteWebhook(c *fiber.Ctx) error {
+	var req ExecuteWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return interfaces.SendBadRequest(c, "잘못된 요청 형식입니다")
+	}
+
+	message, err := wc.webhookUseCase.ExecuteWebhook(c.Params("token"), req.Text, req.Username)
+	if err != nil {
+		if err.Error() == "webhook rate limited" {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(60/usecase.WebhookRatePerMinute))
+			return interfaces.SendTooManyRequests(c)
+		}
+		return sendWebhookError(c, err)
+	}
+
+	return interfaces.SendCreated(c, message)
+}
+
+func sendWebhookError(c *fiber.Ctx, err error) error {
+	var rejected *models.ModerationError
+	if errors.As(err, &rejected) {
+		return interfaces.SendMessageRejected(c, rejected.Reason)
+	}
+
+	switch err.Error() {
+	case "chat not found":
+		return interfaces.SendNotFound(c, "채팅방")
+	case "webhook not found":
+		return interfaces.SendNotFound(c, "웹훅")
+	case "user is not a member of this chat", "unauthorized to manage webhooks":
+		return interfaces.SendForbidden(c)
+	case "invalid bot name":
+		return interfaces.SendBadRequest(c, fmt.Sprintf("이름은 1자 이상 %d자 이하여야 합니다", models.MaxBotNameLength))
+	case "content is required":
+		return interfaces.SendBadRequest(c, "메시지 내용은 필수 항목입니다")
+	case "not supported in encrypted chats":
+		return interfaces.SendBadRequest(c, "암호화된 채팅방에서는 사용할 수 없는 기능입니다")
+	default:
+		return interfaces.SendInternalError(c)
+	}
+}
@@ -2,19 +2,40 @@
 package controllers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/f1rstid/realtime-chat/application/usecase"
+	"github.com/f1rstid/realtime-chat/domain/events"
+	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/infrastructure/logger"
 	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
+	"github.com/f1rstid/realtime-chat/interfaces"
 	"github.com/gofiber/fiber/v2"
 	ws "github.com/gofiber/websocket/v2"
 )
 
+// sendFrameType is the type of frames clients send messages with
+const sendFrameType = "message.send"
+
+// sendMessageFrame sends a message over the WebSocket with the fields of
+// SendMessageRequest. The reply carries the client's requestId.
+type sendMessageFrame struct {
+	RequestID string `json:"requestId"`
+	SendMessageRequest
+}
+
 type WebSocketController struct {
-	hub *websocket.Hub
+	hub            *websocket.Hub
+	messageUseCase *usecase.MessageUsecase
 }
 
-func NewWebSocketController(hub *websocket.Hub) *WebSocketController {
+func NewWebSocketController(hub *websocket.Hub, messageUseCase *usecase.MessageUsecase) *WebSocketController {
 	return &WebSocketController{
-		hub: hub,
+		hub:            hub,
+		messageUseCase: messageUseCase,
 	}
 }
 
@@ -45,12 +66,33 @@ func (wc *WebSocketController) WebSocket(c *ws.Conn) {
 
 	logger.Info("New WebSocket connection - UserID: %d", userIDInt)
 
+	// Clients may resume with their previous connection ID
+	connectionID := c.Query("connectionId")
+	if connectionID == "" {
+		connectionID = newConnectionID()
+	}
+
 	// Create new client
 	client := &websocket.Client{
-		Hub:    wc.hub,
-		Conn:   c,
-		Send:   make(chan []byte, 256),
-		UserID: userIDInt,
+		Hub:          wc.hub,
+		Conn:         c,
+		Send:         make(chan websocket.Frame, 256),
+		UserID:       userIDInt,
+		ConnectionID: connectionID,
+	}
+	isBot := c.Locals("isBot") == true
+	client.OnFrame = func(frameType string, data []byte) {
+		if frameType == sendFrameType {
+			wc.sendMessage(client, isBot, data)
+		}
+	}
+
+	// Tell the client its connection ID before any other event
+	event := events.NewWebSocketEvent(events.EventConnectionReady, 0, &events.ConnectionEventData{
+		ConnectionID: connectionID,
+	})
+	if eventJSON, err := event.ToJSON(); err == nil {
+		client.Send <- websocket.Frame{Data: eventJSON}
 	}
 
 	client.Hub.RegisterClient(client)
@@ -65,3 +107,69 @@ func (wc *WebSocketController) WebSocket(c *ws.Conn) {
 	go client.WritePump()
 	client.ReadPump()
 }
+
+// sendMessage sends a message from a message.send frame and answers with a
+// message.sent frame, or with an error frame using the codes of the REST API
+func (wc *WebSocketController) sendMessage(client *websocket.Client, isBot bool, data []byte) {
+	var frame sendMessageFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		wc.reply(client, events.NewWebSocketError(interfaces.StatusBadRequest, &events.ErrorEventData{
+			Message: "잘못된 요청 형식입니다",
+		}))
+		return
+	}
+
+	if frame.Content == "" {
+		wc.reply(client, events.NewWebSocketError(interfaces.StatusBadRequest, &events.ErrorEventData{
+			RequestID: frame.RequestID,
+			Message:   "메시지 내용은 필수 항목입니다",
+		}))
+		return
+	}
+
+	message, err := wc.messageUseCase.SendMessage(usecase.SendMessageInput{
+		ChatID:          frame.ChatID,
+		SenderID:        client.UserID,
+		Content:         frame.Content,
+		TTLSeconds:      frame.TTLSeconds,
+		ClientMessageID: frame.ClientMessageID,
+		Attachments:     frame.Attachments,
+		SenderIsBot:     isBot,
+	})
+	if err != nil {
+		errorData := &events.ErrorEventData{RequestID: frame.RequestID}
+		code := interfaces.StatusTooManyRequests
+		var limited *models.RateLimitError
+		if errors.As(err, &limited) {
+			errorData.Message = rateLimitMessage(limited)
+			errorData.RetryAfter = limited.RetryAfterSeconds()
+		} else {
+			_, code, errorData.Message = messageSendError(err)
+		}
+		wc.reply(client, events.NewWebSocketError(code, errorData))
+		return
+	}
+
+	wc.reply(client, events.NewWebSocketEvent(events.EventMessageSent, message.ChatID, &events.MessageSentEventData{
+		RequestID: frame.RequestID,
+		Message:   message,
+	}))
+}
+
+func (wc *WebSocketController) reply(client *websocket.Client, event *events.WebSocketResponse) {
+	eventJSON, err := event.ToJSON()
+	if err != nil {
+		logger.Error("Failed to marshal WebSocket reply: %v", err)
+		return
+	}
+	client.Reply(eventJSON)
+}
+
+func newConnectionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Error("Failed to generate connection ID: %v", err)
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
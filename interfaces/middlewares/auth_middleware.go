@@ -3,12 +3,18 @@ package middlewares
 import (
 	"strings"
 
+	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/domain/services"
 	"github.com/f1rstid/realtime-chat/interfaces"
 	"github.com/gofiber/fiber/v2"
 )
 
-func AuthMiddleware(authService services.AuthService) fiber.Handler {
+// BotAuthenticator resolves the bot account holding an API token
+type BotAuthenticator interface {
+	AuthenticateBot(token string) (*models.User, error)
+}
+
+func AuthMiddleware(authService services.AuthService, bots BotAuthenticator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get token from header
 		authHeader := c.Get("authorization")
@@ -24,16 +30,39 @@ func AuthMiddleware(authService services.AuthService) fiber.Handler {
 		}
 
 		// Validate token
-		claims, err := authService.ValidateToken(parts[1])
-		if err != nil {
+		if err := authenticate(c, authService, bots, parts[1]); err != nil {
 			return interfaces.SendUnauthorized(c)
 		}
 
-		// Set claims in context
-		c.Locals("userId", claims.UserID)
-		c.Locals("userEmail", claims.Email)
-		c.Locals("userNickname", claims.Nickname)
-
 		return c.Next()
 	}
 }
+
+// authenticate validates a user session token or a bot API token and sets
+// the caller in the context
+func authenticate(c *fiber.Ctx, authService services.AuthService, bots BotAuthenticator, token string) error {
+	if models.IsBotToken(token) {
+		bot, err := bots.AuthenticateBot(token)
+		if err != nil {
+			return err
+		}
+
+		c.Locals("userId", bot.ID)
+		c.Locals("userEmail", bot.Email)
+		c.Locals("userNickname", bot.Nickname)
+		c.Locals("isBot", true)
+		return nil
+	}
+
+	claims, err := authService.ValidateToken(token)
+	if err != nil {
+		return err
+	}
+
+	// Set claims in context
+	c.Locals("userId", claims.UserID)
+	c.Locals("userEmail", claims.Email)
+	c.Locals("userNickname", claims.Nickname)
+	c.Locals("isBot", false)
+	return nil
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func WebSocketAuthMiddleware(authService services.AuthService) fiber.Handler {
+func WebSocketAuthMiddleware(authService services.AuthService, bots BotAuthenticator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Printf("WebSocket auth middleware - Path: %s", c.Path())
 
@@ -37,20 +37,14 @@ func WebSocketAuthMiddleware(authService services.AuthService) fiber.Handler {
 		}
 
 		// Validate token
-		claims, err := authService.ValidateToken(token)
-		if err != nil {
+		if err := authenticate(c, authService, bots, token); err != nil {
 			log.Printf("WebSocket auth failed: invalid token - %v", err)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid token",
 			})
 		}
 
-		log.Printf("WebSocket auth successful - UserID: %d", claims.UserID)
-
-		// Store user information in context
-		c.Locals("userId", claims.UserID)
-		c.Locals("userEmail", claims.Email)
-		c.Locals("userNickname", claims.Nickname)
+		log.Printf("WebSocket auth successful - UserID: %d", c.Locals("userId"))
 
 		return c.Next()
 	}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type BookmarkRepository struct {
+	DB *sqlx.DB
+}
+
+func NewBookmarkRepository(db *sqlx.DB) repositories.BookmarkRepository {
+	return &BookmarkRepository{DB: db}
+}
+
+// selectBookmarks loads bookmarks with the name of their chat and whether the
+// user can still see the message. Foreign keys are not enforced, so deleted
+// messages, chats and memberships are detected through the joins.
+const selectBookmarks = `
+	SELECT b.*, COALESCE(c.name, '') as chatName,
+		(m.id IS NOT NULL AND c.id IS NOT NULL AND cg.userId IS NOT NULL
+			AND (m.expiresAt IS NULL OR m.expiresAt > $1)) as available
+	FROM bookmarks b
+	LEFT JOIN messages m ON m.id = b.messageId
+	LEFT JOIN chats c ON c.id = b.chatId
+	LEFT JOIN chat_groups cg ON cg.chatId = b.chatId AND cg.userId = b.userId
+`
+
+func (r *BookmarkRepository) Create(bookmark *models.Bookmark) error {
+	query := `
+		INSERT INTO bookmarks (userId, messageId, chatId, note, remindAt, createdAt, updatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(
+		query,
+		bookmark.UserId,
+		bookmark.MessageId,
+		bookmark.ChatId,
+		bookmark.Note,
+		bookmark.RemindAt,
+		bookmark.CreatedAt,
+		bookmark.UpdatedAt,
+	)
+	return row.Scan(&bookmark.ID)
+}
+
+func (r *BookmarkRepository) FindById(id int) (*models.Bookmark, error) {
+	bookmark := models.Bookmark{}
+	query := selectBookmarks + `WHERE b.id = $2`
+	err := r.DB.Get(&bookmark, query, time.Now().UTC(), id)
+	if err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+func (r *BookmarkRepository) FindByUserAndMessage(userId, messageId int) (*models.Bookmark, error) {
+	bookmark := models.Bookmark{}
+	query := selectBookmarks + `WHERE b.userId = $2 AND b.messageId = $3`
+	err := r.DB.Get(&bookmark, query, time.Now().UTC(), userId, messageId)
+	if err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+func (r *BookmarkRepository) FindByUserId(userId, chatId, cursor, limit int) ([]models.Bookmark, error) {
+	bookmarks := []models.Bookmark{}
+	query := selectBookmarks + `
+		WHERE b.userId = $2 AND ($3 = 0 OR b.chatId = $3) AND ($4 = 0 OR b.id < $4)
+		ORDER BY b.id DESC
+		LIMIT $5
+	`
+	err := r.DB.Select(&bookmarks, query, time.Now().UTC(), userId, chatId, cursor, limit)
+	return bookmarks, err
+}
+
+// Update changes the note and reminder; a new reminder time is sent again
+func (r *BookmarkRepository) Update(bookmark *models.Bookmark) error {
+	query := `
+		UPDATE bookmarks
+		SET note = $1, remindAt = $2, remindedAt = $3, updatedAt = $4
+		WHERE id = $5
+	`
+	_, err := r.DB.Exec(query, bookmark.Note, bookmark.RemindAt, bookmark.RemindedAt, bookmark.UpdatedAt, bookmark.ID)
+	return err
+}
+
+func (r *BookmarkRepository) Delete(id int) error {
+	query := `DELETE FROM bookmarks WHERE id = $1`
+	_, err := r.DB.Exec(query, id)
+	return err
+}
+
+func (r *BookmarkRepository) FindDueReminders(now time.Time, limit int) ([]models.Bookmark, error) {
+	bookmarks := []models.Bookmark{}
+	query := selectBookmarks + `
+		WHERE b.remindedAt IS NULL AND b.remindAt <= $2
+		ORDER BY b.remindAt ASC
+		LIMIT $3
+	`
+	err := r.DB.Select(&bookmarks, query, now, now, limit)
+	return bookmarks, err
+}
+
+func (r *BookmarkRepository) MarkReminded(bookmark *models.Bookmark, at time.Time) (bool, error) {
+	query := `
+		UPDATE bookmarks SET remindedAt = $1
+		WHERE id = $2 AND remindedAt IS NULL AND remindAt = $3
+	`
+	return affected(r.DB.Exec(query, at, bookmark.ID, bookmark.RemindAt))
+}
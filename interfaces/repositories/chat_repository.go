@@ -6,19 +6,23 @@ import (
 	"github.com/f1rstid/realtime-chat/domain/repositories"
 	"github.com/jmoiron/sqlx"
 	"strings"
+	"time"
 )
 
 type ChatRepository struct {
 	DB *sqlx.DB
+
+	// cipher decrypts the last messages of chats
+	cipher *ContentCipher
 }
 
-func NewChatRepository(db *sqlx.DB) repositories.ChatRepository {
-	return &ChatRepository{DB: db}
+func NewChatRepository(db *sqlx.DB, cipher *ContentCipher) repositories.ChatRepository {
+	return &ChatRepository{DB: db, cipher: cipher}
 }
 
 func (r *ChatRepository) Create(chat *models.Chat) error {
-	query := `INSERT INTO chats (name) VALUES ($1) RETURNING id`
-	row := r.DB.QueryRow(query, chat.Name)
+	query := `INSERT INTO chats (name, encrypted) VALUES ($1, $2) RETURNING id`
+	row := r.DB.QueryRow(query, chat.Name, chat.Encrypted)
 	return row.Scan(&chat.ID)
 }
 
@@ -39,6 +43,30 @@ func (r *ChatRepository) Update(chat *models.Chat) error {
 	return err
 }
 
+func (r *ChatRepository) UpdateMessageTTL(chatID int, seconds int) error {
+	query := `UPDATE chats SET messageTtlSeconds = $1 WHERE id = $2`
+	_, err := r.DB.Exec(query, seconds, chatID)
+	return err
+}
+
+func (r *ChatRepository) UpdateSlowMode(chatID int, seconds int) error {
+	query := `UPDATE chats SET slowModeSeconds = $1 WHERE id = $2`
+	_, err := r.DB.Exec(query, seconds, chatID)
+	return err
+}
+
+func (r *ChatRepository) UpdateRetention(chatID int, policy models.RetentionPolicy) error {
+	query := `UPDATE chats SET retentionDays = $1, retentionMaxMessages = $2 WHERE id = $3`
+	_, err := r.DB.Exec(query, policy.Days, policy.MaxMessages, chatID)
+	return err
+}
+
+func (r *ChatRepository) UpdateTopic(chatID int, topic string) error {
+	query := `UPDATE chats SET topic = $1 WHERE id = $2`
+	_, err := r.DB.Exec(query, topic, chatID)
+	return err
+}
+
 func (r *ChatRepository) Delete(id int) error {
 	query := `DELETE FROM chats WHERE id = $1`
 	_, err := r.DB.Exec(query, id)
@@ -57,6 +85,33 @@ func (r *ChatRepository) RemoveUserFromChat(chatID, userID int) error {
 	return err
 }
 
+func (r *ChatRepository) GetUserRole(chatID, userID int) (string, error) {
+	var role string
+	query := `SELECT role FROM chat_groups WHERE chatId = $1 AND userId = $2`
+	err := r.DB.Get(&role, query, chatID, userID)
+	return role, err
+}
+
+func (r *ChatRepository) SetUserRole(chatID, userID int, role string) error {
+	query := `UPDATE chat_groups SET role = $1 WHERE chatId = $2 AND userId = $3`
+	_, err := r.DB.Exec(query, role, chatID, userID)
+	return err
+}
+
+// EnsureOwner makes the earliest member owner of a chat left without managers
+func (r *ChatRepository) EnsureOwner(chatID int) error {
+	query := `
+		UPDATE chat_groups SET role = $1
+		WHERE rowid = (
+			SELECT rowid FROM chat_groups WHERE chatId = $2 ORDER BY createdAt, rowid LIMIT 1
+		) AND NOT EXISTS (
+			SELECT 1 FROM chat_groups WHERE chatId = $2 AND role IN ($3, $4)
+		)
+	`
+	_, err := r.DB.Exec(query, models.ChatRoleOwner, chatID, models.ChatRoleOwner, models.ChatRoleAdmin)
+	return err
+}
+
 func (r *ChatRepository) GetChatUsers(chatID int) ([]models.User, error) {
 	var users []models.User
 	query := `
@@ -70,6 +125,20 @@ func (r *ChatRepository) GetChatUsers(chatID int) ([]models.User, error) {
 	return users, err
 }
 
+// GetChatMembers returns the members of a chat with their roles, in the order they joined
+func (r *ChatRepository) GetChatMembers(chatID int) ([]models.ChatMember, error) {
+	members := []models.ChatMember{}
+	query := `
+		SELECT u.id AS userId, u.nickname, cg.role, u.isBot, cg.createdAt AS joinedAt
+		FROM chat_groups cg
+		JOIN users u ON u.id = cg.userId
+		WHERE cg.chatId = $1
+		ORDER BY cg.createdAt, u.id
+	`
+	err := r.DB.Select(&members, query, chatID)
+	return members, err
+}
+
 func (r *ChatRepository) GetUserChats(userID int) ([]models.Chat, error) {
 	var chats []models.Chat
 	query := `
@@ -83,6 +152,21 @@ func (r *ChatRepository) GetUserChats(userID int) ([]models.Chat, error) {
 	return chats, err
 }
 
+// GetEncryptedChatContacts returns the other members of the user's encrypted chats
+func (r *ChatRepository) GetEncryptedChatContacts(userID int) ([]models.User, error) {
+	users := []models.User{}
+	query := `
+		SELECT DISTINCT u.*
+		FROM chat_groups own
+		JOIN chats c ON c.id = own.chatId AND c.encrypted = 1
+		JOIN chat_groups other ON other.chatId = own.chatId AND other.userId != own.userId
+		JOIN users u ON u.id = other.userId
+		WHERE own.userId = $1
+	`
+	err := r.DB.Select(&users, query, userID)
+	return users, err
+}
+
 func (r *ChatRepository) GetLastMessages(chatIDs []int) (map[int]*models.Message, error) {
 	if len(chatIDs) == 0 {
 		return make(map[int]*models.Message), nil
@@ -96,18 +180,22 @@ func (r *ChatRepository) GetLastMessages(chatIDs []int) (map[int]*models.Message
 		args[i] = chatIDs[i]
 	}
 
+	// Disappearing messages that already expired are skipped
+	args = append(args, time.Now().UTC())
+	now := fmt.Sprintf("$%d", len(args))
+
 	query := fmt.Sprintf(`
-        SELECT m.*, u.nickname as senderNickname
+        SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname
         FROM messages m
         JOIN users u ON m.senderId = u.id
         JOIN (
             SELECT chatId, MAX(createdAt) as maxCreatedAt
             FROM messages
-            WHERE chatId IN (%s)
+            WHERE chatId IN (%s) AND (expiresAt IS NULL OR expiresAt > %s)
             GROUP BY chatId
         ) latest ON m.chatId = latest.chatId AND m.createdAt = latest.maxCreatedAt
         ORDER BY m.createdAt DESC
-    `, strings.Join(placeholders, ","))
+    `, strings.Join(placeholders, ","), now)
 
 	var messages []models.Message
 	err := r.DB.Select(&messages, query, args...)
@@ -115,6 +203,10 @@ func (r *ChatRepository) GetLastMessages(chatIDs []int) (map[int]*models.Message
 		return nil, fmt.Errorf("failed to get last messages: %v", err)
 	}
 
+	if err := r.cipher.openMessages(messages); err != nil {
+		return nil, err
+	}
+
 	result := make(map[int]*models.Message)
 	for i := range messages {
 		result[messages[i].ChatId] = &messages[i]
@@ -0,0 +1,715 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/infrastructure/encryption"
+	"github.com/jmoiron/sqlx"
+)
+
+// ContentCipher encrypts message content at rest, along with the copies of
+// message text kept in drafts, scheduled messages, moderation flags, webhook
+// deliveries, polls and link previews. Each chat has its own data key, stored wrapped with the
+// master key, so rotating the master key only re-wraps the data keys. Without
+// a master key messages are stored in plaintext.
+type ContentCipher struct {
+	DB *sqlx.DB
+
+	masterKey   []byte
+	masterKeyID string
+
+	mu sync.Mutex
+	// dataKeys caches unwrapped data keys by chat ID
+	dataKeys map[int][]byte
+}
+
+// sealedMessage is what is encrypted into messages.content. The columns
+// derived from the content are stored empty so they do not leak it.
+type sealedMessage struct {
+	Content     string                    `json:"content"`
+	Formatted   models.RichText           `json:"formatted,omitempty"`
+	PlainText   string                    `json:"plainText,omitempty"`
+	Attachments models.MessageAttachments `json:"attachments,omitempty"`
+}
+
+// sealedColumns are the values written to the content columns of a message
+type sealedColumns struct {
+	Content     string
+	Formatted   models.RichText
+	PlainText   string
+	Attachments models.MessageAttachments
+}
+
+// NewContentCipher returns a cipher using masterKey, which may be nil. It
+// fails when data keys were wrapped with another master key, so a server
+// started with the wrong key does not write messages it cannot read back.
+func NewContentCipher(db *sqlx.DB, masterKey []byte) (*ContentCipher, error) {
+	c := &ContentCipher{DB: db, masterKey: masterKey, dataKeys: make(map[int][]byte)}
+	if masterKey != nil {
+		c.masterKeyID = encryption.KeyID(masterKey)
+	}
+
+	var foreign int
+	query := `SELECT COUNT(*) FROM chat_data_keys WHERE masterKeyId != $1`
+	if err := db.Get(&foreign, query, c.masterKeyID); err != nil {
+		return nil, err
+	}
+	if foreign > 0 {
+		if masterKey == nil {
+			return nil, errors.New("messages are encrypted at rest but no encryption key is configured")
+		}
+		return nil, fmt.Errorf("%d chat data keys are wrapped with another master key", foreign)
+	}
+	return c, nil
+}
+
+// Enabled reports whether new messages are encrypted
+func (c *ContentCipher) Enabled() bool {
+	return c.masterKey != nil
+}
+
+// RotateMasterKey re-wraps every chat data key with newKey and returns the
+// number of keys re-wrapped. Messages are left as they are, since their data
+// keys do not change. The server must be restarted with the new key.
+func (c *ContentCipher) RotateMasterKey(newKey []byte) (int, error) {
+	if !c.Enabled() {
+		return 0, errors.New("no encryption key is configured")
+	}
+	newKeyID := encryption.KeyID(newKey)
+	if newKeyID == c.masterKeyID {
+		return 0, errors.New("the new key is the current key")
+	}
+
+	tx, err := c.DB.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		ChatId     int    `db:"chatId"`
+		WrappedKey string `db:"wrappedKey"`
+	}
+	if err := tx.Select(&rows, `SELECT chatId, wrappedKey FROM chat_data_keys`); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	for _, row := range rows {
+		dataKey, err := encryption.Open(c.masterKey, row.WrappedKey, dataKeyAAD(row.ChatId))
+		if err != nil {
+			return 0, fmt.Errorf("failed to unwrap data key of chat %d: %w", row.ChatId, err)
+		}
+		wrapped, err := encryption.Seal(newKey, dataKey, dataKeyAAD(row.ChatId))
+		if err != nil {
+			return 0, err
+		}
+		query := `UPDATE chat_data_keys SET wrappedKey = $1, masterKeyId = $2, rotatedAt = $3 WHERE chatId = $4`
+		if _, err := tx.Exec(query, wrapped, newKeyID, now, row.ChatId); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.masterKey, c.masterKeyID = newKey, newKeyID
+	c.mu.Unlock()
+	return len(rows), nil
+}
+
+// sealedValueColumns are the columns outside the messages table holding
+// message text. chat and keys are the expressions, over the table aliased t
+// and its join, of the chat and the row keys bound to a value; aad is the
+// table's prefix in rowAAD.
+var sealedValueColumns = []struct {
+	table, column string
+	join, chat    string
+	keys          []string
+	aad           string
+}{
+	{"drafts", "content", "", "t.chatId", []string{"t.userId"}, "draft"},
+	{"scheduled_messages", "content", "", "t.chatId", []string{"t.senderId"}, "scheduled"},
+	{"moderation_flags", "content", "", "t.chatId", []string{"t.messageId"}, "flag"},
+	{"webhook_deliveries", "payload", "", "t.chatId", []string{"t.outgoingWebhookId"}, "delivery"},
+	{"polls", "question", "", "t.chatId", []string{"t.messageId"}, "poll"},
+	{"poll_options", "text", "JOIN polls p ON p.id = t.pollId", "p.chatId", []string{"t.pollId", "t.position"}, "poll_option"},
+}
+
+// EncryptExisting seals the message text stored in plaintext, before a master
+// key was configured, and returns the number of rows sealed per table. The
+// search index is emptied with it and the database is vacuumed, so the
+// plaintext does not stay behind in free pages.
+func (c *ContentCipher) EncryptExisting() (map[string]int, error) {
+	if !c.Enabled() {
+		return nil, errors.New("no encryption key is configured")
+	}
+
+	counts := make(map[string]int)
+	count, err := c.encryptExistingMessages()
+	if err != nil {
+		return nil, err
+	}
+	counts["messages"] = count
+
+	for _, columns := range sealedValueColumns {
+		count, err := c.encryptExistingValues(columns.table, columns.column, columns.join, columns.chat,
+			columns.keys, columns.aad)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s: %w", columns.table, err)
+		}
+		counts[columns.table] = count
+	}
+
+	count, err = c.encryptExistingLinkPreviews()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt link_previews: %w", err)
+	}
+	counts["link_previews"] = count
+
+	var fts int
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'messages_fts'`
+	if err := c.DB.Get(&fts, query); err != nil {
+		return nil, err
+	}
+	if fts > 0 {
+		// Merges the index segments, dropping the deleted plaintext
+		if _, err := c.DB.Exec(`INSERT INTO messages_fts(messages_fts) VALUES ('optimize')`); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := c.DB.Exec(`VACUUM`); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// encryptExistingMessages seals the messages stored in plaintext in batches.
+// A message changed since it was read is left for the next run.
+func (c *ContentCipher) encryptExistingMessages() (int, error) {
+	const batchSize = 500
+
+	count, afterID := 0, 0
+	for {
+		var batch []models.Message
+		query := `
+			SELECT id, chatId, content, formatted, plainText, attachments FROM messages
+			WHERE id > $1
+			ORDER BY id ASC
+			LIMIT $2
+		`
+		if err := c.DB.Select(&batch, query, afterID, batchSize); err != nil {
+			return count, err
+		}
+		if len(batch) == 0 {
+			return count, nil
+		}
+		afterID = batch[len(batch)-1].ID
+
+		// Sealed before the transaction, which may create data keys
+		var plain []models.Message
+		var sealed []sealedColumns
+		for i := range batch {
+			if encryption.IsSealed(batch[i].Content) {
+				continue
+			}
+			columns, err := c.sealMessage(&batch[i])
+			if err != nil {
+				return count, err
+			}
+			plain = append(plain, batch[i])
+			sealed = append(sealed, columns)
+		}
+
+		tx, err := c.DB.Beginx()
+		if err != nil {
+			return count, err
+		}
+		query = `
+			UPDATE messages SET content = $1, formatted = $2, plainText = $3, attachments = $4
+			WHERE id = $5 AND content = $6
+		`
+		sealedCount := 0
+		for i, message := range plain {
+			result, err := tx.Exec(query, sealed[i].Content, sealed[i].Formatted, sealed[i].PlainText, sealed[i].Attachments,
+				message.ID, message.Content)
+			if err != nil {
+				tx.Rollback()
+				return count, err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				tx.Rollback()
+				return count, err
+			}
+			sealedCount += int(affected)
+		}
+		if err := tx.Commit(); err != nil {
+			return count, err
+		}
+		count += sealedCount
+	}
+}
+
+// encryptExistingValues seals the plaintext values of a column listed in
+// sealedValueColumns in batches. A value changed since it was read is left
+// for the next run.
+func (c *ContentCipher) encryptExistingValues(table, column, join, chat string, keys []string, aad string) (int, error) {
+	const batchSize = 500
+
+	type valueRow struct {
+		RowId  int    `db:"rowId"`
+		ChatId int    `db:"chatId"`
+		Key0   int    `db:"key0"`
+		Key1   int    `db:"key1"`
+		Value  string `db:"value"`
+	}
+
+	keyColumns := ""
+	for i, key := range keys {
+		keyColumns += fmt.Sprintf(", %s AS key%d", key, i)
+	}
+
+	count, afterRowID := 0, 0
+	for {
+		var batch []valueRow
+		query := fmt.Sprintf(`
+			SELECT t.rowid AS rowId, %s AS chatId, t.%s AS value%s FROM %s t %s
+			WHERE t.rowid > $1
+			ORDER BY t.rowid ASC
+			LIMIT $2
+		`, chat, column, keyColumns, table, join)
+		if err := c.DB.Select(&batch, query, afterRowID, batchSize); err != nil {
+			return count, err
+		}
+		if len(batch) == 0 {
+			return count, nil
+		}
+		afterRowID = batch[len(batch)-1].RowId
+
+		var plain []valueRow
+		var sealed []string
+		for _, row := range batch {
+			if row.Value == "" || encryption.IsSealed(row.Value) {
+				continue
+			}
+			rowKeys := append([]int{row.ChatId}, row.Key0, row.Key1)[:1+len(keys)]
+			value, err := c.sealValue(row.ChatId, row.Value, rowAAD(aad, rowKeys...))
+			if err != nil {
+				return count, err
+			}
+			plain = append(plain, row)
+			sealed = append(sealed, value)
+		}
+
+		tx, err := c.DB.Beginx()
+		if err != nil {
+			return count, err
+		}
+		query = fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE rowid = $2 AND %s = $3`, table, column, column)
+		sealedCount := 0
+		for i, row := range plain {
+			result, err := tx.Exec(query, sealed[i], row.RowId, row.Value)
+			if err != nil {
+				tx.Rollback()
+				return count, err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				tx.Rollback()
+				return count, err
+			}
+			sealedCount += int(affected)
+		}
+		if err := tx.Commit(); err != nil {
+			return count, err
+		}
+		count += sealedCount
+	}
+}
+
+// encryptExistingLinkPreviews moves the link previews cached in plaintext
+// to the chats whose messages link them, keyed by the lookup key of the URL
+// and sealed, and drops the plaintext cache. It returns the number of
+// previews sealed.
+func (c *ContentCipher) encryptExistingLinkPreviews() (int, error) {
+	var links []struct {
+		ChatId int    `db:"chatId"`
+		URL    string `db:"url"`
+	}
+	query := `
+		SELECT DISTINCT m.chatId, mlp.url
+		FROM message_link_previews mlp
+		JOIN messages m ON m.id = mlp.messageId
+		LEFT JOIN link_previews lp ON lp.url = mlp.url
+		WHERE lp.chatId IS NULL
+	`
+	if err := c.DB.Select(&links, query); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, link := range links {
+		if encryption.IsLookup(link.URL) {
+			continue
+		}
+
+		key, err := c.lookupKey(link.ChatId, link.URL)
+		if err != nil {
+			return count, err
+		}
+
+		// Links whose preview was never stored only get their URL replaced
+		var sealed *linkPreviewRow
+		var row linkPreviewRow
+		query := `SELECT * FROM link_previews WHERE url = $1 AND chatId IS NULL`
+		err = c.DB.Get(&row, query, link.URL)
+		switch {
+		case err == nil:
+			if sealed, err = c.sealLinkPreview(link.ChatId, &row.LinkPreview); err != nil {
+				return count, err
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return count, err
+		}
+
+		tx, err := c.DB.Beginx()
+		if err != nil {
+			return count, err
+		}
+		if sealed != nil {
+			if err := saveLinkPreview(tx, sealed); err != nil {
+				tx.Rollback()
+				return count, err
+			}
+			count++
+		}
+		query = `
+			UPDATE message_link_previews SET url = $1
+			WHERE url = $2 AND messageId IN (SELECT id FROM messages WHERE chatId = $3)
+		`
+		if _, err := tx.Exec(query, key, link.URL, link.ChatId); err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		if err := tx.Commit(); err != nil {
+			return count, err
+		}
+	}
+
+	_, err := c.DB.Exec(`DELETE FROM link_previews WHERE chatId IS NULL`)
+	return count, err
+}
+
+// sealMessage returns the content columns to store for a message. The
+// sealed content is bound to the message ID, so the message must be stored
+// already. The message itself is left readable for the caller.
+func (c *ContentCipher) sealMessage(message *models.Message) (sealedColumns, error) {
+	if !c.Enabled() {
+		return plainColumns(message), nil
+	}
+
+	data, err := json.Marshal(sealedMessage{
+		Content:     message.Content,
+		Formatted:   message.Formatted,
+		PlainText:   message.PlainText,
+		Attachments: message.Attachments,
+	})
+	if err != nil {
+		return sealedColumns{}, err
+	}
+
+	dataKey, err := c.dataKey(message.ChatId, true)
+	if err != nil {
+		return sealedColumns{}, err
+	}
+	content, err := encryption.Seal(dataKey, data, messageAAD(message.ChatId, message.ID))
+	if err != nil {
+		return sealedColumns{}, err
+	}
+	return sealedColumns{Content: content}, nil
+}
+
+// prepareChat loads or creates the data key of a chat. Call it before
+// sealing in a transaction, which would otherwise wait on itself when the
+// key is created through another connection.
+func (c *ContentCipher) prepareChat(chatID int) error {
+	if !c.Enabled() {
+		return nil
+	}
+	_, err := c.dataKey(chatID, true)
+	return err
+}
+
+// plainColumns returns the content columns of a message stored in plaintext
+func plainColumns(message *models.Message) sealedColumns {
+	return sealedColumns{
+		Content:     message.Content,
+		Formatted:   message.Formatted,
+		PlainText:   message.PlainText,
+		Attachments: message.Attachments,
+	}
+}
+
+// openMessage decrypts the content of a message read from the database.
+// Messages stored in plaintext are left as they are.
+func (c *ContentCipher) openMessage(message *models.Message) error {
+	sealed, err := c.open(message.ChatId, message.ID, message.Content)
+	if err != nil || sealed == nil {
+		return err
+	}
+	message.Content = sealed.Content
+	message.Formatted = sealed.Formatted
+	message.PlainText = sealed.PlainText
+	message.Attachments = sealed.Attachments
+	return nil
+}
+
+func (c *ContentCipher) openMessages(messages []models.Message) error {
+	for i := range messages {
+		if err := c.openMessage(&messages[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ContentCipher) openPin(pin *models.PinnedMessage) error {
+	sealed, err := c.open(pin.ChatId, pin.MessageId, pin.Content)
+	if err != nil || sealed == nil {
+		return err
+	}
+	pin.Content = sealed.Content
+	pin.Formatted = sealed.Formatted
+	return nil
+}
+
+// sealLinkPreview returns the link_previews row to store for a preview of a
+// link in a chat. With encryption enabled the row is the chat's own, keyed by
+// the lookup key of the URL, and its fields are sealed; otherwise the preview
+// is cached in plaintext for every chat.
+func (c *ContentCipher) sealLinkPreview(chatID int, preview *models.LinkPreview) (*linkPreviewRow, error) {
+	row := &linkPreviewRow{LinkPreview: *preview}
+	if !c.Enabled() {
+		return row, nil
+	}
+
+	key, err := c.lookupKey(chatID, preview.URL)
+	if err != nil {
+		return nil, err
+	}
+	row.URL = key
+	row.ChatId = sql.NullInt64{Int64: int64(chatID), Valid: true}
+
+	fields := []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{"url", preview.URL, &row.SealedURL},
+		{"title", preview.Title, &row.Title},
+		{"description", preview.Description, &row.Description},
+		{"imageUrl", preview.ImageURL, &row.ImageURL},
+		{"siteName", preview.SiteName, &row.SiteName},
+	}
+	for _, field := range fields {
+		sealed, err := c.sealValue(chatID, field.value, linkPreviewAAD(chatID, key, field.name))
+		if err != nil {
+			return nil, err
+		}
+		*field.dest = sealed
+	}
+	return row, nil
+}
+
+// openLinkPreview returns the preview stored in a link_previews row
+func (c *ContentCipher) openLinkPreview(row *linkPreviewRow) (models.LinkPreview, error) {
+	preview := row.LinkPreview
+	if !row.ChatId.Valid {
+		return preview, nil
+	}
+
+	chatID, key := int(row.ChatId.Int64), row.URL
+	fields := []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{"url", row.SealedURL, &preview.URL},
+		{"title", row.Title, &preview.Title},
+		{"description", row.Description, &preview.Description},
+		{"imageUrl", row.ImageURL, &preview.ImageURL},
+		{"siteName", row.SiteName, &preview.SiteName},
+	}
+	for _, field := range fields {
+		value, err := c.openValue(chatID, field.value, linkPreviewAAD(chatID, key, field.name))
+		if err != nil {
+			return models.LinkPreview{}, err
+		}
+		*field.dest = value
+	}
+	return preview, nil
+}
+
+// lookupKey returns the key under which rows holding a sealed value are
+// found, which is the value itself when encryption is disabled
+func (c *ContentCipher) lookupKey(chatID int, value string) (string, error) {
+	if !c.Enabled() {
+		return value, nil
+	}
+
+	dataKey, err := c.dataKey(chatID, true)
+	if err != nil {
+		return "", err
+	}
+	return encryption.Lookup(dataKey, value), nil
+}
+
+// sealValue seals message text stored outside the messages table with the
+// chat's data key. aad binds it to its row. Empty values are left as they are.
+func (c *ContentCipher) sealValue(chatID int, value string, aad []byte) (string, error) {
+	if !c.Enabled() || value == "" {
+		return value, nil
+	}
+
+	dataKey, err := c.dataKey(chatID, true)
+	if err != nil {
+		return "", err
+	}
+	return encryption.Seal(dataKey, []byte(value), aad)
+}
+
+// openValue decrypts a value sealed by sealValue. Values stored in plaintext
+// are returned as they are.
+func (c *ContentCipher) openValue(chatID int, value string, aad []byte) (string, error) {
+	if !encryption.IsSealed(value) {
+		return value, nil
+	}
+	if !c.Enabled() {
+		return "", errors.New("value is encrypted but no encryption key is configured")
+	}
+
+	dataKey, err := c.dataKey(chatID, false)
+	if err != nil {
+		return "", err
+	}
+	data, err := encryption.Open(dataKey, value, aad)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", aad, err)
+	}
+	return string(data), nil
+}
+
+// open returns the sealed fields of a message's content, or nil if it is plaintext
+func (c *ContentCipher) open(chatID, messageID int, content string) (*sealedMessage, error) {
+	if !encryption.IsSealed(content) {
+		return nil, nil
+	}
+	if !c.Enabled() {
+		return nil, errors.New("message is encrypted but no encryption key is configured")
+	}
+
+	dataKey, err := c.dataKey(chatID, false)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encryption.Open(dataKey, content, messageAAD(chatID, messageID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt message %d of chat %d: %w", messageID, chatID, err)
+	}
+
+	var sealed sealedMessage
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// dataKey returns the data key of a chat, creating it if create is set
+func (c *ContentCipher) dataKey(chatID int, create bool) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.dataKeys[chatID]; ok {
+		return key, nil
+	}
+
+	var wrapped string
+	query := `SELECT wrappedKey FROM chat_data_keys WHERE chatId = $1`
+	err := c.DB.Get(&wrapped, query, chatID)
+	if errors.Is(err, sql.ErrNoRows) && create {
+		wrapped, err = c.createDataKey(chatID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data key of chat %d: %w", chatID, err)
+	}
+
+	key, err := encryption.Open(c.masterKey, wrapped, dataKeyAAD(chatID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key of chat %d: %w", chatID, err)
+	}
+	c.dataKeys[chatID] = key
+	return key, nil
+}
+
+// createDataKey stores a new data key for a chat and returns the wrapped key
+// in use, which is another process's if it created one first
+func (c *ContentCipher) createDataKey(chatID int) (string, error) {
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := encryption.Seal(c.masterKey, key, dataKeyAAD(chatID))
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO chat_data_keys (chatId, wrappedKey, masterKeyId, createdAt)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chatId) DO NOTHING
+	`
+	if _, err := c.DB.Exec(query, chatID, wrapped, c.masterKeyID, time.Now().UTC()); err != nil {
+		return "", err
+	}
+
+	query = `SELECT wrappedKey FROM chat_data_keys WHERE chatId = $1`
+	err = c.DB.Get(&wrapped, query, chatID)
+	return wrapped, err
+}
+
+// messageAAD binds sealed content to its message, so it cannot be copied to
+// another message's row
+func messageAAD(chatID, messageID int) []byte {
+	return []byte("message:" + strconv.Itoa(chatID) + ":" + strconv.Itoa(messageID))
+}
+
+// rowAAD binds a value sealed by sealValue to the row it is stored in
+func rowAAD(table string, keys ...int) []byte {
+	aad := table
+	for _, key := range keys {
+		aad += ":" + strconv.Itoa(key)
+	}
+	return []byte(aad)
+}
+
+// linkPreviewAAD binds a field of a sealed link preview to its row, keyed by
+// the lookup key of the URL
+func linkPreviewAAD(chatID int, key, field string) []byte {
+	return []byte("link_preview:" + strconv.Itoa(chatID) + ":" + key + ":" + field)
+}
+
+// dataKeyAAD binds a wrapped data key to its chat, so it cannot be copied to
+// another chat's row
+func dataKeyAAD(chatID int) []byte {
+	return []byte("chat:" + strconv.Itoa(chatID))
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/infrastructure/encryption"
+	"github.com/f1rstid/realtime-chat/infrastructure/sqlite"
+)
+
+func TestEncryptExistingSealsPollsAndLinkPreviews(t *testing.T) {
+	newTestDB(t)
+
+	plain, err := NewContentCipher(sqlite.DB, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := models.Message{ChatId: 1, SenderId: 1, Type: models.MessageTypeUser, Content: "lunch? https://example.com/menu"}
+	message.CreatedAt, message.UpdatedAt = time.Now(), time.Now()
+	if err := NewMessageRepository(sqlite.DB, plain).Create(&message); err != nil {
+		t.Fatal(err)
+	}
+
+	poll := models.Poll{
+		MessageId: message.ID, ChatId: 1, CreatorId: 1, Question: "lunch?", CreatedAt: time.Now(),
+		Options: []models.PollOption{{Text: "noodles"}, {Text: "rice"}},
+	}
+	if err := NewPollRepository(sqlite.DB, plain).Create(&poll); err != nil {
+		t.Fatal(err)
+	}
+
+	const url = "https://example.com/menu"
+	previews := NewLinkPreviewRepository(sqlite.DB, plain)
+	if err := previews.Save(1, &models.LinkPreview{URL: url, Title: "Menu", FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := previews.SetMessageLinks(1, message.ID, []string{url}); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := NewContentCipher(sqlite.DB, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := cipher.EncryptExisting()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for table, want := range map[string]int{"polls": 1, "poll_options": 2, "link_previews": 1} {
+		if counts[table] != want {
+			t.Errorf("sealed %d rows in %s, want %d", counts[table], table, want)
+		}
+	}
+
+	var stored []string
+	query := `
+		SELECT question FROM polls
+		UNION ALL SELECT text FROM poll_options
+		UNION ALL SELECT title FROM link_previews
+		UNION ALL SELECT sealedUrl FROM link_previews
+	`
+	if err := sqlite.DB.Select(&stored, query); err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range stored {
+		if !encryption.IsSealed(value) {
+			t.Errorf("stored %q in plaintext", value)
+		}
+	}
+	var urls []string
+	query = `SELECT url FROM link_previews UNION ALL SELECT url FROM message_link_previews`
+	if err := sqlite.DB.Select(&urls, query); err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range urls {
+		if !encryption.IsLookup(value) {
+			t.Errorf("stored URL %q in plaintext", value)
+		}
+	}
+
+	found, err := NewPollRepository(sqlite.DB, cipher).FindById(poll.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Question != "lunch?" || len(found.Options) != 2 || found.Options[1].Text != "rice" {
+		t.Errorf("poll = %q %+v, want the question and options back", found.Question, found.Options)
+	}
+
+	previews = NewLinkPreviewRepository(sqlite.DB, cipher)
+	byMessage, err := previews.FindByMessageIds([]int{message.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := byMessage[message.ID]; len(got) != 1 || got[0].URL != url || got[0].Title != "Menu" {
+		t.Errorf("previews = %+v, want the preview of %s", got, url)
+	}
+	if _, err := previews.FindByURL(1, url); err != nil {
+		t.Errorf("cached preview not found by URL: %v", err)
+	}
+}
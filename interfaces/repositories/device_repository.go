@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type DeviceRepository struct {
+	DB *sqlx.DB
+}
+
+func NewDeviceRepository(db *sqlx.DB) repositories.DeviceRepository {
+	return &DeviceRepository{DB: db}
+}
+
+// deviceColumns selects a device with the number of prekeys it has left
+const deviceColumns = `
+	d.*, (SELECT COUNT(*) FROM device_prekeys p WHERE p.deviceId = d.id) as prekeyCount
+`
+
+func (r *DeviceRepository) Create(device *models.Device, prekeys []models.Prekey) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO devices (userId, name, identityKey, createdAt) VALUES ($1, $2, $3, $4) RETURNING id`
+	if err := tx.QueryRow(query, device.UserId, device.Name, device.IdentityKey, device.CreatedAt).Scan(&device.ID); err != nil {
+		return err
+	}
+	if err := insertPrekeys(tx, device.ID, prekeys); err != nil {
+		return err
+	}
+	device.PrekeyCount = len(prekeys)
+
+	return tx.Commit()
+}
+
+func (r *DeviceRepository) FindById(id int) (*models.Device, error) {
+	device := models.Device{}
+	err := r.DB.Get(&device, `SELECT `+deviceColumns+` FROM devices d WHERE d.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *DeviceRepository) FindByUserId(userId int) ([]models.Device, error) {
+	devices := []models.Device{}
+	query := `SELECT ` + deviceColumns + ` FROM devices d WHERE d.userId = $1 ORDER BY d.id`
+	err := r.DB.Select(&devices, query, userId)
+	return devices, err
+}
+
+func (r *DeviceRepository) CountByUserId(userId int) (int, error) {
+	var count int
+	err := r.DB.Get(&count, `SELECT COUNT(*) FROM devices WHERE userId = $1`, userId)
+	return count, err
+}
+
+func (r *DeviceRepository) Delete(id int) (bool, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM device_prekeys WHERE deviceId = $1`, id); err != nil {
+		return false, err
+	}
+	deleted, err := affected(tx.Exec(`DELETE FROM devices WHERE id = $1`, id))
+	if err != nil {
+		return false, err
+	}
+	return deleted, tx.Commit()
+}
+
+func (r *DeviceRepository) AddPrekeys(deviceId int, prekeys []models.Prekey) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertPrekeys(tx, deviceId, prekeys); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertPrekeys(tx *sqlx.Tx, deviceId int, prekeys []models.Prekey) error {
+	query := `
+		INSERT INTO device_prekeys (deviceId, keyId, publicKey)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (deviceId, keyId) DO NOTHING
+	`
+	for _, prekey := range prekeys {
+		if _, err := tx.Exec(query, deviceId, prekey.KeyId, prekey.PublicKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *DeviceRepository) ClaimPrekey(deviceId int) (*models.Prekey, error) {
+	// Deleting in the same statement hands each prekey out at most once
+	prekey := models.Prekey{}
+	query := `
+		DELETE FROM device_prekeys
+		WHERE deviceId = $1 AND keyId = (
+			SELECT keyId FROM device_prekeys WHERE deviceId = $1 ORDER BY keyId LIMIT 1
+		)
+		RETURNING deviceId, keyId, publicKey
+	`
+	err := r.DB.Get(&prekey, query, deviceId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prekey, nil
+}
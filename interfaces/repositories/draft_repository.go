@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type DraftRepository struct {
+	DB *sqlx.DB
+
+	cipher *ContentCipher
+}
+
+func NewDraftRepository(db *sqlx.DB, cipher *ContentCipher) repositories.DraftRepository {
+	return &DraftRepository{DB: db, cipher: cipher}
+}
+
+func (r *DraftRepository) Save(draft *models.Draft) (*models.Draft, bool, error) {
+	// Sealed before the transaction, which may create the chat's data key
+	content, err := r.cipher.sealValue(draft.ChatId, draft.Content, draftAAD(draft))
+	if err != nil {
+		return nil, false, err
+	}
+
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var stored models.Draft
+	query := `SELECT * FROM drafts WHERE userId = $1 AND chatId = $2`
+	err = tx.Get(&stored, query, draft.UserId, draft.ChatId)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return nil, false, err
+	case !draft.UpdatedAt.After(stored.UpdatedAt):
+		// Last write wins; the stored draft is newer
+		if err := r.open(&stored); err != nil {
+			return nil, false, err
+		}
+		return &stored, false, nil
+	}
+
+	query = `
+		INSERT INTO drafts (userId, chatId, content, updatedAt)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (userId, chatId) DO UPDATE SET content = excluded.content, updatedAt = excluded.updatedAt
+	`
+	if _, err := tx.Exec(query, draft.UserId, draft.ChatId, content, draft.UpdatedAt); err != nil {
+		return nil, false, err
+	}
+
+	return draft, true, tx.Commit()
+}
+
+func (r *DraftRepository) FindByUserId(userId int) ([]models.Draft, error) {
+	drafts := []models.Draft{}
+	query := `SELECT * FROM drafts WHERE userId = $1 AND content != ''`
+	if err := r.DB.Select(&drafts, query, userId); err != nil {
+		return nil, err
+	}
+
+	for i := range drafts {
+		if err := r.open(&drafts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return drafts, nil
+}
+
+func (r *DraftRepository) open(draft *models.Draft) error {
+	content, err := r.cipher.openValue(draft.ChatId, draft.Content, draftAAD(draft))
+	if err != nil {
+		return err
+	}
+	draft.Content = content
+	return nil
+}
+
+func draftAAD(draft *models.Draft) []byte {
+	return rowAAD("draft", draft.ChatId, draft.UserId)
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type ExportRepository struct {
+	DB *sqlx.DB
+}
+
+func NewExportRepository(db *sqlx.DB) repositories.ExportRepository {
+	return &ExportRepository{DB: db}
+}
+
+func (r *ExportRepository) Create(export *models.ChatExport) error {
+	query := `
+		INSERT INTO chat_exports (chatId, requestedBy, format, status, createdAt)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(query, export.ChatId, export.RequestedBy, export.Format, export.Status, export.CreatedAt)
+	return row.Scan(&export.ID)
+}
+
+func (r *ExportRepository) FindById(id int) (*models.ChatExport, error) {
+	export := models.ChatExport{}
+	err := r.DB.Get(&export, `SELECT * FROM chat_exports WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *ExportRepository) FindByChatId(chatId int, limit int) ([]models.ChatExport, error) {
+	exports := []models.ChatExport{}
+	query := `
+		SELECT * FROM chat_exports
+		WHERE chatId = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+	err := r.DB.Select(&exports, query, chatId, limit)
+	return exports, err
+}
+
+func (r *ExportRepository) ClaimNext(at time.Time) (*models.ChatExport, error) {
+	export := models.ChatExport{}
+	query := `
+		UPDATE chat_exports
+		SET status = $1, startedAt = $2
+		WHERE id = (SELECT id FROM chat_exports WHERE status = $3 ORDER BY id LIMIT 1)
+		RETURNING *
+	`
+	err := r.DB.Get(&export, query, models.ExportStatusRunning, at, models.ExportStatusPending)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *ExportRepository) Complete(export *models.ChatExport) error {
+	query := `
+		UPDATE chat_exports
+		SET status = $1, fileName = $2, fileSize = $3, messageCount = $4, completedAt = $5, expiresAt = $6
+		WHERE id = $7
+	`
+	_, err := r.DB.Exec(query, models.ExportStatusCompleted, export.FileName, export.FileSize, export.MessageCount,
+		export.CompletedAt, export.ExpiresAt, export.ID)
+	return err
+}
+
+func (r *ExportRepository) Fail(id int, at time.Time) error {
+	query := `UPDATE chat_exports SET status = $1, completedAt = $2 WHERE id = $3`
+	_, err := r.DB.Exec(query, models.ExportStatusFailed, at, id)
+	return err
+}
+
+func (r *ExportRepository) ResetRunning() error {
+	query := `UPDATE chat_exports SET status = $1, startedAt = NULL WHERE status = $2`
+	_, err := r.DB.Exec(query, models.ExportStatusPending, models.ExportStatusRunning)
+	return err
+}
+
+func (r *ExportRepository) FindExpired(now time.Time, limit int) ([]models.ChatExport, error) {
+	exports := []models.ChatExport{}
+	query := `
+		SELECT * FROM chat_exports
+		WHERE status = $1 AND expiresAt <= $2
+		ORDER BY expiresAt
+		LIMIT $3
+	`
+	err := r.DB.Select(&exports, query, models.ExportStatusCompleted, now, limit)
+	return exports, err
+}
+
+func (r *ExportRepository) MarkExpired(id int) error {
+	query := `UPDATE chat_exports SET status = $1, fileName = NULL WHERE id = $2`
+	_, err := r.DB.Exec(query, models.ExportStatusExpired, id)
+	return err
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import "database/sql"
+
+// affected reports whether a statement changed at least one row
+func affected(result sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
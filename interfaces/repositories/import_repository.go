@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type ImportRepository struct {
+	DB *sqlx.DB
+}
+
+func NewImportRepository(db *sqlx.DB) repositories.ImportRepository {
+	return &ImportRepository{DB: db}
+}
+
+func (r *ImportRepository) Create(chatImport *models.ChatImport) error {
+	query := `
+		INSERT INTO chat_imports (requestedBy, source, archivePath, status, createdAt, startedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(query, chatImport.RequestedBy, chatImport.Source, chatImport.ArchivePath, chatImport.Status,
+		chatImport.CreatedAt, chatImport.StartedAt)
+	return row.Scan(&chatImport.ID)
+}
+
+func (r *ImportRepository) FindById(id int) (*models.ChatImport, error) {
+	chatImport := models.ChatImport{}
+	err := r.DB.Get(&chatImport, `SELECT * FROM chat_imports WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &chatImport, nil
+}
+
+func (r *ImportRepository) FindUnfinishedByArchive(archivePath string) (*models.ChatImport, error) {
+	chatImport := models.ChatImport{}
+	query := `
+		SELECT * FROM chat_imports
+		WHERE archivePath = $1 AND status != $2
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	err := r.DB.Get(&chatImport, query, archivePath, models.ImportStatusCompleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chatImport, nil
+}
+
+func (r *ImportRepository) ClaimNext(at time.Time) (*models.ChatImport, error) {
+	chatImport := models.ChatImport{}
+	query := `
+		UPDATE chat_imports
+		SET status = $1, startedAt = $2, error = NULL
+		WHERE id = (SELECT id FROM chat_imports WHERE status = $3 ORDER BY id LIMIT 1)
+		RETURNING *
+	`
+	err := r.DB.Get(&chatImport, query, models.ImportStatusRunning, at, models.ImportStatusPending)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chatImport, nil
+}
+
+func (r *ImportRepository) Claim(id int, at time.Time) (bool, error) {
+	query := `
+		UPDATE chat_imports
+		SET status = $1, startedAt = $2, error = NULL
+		WHERE id = $3 AND status IN ($4, $5)
+	`
+	return affected(r.DB.Exec(query, models.ImportStatusRunning, at, id,
+		models.ImportStatusPending, models.ImportStatusFailed))
+}
+
+func (r *ImportRepository) Requeue(id int) (bool, error) {
+	query := `UPDATE chat_imports SET status = $1, completedAt = NULL WHERE id = $2 AND status = $3`
+	return affected(r.DB.Exec(query, models.ImportStatusPending, id, models.ImportStatusFailed))
+}
+
+func (r *ImportRepository) UpdateCounts(chatImport *models.ChatImport) error {
+	query := `UPDATE chat_imports SET userCount = $1, chatCount = $2, messageCount = $3 WHERE id = $4`
+	_, err := r.DB.Exec(query, chatImport.UserCount, chatImport.ChatCount, chatImport.MessageCount, chatImport.ID)
+	return err
+}
+
+func (r *ImportRepository) Complete(chatImport *models.ChatImport) error {
+	query := `
+		UPDATE chat_imports
+		SET status = $1, userCount = $2, chatCount = $3, messageCount = $4, completedAt = $5
+		WHERE id = $6
+	`
+	_, err := r.DB.Exec(query, models.ImportStatusCompleted, chatImport.UserCount, chatImport.ChatCount,
+		chatImport.MessageCount, chatImport.CompletedAt, chatImport.ID)
+	return err
+}
+
+func (r *ImportRepository) Fail(id int, at time.Time, message string) error {
+	query := `UPDATE chat_imports SET status = $1, completedAt = $2, error = $3 WHERE id = $4`
+	_, err := r.DB.Exec(query, models.ImportStatusFailed, at, message, id)
+	return err
+}
+
+func (r *ImportRepository) ResetRunning() error {
+	query := `UPDATE chat_imports SET status = $1, startedAt = NULL WHERE status = $2`
+	_, err := r.DB.Exec(query, models.ImportStatusPending, models.ImportStatusRunning)
+	return err
+}
+
+func (r *ImportRepository) FindMapping(kind, sourceId string) (int, error) {
+	var targetId int
+	query := `SELECT targetId FROM import_mappings WHERE kind = $1 AND sourceId = $2`
+	err := r.DB.Get(&targetId, query, kind, sourceId)
+	return targetId, err
+}
+
+func (r *ImportRepository) SaveMapping(kind, sourceId string, targetId, importId int) error {
+	query := `
+		INSERT INTO import_mappings (kind, sourceId, targetId, importId, createdAt)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.DB.Exec(query, kind, sourceId, targetId, importId, time.Now().UTC())
+	return err
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type LinkPreviewRepository struct {
+	DB *sqlx.DB
+
+	cipher *ContentCipher
+}
+
+// linkPreviewRow is a row of link_previews. The previews of encrypted chats
+// are cached per chat: url holds the lookup key of the URL, which is sealed
+// in sealedUrl, and chatId is set.
+type linkPreviewRow struct {
+	models.LinkPreview
+	ChatId    sql.NullInt64 `db:"chatId"`
+	SealedURL string        `db:"sealedUrl"`
+}
+
+func NewLinkPreviewRepository(db *sqlx.DB, cipher *ContentCipher) repositories.LinkPreviewRepository {
+	return &LinkPreviewRepository{DB: db, cipher: cipher}
+}
+
+func (r *LinkPreviewRepository) FindByURL(chatId int, url string) (*models.LinkPreview, error) {
+	key, err := r.cipher.lookupKey(chatId, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var row linkPreviewRow
+	query := `SELECT * FROM link_previews WHERE url = $1`
+	if err := r.DB.Get(&row, query, key); err != nil {
+		return nil, err
+	}
+
+	preview, err := r.cipher.openLinkPreview(&row)
+	if err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+func (r *LinkPreviewRepository) Save(chatId int, preview *models.LinkPreview) error {
+	row, err := r.cipher.sealLinkPreview(chatId, preview)
+	if err != nil {
+		return err
+	}
+	return saveLinkPreview(r.DB, row)
+}
+
+func saveLinkPreview(db sqlx.Execer, row *linkPreviewRow) error {
+	query := `
+		INSERT INTO link_previews (url, title, description, imageUrl, siteName, failed, fetchedAt, chatId, sealedUrl)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT(url) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			imageUrl = excluded.imageUrl,
+			siteName = excluded.siteName,
+			failed = excluded.failed,
+			fetchedAt = excluded.fetchedAt,
+			chatId = excluded.chatId,
+			sealedUrl = excluded.sealedUrl
+	`
+	_, err := db.Exec(
+		query,
+		row.URL,
+		row.Title,
+		row.Description,
+		row.ImageURL,
+		row.SiteName,
+		row.Failed,
+		row.FetchedAt,
+		row.ChatId,
+		row.SealedURL,
+	)
+	return err
+}
+
+// SetMessageLinks replaces the links attached to a message
+func (r *LinkPreviewRepository) SetMessageLinks(chatId, messageId int, urls []string) error {
+	// Keyed before the transaction, which may create the chat's data key
+	keys := make([]string, len(urls))
+	for i, url := range urls {
+		key, err := r.cipher.lookupKey(chatId, url)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM message_link_previews WHERE messageId = $1`, messageId); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO message_link_previews (messageId, url, position) VALUES ($1, $2, $3)`
+	for i, key := range keys {
+		if _, err := tx.Exec(query, messageId, key, i); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByMessageIds returns the successfully fetched previews of each message
+func (r *LinkPreviewRepository) FindByMessageIds(messageIds []int) (map[int][]models.LinkPreview, error) {
+	result := make(map[int][]models.LinkPreview)
+	if len(messageIds) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(messageIds))
+	args := make([]interface{}, len(messageIds))
+	for i := range messageIds {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = messageIds[i]
+	}
+
+	query := fmt.Sprintf(`
+		SELECT mlp.messageId, mlp.position, lp.*
+		FROM message_link_previews mlp
+		JOIN link_previews lp ON mlp.url = lp.url
+		WHERE mlp.messageId IN (%s) AND lp.failed = 0
+		ORDER BY mlp.messageId, mlp.position
+	`, strings.Join(placeholders, ","))
+
+	var rows []struct {
+		MessageId int `db:"messageId"`
+		Position  int `db:"position"`
+		linkPreviewRow
+	}
+	if err := r.DB.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get link previews: %v", err)
+	}
+
+	for i := range rows {
+		preview, err := r.cipher.openLinkPreview(&rows[i].linkPreviewRow)
+		if err != nil {
+			return nil, err
+		}
+		result[rows[i].MessageId] = append(result[rows[i].MessageId], preview)
+	}
+
+	return result, nil
+}
@@ -1,6 +1,14 @@
 package repositories
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/domain/repositories"
 	"github.com/jmoiron/sqlx"
@@ -8,60 +16,184 @@ import (
 
 type MessageRepository struct {
 	DB *sqlx.DB
+
+	// ftsEnabled is true when the messages_fts index is kept in sync
+	ftsEnabled bool
+	// cipher encrypts content at rest; the index is left empty when it is enabled
+	cipher *ContentCipher
 }
 
-func NewMessageRepository(db *sqlx.DB) repositories.MessageRepository {
-	return &MessageRepository{DB: db}
+func NewMessageRepository(db *sqlx.DB, cipher *ContentCipher) repositories.MessageRepository {
+	var triggers int
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'messages_fts_%'`
+	if err := db.Get(&triggers, query); err != nil {
+		triggers = 0
+	}
+	return &MessageRepository{DB: db, ftsEnabled: triggers > 0, cipher: cipher}
 }
 
+// Create stores a message. Sealed content is bound to the message ID, so
+// with encryption at rest the content is written once the row exists.
 func (r *MessageRepository) Create(message *models.Message) error {
+	if err := r.cipher.prepareChat(message.ChatId); err != nil {
+		return err
+	}
+
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var columns sealedColumns
+	if !r.cipher.Enabled() {
+		columns = plainColumns(message)
+	}
+
 	query := `
-		INSERT INTO messages (chatId, senderId, content, createdAt, updatedAt)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO messages (
+			chatId, senderId, type, systemEvent, content, formatted, plainText, createdAt, updatedAt, expiresAt,
+			scheduledMessageId, clientMessageId,
+			forwardedFromMessageId, forwardedFromChatId, forwardedFromSenderId, forwardedFromNickname,
+			webhookId, botName, attachments, threadRootId, encrypted
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 		RETURNING id
 	`
-	row := r.DB.QueryRow(
+	row := tx.QueryRow(
 		query,
 		message.ChatId,
 		message.SenderId,
-		message.Content,
+		message.Type,
+		message.SystemEvent,
+		columns.Content,
+		columns.Formatted,
+		columns.PlainText,
 		message.CreatedAt,
 		message.UpdatedAt,
+		message.ExpiresAt,
+		message.ScheduledMessageId,
+		message.ClientMessageId,
+		message.ForwardedFromMessageId,
+		message.ForwardedFromChatId,
+		message.ForwardedFromSenderId,
+		message.ForwardedFromNickname,
+		message.WebhookId,
+		message.BotName,
+		columns.Attachments,
+		message.ThreadRootId,
+		message.Encrypted,
 	)
-	err := row.Scan(&message.ID)
-	if err != nil {
+	if err := row.Scan(&message.ID); err != nil {
+		return err
+	}
+
+	if r.cipher.Enabled() {
+		sealed, err := r.cipher.sealMessage(message)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE messages SET content = $1 WHERE id = $2`, sealed.Content, message.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
+	// Messages posted by a bot are shown under its name
+	if message.BotName != nil {
+		message.SenderNickname = *message.BotName
+		return nil
+	}
+
 	// Fetch sender nickname
 	query = `SELECT nickname FROM users WHERE id = $1`
-	err = r.DB.Get(&message.SenderNickname, query, message.SenderId)
-	return err
+	return r.DB.Get(&message.SenderNickname, query, message.SenderId)
 }
 
 func (r *MessageRepository) FindById(id int) (*models.Message, error) {
 	message := models.Message{}
 	query := `
-		SELECT m.*, u.nickname as senderNickname, m.id as id
+		SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id
+		FROM messages m
+		JOIN users u ON m.senderId = u.id
+		WHERE m.id = $1 AND (m.expiresAt IS NULL OR m.expiresAt > $2)
+	`
+	err := r.DB.Get(&message, query, id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &message, r.cipher.openMessage(&message)
+}
+
+// FindByIds returns the unexpired messages with the given IDs
+func (r *MessageRepository) FindByIds(ids []int) ([]models.Message, error) {
+	messages := []models.Message{}
+	if len(ids) == 0 {
+		return messages, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id
+		FROM messages m
+		JOIN users u ON m.senderId = u.id
+		WHERE m.id IN (?) AND (m.expiresAt IS NULL OR m.expiresAt > ?)
+	`, ids, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.DB.Select(&messages, query, args...); err != nil {
+		return nil, err
+	}
+	return messages, r.cipher.openMessages(messages)
+}
+
+func (r *MessageRepository) FindByScheduledMessageId(scheduledMessageId int) (*models.Message, error) {
+	message := models.Message{}
+	query := `
+		SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id
+		FROM messages m
+		JOIN users u ON m.senderId = u.id
+		WHERE m.scheduledMessageId = $1
+	`
+	err := r.DB.Get(&message, query, scheduledMessageId)
+	if err != nil {
+		return nil, err
+	}
+	return &message, r.cipher.openMessage(&message)
+}
+
+func (r *MessageRepository) FindByClientMessageId(senderId int, clientMessageId string) (*models.Message, error) {
+	message := models.Message{}
+	query := `
+		SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id
 		FROM messages m
 		JOIN users u ON m.senderId = u.id
-		WHERE m.id = $1
+		WHERE m.senderId = $1 AND m.clientMessageId = $2
 	`
-	err := r.DB.Get(&message, query, id)
+	err := r.DB.Get(&message, query, senderId, clientMessageId)
 	if err != nil {
 		return nil, err
 	}
-	return &message, nil
+	return &message, r.cipher.openMessage(&message)
 }
 
 func (r *MessageRepository) Update(message *models.Message) error {
+	sealed, err := r.cipher.sealMessage(message)
+	if err != nil {
+		return err
+	}
+
 	// Update message
 	query := `
 		UPDATE messages 
-		SET content = $1, updatedAt = $2
-		WHERE id = $3
+		SET content = $1, formatted = $2, plainText = $3, attachments = $4, updatedAt = $5
+		WHERE id = $6
 	`
-	_, err := r.DB.Exec(query, message.Content, message.UpdatedAt, message.ID)
+	_, err = r.DB.Exec(query, sealed.Content, sealed.Formatted, sealed.PlainText, sealed.Attachments, message.UpdatedAt, message.ID)
 	if err != nil {
 		return err
 	}
@@ -85,33 +217,436 @@ func (r *MessageRepository) FindByChatId(chatId int, cursor int, limit int) ([]m
 	if cursor == 0 {
 		// First page: get the most recent messages
 		query = `
-			SELECT m.*, u.nickname as senderNickname, m.id as id 
+			SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id 
 			FROM messages m
 			JOIN users u ON m.senderId = u.id
-			WHERE m.chatId = $1
+			WHERE m.chatId = $1 AND (m.expiresAt IS NULL OR m.expiresAt > $2)
 			ORDER BY m.id DESC
-			LIMIT $2
+			LIMIT $3
 		`
-		err = r.DB.Select(&messages, query, chatId, limit)
+		err = r.DB.Select(&messages, query, chatId, time.Now().UTC(), limit)
 	} else {
 		// Subsequent pages: get messages before the cursor
 		query = `
-			SELECT m.*, u.nickname as senderNickname, m.id as id 
+			SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id 
 			FROM messages m
 			JOIN users u ON m.senderId = u.id
-			WHERE m.chatId = $1 AND m.id < $2
+			WHERE m.chatId = $1 AND m.id < $2 AND (m.expiresAt IS NULL OR m.expiresAt > $3)
 			ORDER BY m.id DESC
-			LIMIT $3
+			LIMIT $4
 		`
-		err = r.DB.Select(&messages, query, chatId, cursor, limit)
+		err = r.DB.Select(&messages, query, chatId, cursor, time.Now().UTC(), limit)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return messages, err
+	return messages, r.cipher.openMessages(messages)
+}
+
+// FindAfterId returns messages newer than the cursor, oldest first
+func (r *MessageRepository) FindAfterId(chatId int, cursor int, limit int) ([]models.Message, error) {
+	messages := []models.Message{}
+	query := `
+		SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id
+		FROM messages m
+		JOIN users u ON m.senderId = u.id
+		WHERE m.chatId = $1 AND m.id > $2 AND (m.expiresAt IS NULL OR m.expiresAt > $3)
+		ORDER BY m.id ASC
+		LIMIT $4
+	`
+	if err := r.DB.Select(&messages, query, chatId, cursor, time.Now().UTC(), limit); err != nil {
+		return nil, err
+	}
+	return messages, r.cipher.openMessages(messages)
+}
+
+func (r *MessageRepository) StreamByChatId(chatId int, fn func(*models.Message) error) error {
+	query := `
+		SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, m.id as id
+		FROM messages m
+		JOIN users u ON m.senderId = u.id
+		WHERE m.chatId = $1 AND (m.expiresAt IS NULL OR m.expiresAt > $2)
+		ORDER BY m.id ASC
+	`
+	rows, err := r.DB.Queryx(query, chatId, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message models.Message
+		if err := rows.StructScan(&message); err != nil {
+			return err
+		}
+		if err := r.cipher.openMessage(&message); err != nil {
+			return err
+		}
+		if err := fn(&message); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// FindFirstIdAt returns the ID of the first message sent at or after the given time, or 0
+func (r *MessageRepository) FindFirstIdAt(chatId int, at time.Time) (int, error) {
+	var id int
+	// createdAt is stored in local time, so compare in the same zone
+	query := `
+		SELECT COALESCE(MIN(id), 0) FROM messages
+		WHERE chatId = $1 AND createdAt >= $2 AND (expiresAt IS NULL OR expiresAt > $3)
+	`
+	err := r.DB.Get(&id, query, chatId, at.In(time.Local), time.Now().UTC())
+	return id, err
 }
 
 func (r *MessageRepository) GetLastMessageId(chatId int) (int, error) {
 	var lastId int
-	query := `SELECT COALESCE(MAX(id), 0) FROM messages WHERE chatId = $1`
-	err := r.DB.Get(&lastId, query, chatId)
+	query := `
+		SELECT COALESCE(MAX(id), 0) FROM messages
+		WHERE chatId = $1 AND (expiresAt IS NULL OR expiresAt > $2)
+	`
+	err := r.DB.Get(&lastId, query, chatId, time.Now().UTC())
 	return lastId, err
 }
+
+// FindExpired returns messages whose lifetime has ended, oldest expiry first
+func (r *MessageRepository) FindExpired(now time.Time, limit int) ([]models.Message, error) {
+	messages := []models.Message{}
+	query := `
+		SELECT id, chatId, senderId, createdAt, updatedAt, expiresAt
+		FROM messages
+		WHERE expiresAt IS NOT NULL AND expiresAt <= $1
+		ORDER BY expiresAt ASC
+		LIMIT $2
+	`
+	err := r.DB.Select(&messages, query, now, limit)
+	return messages, err
+}
+
+// messageDependents deletes the rows referring to the messages with the given
+// IDs. Foreign keys are not enforced, so their cascades do not run.
+var messageDependents = []string{
+	`DELETE FROM poll_votes WHERE pollId IN (SELECT id FROM polls WHERE messageId IN (?))`,
+	`DELETE FROM poll_options WHERE pollId IN (SELECT id FROM polls WHERE messageId IN (?))`,
+	`DELETE FROM polls WHERE messageId IN (?)`,
+	`DELETE FROM pinned_messages WHERE messageId IN (?)`,
+	`DELETE FROM message_receipts WHERE messageId IN (?)`,
+	`DELETE FROM bookmarks WHERE messageId IN (?)`,
+	`DELETE FROM message_reactions WHERE messageId IN (?)`,
+	`DELETE FROM message_link_previews WHERE messageId IN (?)`,
+	`DELETE FROM moderation_flags WHERE messageId IN (?)`,
+	`DELETE FROM webhook_deliveries WHERE messageId IN (?)`,
+	`DELETE FROM messages WHERE id IN (?)`,
+}
+
+// DeleteByIds deletes messages together with everything referring to them,
+// including the copies of their content in moderation flags and webhook
+// deliveries, as it removes messages whose content must not be kept.
+func (r *MessageRepository) DeleteByIds(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range messageDependents {
+		query, args, err := sqlx.In(statement, ids)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *MessageRepository) FindRetentionCutoffId(chatId int, keep int) (int, error) {
+	var id int
+	query := `SELECT id FROM messages WHERE chatId = $1 ORDER BY id DESC LIMIT 1 OFFSET $2`
+	err := r.DB.Get(&id, query, chatId, keep-1)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (r *MessageRepository) FindPurgeable(chatId int, before *time.Time, belowId int, limit int) ([]models.Message, error) {
+	messages := []models.Message{}
+
+	var conditions []string
+	args := []interface{}{chatId}
+	if before != nil {
+		// createdAt is stored in local time, so compare in the same zone
+		args = append(args, before.In(time.Local))
+		conditions = append(conditions, fmt.Sprintf("createdAt < $%d", len(args)))
+	}
+	if belowId > 0 {
+		args = append(args, belowId)
+		conditions = append(conditions, fmt.Sprintf("id < $%d", len(args)))
+	}
+	if len(conditions) == 0 {
+		return messages, nil
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, chatId, senderId, createdAt, updatedAt
+		FROM messages
+		WHERE chatId = $1 AND (%s)
+		ORDER BY id ASC
+		LIMIT $%d
+	`, strings.Join(conditions, " OR "), len(args))
+	err := r.DB.Select(&messages, query, args...)
+	return messages, err
+}
+
+// Search finds messages in the user's chats whose plain text matches every term of the query.
+// The trigram index only matches terms of three or more characters, so shorter
+// terms fall back to a LIKE scan. Messages encrypted at rest are searched
+// without the index; see searchSealed for the cursor returned.
+func (r *MessageRepository) Search(filter models.MessageSearchFilter) ([]models.MessageSearchResult, int, error) {
+	results := []models.MessageSearchResult{}
+	terms := strings.Fields(filter.Query)
+	if len(terms) == 0 {
+		return results, 0, nil
+	}
+	if r.cipher.Enabled() {
+		return r.searchSealed(filter, terms)
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var source, snippet string
+	var conditions []string
+	useFTS := r.ftsEnabled && indexableTerms(terms)
+	if useFTS {
+		source = "messages_fts JOIN messages m ON m.id = messages_fts.rowid"
+		snippet = fmt.Sprintf("snippet(messages_fts, 0, %s, %s, '…', 16)",
+			arg(models.SnippetMatchStart), arg(models.SnippetMatchEnd))
+		conditions = append(conditions, "messages_fts MATCH "+arg(ftsQuery(terms)))
+	} else {
+		source = "messages m"
+		snippet = "''"
+		for _, term := range terms {
+			conditions = append(conditions, "m.plainText LIKE "+arg("%"+escapeLike(term)+"%")+` ESCAPE '\'`)
+		}
+	}
+
+	conditions = append(conditions, searchFilterConditions(filter, arg)...)
+
+	query := fmt.Sprintf(`
+		SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, c.name as chatName, %s as snippet
+		FROM %s
+		JOIN users u ON m.senderId = u.id
+		JOIN chats c ON m.chatId = c.id
+		WHERE %s
+		ORDER BY m.id DESC
+		LIMIT %s
+	`, snippet, source, strings.Join(conditions, " AND "), arg(filter.Limit))
+
+	if err := r.DB.Select(&results, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	if !useFTS {
+		for i := range results {
+			results[i].Snippet = buildSnippet(results[i].PlainText, terms)
+		}
+	}
+
+	return results, 0, nil
+}
+
+// maxSealedSearchScan is the number of messages a search of messages
+// encrypted at rest decrypts at most before returning a partial page
+const maxSealedSearchScan = 5000
+
+// searchSealed searches messages encrypted at rest, which are not in the
+// index. Candidates are decrypted newest first until the page is full or
+// maxSealedSearchScan messages were read; then the ID of the last message
+// read is returned to continue the search from.
+func (r *MessageRepository) searchSealed(filter models.MessageSearchFilter, terms []string) ([]models.MessageSearchResult, int, error) {
+	const batchSize = 500
+
+	results := []models.MessageSearchResult{}
+	for scanned := 0; len(results) < filter.Limit; {
+		if scanned >= maxSealedSearchScan {
+			return results, filter.Cursor, nil
+		}
+
+		var args []interface{}
+		arg := func(value interface{}) string {
+			args = append(args, value)
+			return fmt.Sprintf("$%d", len(args))
+		}
+
+		query := fmt.Sprintf(`
+			SELECT m.*, COALESCE(m.botName, u.nickname) as senderNickname, c.name as chatName, '' as snippet
+			FROM messages m
+			JOIN users u ON m.senderId = u.id
+			JOIN chats c ON m.chatId = c.id
+			WHERE %s
+			ORDER BY m.id DESC
+			LIMIT %s
+		`, strings.Join(searchFilterConditions(filter, arg), " AND "), arg(batchSize))
+
+		var batch []models.MessageSearchResult
+		if err := r.DB.Select(&batch, query, args...); err != nil {
+			return nil, 0, err
+		}
+
+		for i := range batch {
+			if err := r.cipher.openMessage(&batch[i].Message); err != nil {
+				return nil, 0, err
+			}
+			if !matchesTerms(batch[i].PlainText, terms) {
+				continue
+			}
+			batch[i].Snippet = buildSnippet(batch[i].PlainText, terms)
+			results = append(results, batch[i])
+			if len(results) == filter.Limit {
+				break
+			}
+		}
+
+		if len(batch) < batchSize {
+			break
+		}
+		scanned += len(batch)
+		filter.Cursor = batch[len(batch)-1].ID
+	}
+
+	return results, 0, nil
+}
+
+// searchFilterConditions returns the conditions of a search other than its terms
+func searchFilterConditions(filter models.MessageSearchFilter, arg func(interface{}) string) []string {
+	conditions := []string{
+		"m.chatId IN (SELECT chatId FROM chat_groups WHERE userId = " + arg(filter.UserId) + ")",
+		"m.type = " + arg(models.MessageTypeUser),
+		"(m.expiresAt IS NULL OR m.expiresAt > " + arg(time.Now().UTC()) + ")",
+	}
+	if filter.ChatId != 0 {
+		conditions = append(conditions, "m.chatId = "+arg(filter.ChatId))
+	}
+	if filter.SenderId != 0 {
+		conditions = append(conditions, "m.senderId = "+arg(filter.SenderId))
+	}
+	// createdAt is stored in local time and compared as text
+	if filter.From != nil {
+		conditions = append(conditions, "m.createdAt >= "+arg(filter.From.In(time.Local)))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "m.createdAt < "+arg(filter.To.In(time.Local)))
+	}
+	if filter.Cursor != 0 {
+		conditions = append(conditions, "m.id < "+arg(filter.Cursor))
+	}
+	return conditions
+}
+
+// matchesTerms reports whether text contains every term, ignoring case like LIKE
+func matchesTerms(text string, terms []string) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if !strings.Contains(text, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexableTerms reports whether every term is long enough for the trigram index
+func indexableTerms(terms []string) bool {
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// ftsQuery quotes each term as an FTS5 phrase so user input is never parsed as query syntax
+func ftsQuery(terms []string) string {
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(phrases, " ")
+}
+
+func escapeLike(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(term)
+}
+
+// buildSnippet mimics the FTS5 snippet function for LIKE matches
+func buildSnippet(content string, terms []string) string {
+	const contextBefore, contextAfter = 16, 48
+
+	runes := []rune(content)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// Mark every rune covered by a term
+	matched := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) != string(needle) {
+				continue
+			}
+			for j := i; j < i+len(needle); j++ {
+				matched[j] = true
+			}
+			if first == -1 || i < first {
+				first = i
+			}
+		}
+	}
+	if first == -1 {
+		first = 0
+	}
+
+	start := first - contextBefore
+	if start < 0 {
+		start = 0
+	}
+	end := first + contextAfter
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if matched[i] && (i == start || !matched[i-1]) {
+			sb.WriteString(models.SnippetMatchStart)
+		}
+		sb.WriteRune(runes[i])
+		if matched[i] && (i == end-1 || !matched[i+1]) {
+			sb.WriteString(models.SnippetMatchEnd)
+		}
+	}
+	if end < len(runes) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
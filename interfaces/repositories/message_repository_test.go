@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/infrastructure/sqlite"
+)
+
+// useLocalZone runs a test with the server in a zone ahead of UTC
+func useLocalZone(t *testing.T) {
+	t.Helper()
+	local := time.Local
+	time.Local = time.FixedZone("KST", 9*60*60)
+	t.Cleanup(func() { time.Local = local })
+}
+
+// newTestDB migrates a database in the test's temporary directory, with a
+// user belonging to one chat
+func newTestDB(t *testing.T) {
+	t.Helper()
+	if err := sqlite.InitDB(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sqlite.CloseDB)
+	if err := sqlite.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []string{
+		`INSERT INTO users (id, email, password, nickname) VALUES (1, 'u1@example.com', 'x', 'u1')`,
+		`INSERT INTO chats (id, name) VALUES (1, 'chat')`,
+		`INSERT INTO chat_groups (chatId, userId) VALUES (1, 1)`,
+	}
+	for _, statement := range statements {
+		if _, err := sqlite.DB.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSearchDateRangeWithUTCBounds(t *testing.T) {
+	useLocalZone(t)
+	newTestDB(t)
+
+	cipher, err := NewContentCipher(sqlite.DB, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewMessageRepository(sqlite.DB, cipher)
+
+	for _, message := range []models.Message{
+		{Content: "needle early", PlainText: "needle early", CreatedAt: time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)},
+		{Content: "needle late", PlainText: "needle late", CreatedAt: time.Date(2026, 10, 19, 11, 0, 0, 0, time.Local)},
+	} {
+		message.ChatId, message.SenderId, message.Type = 1, 1, models.MessageTypeUser
+		message.UpdatedAt = message.CreatedAt
+		if err := repo.Create(&message); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 10:30-12:00 in the server's zone
+	from := time.Date(2026, 10, 19, 1, 30, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	results, _, err := repo.Search(models.MessageSearchFilter{UserId: 1, Query: "needle", From: &from, To: &to, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Content != "needle late" {
+		var contents []string
+		for _, result := range results {
+			contents = append(contents, result.Content)
+		}
+		t.Fatalf("results = %q, want only the message sent at 11:00", contents)
+	}
+}
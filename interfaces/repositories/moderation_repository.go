@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type ModerationRepository struct {
+	DB *sqlx.DB
+
+	cipher *ContentCipher
+}
+
+func NewModerationRepository(db *sqlx.DB, cipher *ContentCipher) repositories.ModerationRepository {
+	return &ModerationRepository{DB: db, cipher: cipher}
+}
+
+func (r *ModerationRepository) CreateFlag(flag *models.ModerationFlag) error {
+	content, err := r.cipher.sealValue(flag.ChatId, flag.Content, flagAAD(flag))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO moderation_flags (messageId, chatId, senderId, content, reasons, status, createdAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(query, flag.MessageId, flag.ChatId, flag.SenderId, content, flag.Reasons, flag.Status, flag.CreatedAt)
+	return row.Scan(&flag.ID)
+}
+
+func (r *ModerationRepository) FindFlagById(id int) (*models.ModerationFlag, error) {
+	flag := models.ModerationFlag{}
+	query := `
+		SELECT f.*, COALESCE(u.nickname, '') AS senderNickname
+		FROM moderation_flags f
+		LEFT JOIN users u ON u.id = f.senderId
+		WHERE f.id = $1
+	`
+	err := r.DB.Get(&flag, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &flag, r.open(&flag)
+}
+
+func (r *ModerationRepository) FindFlags(reviewerId, chatId int, status string, cursor, limit int) ([]models.ModerationFlag, error) {
+	flags := []models.ModerationFlag{}
+	query := `
+		SELECT f.*, COALESCE(u.nickname, '') AS senderNickname
+		FROM moderation_flags f
+		JOIN chat_groups cg ON cg.chatId = f.chatId AND cg.userId = $1 AND cg.role IN ($2, $3)
+		LEFT JOIN users u ON u.id = f.senderId
+		WHERE ($4 = 0 OR f.chatId = $4) AND ($5 = '' OR f.status = $5) AND ($6 = 0 OR f.id < $6)
+		ORDER BY f.id DESC
+		LIMIT $7
+	`
+	if err := r.DB.Select(&flags, query, reviewerId, models.ChatRoleOwner, models.ChatRoleAdmin, chatId, status, cursor, limit); err != nil {
+		return nil, err
+	}
+
+	for i := range flags {
+		if err := r.open(&flags[i]); err != nil {
+			return nil, err
+		}
+	}
+	return flags, nil
+}
+
+func (r *ModerationRepository) ResolveFlag(id int, status string, reviewerId int, at time.Time) (bool, error) {
+	query := `
+		UPDATE moderation_flags
+		SET status = $1, reviewedBy = $2, reviewedAt = $3
+		WHERE id = $4 AND status = $5
+	`
+	result, err := r.DB.Exec(query, status, reviewerId, at, id, models.FlagStatusPending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *ModerationRepository) ResolveMessageFlags(messageId int, status string, reviewerId int, at time.Time) error {
+	query := `
+		UPDATE moderation_flags
+		SET status = $1, reviewedBy = $2, reviewedAt = $3
+		WHERE messageId = $4 AND status = $5
+	`
+	_, err := r.DB.Exec(query, status, reviewerId, at, messageId, models.FlagStatusPending)
+	return err
+}
+
+func (r *ModerationRepository) open(flag *models.ModerationFlag) error {
+	content, err := r.cipher.openValue(flag.ChatId, flag.Content, flagAAD(flag))
+	if err != nil {
+		return err
+	}
+	flag.Content = content
+	return nil
+}
+
+func flagAAD(flag *models.ModerationFlag) []byte {
+	return rowAAD("flag", flag.ChatId, flag.MessageId)
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutgoingWebhookRepository struct {
+	DB *sqlx.DB
+
+	// cipher seals delivery payloads, which contain message content
+	cipher *ContentCipher
+}
+
+func NewOutgoingWebhookRepository(db *sqlx.DB, cipher *ContentCipher) repositories.OutgoingWebhookRepository {
+	return &OutgoingWebhookRepository{DB: db, cipher: cipher}
+}
+
+func (r *OutgoingWebhookRepository) Create(webhook *models.OutgoingWebhook) error {
+	query := `
+		INSERT INTO outgoing_webhooks (ownerId, chatId, url, secret, events, createdAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(query, webhook.OwnerId, webhook.ChatId, webhook.URL, webhook.Secret, webhook.Events, webhook.CreatedAt)
+	return row.Scan(&webhook.ID)
+}
+
+func (r *OutgoingWebhookRepository) FindById(id int) (*models.OutgoingWebhook, error) {
+	webhook := models.OutgoingWebhook{}
+	query := `SELECT * FROM outgoing_webhooks WHERE id = $1`
+	err := r.DB.Get(&webhook, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *OutgoingWebhookRepository) FindByOwnerId(ownerId int) ([]models.OutgoingWebhook, error) {
+	webhooks := []models.OutgoingWebhook{}
+	query := `SELECT * FROM outgoing_webhooks WHERE ownerId = $1 ORDER BY id ASC`
+	err := r.DB.Select(&webhooks, query, ownerId)
+	return webhooks, err
+}
+
+func (r *OutgoingWebhookRepository) FindSubscribers(chatId int) ([]models.OutgoingWebhook, error) {
+	webhooks := []models.OutgoingWebhook{}
+	query := `
+		SELECT w.*
+		FROM outgoing_webhooks w
+		JOIN chat_groups cg ON cg.chatId = $1 AND cg.userId = w.ownerId
+		WHERE (w.chatId = $1 AND cg.role IN ($2, $3)) OR w.chatId IS NULL
+		ORDER BY w.id ASC
+	`
+	err := r.DB.Select(&webhooks, query, chatId, models.ChatRoleOwner, models.ChatRoleAdmin)
+	return webhooks, err
+}
+
+func (r *OutgoingWebhookRepository) Delete(id int) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM webhook_deliveries WHERE outgoingWebhookId = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM outgoing_webhooks WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *OutgoingWebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	// Sealed before the transaction, which may create the chat's data key
+	payloads := make([]string, len(deliveries))
+	for i := range deliveries {
+		payload, err := r.cipher.sealValue(deliveries[i].ChatId, deliveries[i].Payload, deliveryAAD(&deliveries[i]))
+		if err != nil {
+			return err
+		}
+		payloads[i] = payload
+	}
+
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhook_deliveries (outgoingWebhookId, eventType, chatId, messageId, payload, status, attempts, nextAttemptAt, createdAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		row := tx.QueryRow(query, delivery.OutgoingWebhookId, delivery.EventType, delivery.ChatId, delivery.MessageId,
+			payloads[i], delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.CreatedAt)
+		if err := row.Scan(&delivery.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *OutgoingWebhookRepository) FindDeliveryById(id int) (*models.WebhookDelivery, error) {
+	delivery := models.WebhookDelivery{}
+	query := `SELECT * FROM webhook_deliveries WHERE id = $1`
+	err := r.DB.Get(&delivery, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, r.open(&delivery)
+}
+
+func (r *OutgoingWebhookRepository) FindDeliveries(webhookId int, status string, cursor, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE outgoingWebhookId = $1 AND ($2 = '' OR status = $2) AND ($3 = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4
+	`
+	if err := r.DB.Select(&deliveries, query, webhookId, status, cursor, limit); err != nil {
+		return nil, err
+	}
+	return deliveries, r.openAll(deliveries)
+}
+
+func (r *OutgoingWebhookRepository) FindDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE status = $1 AND nextAttemptAt <= $2
+		ORDER BY nextAttemptAt ASC, id ASC
+		LIMIT $3
+	`
+	if err := r.DB.Select(&deliveries, query, models.DeliveryStatusPending, now, limit); err != nil {
+		return nil, err
+	}
+	return deliveries, r.openAll(deliveries)
+}
+
+func (r *OutgoingWebhookRepository) RecordAttempt(delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, nextAttemptAt = $3, lastAttemptAt = $4,
+			responseStatus = $5, lastError = $6, deliveredAt = $7
+		WHERE id = $8
+	`
+	_, err := r.DB.Exec(query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastAttemptAt,
+		delivery.ResponseStatus, delivery.LastError, delivery.DeliveredAt, delivery.ID)
+	return err
+}
+
+func (r *OutgoingWebhookRepository) Requeue(id int, at time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = 0, nextAttemptAt = $2, responseStatus = NULL, lastError = NULL, deliveredAt = NULL
+		WHERE id = $3
+	`
+	_, err := r.DB.Exec(query, models.DeliveryStatusPending, at, id)
+	return err
+}
+
+func (r *OutgoingWebhookRepository) open(delivery *models.WebhookDelivery) error {
+	payload, err := r.cipher.openValue(delivery.ChatId, delivery.Payload, deliveryAAD(delivery))
+	if err != nil {
+		return err
+	}
+	delivery.Payload = payload
+	return nil
+}
+
+func (r *OutgoingWebhookRepository) openAll(deliveries []models.WebhookDelivery) error {
+	for i := range deliveries {
+		if err := r.open(&deliveries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deliveryAAD(delivery *models.WebhookDelivery) []byte {
+	return rowAAD("delivery", delivery.ChatId, delivery.OutgoingWebhookId)
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type PinRepository struct {
+	DB *sqlx.DB
+
+	// cipher decrypts the content of pinned messages
+	cipher *ContentCipher
+}
+
+func NewPinRepository(db *sqlx.DB, cipher *ContentCipher) repositories.PinRepository {
+	return &PinRepository{DB: db, cipher: cipher}
+}
+
+func (r *PinRepository) Create(pin *models.PinnedMessage) error {
+	query := `
+		INSERT INTO pinned_messages (chatId, messageId, pinnedBy, pinnedAt)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.DB.Exec(query, pin.ChatId, pin.MessageId, pin.PinnedBy, pin.PinnedAt)
+	if err != nil {
+		return err
+	}
+
+	// Fetch pinner nickname
+	query = `SELECT nickname FROM users WHERE id = $1`
+	return r.DB.Get(&pin.PinnedByNickname, query, pin.PinnedBy)
+}
+
+func (r *PinRepository) Delete(chatId, messageId int) error {
+	query := `DELETE FROM pinned_messages WHERE chatId = $1 AND messageId = $2`
+	_, err := r.DB.Exec(query, chatId, messageId)
+	return err
+}
+
+func (r *PinRepository) Exists(chatId, messageId int) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM pinned_messages WHERE chatId = $1 AND messageId = $2`
+	err := r.DB.Get(&count, query, chatId, messageId)
+	return count > 0, err
+}
+
+func (r *PinRepository) CountByChatId(chatId int) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM pinned_messages p
+		JOIN messages m ON p.messageId = m.id
+		WHERE p.chatId = $1 AND (m.expiresAt IS NULL OR m.expiresAt > $2)
+	`
+	err := r.DB.Get(&count, query, chatId, time.Now().UTC())
+	return count, err
+}
+
+// FindByChatId returns the pins of a chat, most recently pinned first
+func (r *PinRepository) FindByChatId(chatId int) ([]models.PinnedMessage, error) {
+	pins := []models.PinnedMessage{}
+	query := `
+		SELECT p.chatId, p.messageId, p.pinnedBy, p.pinnedAt,
+			pu.nickname as pinnedByNickname,
+			m.senderId, COALESCE(m.botName, su.nickname) as senderNickname, m.content, m.formatted,
+			m.createdAt as messageCreatedAt, m.updatedAt as messageUpdatedAt
+		FROM pinned_messages p
+		JOIN messages m ON p.messageId = m.id
+		JOIN users su ON m.senderId = su.id
+		JOIN users pu ON p.pinnedBy = pu.id
+		WHERE p.chatId = $1 AND (m.expiresAt IS NULL OR m.expiresAt > $2)
+		ORDER BY p.pinnedAt DESC
+	`
+	if err := r.DB.Select(&pins, query, chatId, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	for i := range pins {
+		if err := r.cipher.openPin(&pins[i]); err != nil {
+			return nil, err
+		}
+	}
+	return pins, nil
+}
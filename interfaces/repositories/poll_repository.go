@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type PollRepository struct {
+	DB *sqlx.DB
+
+	cipher *ContentCipher
+}
+
+func NewPollRepository(db *sqlx.DB, cipher *ContentCipher) repositories.PollRepository {
+	return &PollRepository{DB: db, cipher: cipher}
+}
+
+func (r *PollRepository) Create(poll *models.Poll) error {
+	// Sealed before the transaction, which may create the chat's data key.
+	// The options are sealed once the poll ID is known, with the key cached.
+	question, err := r.cipher.sealValue(poll.ChatId, poll.Question, pollAAD(poll))
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO polls (messageId, chatId, creatorId, question, multipleChoice, anonymous, closesAt, createdAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	result, err := tx.Exec(query, poll.MessageId, poll.ChatId, poll.CreatorId, question,
+		poll.MultipleChoice, poll.Anonymous, poll.ClosesAt, poll.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	poll.ID = int(id)
+
+	query = `INSERT INTO poll_options (pollId, position, text) VALUES ($1, $2, $3)`
+	for i := range poll.Options {
+		option := &poll.Options[i]
+		option.PollId = poll.ID
+		option.Position = i
+		text, err := r.cipher.sealValue(poll.ChatId, option.Text, pollOptionAAD(poll, option))
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec(query, option.PollId, option.Position, text)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		option.ID = int(id)
+	}
+
+	return tx.Commit()
+}
+
+func (r *PollRepository) FindById(id int) (*models.Poll, error) {
+	var poll models.Poll
+	if err := r.DB.Get(&poll, `SELECT * FROM polls WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	polls := []models.Poll{poll}
+	if err := r.loadOptions(polls); err != nil {
+		return nil, err
+	}
+	return &polls[0], nil
+}
+
+func (r *PollRepository) FindByMessageIds(messageIds []int) ([]models.Poll, error) {
+	polls := []models.Poll{}
+	if len(messageIds) == 0 {
+		return polls, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT * FROM polls WHERE messageId IN (?)`, messageIds)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.DB.Select(&polls, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadOptions(polls); err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+// loadOptions sets the options of each poll in position order
+func (r *PollRepository) loadOptions(polls []models.Poll) error {
+	if len(polls) == 0 {
+		return nil
+	}
+
+	pollIds := make([]int, len(polls))
+	byPoll := make(map[int]int, len(polls))
+	for i, poll := range polls {
+		pollIds[i] = poll.ID
+		byPoll[poll.ID] = i
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT * FROM poll_options WHERE pollId IN (?) ORDER BY pollId, position
+	`, pollIds)
+	if err != nil {
+		return err
+	}
+
+	var options []models.PollOption
+	if err := r.DB.Select(&options, query, args...); err != nil {
+		return err
+	}
+	for _, option := range options {
+		i := byPoll[option.PollId]
+		polls[i].Options = append(polls[i].Options, option)
+	}
+
+	for i := range polls {
+		if err := r.open(&polls[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PollRepository) open(poll *models.Poll) error {
+	question, err := r.cipher.openValue(poll.ChatId, poll.Question, pollAAD(poll))
+	if err != nil {
+		return err
+	}
+	poll.Question = question
+
+	for i := range poll.Options {
+		option := &poll.Options[i]
+		text, err := r.cipher.openValue(poll.ChatId, option.Text, pollOptionAAD(poll, option))
+		if err != nil {
+			return err
+		}
+		option.Text = text
+	}
+	return nil
+}
+
+func (r *PollRepository) FindVotes(pollIds []int) ([]models.PollVote, error) {
+	votes := []models.PollVote{}
+	if len(pollIds) == 0 {
+		return votes, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT pv.pollId, pv.optionId, pv.userId, u.nickname
+		FROM poll_votes pv
+		JOIN users u ON pv.userId = u.id
+		WHERE pv.pollId IN (?)
+		ORDER BY pv.createdAt, pv.userId
+	`, pollIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB.Select(&votes, query, args...)
+	return votes, err
+}
+
+func (r *PollRepository) ReplaceVotes(pollId, userId int, optionIds []int, at time.Time) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM poll_votes WHERE pollId = $1 AND userId = $2`, pollId, userId); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO poll_votes (pollId, optionId, userId, createdAt) VALUES ($1, $2, $3, $4)`
+	for _, optionId := range optionIds {
+		if _, err := tx.Exec(query, pollId, optionId, userId, at); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PollRepository) Close(id int, at time.Time) (bool, error) {
+	query := `UPDATE polls SET closedAt = $1 WHERE id = $2 AND closedAt IS NULL`
+	return affected(r.DB.Exec(query, at, id))
+}
+
+func pollAAD(poll *models.Poll) []byte {
+	return rowAAD("poll", poll.ChatId, poll.MessageId)
+}
+
+func pollOptionAAD(poll *models.Poll, option *models.PollOption) []byte {
+	return rowAAD("poll_option", poll.ChatId, option.PollId, option.Position)
+}
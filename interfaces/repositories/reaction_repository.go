@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type ReactionRepository struct {
+	DB *sqlx.DB
+}
+
+func NewReactionRepository(db *sqlx.DB) repositories.ReactionRepository {
+	return &ReactionRepository{DB: db}
+}
+
+func (r *ReactionRepository) Add(messageId, userId int, emoji string, at time.Time) error {
+	query := `
+		INSERT INTO message_reactions (messageId, userId, emoji, createdAt)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (messageId, userId, emoji) DO NOTHING
+	`
+	_, err := r.DB.Exec(query, messageId, userId, emoji, at)
+	return err
+}
+
+func (r *ReactionRepository) Summarize(messageIds []int, viewerId int) ([]models.Reaction, error) {
+	reactions := []models.Reaction{}
+	if len(messageIds) == 0 {
+		return reactions, nil
+	}
+
+	// Emojis are listed in the order they were first used on each message
+	query, args, err := sqlx.In(`
+		SELECT messageId, emoji, COUNT(*) as count, MAX(userId = ?) as reacted
+		FROM message_reactions
+		WHERE messageId IN (?)
+		GROUP BY messageId, emoji
+		ORDER BY messageId, MIN(createdAt), emoji
+	`, viewerId, messageIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB.Select(&reactions, query, args...)
+	return reactions, err
+}
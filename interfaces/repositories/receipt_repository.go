@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type ReceiptRepository struct {
+	DB *sqlx.DB
+}
+
+func NewReceiptRepository(db *sqlx.DB) repositories.ReceiptRepository {
+	return &ReceiptRepository{DB: db}
+}
+
+func (r *ReceiptRepository) CreateForMessage(messageId int, recipientIds []int) error {
+	if len(recipientIds) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]interface{}, len(recipientIds))
+	for i, userId := range recipientIds {
+		rows[i] = map[string]interface{}{
+			"messageId": messageId,
+			"userId":    userId,
+			"status":    models.DeliveryStatusSent,
+		}
+	}
+
+	query := `
+		INSERT OR IGNORE INTO message_receipts (messageId, userId, status)
+		VALUES (:messageId, :userId, :status)
+	`
+	_, err := r.DB.NamedExec(query, rows)
+	return err
+}
+
+func (r *ReceiptRepository) MarkDelivered(userId int, messageIds []int, at time.Time) ([]int, error) {
+	if len(messageIds) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query, args, err := sqlx.In(`
+		SELECT messageId FROM message_receipts
+		WHERE userId = ? AND status = ? AND messageId IN (?)
+	`, userId, models.DeliveryStatusSent, messageIds)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []int{}
+	if err := tx.Select(&changed, query, args...); err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+
+	query, args, err = sqlx.In(`
+		UPDATE message_receipts SET status = ?, deliveredAt = ?
+		WHERE userId = ? AND messageId IN (?)
+	`, models.DeliveryStatusDelivered, at, userId, changed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		return nil, err
+	}
+
+	return changed, tx.Commit()
+}
+
+func (r *ReceiptRepository) MarkReadUpTo(userId, chatId, messageId int, at time.Time) ([]int, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	changed := []int{}
+	query := `
+		SELECT mr.messageId
+		FROM message_receipts mr
+		JOIN messages m ON mr.messageId = m.id
+		WHERE mr.userId = $1 AND mr.status != $2 AND m.chatId = $3 AND m.id <= $4
+	`
+	if err := tx.Select(&changed, query, userId, models.DeliveryStatusRead, chatId, messageId); err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+
+	update, args, err := sqlx.In(`
+		UPDATE message_receipts SET status = ?, deliveredAt = COALESCE(deliveredAt, ?), readAt = ?
+		WHERE userId = ? AND messageId IN (?)
+	`, models.DeliveryStatusRead, at, at, userId, changed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(update, args...); err != nil {
+		return nil, err
+	}
+
+	return changed, tx.Commit()
+}
+
+func (r *ReceiptRepository) Summarize(messageIds []int) ([]models.DeliverySummary, error) {
+	summaries := []models.DeliverySummary{}
+	if len(messageIds) == 0 {
+		return summaries, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT m.id as messageId, m.chatId, m.senderId,
+			COUNT(mr.userId) as recipients,
+			COALESCE(SUM(mr.status IN ('delivered', 'read')), 0) as delivered,
+			COALESCE(SUM(mr.status = 'read'), 0) as read
+		FROM messages m
+		LEFT JOIN message_receipts mr ON mr.messageId = m.id
+		WHERE m.id IN (?)
+		GROUP BY m.id
+	`, messageIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB.Select(&summaries, query, args...)
+	return summaries, err
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type RetentionRepository struct {
+	DB *sqlx.DB
+}
+
+func NewRetentionRepository(db *sqlx.DB) repositories.RetentionRepository {
+	return &RetentionRepository{DB: db}
+}
+
+func (r *RetentionRepository) FindChats(afterId int, limit int) ([]models.ChatRetention, error) {
+	chats := []models.ChatRetention{}
+	query := `
+		SELECT c.id, c.retentionDays, c.retentionMaxMessages, h.chatId IS NOT NULL as legalHold
+		FROM chats c
+		LEFT JOIN legal_holds h ON h.chatId = c.id
+		WHERE c.id > $1
+		ORDER BY c.id
+		LIMIT $2
+	`
+	err := r.DB.Select(&chats, query, afterId, limit)
+	return chats, err
+}
+
+func (r *RetentionRepository) FindLegalHold(chatId int) (*models.LegalHold, error) {
+	hold := models.LegalHold{}
+	err := r.DB.Get(&hold, `SELECT * FROM legal_holds WHERE chatId = $1`, chatId)
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *RetentionRepository) FindLegalHolds() ([]models.LegalHold, error) {
+	holds := []models.LegalHold{}
+	err := r.DB.Select(&holds, `SELECT * FROM legal_holds ORDER BY createdAt`)
+	return holds, err
+}
+
+func (r *RetentionRepository) PlaceLegalHold(hold *models.LegalHold) error {
+	query := `
+		INSERT INTO legal_holds (chatId, reason, placedBy, createdAt)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chatId) DO UPDATE SET reason = excluded.reason
+	`
+	_, err := r.DB.Exec(query, hold.ChatId, hold.Reason, hold.PlacedBy, hold.CreatedAt)
+	return err
+}
+
+func (r *RetentionRepository) ReleaseLegalHold(chatId int) (bool, error) {
+	return affected(r.DB.Exec(`DELETE FROM legal_holds WHERE chatId = $1`, chatId))
+}
+
+func (r *RetentionRepository) CreateRun(run *models.RetentionRun) error {
+	query := `INSERT INTO retention_runs (status, startedAt) VALUES ($1, $2) RETURNING id`
+	row := r.DB.QueryRow(query, run.Status, run.StartedAt)
+	return row.Scan(&run.ID)
+}
+
+func (r *RetentionRepository) FinishRun(run *models.RetentionRun) error {
+	query := `
+		UPDATE retention_runs
+		SET status = $1, chatCount = $2, heldChatCount = $3, deletedCount = $4, error = $5, completedAt = $6
+		WHERE id = $7
+	`
+	_, err := r.DB.Exec(query, run.Status, run.ChatCount, run.HeldChatCount, run.DeletedCount, run.Error,
+		run.CompletedAt, run.ID)
+	return err
+}
+
+func (r *RetentionRepository) FailInterrupted(at time.Time) error {
+	// The counts are rebuilt from the chats purged before the interruption
+	query := `
+		UPDATE retention_runs
+		SET status = $1, error = $2, completedAt = $3,
+			chatCount = (SELECT COUNT(*) FROM retention_run_chats WHERE runId = retention_runs.id),
+			deletedCount = (SELECT COALESCE(SUM(deletedCount), 0) FROM retention_run_chats WHERE runId = retention_runs.id)
+		WHERE status = $4
+	`
+	_, err := r.DB.Exec(query, models.RetentionRunFailed, "interrupted by a restart", at, models.RetentionRunRunning)
+	return err
+}
+
+func (r *RetentionRepository) FindRunById(id int) (*models.RetentionRun, error) {
+	run := models.RetentionRun{}
+	err := r.DB.Get(&run, `SELECT * FROM retention_runs WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *RetentionRepository) FindRuns(limit int) ([]models.RetentionRun, error) {
+	runs := []models.RetentionRun{}
+	err := r.DB.Select(&runs, `SELECT * FROM retention_runs ORDER BY id DESC LIMIT $1`, limit)
+	return runs, err
+}
+
+func (r *RetentionRepository) SaveRunChat(entry *models.RetentionRunChat) error {
+	query := `
+		INSERT INTO retention_run_chats (
+			runId, chatId, days, maxMessages, deletedCount,
+			firstMessageId, lastMessageId, oldestCreatedAt, newestCreatedAt
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (runId, chatId) DO UPDATE SET
+			deletedCount = excluded.deletedCount,
+			lastMessageId = excluded.lastMessageId,
+			oldestCreatedAt = excluded.oldestCreatedAt,
+			newestCreatedAt = excluded.newestCreatedAt
+	`
+	_, err := r.DB.Exec(query, entry.RunId, entry.ChatId, entry.Days, entry.MaxMessages, entry.DeletedCount,
+		entry.FirstMessageId, entry.LastMessageId, entry.OldestCreatedAt, entry.NewestCreatedAt)
+	return err
+}
+
+func (r *RetentionRepository) FindRunChats(runId int) ([]models.RetentionRunChat, error) {
+	entries := []models.RetentionRunChat{}
+	query := `SELECT * FROM retention_run_chats WHERE runId = $1 ORDER BY chatId`
+	err := r.DB.Select(&entries, query, runId)
+	return entries, err
+}
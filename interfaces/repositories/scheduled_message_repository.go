@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type ScheduledMessageRepository struct {
+	DB *sqlx.DB
+
+	cipher *ContentCipher
+}
+
+func NewScheduledMessageRepository(db *sqlx.DB, cipher *ContentCipher) repositories.ScheduledMessageRepository {
+	return &ScheduledMessageRepository{DB: db, cipher: cipher}
+}
+
+func (r *ScheduledMessageRepository) Create(scheduled *models.ScheduledMessage) error {
+	content, err := r.cipher.sealValue(scheduled.ChatId, scheduled.Content, scheduledAAD(scheduled))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO scheduled_messages (chatId, senderId, content, scheduledAt, status, createdAt, updatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(
+		query,
+		scheduled.ChatId,
+		scheduled.SenderId,
+		content,
+		scheduled.ScheduledAt,
+		scheduled.Status,
+		scheduled.CreatedAt,
+		scheduled.UpdatedAt,
+	)
+	return row.Scan(&scheduled.ID)
+}
+
+func (r *ScheduledMessageRepository) FindById(id int) (*models.ScheduledMessage, error) {
+	scheduled := models.ScheduledMessage{}
+	query := `SELECT * FROM scheduled_messages WHERE id = $1`
+	err := r.DB.Get(&scheduled, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &scheduled, r.open(&scheduled)
+}
+
+// FindPendingBySenderId returns the pending messages of a sender, optionally limited to a chat
+func (r *ScheduledMessageRepository) FindPendingBySenderId(senderId int, chatId int) ([]models.ScheduledMessage, error) {
+	scheduled := []models.ScheduledMessage{}
+	query := `
+		SELECT * FROM scheduled_messages
+		WHERE senderId = $1 AND status = $2 AND ($3 = 0 OR chatId = $3)
+		ORDER BY scheduledAt ASC
+	`
+	if err := r.DB.Select(&scheduled, query, senderId, models.ScheduledStatusPending, chatId); err != nil {
+		return nil, err
+	}
+	return scheduled, r.openAll(scheduled)
+}
+
+func (r *ScheduledMessageRepository) UpdatePending(scheduled *models.ScheduledMessage) (bool, error) {
+	content, err := r.cipher.sealValue(scheduled.ChatId, scheduled.Content, scheduledAAD(scheduled))
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		UPDATE scheduled_messages
+		SET content = $1, scheduledAt = $2, updatedAt = $3
+		WHERE id = $4 AND status = $5
+	`
+	result, err := r.DB.Exec(
+		query,
+		content,
+		scheduled.ScheduledAt,
+		scheduled.UpdatedAt,
+		scheduled.ID,
+		models.ScheduledStatusPending,
+	)
+	return affected(result, err)
+}
+
+func (r *ScheduledMessageRepository) DeletePending(id int) (bool, error) {
+	query := `DELETE FROM scheduled_messages WHERE id = $1 AND status = $2`
+	result, err := r.DB.Exec(query, id, models.ScheduledStatusPending)
+	return affected(result, err)
+}
+
+func (r *ScheduledMessageRepository) FindDue(now time.Time, limit int) ([]models.ScheduledMessage, error) {
+	scheduled := []models.ScheduledMessage{}
+	query := `
+		SELECT * FROM scheduled_messages
+		WHERE status = $1 AND scheduledAt <= $2
+		ORDER BY scheduledAt ASC, id ASC
+		LIMIT $3
+	`
+	if err := r.DB.Select(&scheduled, query, models.ScheduledStatusPending, now, limit); err != nil {
+		return nil, err
+	}
+	return scheduled, r.openAll(scheduled)
+}
+
+func (r *ScheduledMessageRepository) Claim(id int) (bool, error) {
+	query := `UPDATE scheduled_messages SET status = $1, updatedAt = $2 WHERE id = $3 AND status = $4`
+	result, err := r.DB.Exec(query, models.ScheduledStatusDispatching, time.Now().UTC(), id, models.ScheduledStatusPending)
+	return affected(result, err)
+}
+
+func (r *ScheduledMessageRepository) MarkSent(id int, messageId *int) error {
+	query := `UPDATE scheduled_messages SET status = $1, messageId = $2, lastError = '', updatedAt = $3 WHERE id = $4`
+	_, err := r.DB.Exec(query, models.ScheduledStatusSent, messageId, time.Now().UTC(), id)
+	return err
+}
+
+func (r *ScheduledMessageRepository) MarkFailed(id int, reason string) error {
+	query := `UPDATE scheduled_messages SET status = $1, lastError = $2, updatedAt = $3 WHERE id = $4`
+	_, err := r.DB.Exec(query, models.ScheduledStatusFailed, reason, time.Now().UTC(), id)
+	return err
+}
+
+func (r *ScheduledMessageRepository) Release(id int) error {
+	query := `UPDATE scheduled_messages SET status = $1, updatedAt = $2 WHERE id = $3 AND status = $4`
+	_, err := r.DB.Exec(query, models.ScheduledStatusPending, time.Now().UTC(), id, models.ScheduledStatusDispatching)
+	return err
+}
+
+func (r *ScheduledMessageRepository) ReleaseDispatching() (int64, error) {
+	query := `UPDATE scheduled_messages SET status = $1, updatedAt = $2 WHERE status = $3`
+	result, err := r.DB.Exec(query, models.ScheduledStatusPending, time.Now().UTC(), models.ScheduledStatusDispatching)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *ScheduledMessageRepository) open(scheduled *models.ScheduledMessage) error {
+	content, err := r.cipher.openValue(scheduled.ChatId, scheduled.Content, scheduledAAD(scheduled))
+	if err != nil {
+		return err
+	}
+	scheduled.Content = content
+	return nil
+}
+
+func (r *ScheduledMessageRepository) openAll(scheduled []models.ScheduledMessage) error {
+	for i := range scheduled {
+		if err := r.open(&scheduled[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduledAAD binds the content to its sender and chat, which do not change
+// after it is scheduled, as the ID is only known once the row is stored
+func scheduledAAD(scheduled *models.ScheduledMessage) []byte {
+	return rowAAD("scheduled", scheduled.ChatId, scheduled.SenderId)
+}
@@ -15,8 +15,12 @@ func NewUserRepository(db *sqlx.DB) repositories.UserRepository {
 }
 
 func (r *UserRepository) Create(user *models.User) (*models.User, error) {
-	query := `INSERT INTO users (email, nickname, password) VALUES ($1, $2, $3) RETURNING id`
-	row := r.DB.QueryRow(query, user.Email, user.Nickname, user.Password)
+	query := `
+		INSERT INTO users (email, nickname, password, isBot, ownerId, apiTokenHash)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(query, user.Email, user.Nickname, user.Password, user.IsBot, user.OwnerId, user.APITokenHash)
 	err := row.Scan(&user.ID)
 	if err != nil {
 		return nil, err
@@ -67,9 +71,36 @@ func (r *UserRepository) Delete(id int) error {
 	return err
 }
 
-func (r *UserRepository) FindAllExcept(excludeUserId int) ([]models.User, error) {
+func (r *UserRepository) FindAllExcept(excludeUserId int, includeBots bool) ([]models.User, error) {
 	var users []models.User
-	query := `SELECT id, email, nickname, createdAt FROM users WHERE id != $1 ORDER BY createdAt DESC`
-	err := r.DB.Select(&users, query, excludeUserId)
+	query := `
+		SELECT id, email, nickname, createdAt, isBot, ownerId FROM users
+		WHERE id != $1 AND ($2 OR isBot = 0)
+		ORDER BY createdAt DESC
+	`
+	err := r.DB.Select(&users, query, excludeUserId, includeBots)
 	return users, err
 }
+
+func (r *UserRepository) FindBotsByOwnerId(ownerId int) ([]models.User, error) {
+	users := []models.User{}
+	query := `SELECT * FROM users WHERE isBot = 1 AND ownerId = $1 ORDER BY id ASC`
+	err := r.DB.Select(&users, query, ownerId)
+	return users, err
+}
+
+func (r *UserRepository) FindBotByTokenHash(tokenHash string) (*models.User, error) {
+	user := models.User{}
+	query := `SELECT * FROM users WHERE isBot = 1 AND apiTokenHash = $1`
+	err := r.DB.Get(&user, query, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) UpdateAPITokenHash(id int, tokenHash string) error {
+	query := `UPDATE users SET apiTokenHash = $1 WHERE id = $2 AND isBot = 1`
+	_, err := r.DB.Exec(query, tokenHash, id)
+	return err
+}
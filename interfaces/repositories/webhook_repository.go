@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/f1rstid/realtime-chat/domain/models"
+	"github.com/f1rstid/realtime-chat/domain/repositories"
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepository struct {
+	DB *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) repositories.WebhookRepository {
+	return &WebhookRepository{DB: db}
+}
+
+func (r *WebhookRepository) Create(webhook *models.IncomingWebhook) error {
+	query := `
+		INSERT INTO incoming_webhooks (chatId, name, tokenHash, createdBy, createdAt)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	row := r.DB.QueryRow(query, webhook.ChatId, webhook.Name, webhook.TokenHash, webhook.CreatedBy, webhook.CreatedAt)
+	return row.Scan(&webhook.ID)
+}
+
+func (r *WebhookRepository) FindById(id int) (*models.IncomingWebhook, error) {
+	webhook := models.IncomingWebhook{}
+	query := `SELECT * FROM incoming_webhooks WHERE id = $1`
+	err := r.DB.Get(&webhook, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) FindActiveByTokenHash(tokenHash string) (*models.IncomingWebhook, error) {
+	webhook := models.IncomingWebhook{}
+	query := `SELECT * FROM incoming_webhooks WHERE tokenHash = $1 AND revokedAt IS NULL`
+	err := r.DB.Get(&webhook, query, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) FindActiveByChatId(chatId int) ([]models.IncomingWebhook, error) {
+	webhooks := []models.IncomingWebhook{}
+	query := `
+		SELECT * FROM incoming_webhooks
+		WHERE chatId = $1 AND revokedAt IS NULL
+		ORDER BY id ASC
+	`
+	err := r.DB.Select(&webhooks, query, chatId)
+	return webhooks, err
+}
+
+func (r *WebhookRepository) Revoke(id int, at time.Time) (bool, error) {
+	query := `UPDATE incoming_webhooks SET revokedAt = $1 WHERE id = $2 AND revokedAt IS NULL`
+	return affected(r.DB.Exec(query, at, id))
+}
+
+func (r *WebhookRepository) TouchLastUsed(id int, at time.Time) error {
+	query := `UPDATE incoming_webhooks SET lastUsedAt = $1 WHERE id = $2`
+	_, err := r.DB.Exec(query, at, id)
+	return err
+}
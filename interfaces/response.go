@@ -2,6 +2,8 @@
 package interfaces
 
 import (
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -31,6 +33,8 @@ const (
 	StatusInvalidCredentials = 4006
 	StatusValidationError    = 4007
 	StatusInvalidToken       = 4008
+	StatusTooManyRequests    = 4009
+	StatusMessageRejected    = 4010
 
 	// Server error codes (5xxx)
 	StatusInternalError = 5000
@@ -76,17 +80,47 @@ func SendUnauthorized(c *fiber.Ctx) error {
 }
 
 func SendForbidden(c *fiber.Ctx) error {
-	return SendError(c, fiber.StatusForbidden, StatusForbidden, "접근 권한이 없습니다")
+	return SendError(c, fiber.StatusForbidden, StatusForbidden, ForbiddenMessage)
 }
 
 func SendNotFound(c *fiber.Ctx, resource string) error {
-	return SendError(c, fiber.StatusNotFound, StatusNotFound, resource+"를 찾을 수 없습니다")
+	return SendError(c, fiber.StatusNotFound, StatusNotFound, NotFoundMessage(resource))
 }
 
 func SendEmailExists(c *fiber.Ctx) error {
 	return SendError(c, fiber.StatusConflict, StatusEmailExists, "이미 사용중인 이메일입니다")
 }
 
+func SendTooManyRequests(c *fiber.Ctx) error {
+	return SendError(c, fiber.StatusTooManyRequests, StatusTooManyRequests, "요청이 너무 많습니다. 잠시 후 다시 시도해주세요")
+}
+
+// SendRateLimited reports a request over a rate limit and tells the client
+// in the Retry-After header how many seconds to wait
+func SendRateLimited(c *fiber.Ctx, retryAfter int, message string) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return SendError(c, fiber.StatusTooManyRequests, StatusTooManyRequests, message)
+}
+
+// SendMessageRejected reports a message refused by moderation with the filter's reason
+func SendMessageRejected(c *fiber.Ctx, reason string) error {
+	return SendError(c, fiber.StatusUnprocessableEntity, StatusMessageRejected, MessageRejectedMessage(reason))
+}
+
 func SendInternalError(c *fiber.Ctx) error {
-	return SendError(c, fiber.StatusInternalServerError, StatusInternalError, "내부 서버 오류가 발생했습니다")
+	return SendError(c, fiber.StatusInternalServerError, StatusInternalError, InternalErrorMessage)
+}
+
+// Error messages shared with the WebSocket error frames
+const (
+	ForbiddenMessage     = "접근 권한이 없습니다"
+	InternalErrorMessage = "내부 서버 오류가 발생했습니다"
+)
+
+func NotFoundMessage(resource string) string {
+	return resource + "를 찾을 수 없습니다"
+}
+
+func MessageRejectedMessage(reason string) string {
+	return "메시지를 보낼 수 없습니다: " + reason
 }
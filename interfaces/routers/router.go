@@ -1,10 +1,19 @@
 package routers
 
 import (
+	"log"
+
 	"github.com/f1rstid/realtime-chat/application/usecase"
 	"github.com/f1rstid/realtime-chat/config"
+	"github.com/f1rstid/realtime-chat/domain/models"
 	"github.com/f1rstid/realtime-chat/domain/services"
+	"github.com/f1rstid/realtime-chat/infrastructure/encryption"
+	"github.com/f1rstid/realtime-chat/infrastructure/export"
+	"github.com/f1rstid/realtime-chat/infrastructure/logger"
+	"github.com/f1rstid/realtime-chat/infrastructure/moderation"
 	"github.com/f1rstid/realtime-chat/infrastructure/sqlite"
+	"github.com/f1rstid/realtime-chat/infrastructure/unfurl"
+	"github.com/f1rstid/realtime-chat/infrastructure/webhook"
 	"github.com/f1rstid/realtime-chat/infrastructure/websocket"
 	"github.com/f1rstid/realtime-chat/interfaces/controllers"
 	"github.com/f1rstid/realtime-chat/interfaces/middlewares"
@@ -19,27 +28,117 @@ func SetRoutes(app *fiber.App, config *config.Config) {
 	wsHub := websocket.NewHub()
 	go wsHub.Run()
 
+	// Message content is encrypted at rest when a master key is configured
+	masterKey, err := encryption.LoadMasterKey(config.EncryptionKey, config.EncryptionKeyFile)
+	if err != nil {
+		logger.Error("Failed to load encryption key: %v", err)
+		log.Fatal(err)
+	}
+	contentCipher, err := repositories.NewContentCipher(sqlite.DB, masterKey)
+	if err != nil {
+		logger.Error("Failed to initialize message encryption: %v", err)
+		log.Fatal(err)
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(sqlite.DB)
-	chatRepo := repositories.NewChatRepository(sqlite.DB)
-	messageRepo := repositories.NewMessageRepository(sqlite.DB)
+	chatRepo := repositories.NewChatRepository(sqlite.DB, contentCipher)
+	messageRepo := repositories.NewMessageRepository(sqlite.DB, contentCipher)
+	pinRepo := repositories.NewPinRepository(sqlite.DB, contentCipher)
+	linkPreviewRepo := repositories.NewLinkPreviewRepository(sqlite.DB, contentCipher)
+	scheduledMessageRepo := repositories.NewScheduledMessageRepository(sqlite.DB, contentCipher)
+	receiptRepo := repositories.NewReceiptRepository(sqlite.DB)
+	pollRepo := repositories.NewPollRepository(sqlite.DB, contentCipher)
+	draftRepo := repositories.NewDraftRepository(sqlite.DB, contentCipher)
+	bookmarkRepo := repositories.NewBookmarkRepository(sqlite.DB)
+	webhookRepo := repositories.NewWebhookRepository(sqlite.DB)
+	outgoingWebhookRepo := repositories.NewOutgoingWebhookRepository(sqlite.DB, contentCipher)
+	moderationRepo := repositories.NewModerationRepository(sqlite.DB, contentCipher)
+	exportRepo := repositories.NewExportRepository(sqlite.DB)
+	reactionRepo := repositories.NewReactionRepository(sqlite.DB)
+	importRepo := repositories.NewImportRepository(sqlite.DB)
+	retentionRepo := repositories.NewRetentionRepository(sqlite.DB)
+	deviceRepo := repositories.NewDeviceRepository(sqlite.DB)
 
 	// Initialize services
 	authService := services.NewAuthService(config.JWTSecret)
 	userService := services.NewUserService(userRepo)
+	linkPreviewFetcher := unfurl.NewHTTPFetcher(unfurl.DefaultOptions())
+	webhookOptions := webhook.DefaultOptions()
+	webhookOptions.AllowPrivateNetworks = config.WebhookAllowPrivateNetworks
+	webhookSender := webhook.NewHTTPSender(webhookOptions)
+	moderationFilters, err := moderation.LoadFilters(config.ModerationConfigPath)
+	if err != nil {
+		logger.Error("Failed to load moderation config: %v", err)
+		log.Fatal(err)
+	}
+	retentionPolicy := models.RetentionPolicy{Days: config.RetentionDays, MaxMessages: config.RetentionMaxMessages}
+	if err := models.ValidateRetentionPolicy(retentionPolicy); err != nil {
+		logger.Error("Invalid message retention config: %v", err)
+		log.Fatal(err)
+	}
+	exportStore, err := export.NewFileStore(config.ExportDir)
+	if err != nil {
+		logger.Error("Failed to create export directory: %v", err)
+		log.Fatal(err)
+	}
 
 	// Initialize usecases
 	authUseCase := usecase.NewAuthUsecase(userRepo, authService)
-	chatUseCase := usecase.NewChatUsecase(chatRepo, messageRepo, userRepo)
-	messageUseCase := usecase.NewMessageUsecase(messageRepo, chatRepo, wsHub)
+	botUseCase := usecase.NewBotUsecase(userRepo)
+	linkPreviewUseCase := usecase.NewLinkPreviewUsecase(linkPreviewRepo, messageRepo, chatRepo, linkPreviewFetcher, wsHub)
+	go linkPreviewUseCase.Run()
+	receiptUseCase := usecase.NewReceiptUsecase(receiptRepo, chatRepo, wsHub)
+	wsHub.OnDelivered(receiptUseCase.RecordDelivery)
+	go receiptUseCase.Run()
+	pollUseCase := usecase.NewPollUsecase(pollRepo, messageRepo, chatRepo, wsHub)
+	commandUseCase := usecase.NewCommandUsecase()
+	outgoingWebhookUseCase := usecase.NewOutgoingWebhookUsecase(outgoingWebhookRepo, chatRepo, webhookSender)
+	go outgoingWebhookUseCase.Run()
+	moderationPipeline := usecase.NewModerationPipeline(moderationRepo, moderationFilters)
+	messageUseCase := usecase.NewMessageUsecase(messageRepo, chatRepo, pinRepo, reactionRepo, deviceRepo, linkPreviewUseCase, receiptUseCase, pollUseCase, commandUseCase, moderationPipeline, outgoingWebhookUseCase, wsHub)
+	chatUseCase := usecase.NewChatUsecase(chatRepo, messageRepo, userRepo, draftRepo, messageUseCase, outgoingWebhookUseCase, wsHub)
+	usecase.RegisterBuiltinCommands(commandUseCase, chatUseCase, chatRepo, userRepo)
+	pinUseCase := usecase.NewPinUsecase(pinRepo, messageRepo, chatRepo, messageUseCase, wsHub)
+	scheduledMessageUseCase := usecase.NewScheduledMessageUsecase(scheduledMessageRepo, chatRepo, messageUseCase)
+	go scheduledMessageUseCase.Run()
+	messageExpiryUseCase := usecase.NewMessageExpiryUsecase(messageRepo, chatRepo, wsHub)
+	go messageExpiryUseCase.Run()
+	draftUseCase := usecase.NewDraftUsecase(draftRepo, chatRepo, wsHub)
+	bookmarkUseCase := usecase.NewBookmarkUsecase(bookmarkRepo, messageRepo, chatRepo, wsHub)
+	go bookmarkUseCase.Run()
+	webhookUseCase := usecase.NewWebhookUsecase(webhookRepo, chatRepo, messageUseCase)
+	moderationUseCase := usecase.NewModerationUsecase(moderationRepo, chatRepo, messageUseCase)
+	exportUseCase := usecase.NewExportUsecase(exportRepo, chatRepo, messageRepo, exportStore)
+	go exportUseCase.Run()
+	importUseCase := usecase.NewImportUsecase(importRepo, userRepo, chatRepo, messageRepo, reactionRepo, config.ImportDir, config.AdminEmails)
+	go importUseCase.Run()
+	retentionUseCase := usecase.NewRetentionUsecase(retentionRepo, chatRepo, messageRepo, userRepo, wsHub, retentionPolicy, config.AdminEmails)
+	go retentionUseCase.Run()
+	deviceUseCase := usecase.NewDeviceUsecase(deviceRepo, chatRepo, userRepo, wsHub)
 	userUseCase := usecase.NewUserUseCase(userRepo, userService)
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(authUseCase)
+	botController := controllers.NewBotController(botUseCase)
 	chatController := controllers.NewChatController(chatUseCase, messageUseCase)
 	messageController := controllers.NewMessageController(messageUseCase)
-	wsController := controllers.NewWebSocketController(wsHub)
+	wsController := controllers.NewWebSocketController(wsHub, messageUseCase)
 	userController := controllers.NewUserController(userUseCase)
+	pinController := controllers.NewPinController(pinUseCase)
+	scheduledMessageController := controllers.NewScheduledMessageController(scheduledMessageUseCase)
+	receiptController := controllers.NewReceiptController(receiptUseCase)
+	pollController := controllers.NewPollController(messageUseCase, pollUseCase)
+	draftController := controllers.NewDraftController(draftUseCase)
+	bookmarkController := controllers.NewBookmarkController(bookmarkUseCase)
+	commandController := controllers.NewCommandController(commandUseCase)
+	webhookController := controllers.NewWebhookController(webhookUseCase)
+	outgoingWebhookController := controllers.NewOutgoingWebhookController(outgoingWebhookUseCase)
+	moderationController := controllers.NewModerationController(moderationUseCase)
+	exportController := controllers.NewExportController(exportUseCase)
+	importController := controllers.NewImportController(importUseCase)
+	retentionController := controllers.NewRetentionController(retentionUseCase)
+	deviceController := controllers.NewDeviceController(deviceUseCase)
 
 	// Health check route
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -54,29 +153,125 @@ func SetRoutes(app *fiber.App, config *config.Config) {
 	auth.Post("/register", authController.Register)
 	auth.Post("/login", authController.Login)
 
+	// Incoming webhooks authenticate with the token in their URL
+	app.Post(usecase.WebhookPath+":token", webhookController.ExecuteWebhook)
+
 	// Protected routes
-	api := app.Group("/api", middlewares.AuthMiddleware(authService))
+	api := app.Group("/api", middlewares.AuthMiddleware(authService, botUseCase))
 
 	// Chat routes
 	chats := api.Group("/chats")
 	chats.Get("/", chatController.GetUserChats)
 	chats.Post("/private", chatController.CreatePrivateChat)
 	chats.Post("/group", chatController.CreateGroupChat)
+	api.Put("/chats/:chatId", chatController.RenameChat)
+	api.Put("/chats/:chatId/disappearing", chatController.SetDisappearingMessages)
+	api.Put("/chats/:chatId/slow-mode", chatController.SetSlowMode)
+	api.Get("/chats/:chatId/retention", retentionController.GetChatRetention)
+	api.Put("/chats/:chatId/retention", chatController.SetRetention)
+	api.Post("/chats/:chatId/members", chatController.AddMembers)
+	api.Delete("/chats/:chatId/members/:userId", chatController.RemoveMember)
+	api.Put("/chats/:chatId/members/:userId/role", chatController.SetMemberRole)
 	api.Get("/chats/:chatId/messages", messageController.GetChatMessages)
+	api.Post("/chats/:chatId/read", receiptController.MarkRead)
+	api.Put("/chats/:chatId/draft", draftController.SaveDraft)
+	api.Post("/chats/:chatId/polls", pollController.CreatePoll)
+	api.Get("/chats/:chatId/pins", pinController.GetPinnedMessages)
+	api.Post("/chats/:chatId/pins", pinController.PinMessage)
+	api.Delete("/chats/:chatId/pins/:messageId", pinController.UnpinMessage)
+	api.Get("/chats/:chatId/webhooks", webhookController.GetWebhooks)
+	api.Post("/chats/:chatId/webhooks", webhookController.CreateWebhook)
+	api.Delete("/chats/:chatId/webhooks/:webhookId", webhookController.RevokeWebhook)
 
 	// Message routes
 	messages := api.Group("/messages")
 	messages.Post("/", messageController.SendMessage)
+	messages.Post("/forward", messageController.ForwardMessages)
+	messages.Get("/search", messageController.SearchMessages)
 	messages.Put("/:id", messageController.UpdateMessage)
 	messages.Delete("/:id", messageController.DeleteMessage)
 
+	// Command routes
+	api.Get("/commands", commandController.GetCommands)
+
+	// Poll routes
+	polls := api.Group("/polls")
+	polls.Get("/:pollId", pollController.GetPoll)
+	polls.Post("/:pollId/votes", pollController.Vote)
+	polls.Delete("/:pollId/votes", pollController.RetractVote)
+	polls.Post("/:pollId/close", pollController.ClosePoll)
+
+	// Bookmark routes
+	bookmarks := api.Group("/bookmarks")
+	bookmarks.Get("/", bookmarkController.GetBookmarks)
+	bookmarks.Post("/", bookmarkController.CreateBookmark)
+	bookmarks.Get("/:id", bookmarkController.GetBookmark)
+	bookmarks.Put("/:id", bookmarkController.UpdateBookmark)
+	bookmarks.Delete("/:id", bookmarkController.DeleteBookmark)
+
+	// Outgoing webhook routes
+	outgoingWebhooks := api.Group("/outgoing-webhooks")
+	outgoingWebhooks.Get("/", outgoingWebhookController.GetOutgoingWebhooks)
+	outgoingWebhooks.Post("/", outgoingWebhookController.CreateOutgoingWebhook)
+	outgoingWebhooks.Get("/:id", outgoingWebhookController.GetOutgoingWebhook)
+	outgoingWebhooks.Delete("/:id", outgoingWebhookController.DeleteOutgoingWebhook)
+	outgoingWebhooks.Get("/:id/deliveries", outgoingWebhookController.GetDeliveries)
+	outgoingWebhooks.Post("/:id/deliveries/:deliveryId/replay", outgoingWebhookController.ReplayDelivery)
+
+	// Moderation routes
+	moderationFlags := api.Group("/moderation/flags")
+	moderationFlags.Get("/", moderationController.GetFlags)
+	moderationFlags.Post("/:id/approve", moderationController.ApproveFlag)
+	moderationFlags.Post("/:id/remove", moderationController.RemoveFlaggedMessage)
+
+	// Export routes
+	api.Post("/chats/:chatId/exports", exportController.CreateExport)
+	api.Get("/chats/:chatId/exports", exportController.GetExports)
+	api.Get("/exports/:id", exportController.GetExport)
+	api.Get("/exports/:id/download", exportController.DownloadExport)
+
+	// Import routes
+	imports := api.Group("/imports")
+	imports.Post("/", importController.CreateImport)
+	imports.Get("/:id", importController.GetImport)
+	imports.Post("/:id/resume", importController.ResumeImport)
+
+	// Retention routes
+	api.Put("/chats/:chatId/legal-hold", retentionController.PlaceLegalHold)
+	api.Delete("/chats/:chatId/legal-hold", retentionController.ReleaseLegalHold)
+	api.Get("/retention/legal-holds", retentionController.GetLegalHolds)
+	api.Get("/retention/runs", retentionController.GetRetentionRuns)
+	api.Get("/retention/runs/:id", retentionController.GetRetentionRun)
+
+	// Device key routes
+	devices := api.Group("/devices")
+	devices.Get("/", deviceController.GetDevices)
+	devices.Post("/", deviceController.RegisterDevice)
+	devices.Post("/:id/prekeys", deviceController.AddPrekeys)
+	devices.Delete("/:id", deviceController.RemoveDevice)
+
+	// Scheduled message routes
+	scheduledMessages := api.Group("/scheduled-messages")
+	scheduledMessages.Get("/", scheduledMessageController.GetScheduledMessages)
+	scheduledMessages.Post("/", scheduledMessageController.CreateScheduledMessage)
+	scheduledMessages.Get("/:id", scheduledMessageController.GetScheduledMessage)
+	scheduledMessages.Put("/:id", scheduledMessageController.UpdateScheduledMessage)
+	scheduledMessages.Delete("/:id", scheduledMessageController.DeleteScheduledMessage)
+
+	// Bot routes
+	bots := api.Group("/bots")
+	bots.Get("/", botController.GetBots)
+	bots.Post("/", botController.CreateBot)
+	bots.Post("/:id/token", botController.RotateBotToken)
+
 	users := api.Group("/users")
 	users.Get("/", userController.GetAllUsers) // 새로운 라우트 추가
+	users.Get("/:userId/keys", deviceController.GetUserKeys)
 
 	// WebSocket routes with authentication
 	//app.Use("/ws", middlewares.WebSocketAuthMiddleware(authService))
 	//app.Use("/ws/:chatId", wsController.HandleWebSocket)
 	//app.Get("/ws/:chatId", ws.New(wsController.WebSocket))
-	app.Use("/ws", middlewares.WebSocketAuthMiddleware(authService))
+	app.Use("/ws", middlewares.WebSocketAuthMiddleware(authService, botUseCase))
 	app.Get("/ws", ws.New(wsController.WebSocket))
 }
@@ -70,6 +70,33 @@ func main() {
 	}
 	defer sqlite.CloseDB()
 
+	// Slack 내보내기 가져오기 명령
+	if len(os.Args) > 1 && os.Args[1] == importCommand {
+		if err := runImportCommand(config, os.Args[2:]); err != nil {
+			logger.Error("Failed to import: %v", err)
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// 마스터 암호화 키 교체 명령
+	if len(os.Args) > 1 && os.Args[1] == rotateKeysCommand {
+		if err := runRotateKeysCommand(config, os.Args[2:]); err != nil {
+			logger.Error("Failed to rotate encryption key: %v", err)
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// 기존 평문 메시지 암호화 명령
+	if len(os.Args) > 1 && os.Args[1] == encryptMessagesCommand {
+		if err := runEncryptMessagesCommand(config, os.Args[2:]); err != nil {
+			logger.Error("Failed to encrypt messages: %v", err)
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Fiber 앱 생성
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middlewares.ErrorHandler(),
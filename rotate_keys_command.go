@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/f1rstid/realtime-chat/config"
+	"github.com/f1rstid/realtime-chat/infrastructure/encryption"
+	"github.com/f1rstid/realtime-chat/infrastructure/sqlite"
+	"github.com/f1rstid/realtime-chat/interfaces/repositories"
+)
+
+// rotateKeysCommand is the subcommand replacing the master encryption key
+const rotateKeysCommand = "rotate-keys"
+
+// runRotateKeysCommand re-wraps the chat data keys with a new master key:
+//
+//	openssl rand -base64 32 > new.key
+//	realtime-chat rotate-keys -new-key-file new.key
+//
+// The current key is read from the config as usual. Run it while the server
+// is stopped, then start the server with the new key.
+func runRotateKeysCommand(config *config.Config, args []string) error {
+	flags := flag.NewFlagSet(rotateKeysCommand, flag.ExitOnError)
+	newKeyFile := flags.String("new-key-file", "", "file holding the new base64 master key")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s -new-key-file FILE\n", os.Args[0], rotateKeysCommand)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *newKeyFile == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("a new key file is required")
+	}
+
+	newKey, err := encryption.LoadMasterKey("", *newKeyFile)
+	if err != nil {
+		return err
+	}
+
+	contentCipher, err := newContentCipher(config)
+	if err != nil {
+		return err
+	}
+	count, err := contentCipher.RotateMasterKey(newKey)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Re-wrapped %d chat data keys; set ENCRYPTION_KEY_FILE=%s before starting the server\n", count, *newKeyFile)
+	return nil
+}
+
+// newContentCipher creates the message content cipher with the configured master key
+func newContentCipher(config *config.Config) (*repositories.ContentCipher, error) {
+	masterKey, err := encryption.LoadMasterKey(config.EncryptionKey, config.EncryptionKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return repositories.NewContentCipher(sqlite.DB, masterKey)
+}